@@ -2,11 +2,13 @@ package com.anterka.closeauthbackend.client.controller;
 
 import com.anterka.closeauthbackend.client.dto.request.*;
 import com.anterka.closeauthbackend.client.dto.response.ApplicationRoleResponse;
+import com.anterka.closeauthbackend.client.dto.response.OwnedClientResponse;
 import com.anterka.closeauthbackend.client.dto.response.RegistrationConfigResponse;
 import com.anterka.closeauthbackend.client.dto.response.ThemeConfigResponse;
 import com.anterka.closeauthbackend.client.dto.response.ThemeResponse;
 import com.anterka.closeauthbackend.client.service.ApplicationRegistrationConfigService;
 import com.anterka.closeauthbackend.client.service.ApplicationRoleService;
+import com.anterka.closeauthbackend.client.service.ClientOwnershipVerifier;
 import com.anterka.closeauthbackend.client.service.ClientThemeService;
 import com.anterka.closeauthbackend.client.service.ThemeConfigurationService;
 import com.anterka.closeauthbackend.common.dto.CustomApiResponse;
@@ -47,6 +49,7 @@ public class ClientConfigurationController {
     private final ClientThemeService themeService;
     private final ThemeConfigurationService themeConfigService;
     private final ClientIpResolver clientIpResolver;
+    private final ClientOwnershipVerifier clientOwnershipVerifier;
 
     /**
      * Builds the immutable per-request user/action context (user id + audit
@@ -59,6 +62,26 @@ public class ClientConfigurationController {
                 request.getHeader("User-Agent"));
     }
 
+    // ========================================
+    // OWNED CLIENTS
+    // ========================================
+
+    @GetMapping
+    @PreAuthorize("hasAuthority('SCOPE_client.create')")
+    public ResponseEntity<CustomApiResponse<List<OwnedClientResponse>>> getOwnedClients(HttpServletRequest request) {
+
+        Integer userId = UserContextHelper.getUserId(request);
+        log.info("Listing owned clients for user: {}", userId);
+        List<OwnedClientResponse> clients = clientOwnershipVerifier.ownedClients(userId);
+
+        return ResponseEntity.ok(CustomApiResponse.<List<OwnedClientResponse>>builder()
+                .timestamp(LocalDateTime.now())
+                .status(ResponseStatusEnum.SUCCESS)
+                .message("Owned clients retrieved successfully")
+                .data(clients)
+                .build());
+    }
+
     // ========================================
     // APPLICATION ROLES ENDPOINTS
     // ========================================
@@ -0,0 +1,21 @@
+package com.anterka.closeauthbackend.client.dto.response;
+
+import lombok.AllArgsConstructor;
+import lombok.Builder;
+import lombok.Data;
+import lombok.NoArgsConstructor;
+
+/**
+ * A client the authenticated admin owns. {@code id} is the value used in
+ * /api/v1/clients/{clientId}/** paths.
+ */
+@Data
+@Builder
+@NoArgsConstructor
+@AllArgsConstructor
+public class OwnedClientResponse {
+
+    private String id;
+    private String clientId;
+    private String clientName;
+}
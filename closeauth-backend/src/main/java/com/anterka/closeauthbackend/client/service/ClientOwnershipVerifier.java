@@ -1,11 +1,14 @@
 package com.anterka.closeauthbackend.client.service;
 
+import com.anterka.closeauthbackend.client.dto.response.OwnedClientResponse;
 import com.anterka.closeauthbackend.client.repository.ClientOwnershipRepository;
 import com.anterka.closeauthbackend.common.exception.ClientOwnershipException;
 import lombok.RequiredArgsConstructor;
 import lombok.extern.slf4j.Slf4j;
 import org.springframework.stereotype.Component;
 
+import java.util.List;
+
 /**
  * Shared component for verifying client ownership.
  * Works purely on the resolved user id so the service layer stays free of web types.
@@ -30,4 +33,21 @@ public class ClientOwnershipVerifier {
             throw new ClientOwnershipException("You do not have permission to modify this client");
         }
     }
+
+    /**
+     * Lists the clients owned by the given user (used by the BFF to enforce
+     * client ownership before proxying client-configuration requests).
+     *
+     * @param userId the authenticated admin user id
+     * @return owned clients, empty if none
+     */
+    public List<OwnedClientResponse> ownedClients(Integer userId) {
+        return clientOwnershipRepository.findByUser_Id(userId).stream()
+                .map(ownership -> OwnedClientResponse.builder()
+                        .id(ownership.getClient().getId())
+                        .clientId(ownership.getClient().getClientId())
+                        .clientName(ownership.getClient().getClientName())
+                        .build())
+                .toList();
+    }
 }
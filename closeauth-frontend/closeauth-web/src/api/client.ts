@@ -80,6 +80,12 @@ async function request<T>(path: string, init: RequestInit = {}): Promise<T> {
     throw new ApiError(503, 'Backend unavailable')
   }
 
+  // The BFF rotates the CSRF token on login/logout and announces it in this header
+  const rotatedCsrfToken = response.headers.get('X-CSRF-Token')
+  if (rotatedCsrfToken) {
+    csrfToken = rotatedCsrfToken
+  }
+
   // Try to parse JSON regardless of status so we can surface server messages
   const json = await response.json().catch(() => null)
 
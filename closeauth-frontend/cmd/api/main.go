@@ -1,35 +1,41 @@
 package main
 
 import (
+	"closeauth-frontend/internal/config"
 	"closeauth-frontend/internal/logger"
+	"closeauth-frontend/internal/tracing"
 	"context"
+	"flag"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"closeauth-frontend/internal/server"
 )
 
-func gracefulShutdown(apiServer *http.Server, done chan bool) {
-	// Create context that listens for the interrupt signal from the OS.
-	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
-	defer stop()
-
+// gracefulShutdown stops every listener (the API server first, then any
+// auxiliary ones such as the ops listener) once ctx is cancelled by SIGINT or
+// SIGTERM.
+func gracefulShutdown(ctx context.Context, done chan bool, servers ...*http.Server) {
 	// Listen for the interrupt signal.
 	<-ctx.Done()
 
 	log.Println("shutting down gracefully, press Ctrl+C again to force")
 
-	// The context is used to inform the server it has 5 seconds to finish
-	// the request it is currently handling
+	// The context is used to inform the servers they have 5 seconds to finish
+	// the requests they are currently handling
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
-	if err := apiServer.Shutdown(ctx); err != nil {
-		log.Printf("Server forced to shutdown with error: %v", err)
+	for _, srv := range servers {
+		if err := srv.Shutdown(ctx); err != nil {
+			log.Printf("Server forced to shutdown with error: %v", err)
+		}
 	}
 
 	log.Println("Server exiting")
@@ -38,23 +44,125 @@ func gracefulShutdown(apiServer *http.Server, done chan bool) {
 	done <- true
 }
 
+// overrideFlags collects repeated --set section.key=value flags.
+type overrideFlags map[string]string
+
+func (o overrideFlags) String() string {
+	return fmt.Sprint(map[string]string(o))
+}
+
+func (o overrideFlags) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok || strings.TrimSpace(key) == "" {
+		return fmt.Errorf("expected section.key=value, got %q", value)
+	}
+	o[strings.TrimSpace(key)] = val
+	return nil
+}
+
 func main() {
+	configFile := flag.String("config", config.DefaultFile(), "YAML or TOML config file (default $CONFIG_FILE)")
+	printConfig := flag.Bool("print-config", false, "print the effective configuration and the source of each value, then exit")
+	overrides := overrideFlags{}
+	flag.Var(overrides, "set", "override a setting, e.g. --set server.port=9090 (repeatable)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] [migrate up|down [N]|status]\n\nflags:\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	// Defaults < config file < environment < --set
+	loadOpts := config.Options{File: *configFile, Overrides: overrides}
+	cfg, err := config.Load(loadOpts)
+	if *printConfig {
+		cfg.Print(os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid configuration:\n%v\n", err)
+		os.Exit(1)
+	}
+	if *printConfig {
+		return
+	}
+
 	// Initialize structured logger before anything else
-	logger.Init(os.Getenv("LOG_LEVEL"), os.Getenv("LOG_FORMAT"))
-	server := server.NewServer()
+	logCfg := cfg.Logging
+	logger.Init(logCfg.Level, logCfg.Format, logger.RedactOptions{
+		ExtraKeys:  logCfg.RedactExtraKeys,
+		HashEmails: logCfg.HashEmails,
+	})
+	if err := logger.SetComponentLevels(logCfg.ComponentLevels); err != nil {
+		slog.Warn("invalid component log levels", "error", err)
+	}
+
+	// Subcommands run instead of the server
+	switch flag.Arg(0) {
+	case "":
+	case "migrate":
+		os.Exit(runMigrate(cfg.Database, flag.Args()[1:]))
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", flag.Arg(0))
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Tracing before the server so startup calls to Spring are traced too
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing)
+	if err != nil {
+		slog.Warn("failed to initialise tracing, tracing disabled", "error", err)
+		shutdownTracing = func(context.Context) error { return nil }
+	}
+
+	// Create context that listens for the interrupt signal from the OS; it
+	// also stops the server's background goroutines.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// SIGHUP and POST /api/admin/config/reload re-read the same file and flags
+	apiServer, ops, err := server.NewServer(ctx, cfg, func() (*config.Config, error) {
+		return config.Load(loadOpts)
+	})
+	if err != nil {
+		slog.Error("failed to start server", "error", err)
+		os.Exit(1)
+	}
 
 	// Create a done channel to signal when the shutdown is complete
 	done := make(chan bool, 1)
 
+	// Internal ops listener (readiness, metrics, pprof, diagnostics)
+	shutdownServers := []*http.Server{apiServer}
+	if ops != nil {
+		shutdownServers = append(shutdownServers, ops.Server)
+		go func() {
+			if err := ops.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				slog.Error("ops listener failed", "addr", ops.String(), "error", err)
+				os.Exit(1)
+			}
+		}()
+	}
+
 	// Run graceful shutdown in a separate goroutine
-	go gracefulShutdown(server, done)
+	go gracefulShutdown(ctx, done, shutdownServers...)
 
-	err := server.ListenAndServe()
+	if apiServer.TLSConfig != nil {
+		// Certificates come from TLSConfig.GetCertificate
+		err = apiServer.ListenAndServeTLS("", "")
+	} else {
+		err = apiServer.ListenAndServe()
+	}
 	if err != nil && err != http.ErrServerClosed {
 		panic(fmt.Sprintf("http server error: %s", err))
 	}
 
 	// Wait for the graceful shutdown to complete
 	<-done
+
+	// Flush buffered spans
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(ctx); err != nil {
+		log.Printf("Tracing shutdown error: %v", err)
+	}
 	log.Println("Graceful shutdown complete.")
 }
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"closeauth-frontend/internal/config"
+	"closeauth-frontend/internal/database"
+	"closeauth-frontend/internal/database/migrations"
+)
+
+const migrateUsage = `usage: main [flags] migrate <command>
+
+commands:
+  up          apply all pending migrations
+  down [N]    revert the last N migrations (default 1)
+  status      list migrations and whether they are applied`
+
+// runMigrate implements the migrate subcommand and returns the exit code.
+func runMigrate(cfg *config.DatabaseConfig, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	steps := 1
+	switch {
+	case args[0] != "up" && args[0] != "down" && args[0] != "status":
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	case args[0] == "down" && len(args) == 2:
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 {
+			fmt.Fprintf(os.Stderr, "migrate down: N must be a positive integer, got %q\n", args[1])
+			return 2
+		}
+		steps = n
+	case len(args) > 1:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	db, err := database.NewDatabase(cfg)
+	if err != nil {
+		slog.Error("migrate: database unavailable", "error", err)
+		return 1
+	}
+	defer db.Close()
+
+	migrator, err := migrations.New(db.DB, slog.Default())
+	if err != nil {
+		slog.Error("migrate: invalid embedded migrations", "error", err)
+		return 1
+	}
+
+	// Long enough to wait out another instance holding the migration lock
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Minute)
+	defer cancel()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		printMigrations(os.Stdout, "applied", applied)
+		if err != nil {
+			slog.Error("migrate up failed", "error", err)
+			return 1
+		}
+	case "down":
+		reverted, err := migrator.Down(ctx, steps)
+		printMigrations(os.Stdout, "reverted", reverted)
+		if err != nil {
+			slog.Error("migrate down failed", "error", err)
+			return 1
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			slog.Error("migrate status failed", "error", err)
+			return 1
+		}
+		printStatus(os.Stdout, statuses)
+	}
+	return 0
+}
+
+func printMigrations(w io.Writer, verb string, done []migrations.Migration) {
+	if len(done) == 0 {
+		fmt.Fprintf(w, "nothing %s\n", verb)
+		return
+	}
+	for _, m := range done {
+		fmt.Fprintf(w, "%s %s\n", verb, m.ID())
+	}
+}
+
+func printStatus(w io.Writer, statuses []migrations.Status) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, s := range statuses {
+		state, appliedAt := "pending", ""
+		if s.Applied {
+			state, appliedAt = "applied", s.AppliedAt.Format(time.RFC3339)
+		}
+		if s.Unknown {
+			state = "applied (unknown to this binary)"
+		}
+		fmt.Fprintf(tw, "%04d\t%s\t%s\t%s\n", s.Version, s.Name, state, appliedAt)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,133 @@
+package certreload
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeKeyPair writes a self-signed certificate for commonName and returns
+// the paths. mtime is set explicitly so rotations are detected reliably.
+func writeKeyPair(t *testing.T, dir, commonName string, mtime time.Time) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	for path, block := range map[string]*pem.Block{
+		certFile: {Type: "CERTIFICATE", Bytes: der},
+		keyFile:  {Type: "EC PRIVATE KEY", Bytes: keyDER},
+	} {
+		if err := os.WriteFile(path, pem.EncodeToMemory(block), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return certFile, keyFile
+}
+
+func commonName(t *testing.T, r *Reloader) string {
+	t.Helper()
+	cert, err := r.GetCertificate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert.Leaf.Subject.CommonName
+}
+
+func TestReloader_PicksUpRotation(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Now().Add(-time.Hour)
+	certFile, keyFile := writeKeyPair(t, dir, "first", start)
+
+	r, err := New(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if reloaded, err := r.Reload(); err != nil || reloaded {
+		t.Errorf("Reload() without changes = %v, %v; want false, nil", reloaded, err)
+	}
+
+	writeKeyPair(t, dir, "second", start.Add(time.Minute))
+	if reloaded, err := r.Reload(); err != nil || !reloaded {
+		t.Fatalf("Reload() after rotation = %v, %v; want true, nil", reloaded, err)
+	}
+	if got := commonName(t, r); got != "second" {
+		t.Errorf("serving certificate %q, want %q", got, "second")
+	}
+}
+
+func TestReloader_KeepsCurrentOnBadFiles(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeKeyPair(t, dir, "good", time.Now().Add(-time.Hour))
+
+	r, err := New(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := os.WriteFile(keyFile, []byte("not a key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Reload(); err == nil {
+		t.Error("Reload() should fail for a corrupt key")
+	}
+	if got := commonName(t, r); got != "good" {
+		t.Errorf("serving certificate %q after failed reload, want %q", got, "good")
+	}
+}
+
+func TestReloader_WatchStopsWithContext(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeKeyPair(t, dir, "first", time.Now().Add(-time.Hour))
+
+	r, err := New(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		r.Watch(ctx, time.Millisecond, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		close(stopped)
+	}()
+	cancel()
+
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Watch() still running after its context was cancelled")
+	}
+}
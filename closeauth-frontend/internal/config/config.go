@@ -0,0 +1,153 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+)
+
+// Config is the complete BFF configuration tree.
+type Config struct {
+	Server     *ServerConfig
+	TLS        *TLSConfig
+	Spring     *SpringConfig
+	Database   *DatabaseConfig
+	Middleware *MiddlewareConfig
+	Security   *SecurityConfig
+	Logging    *LoggingConfig
+	Metrics    *MetricsConfig
+	Tracing    *TracingConfig
+	Health     *HealthConfig
+	Ops        *OpsConfig
+	Themes     *ThemesConfig
+
+	settings []Setting
+}
+
+// Options controls where Load reads configuration from.
+type Options struct {
+	// File is an optional YAML or TOML config file
+	File string
+
+	// Overrides are "section.key" values from --set flags; they win over everything else
+	Overrides map[string]string
+
+	// LookupEnv reads environment variables; nil uses os.LookupEnv
+	LookupEnv func(string) (string, bool)
+}
+
+// Load builds the configuration from defaults, the config file, environment
+// variables and flag overrides, then validates every section.
+//
+// All problems are reported together in the returned error. The Config is
+// returned even when invalid so it can still be printed.
+func Load(opts Options) (*Config, error) {
+	var file map[string]string
+	var errs []error
+	if opts.File != "" {
+		var err error
+		if file, err = readConfigFile(opts.File); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	l := newLoader(file, opts.Overrides, opts.LookupEnv)
+	cfg := &Config{
+		Server:     loadServerConfig(l),
+		TLS:        loadTLSConfig(l),
+		Spring:     loadSpringConfig(l),
+		Database:   loadDatabaseConfig(l),
+		Middleware: loadMiddlewareConfig(l),
+		Security:   loadSecurityConfig(l),
+		Logging:    loadLoggingConfig(l),
+		Metrics:    loadMetricsConfig(l),
+		Tracing:    loadTracingConfig(l),
+		Health:     loadHealthConfig(l),
+		Ops:        loadOpsConfig(l),
+		Themes:     loadThemesConfig(l),
+	}
+	l.unknownKeys()
+	cfg.settings = l.settings
+
+	errs = append(errs, l.errs...)
+	if err := cfg.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	return cfg, errors.Join(errs...)
+}
+
+// Validate runs every section's Validate and joins the failures.
+func (c *Config) Validate() error {
+	sections := []struct {
+		name string
+		v    interface{ Validate() error }
+	}{
+		{"server", c.Server},
+		{"tls", c.TLS},
+		{"spring", c.Spring},
+		{"database", c.Database},
+		{"middleware", c.Middleware},
+		{"security", c.Security},
+		{"logging", c.Logging},
+		{"metrics", c.Metrics},
+		{"tracing", c.Tracing},
+		{"health", c.Health},
+		{"ops", c.Ops},
+		{"themes", c.Themes},
+	}
+
+	var errs []error
+	for _, section := range sections {
+		if err := section.v.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", section.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Settings returns every resolved setting with its source, in load order.
+func (c *Config) Settings() []Setting {
+	return c.settings
+}
+
+// Changed returns the keys whose effective value differs between c and other.
+func (c *Config) Changed(other *Config) []string {
+	previous := make(map[string]string, len(c.settings))
+	for _, s := range c.settings {
+		previous[s.Key] = s.Value
+	}
+
+	var changed []string
+	for _, s := range other.settings {
+		if v, ok := previous[s.Key]; !ok || v != s.Value {
+			changed = append(changed, s.Key)
+		}
+	}
+	return changed
+}
+
+// Print writes the effective configuration as a table of key, value and
+// source. Secrets are masked.
+func (c *Config) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tVALUE\tSOURCE")
+	for _, s := range c.settings {
+		value := s.Value
+		if s.Secret && value != "" {
+			value = "******"
+		}
+		source := s.Source
+		if source == SourceEnv {
+			source = fmt.Sprintf("env (%s)", s.Env)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", s.Key, value, source)
+	}
+	return tw.Flush()
+}
+
+// DefaultFile returns the config file named by CONFIG_FILE, if any.
+func DefaultFile() string {
+	return os.Getenv("CONFIG_FILE")
+}
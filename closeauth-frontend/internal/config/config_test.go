@@ -0,0 +1,283 @@
+package config
+
+import (
+	"bytes"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func envMap(vars map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := vars[key]
+		return v, ok
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func sourceOf(cfg *Config, key string) string {
+	for _, s := range cfg.Settings() {
+		if s.Key == key {
+			return s.Source
+		}
+	}
+	return ""
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	file := writeFile(t, "bff.yaml", `
+server:
+  port: 8081
+  read_timeout: 15s
+logging:
+  level: warn
+  redact_extra_keys: [phone, national_id]
+`)
+
+	cfg, err := Load(Options{
+		File:      file,
+		Overrides: map[string]string{"logging.level": "debug"},
+		LookupEnv: envMap(map[string]string{"PORT": "8082", "LOG_LEVEL": "error", "DB_HOST": ""}),
+	})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		key    string
+		got    any
+		want   any
+		source string
+	}{
+		{"server.port", cfg.Server.Port, 8082, SourceEnv},
+		{"server.read_timeout", cfg.Server.ReadTimeout, 15 * time.Second, SourceFile},
+		{"server.write_timeout", cfg.Server.WriteTimeout, 30 * time.Second, SourceDefault},
+		{"logging.level", cfg.Logging.Level, "debug", SourceFlag},
+		{"logging.redact_extra_keys", strings.Join(cfg.Logging.RedactExtraKeys, ","), "phone,national_id", SourceFile},
+		{"database.host", cfg.Database.Host, "localhost", SourceDefault}, // empty env counts as unset
+		{"metrics.enabled", cfg.Metrics.Enabled, false, SourceDefault},
+		{"ops.pprof", cfg.Ops.Pprof, false, SourceDefault},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.key, tt.got, tt.want)
+		}
+		if got := sourceOf(cfg, tt.key); got != tt.source {
+			t.Errorf("%s source = %q, want %q", tt.key, got, tt.source)
+		}
+	}
+}
+
+func TestLoad_TOML(t *testing.T) {
+	file := writeFile(t, "bff.toml", `
+[spring]
+server_url = "https://auth.example.com"
+
+[health]
+database_required = true
+`)
+
+	cfg, err := Load(Options{File: file, LookupEnv: envMap(nil)})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Spring.ServerURL != "https://auth.example.com" || !cfg.Health.DatabaseRequired {
+		t.Errorf("TOML values not applied: spring=%+v health=%+v", cfg.Spring, cfg.Health)
+	}
+}
+
+func TestLoad_AggregatesErrors(t *testing.T) {
+	file := writeFile(t, "bff.yaml", "server:\n  prot: 1\n")
+
+	cfg, err := Load(Options{
+		File: file,
+		LookupEnv: envMap(map[string]string{
+			"DB_PORT":              "five",
+			"LOG_FORMAT":           "xml",
+			"OTEL_TRACES_EXPORTER": "zipkin",
+			"TLS_CERT_FILE":        "/etc/bff/tls.crt",
+			"THEME_SOURCE":         "s3",
+		}),
+	})
+	if cfg == nil {
+		t.Fatal("Load() should return the config even when invalid")
+	}
+	if err == nil {
+		t.Fatal("Load() error = nil, want aggregated errors")
+	}
+
+	for _, want := range []string{
+		"server.prot: unknown file setting",
+		"DB_PORT: invalid integer",
+		"logging: log format must be text or json",
+		"tracing: trace exporter must be one of",
+		"tls: cert file and key file must be set together",
+		"themes: theme source must be spring or local",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
+
+func TestLoad_UnsupportedFile(t *testing.T) {
+	file := writeFile(t, "bff.ini", "port=1")
+	if _, err := Load(Options{File: file, LookupEnv: envMap(nil)}); err == nil || !strings.Contains(err.Error(), "unsupported format") {
+		t.Errorf("Load() error = %v, want unsupported format", err)
+	}
+}
+
+func TestPrint_RedactsSecrets(t *testing.T) {
+	cfg, err := Load(Options{LookupEnv: envMap(map[string]string{
+		"DB_PASSWORD":                  "hunter2",
+		"OAUTH_CONTEXT_ENCRYPTION_KEY": "0123456789abcdef0123456789abcdef",
+	})})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := cfg.Print(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	if strings.Contains(out, "hunter2") || strings.Contains(out, "0123456789abcdef") {
+		t.Errorf("Print() leaked a secret:\n%s", out)
+	}
+	if !strings.Contains(out, "env (DB_PASSWORD)") {
+		t.Errorf("Print() should name the env variable a value came from:\n%s", out)
+	}
+}
+
+func TestOpsConfig_Validate(t *testing.T) {
+	tests := []struct {
+		addr    string
+		wantErr bool
+	}{
+		{"", false},
+		{"127.0.0.1:9091", false},
+		{":9091", false},
+		{"unix:/run/closeauth/ops.sock", false},
+		{"unix:ops.sock", true},
+		{"localhost", true},
+	}
+	for _, tt := range tests {
+		err := (&OpsConfig{Addr: tt.addr}).Validate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%q) error = %v, wantErr %v", tt.addr, err, tt.wantErr)
+		}
+	}
+}
+
+func TestThemesConfig_Validate(t *testing.T) {
+	tests := []struct {
+		level, enforcement string
+		previewKey         string
+		previewTTL         time.Duration
+		wantErr            bool
+	}{
+		{ContrastAA, ContrastWarn, "", time.Hour, false},
+		{ContrastAAA, ContrastReject, "", time.Hour, false},
+		{ContrastAA, ContrastOff, "", time.Hour, false},
+		{"A", ContrastWarn, "", time.Hour, true},
+		{"aa", ContrastWarn, "", time.Hour, true},
+		{ContrastAA, "block", "", time.Hour, true},
+		{ContrastAA, ContrastWarn, strings.Repeat("k", 32), 7 * 24 * time.Hour, false},
+		{ContrastAA, ContrastWarn, "short", time.Hour, true},
+		{ContrastAA, ContrastWarn, "", time.Second, true},
+		{ContrastAA, ContrastWarn, "", 30 * 24 * time.Hour, true},
+	}
+	for _, tt := range tests {
+		cfg := &ThemesConfig{
+			Source:              ThemeSourceSpring,
+			ContrastLevel:       tt.level,
+			ContrastEnforcement: tt.enforcement,
+			PreviewSigningKey:   tt.previewKey,
+			PreviewTTL:          tt.previewTTL,
+			AssetStorage:        AssetStorageDatabase,
+			AssetMaxUploadBytes: 2 << 20,
+		}
+		if err := cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%s, %s, %d-byte key, %v) error = %v, wantErr %v", tt.level, tt.enforcement, len(tt.previewKey), tt.previewTTL, err, tt.wantErr)
+		}
+	}
+}
+
+func TestThemesConfig_ValidateAssets(t *testing.T) {
+	tests := []struct {
+		storage, dir string
+		maxUpload    int
+		wantErr      bool
+	}{
+		{AssetStorageDatabase, "", 2 << 20, false},
+		{AssetStorageFilesystem, "/var/lib/closeauth/assets", 2 << 20, false},
+		{AssetStorageFilesystem, "", 2 << 20, true},
+		{"s3", "", 2 << 20, true},
+		{AssetStorageDatabase, "", 100, true},
+		{AssetStorageDatabase, "", 64 << 20, true},
+	}
+	for _, tt := range tests {
+		cfg := &ThemesConfig{
+			Source:              ThemeSourceSpring,
+			ContrastLevel:       ContrastAA,
+			ContrastEnforcement: ContrastWarn,
+			PreviewTTL:          time.Hour,
+			AssetStorage:        tt.storage,
+			AssetDir:            tt.dir,
+			AssetMaxUploadBytes: tt.maxUpload,
+		}
+		if err := cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%s, %q, %d) error = %v, wantErr %v", tt.storage, tt.dir, tt.maxUpload, err, tt.wantErr)
+		}
+	}
+}
+
+func TestLoad_ComponentLevels(t *testing.T) {
+	env := map[string]string{"LOG_COMPONENT_LEVELS": "spring_client=debug, token_manager=warn"}
+	cfg, err := Load(Options{LookupEnv: envMap(env)})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := map[string]string{"spring_client": "debug", "token_manager": "warn"}
+	if !maps.Equal(cfg.Logging.ComponentLevels, want) {
+		t.Errorf("ComponentLevels = %v, want %v", cfg.Logging.ComponentLevels, want)
+	}
+
+	for _, bad := range []string{"spring_client", "spring_client=loud", "=debug"} {
+		_, err := Load(Options{LookupEnv: envMap(map[string]string{"LOG_COMPONENT_LEVELS": bad})})
+		if err == nil {
+			t.Errorf("LOG_COMPONENT_LEVELS=%q: Load() succeeded, want error", bad)
+		}
+	}
+}
+
+func TestLoad_SQLiteDriver(t *testing.T) {
+	env := map[string]string{"DB_DRIVER": "sqlite", "DB_PATH": "/var/lib/closeauth/bff.db", "DB_PORT": "0"}
+	cfg, err := Load(Options{LookupEnv: envMap(env)})
+	if err != nil {
+		t.Fatalf("Load() error = %v (the postgres port is irrelevant for sqlite)", err)
+	}
+	dsn := cfg.Database.ConnectionString()
+	if !strings.HasPrefix(dsn, "file:/var/lib/closeauth/bff.db?") || !strings.Contains(dsn, "_txlock=immediate") {
+		t.Errorf("ConnectionString() = %q", dsn)
+	}
+
+	if _, err := Load(Options{LookupEnv: envMap(map[string]string{"DB_DRIVER": "mysql"})}); err == nil {
+		t.Error("DB_DRIVER=mysql: Load() succeeded, want error")
+	}
+	if err := (&DatabaseConfig{Driver: DriverSQLite, PingInterval: time.Second, ReconnectMaxBackoff: time.Second}).Validate(); err == nil {
+		t.Error("Validate() with an empty sqlite path succeeded, want error")
+	}
+}
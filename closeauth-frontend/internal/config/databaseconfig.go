@@ -2,12 +2,24 @@ package config
 
 import (
 	"fmt"
-	"os"
-	"strconv"
+	"net/url"
 	"time"
 )
 
+// Supported database drivers.
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
 type DatabaseConfig struct {
+	// Driver selects the backend: postgres (default) or sqlite, an embedded
+	// single-file database for small self-hosted deployments
+	Driver string
+
+	// Path is the SQLite data file; the Postgres settings below are ignored
+	Path string
+
 	Host            string
 	Port            int
 	User            string
@@ -17,45 +29,96 @@ type DatabaseConfig struct {
 	MaxOpenConns    int
 	MaxIdleConns    int
 	ConnMaxLifetime time.Duration
-}
 
-func LoadDatabaseConfig() (*DatabaseConfig, error) {
-	port, err := strconv.Atoi(getEnv("DB_PORT", "5432"))
-	if err != nil {
-		return nil, fmt.Errorf("invalid DB_PORT: %w", err)
-	}
+	// PingInterval is how often a connected database is checked
+	PingInterval time.Duration
 
-	maxOpen, _ := strconv.Atoi(getEnv("DB_MAX_OPEN_CONNS", "25"))
-	maxIdle, _ := strconv.Atoi(getEnv("DB_MAX_IDLE_CONNS", "5"))
+	// ReconnectMaxBackoff caps the delay between reconnection attempts
+	ReconnectMaxBackoff time.Duration
 
-	lifetime, err := time.ParseDuration(getEnv("DB_CONN_MAX_LIFETIME", "5m"))
-	if err != nil {
-		lifetime = 5 * time.Minute
-	}
+	// AutoMigrate applies pending schema migrations at startup instead of
+	// refusing to start
+	AutoMigrate bool
+}
 
+// loadDatabaseConfig reads the database.* settings.
+func loadDatabaseConfig(l *loader) *DatabaseConfig {
 	return &DatabaseConfig{
-		Host:            getEnv("DB_HOST", "localhost"),
-		Port:            port,
-		User:            getEnv("DB_USER", "postgres"),
-		Password:        getEnv("DB_PASSWORD", ""),
-		DBName:          getEnv("DB_NAME", "closeauth_bff"),
-		SSLMode:         getEnv("DB_SSLMODE", "disable"),
-		MaxOpenConns:    maxOpen,
-		MaxIdleConns:    maxIdle,
-		ConnMaxLifetime: lifetime,
-	}, nil
+		Driver:              l.str("database.driver", "DB_DRIVER", DriverPostgres),
+		Path:                l.str("database.path", "DB_PATH", "closeauth-bff.db"),
+		Host:                l.str("database.host", "DB_HOST", "localhost"),
+		Port:                l.int("database.port", "DB_PORT", 5432),
+		User:                l.str("database.user", "DB_USER", "postgres"),
+		Password:            l.secret("database.password", "DB_PASSWORD", ""),
+		DBName:              l.str("database.name", "DB_NAME", "closeauth_bff"),
+		SSLMode:             l.str("database.sslmode", "DB_SSLMODE", "disable"),
+		MaxOpenConns:        l.int("database.max_open_conns", "DB_MAX_OPEN_CONNS", 25),
+		MaxIdleConns:        l.int("database.max_idle_conns", "DB_MAX_IDLE_CONNS", 5),
+		ConnMaxLifetime:     l.duration("database.conn_max_lifetime", "DB_CONN_MAX_LIFETIME", 5*time.Minute),
+		PingInterval:        l.duration("database.ping_interval", "DB_PING_INTERVAL", 15*time.Second),
+		ReconnectMaxBackoff: l.duration("database.reconnect_max_backoff", "DB_RECONNECT_MAX_BACKOFF", time.Minute),
+		AutoMigrate:         l.bool("database.auto_migrate", "DB_AUTO_MIGRATE", false),
+	}
+}
+
+// Validate checks if the database configuration is valid.
+func (c *DatabaseConfig) Validate() error {
+	switch c.Driver {
+	case DriverPostgres:
+		if c.Port <= 0 || c.Port > 65535 {
+			return fmt.Errorf("port must be between 1 and 65535, got %d", c.Port)
+		}
+	case DriverSQLite:
+		if c.Path == "" {
+			return fmt.Errorf("path is required for the sqlite driver")
+		}
+	default:
+		return fmt.Errorf("driver must be postgres or sqlite, got %q", c.Driver)
+	}
+	if c.MaxOpenConns < 0 || c.MaxIdleConns < 0 {
+		return fmt.Errorf("connection pool sizes must be non-negative, got open=%d idle=%d", c.MaxOpenConns, c.MaxIdleConns)
+	}
+	if c.ConnMaxLifetime < 0 {
+		return fmt.Errorf("connection max lifetime must be non-negative, got %v", c.ConnMaxLifetime)
+	}
+	if c.PingInterval <= 0 {
+		return fmt.Errorf("ping interval must be positive, got %v", c.PingInterval)
+	}
+	if c.ReconnectMaxBackoff < time.Second {
+		return fmt.Errorf("reconnect max backoff must be at least 1s, got %v", c.ReconnectMaxBackoff)
+	}
+	return nil
 }
 
+// ConnectionString returns the data source name for the configured driver.
 func (c *DatabaseConfig) ConnectionString() string {
+	if c.Driver == DriverSQLite {
+		return sqliteDSN(c.Path)
+	}
 	return fmt.Sprintf(
 		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
 		c.Host, c.Port, c.User, c.Password, c.DBName, c.SSLMode,
 	)
 }
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// sqliteDSN opens path with foreign keys enforced, WAL so readers never block
+// the writer, a busy timeout instead of immediate SQLITE_BUSY errors, and
+// immediate transactions so read-then-write transactions cannot deadlock.
+// Times are stored as "2006-01-02 15:04:05.999999999-07:00", which sorts
+// correctly as text.
+func sqliteDSN(path string) string {
+	params := url.Values{
+		"_pragma":      {"foreign_keys(1)", "journal_mode(WAL)", "busy_timeout(5000)"},
+		"_txlock":      {"immediate"},
+		"_time_format": {"sqlite"},
+	}
+	return "file:" + path + "?" + params.Encode()
+}
+
+// Target describes the database for logs, without credentials.
+func (c *DatabaseConfig) Target() string {
+	if c.Driver == DriverSQLite {
+		return "sqlite:" + c.Path
 	}
-	return defaultValue
+	return fmt.Sprintf("postgres:%s:%d/%s", c.Host, c.Port, c.DBName)
 }
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// HealthConfig holds liveness/readiness probe settings.
+type HealthConfig struct {
+	// CacheTTL is how long a readiness result is reused so frequent probes
+	// do not hammer Spring
+	CacheTTL time.Duration
+
+	// CheckTimeout bounds each individual dependency check
+	CheckTimeout time.Duration
+
+	// DatabaseRequired makes /readyz fail when the database is unavailable. When
+	// false the database is reported but never affects readiness.
+	DatabaseRequired bool
+}
+
+// loadHealthConfig reads the health.* settings.
+func loadHealthConfig(l *loader) *HealthConfig {
+	return &HealthConfig{
+		CacheTTL:         l.duration("health.cache_ttl", "HEALTH_CACHE_TTL", 5*time.Second),
+		CheckTimeout:     l.duration("health.check_timeout", "HEALTH_CHECK_TIMEOUT", 3*time.Second),
+		DatabaseRequired: l.bool("health.database_required", "DATABASE_REQUIRED", false),
+	}
+}
+
+// Validate checks if the health configuration is valid.
+func (c *HealthConfig) Validate() error {
+	if c.CacheTTL < 0 {
+		return fmt.Errorf("health cache TTL must be non-negative, got %v", c.CacheTTL)
+	}
+	if c.CheckTimeout <= 0 {
+		return fmt.Errorf("health check timeout must be positive, got %v", c.CheckTimeout)
+	}
+	return nil
+}
@@ -0,0 +1,258 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Sources a setting can come from, lowest precedence first.
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+)
+
+// Setting records the effective value of one configuration key and where it came from.
+type Setting struct {
+	Key    string
+	Env    string
+	Value  string
+	Source string
+	Secret bool
+}
+
+// loader resolves settings from defaults, an optional config file, environment
+// variables and --set flags (in increasing precedence). Malformed values are
+// collected instead of silently falling back to defaults.
+type loader struct {
+	file      map[string]string
+	flags     map[string]string
+	lookupEnv func(string) (string, bool)
+	used      map[string]bool
+	settings  []Setting
+	errs      []error
+}
+
+func newLoader(file, flags map[string]string, lookupEnv func(string) (string, bool)) *loader {
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
+	}
+	return &loader{
+		file:      file,
+		flags:     flags,
+		lookupEnv: lookupEnv,
+		used:      make(map[string]bool),
+	}
+}
+
+// raw returns the highest-precedence value for key. An empty environment
+// variable counts as unset, matching the previous env-only behaviour.
+func (l *loader) raw(key, env string) (value, source string, ok bool) {
+	l.used[key] = true
+	if v, ok := l.flags[key]; ok {
+		return v, SourceFlag, true
+	}
+	if env != "" {
+		if v, ok := l.lookupEnv(env); ok && v != "" {
+			return v, SourceEnv, true
+		}
+	}
+	if v, ok := l.file[key]; ok {
+		return v, SourceFile, true
+	}
+	return "", SourceDefault, false
+}
+
+func (l *loader) record(key, env, value, source string, secret bool) {
+	l.settings = append(l.settings, Setting{Key: key, Env: env, Value: value, Source: source, Secret: secret})
+}
+
+func (l *loader) fail(key, env, source, format string, args ...any) {
+	name := key
+	if source == SourceEnv {
+		name = env
+	}
+	l.errs = append(l.errs, fmt.Errorf("%s: %s", name, fmt.Sprintf(format, args...)))
+}
+
+func (l *loader) str(key, env, def string) string {
+	v, source, ok := l.raw(key, env)
+	if !ok {
+		v = def
+	}
+	l.record(key, env, v, source, false)
+	return v
+}
+
+// secret is str for values that must never be printed.
+func (l *loader) secret(key, env, def string) string {
+	v, source, ok := l.raw(key, env)
+	if !ok {
+		v = def
+	}
+	l.record(key, env, v, source, true)
+	return v
+}
+
+func (l *loader) int(key, env string, def int) int {
+	raw, source, ok := l.raw(key, env)
+	v := def
+	if ok {
+		parsed, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			l.fail(key, env, source, "invalid integer %q", raw)
+		} else {
+			v = parsed
+		}
+	}
+	l.record(key, env, strconv.Itoa(v), source, false)
+	return v
+}
+
+func (l *loader) float(key, env string, def float64) float64 {
+	raw, source, ok := l.raw(key, env)
+	v := def
+	if ok {
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			l.fail(key, env, source, "invalid number %q", raw)
+		} else {
+			v = parsed
+		}
+	}
+	l.record(key, env, strconv.FormatFloat(v, 'g', -1, 64), source, false)
+	return v
+}
+
+// bool accepts the values understood by strconv.ParseBool ("true", "1", "false", ...).
+func (l *loader) bool(key, env string, def bool) bool {
+	raw, source, ok := l.raw(key, env)
+	v := def
+	if ok {
+		parsed, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			l.fail(key, env, source, "invalid boolean %q", raw)
+		} else {
+			v = parsed
+		}
+	}
+	l.record(key, env, strconv.FormatBool(v), source, false)
+	return v
+}
+
+// duration supports both duration strings (e.g., "1m30s") and integer seconds.
+func (l *loader) duration(key, env string, def time.Duration) time.Duration {
+	raw, source, ok := l.raw(key, env)
+	v := def
+	if ok {
+		raw = strings.TrimSpace(raw)
+		if d, err := time.ParseDuration(raw); err == nil {
+			v = d
+		} else if seconds, err := strconv.Atoi(raw); err == nil {
+			v = time.Duration(seconds) * time.Second
+		} else {
+			l.fail(key, env, source, "invalid duration %q", raw)
+		}
+	}
+	l.record(key, env, v.String(), source, false)
+	return v
+}
+
+// list reads a comma-separated list. Blank entries are dropped.
+func (l *loader) list(key, env string) []string {
+	return l.listDefault(key, env, nil)
+}
+
+// listDefault is list with a default used when the setting is absent.
+func (l *loader) listDefault(key, env string, def []string) []string {
+	raw, source, ok := l.raw(key, env)
+	if !ok {
+		l.record(key, env, strings.Join(def, ","), source, false)
+		return def
+	}
+	var result []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	l.record(key, env, strings.Join(result, ","), source, false)
+	return result
+}
+
+// unknownKeys reports file and flag keys no section asked for, which are
+// almost always typos.
+func (l *loader) unknownKeys() {
+	for _, input := range []struct {
+		source string
+		values map[string]string
+	}{{SourceFile, l.file}, {SourceFlag, l.flags}} {
+		keys := make([]string, 0, len(input.values))
+		for key := range input.values {
+			if !l.used[key] {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			l.errs = append(l.errs, fmt.Errorf("%s: unknown %s setting", key, input.source))
+		}
+	}
+}
+
+// readConfigFile parses a YAML (.yaml, .yml) or TOML (.toml) file into
+// flattened "section.key" entries.
+func readConfigFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config file: %w", err)
+	}
+
+	tree := make(map[string]any)
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &tree)
+	case ".toml":
+		err = toml.Unmarshal(data, &tree)
+	default:
+		return nil, fmt.Errorf("config file %s: unsupported format %q (use .yaml, .yml or .toml)", path, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse config file %s: %w", path, err)
+	}
+
+	flat := make(map[string]string)
+	flatten("", tree, flat)
+	return flat, nil
+}
+
+func flatten(prefix string, value any, out map[string]string) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			flatten(key, child, out)
+		}
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = fmt.Sprint(item)
+		}
+		out[prefix] = strings.Join(items, ",")
+	case nil:
+		out[prefix] = ""
+	default:
+		out[prefix] = fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LoggingConfig holds structured logging settings.
+type LoggingConfig struct {
+	// Level is the minimum log level: debug, info, warn or error
+	Level string
+
+	// ComponentLevels override Level for loggers carrying a matching
+	// "component" or "handler" attribute, e.g. spring_client → debug
+	ComponentLevels map[string]string
+
+	// Format is the output format: text or json
+	Format string
+
+	// RedactExtraKeys are attribute keys masked in addition to the built-in
+	// sensitive keys (password, client_secret, access_token, otp, ...)
+	RedactExtraKeys []string
+
+	// HashEmails replaces email addresses in log output with a stable hash
+	HashEmails bool
+}
+
+// loadLoggingConfig reads the logging.* settings.
+//
+// logging.redact_extra_keys (LOG_REDACT_EXTRA_KEYS) is a comma-separated list,
+// e.g. "phone,national_id". logging.component_levels (LOG_COMPONENT_LEVELS)
+// is a comma-separated list of component=level pairs, e.g.
+// "spring_client=debug,token_manager=warn".
+func loadLoggingConfig(l *loader) *LoggingConfig {
+	return &LoggingConfig{
+		Level:           l.str("logging.level", "LOG_LEVEL", "info"),
+		ComponentLevels: componentLevels(l.list("logging.component_levels", "LOG_COMPONENT_LEVELS")),
+		Format:          l.str("logging.format", "LOG_FORMAT", "text"),
+		RedactExtraKeys: l.list("logging.redact_extra_keys", "LOG_REDACT_EXTRA_KEYS"),
+		HashEmails:      l.bool("logging.hash_emails", "LOG_HASH_EMAILS", false),
+	}
+}
+
+// componentLevels parses component=level pairs. A pair without "=" keeps an
+// empty level so Validate reports it.
+func componentLevels(pairs []string) map[string]string {
+	if len(pairs) == 0 {
+		return nil
+	}
+	levels := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		component, level, _ := strings.Cut(pair, "=")
+		levels[strings.TrimSpace(component)] = strings.TrimSpace(level)
+	}
+	return levels
+}
+
+// Validate checks if the logging configuration is valid.
+func (c *LoggingConfig) Validate() error {
+	if !validLogLevel(c.Level) {
+		return fmt.Errorf("log level must be one of debug, info, warn, error, got %q", c.Level)
+	}
+	for component, level := range c.ComponentLevels {
+		if component == "" {
+			return fmt.Errorf("component levels: missing component name in %q", "="+level)
+		}
+		if !validLogLevel(level) {
+			return fmt.Errorf("component levels: %s: log level must be one of debug, info, warn, error, got %q", component, level)
+		}
+	}
+	switch strings.ToLower(c.Format) {
+	case "text", "json":
+	default:
+		return fmt.Errorf("log format must be text or json, got %q", c.Format)
+	}
+	return nil
+}
+
+func validLogLevel(level string) bool {
+	switch strings.ToLower(level) {
+	case "debug", "info", "warn", "warning", "error":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// MetricsConfig holds Prometheus metrics endpoint settings.
+type MetricsConfig struct {
+	// Enabled turns the metrics endpoint on. Off by default: the metrics
+	// reveal OAuth outcomes and per-route traffic.
+	Enabled bool
+
+	// Addr is an optional separate listen address (e.g. ":9090") so metrics are
+	// not exposed on the public port. Empty serves metrics on the main server.
+	Addr string
+
+	// Path is the URL path of the metrics endpoint
+	Path string
+}
+
+// loadMetricsConfig reads the metrics.* settings.
+func loadMetricsConfig(l *loader) *MetricsConfig {
+	return &MetricsConfig{
+		Enabled: l.bool("metrics.enabled", "METRICS_ENABLED", false),
+		Addr:    l.str("metrics.addr", "METRICS_ADDR", ""),
+		Path:    l.str("metrics.path", "METRICS_PATH", "/metrics"),
+	}
+}
+
+// Validate checks if the metrics configuration is valid.
+func (c *MetricsConfig) Validate() error {
+	if !strings.HasPrefix(c.Path, "/") {
+		return fmt.Errorf("metrics path must start with '/', got %q", c.Path)
+	}
+	if c.Addr != "" {
+		if _, _, err := net.SplitHostPort(c.Addr); err != nil {
+			return fmt.Errorf("metrics address %q must be host:port: %w", c.Addr, err)
+		}
+	}
+	return nil
+}
@@ -17,17 +17,31 @@ type MiddlewareConfig struct {
 	// CSRFTokenLength is the length of CSRF tokens in bytes
 	CSRFTokenLength int
 
+	// CSRFTokenMaxAge is how long an issued CSRF token remains valid
+	CSRFTokenMaxAge time.Duration
+
+	// CSRFSigningKey is the HMAC key for CSRF tokens.
+	// Empty derives a key from EncryptionKey.
+	CSRFSigningKey string
+
+	// EncryptionKey is the AES-256 key for the session and oauth_context cookies.
+	// Empty uses an insecure development key.
+	EncryptionKey string
+
 	// SessionTimeout is the duration before a session expires
 	SessionTimeout time.Duration
 }
 
-// LoadMiddlewareConfig loads middleware configuration from environment variables with sensible defaults.
-func LoadMiddlewareConfig() *MiddlewareConfig {
+// loadMiddlewareConfig reads the middleware.* settings.
+func loadMiddlewareConfig(l *loader) *MiddlewareConfig {
 	return &MiddlewareConfig{
-		OAuthContextCookieMaxAge: getEnvInt("OAUTH_CONTEXT_COOKIE_MAX_AGE", 600), // 10 minutes
-		SessionCookieMaxAge:      getEnvInt("SESSION_COOKIE_MAX_AGE", 86400),     // 24 hours
-		CSRFTokenLength:          getEnvInt("CSRF_TOKEN_LENGTH", 32),
-		SessionTimeout:           getEnvDuration("SESSION_TIMEOUT", 24*time.Hour),
+		OAuthContextCookieMaxAge: l.int("middleware.oauth_context_cookie_max_age", "OAUTH_CONTEXT_COOKIE_MAX_AGE", 600), // 10 minutes
+		SessionCookieMaxAge:      l.int("middleware.session_cookie_max_age", "SESSION_COOKIE_MAX_AGE", 86400),           // 24 hours
+		CSRFTokenLength:          l.int("middleware.csrf_token_length", "CSRF_TOKEN_LENGTH", 32),
+		CSRFTokenMaxAge:          l.duration("middleware.csrf_token_max_age", "CSRF_TOKEN_MAX_AGE", 12*time.Hour),
+		CSRFSigningKey:           l.secret("middleware.csrf_signing_key", "CSRF_SIGNING_KEY", ""),
+		EncryptionKey:            l.secret("middleware.encryption_key", "OAUTH_CONTEXT_ENCRYPTION_KEY", ""),
+		SessionTimeout:           l.duration("middleware.session_timeout", "SESSION_TIMEOUT", 24*time.Hour),
 	}
 }
 
@@ -45,6 +59,12 @@ func (c *MiddlewareConfig) Validate() error {
 	if c.CSRFTokenLength < 16 {
 		return fmt.Errorf("CSRF token length must be at least 16 bytes, got %d", c.CSRFTokenLength)
 	}
+	if c.CSRFTokenMaxAge < time.Minute {
+		return fmt.Errorf("CSRF token max age must be at least 1 minute, got %v", c.CSRFTokenMaxAge)
+	}
+	if c.CSRFSigningKey != "" && len(c.CSRFSigningKey) < 32 {
+		return fmt.Errorf("CSRF signing key must be at least 32 bytes, got %d", len(c.CSRFSigningKey))
+	}
 	if c.SessionTimeout < time.Minute {
 		return fmt.Errorf("session timeout must be at least 1 minute, got %v", c.SessionTimeout)
 	}
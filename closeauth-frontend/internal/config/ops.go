@@ -0,0 +1,52 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"path/filepath"
+	"strings"
+)
+
+// OpsConfig holds the internal operations listener settings. It serves
+// readiness, metrics, pprof and diagnostics away from the public port.
+type OpsConfig struct {
+	// Addr is "host:port" or "unix:/path/to/socket". Empty disables the listener.
+	Addr string
+
+	// Pprof mounts net/http/pprof under /debug/pprof/ (off by default)
+	Pprof bool
+}
+
+// loadOpsConfig reads the ops.* settings.
+func loadOpsConfig(l *loader) *OpsConfig {
+	return &OpsConfig{
+		Addr:  l.str("ops.addr", "OPS_ADDR", ""),
+		Pprof: l.bool("ops.pprof", "OPS_PPROF", false),
+	}
+}
+
+// Network returns the listener network and address for Addr.
+func (c *OpsConfig) Network() (network, address string) {
+	if path, ok := strings.CutPrefix(c.Addr, "unix:"); ok {
+		return "unix", path
+	}
+	return "tcp", c.Addr
+}
+
+// Validate checks if the ops configuration is valid.
+func (c *OpsConfig) Validate() error {
+	if c.Addr == "" {
+		return nil
+	}
+	network, address := c.Network()
+	if network == "unix" {
+		if !filepath.IsAbs(address) {
+			return fmt.Errorf("ops socket path must be absolute, got %q", address)
+		}
+		return nil
+	}
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return fmt.Errorf("ops address %q must be host:port or unix:/path: %w", c.Addr, err)
+	}
+	return nil
+}
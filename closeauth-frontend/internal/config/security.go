@@ -0,0 +1,77 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SecurityConfig holds browser security header settings.
+type SecurityConfig struct {
+	// HSTSMaxAge is the Strict-Transport-Security max-age in seconds (production only)
+	HSTSMaxAge int
+
+	// EmbeddedLoginOrigins maps a client_id to the origins allowed to frame
+	// its hosted login/consent pages. Clients not listed cannot be framed.
+	EmbeddedLoginOrigins map[string][]string
+
+	// ClientAccessCacheTTL is how long an admin's accessible client IDs are cached
+	ClientAccessCacheTTL time.Duration
+
+	// CORSAllowedOrigins are the origins allowed to make credentialed
+	// cross-origin requests; "*" wildcards are supported (e.g. "https://*.example.com")
+	CORSAllowedOrigins []string
+}
+
+// loadSecurityConfig reads the security.* settings.
+//
+// security.embedded_login_clients (EMBEDDED_LOGIN_CLIENTS) uses the format
+// "client-a=https://a.example https://b.example;client-b=https://c.example".
+func loadSecurityConfig(l *loader) *SecurityConfig {
+	return &SecurityConfig{
+		HSTSMaxAge:           l.int("security.hsts_max_age", "HSTS_MAX_AGE", 31536000), // 1 year
+		EmbeddedLoginOrigins: parseEmbeddedLoginClients(l.str("security.embedded_login_clients", "EMBEDDED_LOGIN_CLIENTS", "")),
+		ClientAccessCacheTTL: l.duration("security.client_access_cache_ttl", "CLIENT_ACCESS_CACHE_TTL", 5*time.Minute),
+		CORSAllowedOrigins:   l.listDefault("security.cors_allowed_origins", "CORS_ALLOWED_ORIGINS", []string{"https://*", "http://*"}),
+	}
+}
+
+// Validate checks if the security configuration is valid.
+func (c *SecurityConfig) Validate() error {
+	if c.HSTSMaxAge < 0 {
+		return fmt.Errorf("HSTS max age must be non-negative, got %d", c.HSTSMaxAge)
+	}
+	if c.ClientAccessCacheTTL < 0 {
+		return fmt.Errorf("client access cache TTL must be non-negative, got %v", c.ClientAccessCacheTTL)
+	}
+	for _, origin := range c.CORSAllowedOrigins {
+		if !strings.HasPrefix(origin, "https://") && !strings.HasPrefix(origin, "http://") {
+			return fmt.Errorf("CORS origin %q must start with http:// or https://", origin)
+		}
+	}
+	for clientID, origins := range c.EmbeddedLoginOrigins {
+		for _, origin := range origins {
+			u, err := url.Parse(origin)
+			if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" || (u.Path != "" && u.Path != "/") {
+				return fmt.Errorf("embedded login origin %q for client %s must be a scheme://host[:port] origin", origin, clientID)
+			}
+		}
+	}
+	return nil
+}
+
+func parseEmbeddedLoginClients(raw string) map[string][]string {
+	result := make(map[string][]string)
+	for _, entry := range strings.Split(raw, ";") {
+		clientID, origins, ok := strings.Cut(entry, "=")
+		clientID = strings.TrimSpace(clientID)
+		if !ok || clientID == "" {
+			continue
+		}
+		for _, origin := range strings.Fields(origins) {
+			result[clientID] = append(result[clientID], strings.TrimRight(origin, "/"))
+		}
+	}
+	return result
+}
@@ -20,13 +20,13 @@ type ServerConfig struct {
 	Port int
 }
 
-// LoadServerConfig loads server configuration from environment variables with sensible defaults.
-func LoadServerConfig() *ServerConfig {
+// loadServerConfig reads the server.* settings.
+func loadServerConfig(l *loader) *ServerConfig {
 	return &ServerConfig{
-		IdleTimeout:  getEnvDuration("SERVER_IDLE_TIMEOUT", time.Minute),
-		ReadTimeout:  getEnvDuration("SERVER_READ_TIMEOUT", 10*time.Second),
-		WriteTimeout: getEnvDuration("SERVER_WRITE_TIMEOUT", 30*time.Second),
-		Port:         getEnvInt("PORT", 8080),
+		IdleTimeout:  l.duration("server.idle_timeout", "SERVER_IDLE_TIMEOUT", time.Minute),
+		ReadTimeout:  l.duration("server.read_timeout", "SERVER_READ_TIMEOUT", 10*time.Second),
+		WriteTimeout: l.duration("server.write_timeout", "SERVER_WRITE_TIMEOUT", 30*time.Second),
+		Port:         l.int("server.port", "PORT", 8080),
 	}
 }
 
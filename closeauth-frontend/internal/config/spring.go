@@ -0,0 +1,64 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// SpringConfig holds the connection settings for the Spring Authorization Server.
+// spring.NewConfig turns it into endpoint URLs.
+type SpringConfig struct {
+	// ServerURL is the base URL of the Spring Authorization Server (e.g., "http://localhost:9088")
+	ServerURL string
+
+	// ContextPath is appended to ServerURL (e.g., "/closeauth")
+	ContextPath string
+
+	// Client credentials for service-to-service auth (client_credentials grant)
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scope        string
+
+	// BFFBaseURL is the BFF's own base URL for building redirect URLs
+	BFFBaseURL string
+
+	// Environment controls production-only behaviour such as Secure cookies and HSTS
+	Environment string
+}
+
+// loadSpringConfig reads the spring.* settings.
+func loadSpringConfig(l *loader) *SpringConfig {
+	return &SpringConfig{
+		ServerURL:    l.str("spring.server_url", "OAUTH2_SERVER_URL", "http://localhost:9088"),
+		ContextPath:  l.str("spring.context_path", "OAUTH2_API_CONTEXT_PATH", "/closeauth"),
+		ClientID:     l.str("spring.client_id", "DEFAULT_CLIENT_ID", "test1"),
+		ClientSecret: l.secret("spring.client_secret", "DEFAULT_CLIENT_SECRET", "test1"),
+		RedirectURL:  l.str("spring.redirect_url", "DEFAULT_REDIRECT_URL", "http://127.0.0.1:8083/login/oauth2/code/public-client-react"),
+		Scope:        l.str("spring.scope", "DEFAULT_SCOPE", "client.create"),
+		BFFBaseURL:   l.str("spring.bff_base_url", "BFF_BASE_URL", "http://localhost:8080"),
+		Environment:  l.str("spring.environment", "ENVIRONMENT", "development"),
+	}
+}
+
+// Validate checks if the Spring configuration is valid.
+func (c *SpringConfig) Validate() error {
+	if !isHTTPURL(c.ServerURL) {
+		return fmt.Errorf("server URL must be an absolute http(s) URL, got %q", c.ServerURL)
+	}
+	if !isHTTPURL(c.BFFBaseURL) {
+		return fmt.Errorf("BFF base URL must be an absolute http(s) URL, got %q", c.BFFBaseURL)
+	}
+	if c.ClientID == "" || c.ClientSecret == "" {
+		return fmt.Errorf("client ID and client secret are required")
+	}
+	if c.Environment == "" {
+		return fmt.Errorf("environment is required")
+	}
+	return nil
+}
+
+func isHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// Where the admin theme API reads and writes themes.
+const (
+	ThemeSourceSpring = "spring"
+	ThemeSourceLocal  = "local"
+)
+
+// WCAG 2.1 conformance levels theme colours are linted against.
+const (
+	ContrastAA  = "AA"
+	ContrastAAA = "AAA"
+)
+
+// What a theme write below the contrast level does.
+const (
+	ContrastOff    = "off"
+	ContrastWarn   = "warn"
+	ContrastReject = "reject"
+)
+
+// Where uploaded theme assets (logos and backgrounds) are stored.
+const (
+	AssetStorageDatabase   = "database"
+	AssetStorageFilesystem = "filesystem"
+)
+
+// ThemesConfig holds settings for client login-page themes.
+type ThemesConfig struct {
+	// Source is spring to proxy theme edits to the authorization server, or
+	// local to keep them in the BFF database the hosted login page renders from
+	Source string
+
+	// ContrastLevel is AA (4.5:1) or AAA (7:1) for text contrast
+	ContrastLevel string
+
+	// ContrastEnforcement is off, warn (save and report the failing pairs) or
+	// reject (refuse the write)
+	ContrastEnforcement string
+
+	// PreviewSigningKey is the HMAC key for theme preview links.
+	// Empty derives a key from the cookie encryption key.
+	PreviewSigningKey string
+
+	// PreviewTTL is how long a theme preview link stays valid
+	PreviewTTL time.Duration
+
+	// AssetStorage is database to keep uploaded asset variants in the BFF
+	// database, or filesystem to keep them under AssetDir
+	AssetStorage string
+
+	// AssetDir is the directory asset variants are written to; shared by all
+	// replicas when more than one runs
+	AssetDir string
+
+	// AssetMaxUploadBytes bounds the size of one uploaded image
+	AssetMaxUploadBytes int
+}
+
+// loadThemesConfig reads the themes.* settings.
+func loadThemesConfig(l *loader) *ThemesConfig {
+	return &ThemesConfig{
+		Source:              l.str("themes.source", "THEME_SOURCE", ThemeSourceSpring),
+		ContrastLevel:       l.str("themes.contrast_level", "THEME_CONTRAST_LEVEL", ContrastAA),
+		ContrastEnforcement: l.str("themes.contrast_enforcement", "THEME_CONTRAST_ENFORCEMENT", ContrastWarn),
+		PreviewSigningKey:   l.secret("themes.preview_signing_key", "THEME_PREVIEW_SIGNING_KEY", ""),
+		PreviewTTL:          l.duration("themes.preview_ttl", "THEME_PREVIEW_TTL", time.Hour),
+		AssetStorage:        l.str("themes.asset_storage", "THEME_ASSET_STORAGE", AssetStorageDatabase),
+		AssetDir:            l.str("themes.asset_dir", "THEME_ASSET_DIR", ""),
+		AssetMaxUploadBytes: l.int("themes.asset_max_upload_bytes", "THEME_ASSET_MAX_UPLOAD_BYTES", 2<<20),
+	}
+}
+
+// Validate checks if the themes configuration is valid.
+func (c *ThemesConfig) Validate() error {
+	if c.Source != ThemeSourceSpring && c.Source != ThemeSourceLocal {
+		return fmt.Errorf("theme source must be spring or local, got %q", c.Source)
+	}
+	if c.ContrastLevel != ContrastAA && c.ContrastLevel != ContrastAAA {
+		return fmt.Errorf("theme contrast level must be AA or AAA, got %q", c.ContrastLevel)
+	}
+	switch c.ContrastEnforcement {
+	case ContrastOff, ContrastWarn, ContrastReject:
+	default:
+		return fmt.Errorf("theme contrast enforcement must be off, warn or reject, got %q", c.ContrastEnforcement)
+	}
+	if c.PreviewSigningKey != "" && len(c.PreviewSigningKey) < 32 {
+		return fmt.Errorf("theme preview signing key must be at least 32 bytes, got %d", len(c.PreviewSigningKey))
+	}
+	if c.PreviewTTL < time.Minute || c.PreviewTTL > 7*24*time.Hour {
+		return fmt.Errorf("theme preview TTL must be between 1 minute and 7 days, got %v", c.PreviewTTL)
+	}
+	switch c.AssetStorage {
+	case AssetStorageDatabase:
+	case AssetStorageFilesystem:
+		if c.AssetDir == "" {
+			return fmt.Errorf("theme asset dir is required when asset storage is filesystem")
+		}
+	default:
+		return fmt.Errorf("theme asset storage must be database or filesystem, got %q", c.AssetStorage)
+	}
+	if c.AssetMaxUploadBytes < 1<<10 || c.AssetMaxUploadBytes > 32<<20 {
+		return fmt.Errorf("theme asset max upload bytes must be between 1 KiB and 32 MiB, got %d", c.AssetMaxUploadBytes)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"time"
+)
+
+// TLSConfig holds native TLS serving settings. TLS is off unless a
+// certificate and key are configured, in which case a fronting proxy is not needed.
+type TLSConfig struct {
+	// CertFile and KeyFile are PEM files; both are re-read when they change on disk
+	CertFile string
+	KeyFile  string
+
+	// MinVersion is "1.2" or "1.3"
+	MinVersion string
+
+	// CipherSuites restricts TLS 1.2 cipher suites by Go name (e.g.
+	// "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"). Empty uses Go's secure defaults.
+	// TLS 1.3 suites are not configurable.
+	CipherSuites []string
+
+	// HTTP2 enables HTTP/2 over TLS
+	HTTP2 bool
+
+	// RedirectAddr is an optional plain HTTP listener (e.g. ":80") that
+	// redirects every request to HTTPS
+	RedirectAddr string
+
+	// ClientCAFile enables client-certificate verification for /api/admin:
+	// admin API requests must present a certificate signed by one of these CAs
+	ClientCAFile string
+
+	// ReloadInterval is how often the certificate files are checked for rotation
+	ReloadInterval time.Duration
+}
+
+// loadTLSConfig reads the tls.* settings.
+func loadTLSConfig(l *loader) *TLSConfig {
+	return &TLSConfig{
+		CertFile:       l.str("tls.cert_file", "TLS_CERT_FILE", ""),
+		KeyFile:        l.str("tls.key_file", "TLS_KEY_FILE", ""),
+		MinVersion:     l.str("tls.min_version", "TLS_MIN_VERSION", "1.2"),
+		CipherSuites:   l.list("tls.cipher_suites", "TLS_CIPHER_SUITES"),
+		HTTP2:          l.bool("tls.http2", "TLS_HTTP2", true),
+		RedirectAddr:   l.str("tls.redirect_addr", "TLS_REDIRECT_ADDR", ""),
+		ClientCAFile:   l.str("tls.client_ca_file", "TLS_CLIENT_CA_FILE", ""),
+		ReloadInterval: l.duration("tls.reload_interval", "TLS_RELOAD_INTERVAL", time.Minute),
+	}
+}
+
+// Enabled reports whether the BFF terminates TLS itself.
+func (c *TLSConfig) Enabled() bool {
+	return c.CertFile != ""
+}
+
+// TLSMinVersion returns the crypto/tls constant for MinVersion.
+func (c *TLSConfig) TLSMinVersion() uint16 {
+	if c.MinVersion == "1.3" {
+		return tls.VersionTLS13
+	}
+	return tls.VersionTLS12
+}
+
+// CipherSuiteIDs resolves CipherSuites to crypto/tls IDs. Unknown and
+// insecure suites are rejected by Validate.
+func (c *TLSConfig) CipherSuiteIDs() []uint16 {
+	byName := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		byName[suite.Name] = suite.ID
+	}
+
+	var ids []uint16
+	for _, name := range c.CipherSuites {
+		if id, ok := byName[name]; ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// Validate checks if the TLS configuration is valid.
+func (c *TLSConfig) Validate() error {
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return fmt.Errorf("cert file and key file must be set together")
+	}
+	if !c.Enabled() && (c.RedirectAddr != "" || c.ClientCAFile != "") {
+		return fmt.Errorf("redirect listener and client certificates require a cert file and key file")
+	}
+	if c.MinVersion != "1.2" && c.MinVersion != "1.3" {
+		return fmt.Errorf("min version must be 1.2 or 1.3, got %q", c.MinVersion)
+	}
+	if len(c.CipherSuites) > 0 && len(c.CipherSuiteIDs()) != len(c.CipherSuites) {
+		return fmt.Errorf("cipher suites must be names from crypto/tls.CipherSuites(), got %v", c.CipherSuites)
+	}
+	if c.RedirectAddr != "" {
+		if _, _, err := net.SplitHostPort(c.RedirectAddr); err != nil {
+			return fmt.Errorf("redirect address %q must be host:port: %w", c.RedirectAddr, err)
+		}
+	}
+	if c.ReloadInterval <= 0 {
+		return fmt.Errorf("reload interval must be positive, got %v", c.ReloadInterval)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Trace exporters supported by TracingConfig.Exporter.
+const (
+	TraceExporterNone   = "none"
+	TraceExporterStdout = "stdout"
+	TraceExporterOTLP   = "otlp"
+)
+
+// TracingConfig holds OpenTelemetry tracing settings. Variable names follow the
+// OpenTelemetry conventions; the OTLP exporter additionally honours the standard
+// OTEL_EXPORTER_OTLP_* variables (headers, TLS, timeout).
+type TracingConfig struct {
+	// Exporter is none, stdout or otlp
+	Exporter string
+
+	// OTLPEndpoint is the OTLP/HTTP collector URL (e.g. "http://otel-collector:4318")
+	OTLPEndpoint string
+
+	// ServiceName is reported as the service.name resource attribute
+	ServiceName string
+
+	// SampleRatio is the fraction of new traces sampled (0..1); sampled parents are always honoured
+	SampleRatio float64
+}
+
+// loadTracingConfig reads the tracing.* settings.
+func loadTracingConfig(l *loader) *TracingConfig {
+	return &TracingConfig{
+		Exporter:     strings.ToLower(l.str("tracing.exporter", "OTEL_TRACES_EXPORTER", TraceExporterNone)),
+		OTLPEndpoint: l.str("tracing.otlp_endpoint", "OTEL_EXPORTER_OTLP_ENDPOINT", ""),
+		ServiceName:  l.str("tracing.service_name", "OTEL_SERVICE_NAME", "closeauth-frontend"),
+		SampleRatio:  l.float("tracing.sample_ratio", "OTEL_TRACES_SAMPLER_ARG", 1.0),
+	}
+}
+
+// Validate checks if the tracing configuration is valid.
+func (c *TracingConfig) Validate() error {
+	switch c.Exporter {
+	case TraceExporterNone, TraceExporterStdout, TraceExporterOTLP:
+	default:
+		return fmt.Errorf("trace exporter must be one of none, stdout, otlp, got %q", c.Exporter)
+	}
+	if c.SampleRatio < 0 || c.SampleRatio > 1 {
+		return fmt.Errorf("trace sample ratio must be between 0 and 1, got %v", c.SampleRatio)
+	}
+	if c.ServiceName == "" {
+		return fmt.Errorf("service name is required")
+	}
+	return nil
+}
@@ -7,6 +7,12 @@ const (
 	RouteOAuthConsent   = "/closeauth/oauth2/consent"
 )
 
+// Liveness / readiness probes
+const (
+	RouteHealthz = "/healthz"
+	RouteReadyz  = "/readyz"
+)
+
 // Public API routes
 const (
 	RouteAPICSRF   = "/api/csrf"
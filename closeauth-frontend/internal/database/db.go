@@ -8,6 +8,7 @@ import (
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
 
 	"closeauth-frontend/internal/config"
 )
@@ -17,7 +18,12 @@ type Database struct {
 }
 
 func NewDatabase(cfg *config.DatabaseConfig) (*Database, error) {
-	db, err := sqlx.Connect("postgres", cfg.ConnectionString())
+	return Connect(context.Background(), cfg)
+}
+
+// Connect opens the pool and verifies it, giving up when ctx ends.
+func Connect(ctx context.Context, cfg *config.DatabaseConfig) (*Database, error) {
+	db, err := sqlx.ConnectContext(ctx, cfg.Driver, cfg.ConnectionString())
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
@@ -28,15 +34,22 @@ func NewDatabase(cfg *config.DatabaseConfig) (*Database, error) {
 	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
 
 	// Verify connection
-	if err := db.Ping(); err != nil {
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	log.Printf("Database connection established: %s:%d/%s", cfg.Host, cfg.Port, cfg.DBName)
+	log.Printf("Database connection established: %s", cfg.Target())
 
 	return &Database{db}, nil
 }
 
+// SQLite reports whether this is the embedded SQLite backend, for the few
+// statements that differ from Postgres.
+func (db *Database) SQLite() bool {
+	return db.DriverName() == config.DriverSQLite
+}
+
 func (db *Database) Close() error {
 	log.Println("Closing database connection...")
 	return db.DB.Close()
@@ -0,0 +1,457 @@
+// Package migrations owns the BFF database schema. Versioned SQL files are
+// embedded in the binary, one directory per driver (sql/postgres, sql/sqlite),
+// and applied in order by a Migrator, which records them in schema_migrations
+// and holds a row in schema_migrations_lock while it runs so concurrent
+// instances never migrate at the same time.
+package migrations
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"closeauth-frontend/internal/config"
+)
+
+//go:embed sql
+var files embed.FS
+
+// ErrSchemaBehind is returned by Check when migrations are pending.
+var ErrSchemaBehind = errors.New("database schema is behind")
+
+const (
+	// lockPollInterval is how often a waiting Migrator retries the lock
+	lockPollInterval = time.Second
+
+	// staleLockAfter is when a lock whose holder stopped heartbeating is taken over
+	staleLockAfter = 10 * time.Minute
+)
+
+// dialect is the driver-specific SQL the Migrator uses for its own bookkeeping.
+type dialect struct {
+	bootstrap   string
+	tableExists string // reports whether schema_migrations exists
+	clearStale  string // $1: staleLockAfter in seconds
+	acquire     string // $1: owner
+	heartbeat   string // $1: owner
+}
+
+var dialects = map[string]dialect{
+	config.DriverPostgres: {
+		bootstrap: `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    version     BIGINT PRIMARY KEY,
+    name        VARCHAR(255) NOT NULL,
+    applied_at  TIMESTAMPTZ  NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS schema_migrations_lock (
+    id          INTEGER PRIMARY KEY CHECK (id = 1),
+    locked_by   VARCHAR(255) NOT NULL,
+    locked_at   TIMESTAMPTZ  NOT NULL
+);
+`,
+		tableExists: `SELECT to_regclass('schema_migrations') IS NOT NULL`,
+		clearStale:  `DELETE FROM schema_migrations_lock WHERE locked_at < now() - make_interval(secs => $1)`,
+		acquire:     `INSERT INTO schema_migrations_lock (id, locked_by, locked_at) VALUES (1, $1, now()) ON CONFLICT (id) DO NOTHING`,
+		heartbeat:   `UPDATE schema_migrations_lock SET locked_at = now() WHERE id = 1 AND locked_by = $1`,
+	},
+	config.DriverSQLite: {
+		bootstrap: `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    version     INTEGER PRIMARY KEY,
+    name        VARCHAR(255) NOT NULL,
+    applied_at  TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS schema_migrations_lock (
+    id          INTEGER PRIMARY KEY CHECK (id = 1),
+    locked_by   VARCHAR(255) NOT NULL,
+    locked_at   TIMESTAMP    NOT NULL
+);
+`,
+		tableExists: `SELECT EXISTS (SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations')`,
+		clearStale:  `DELETE FROM schema_migrations_lock WHERE locked_at < datetime('now', '-' || $1 || ' seconds')`,
+		acquire:     `INSERT INTO schema_migrations_lock (id, locked_by, locked_at) VALUES (1, $1, CURRENT_TIMESTAMP) ON CONFLICT (id) DO NOTHING`,
+		heartbeat:   `UPDATE schema_migrations_lock SET locked_at = CURRENT_TIMESTAMP WHERE id = 1 AND locked_by = $1`,
+	},
+}
+
+// fileName matches "0001_create_things.up.sql" and "0001_create_things.down.sql".
+var fileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration is one schema version with its forward and rollback SQL.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// ID returns the migration's file prefix, e.g. "0002_admin_audit_log".
+func (m Migration) ID() string {
+	return fmt.Sprintf("%04d_%s", m.Version, m.Name)
+}
+
+// Status describes one migration known to the binary or the database.
+type Status struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+
+	// Unknown is set for versions recorded in the database but missing from
+	// this binary, i.e. applied by a newer release.
+	Unknown bool
+}
+
+type appliedMigration struct {
+	Version   int       `db:"version"`
+	Name      string    `db:"name"`
+	AppliedAt time.Time `db:"applied_at"`
+}
+
+// Load returns the embedded migrations for driver in version order.
+func Load(driver string) ([]Migration, error) {
+	if _, ok := dialects[driver]; !ok {
+		return nil, fmt.Errorf("no migrations for database driver %q", driver)
+	}
+	return parse(files, path.Join("sql", driver))
+}
+
+// parse reads paired up/down files from dir. Every version needs both.
+func parse(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration %s: name must look like 0001_description.up.sql", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		body, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("read migration %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d is used by both %q and %q", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if strings.TrimSpace(m.Up) == "" || strings.TrimSpace(m.Down) == "" {
+			return nil, fmt.Errorf("migration %s needs non-empty .up.sql and .down.sql files", m.ID())
+		}
+		migrations = append(migrations, *m)
+	}
+	slices.SortFunc(migrations, func(a, b Migration) int { return a.Version - b.Version })
+	return migrations, nil
+}
+
+// Migrator applies and reverts the embedded migrations.
+type Migrator struct {
+	db         *sqlx.DB
+	dialect    dialect
+	migrations []Migration
+	owner      string
+	logger     *slog.Logger
+}
+
+// New creates a Migrator for the embedded migrations of db's driver.
+func New(db *sqlx.DB, logger *slog.Logger) (*Migrator, error) {
+	migrations, err := Load(db.DriverName())
+	if err != nil {
+		return nil, err
+	}
+	host, _ := os.Hostname()
+	return &Migrator{
+		db:         db,
+		dialect:    dialects[db.DriverName()],
+		migrations: migrations,
+		owner:      fmt.Sprintf("%s:%d:%d", host, os.Getpid(), time.Now().UnixNano()),
+		logger:     logger.With("component", "migrations"),
+	}, nil
+}
+
+// Up applies every pending migration, each in its own transaction, and
+// returns the ones it applied.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	release, err := m.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, migration := range pending(m.migrations, applied) {
+		start := time.Now()
+		err := m.inTx(ctx, migration.Up,
+			`INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, migration.Version, migration.Name)
+		if err != nil {
+			return done, fmt.Errorf("migration %s failed: %w", migration.ID(), err)
+		}
+		m.logger.Info("migration applied", "migration", migration.ID(), "duration_ms", time.Since(start).Milliseconds())
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Down reverts the most recent steps migrations, newest first.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	release, err := m.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := revertPlan(m.migrations, applied, steps)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, migration := range plan {
+		err := m.inTx(ctx, migration.Down,
+			`DELETE FROM schema_migrations WHERE version = $1`, migration.Version)
+		if err != nil {
+			return done, fmt.Errorf("reverting migration %s failed: %w", migration.ID(), err)
+		}
+		m.logger.Info("migration reverted", "migration", migration.ID())
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Status lists every migration known to the binary or the database, by version.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]appliedMigration, len(applied))
+	for _, a := range applied {
+		byVersion[a.Version] = a
+	}
+
+	var statuses []Status
+	for _, migration := range m.migrations {
+		a, ok := byVersion[migration.Version]
+		statuses = append(statuses, Status{Version: migration.Version, Name: migration.Name, Applied: ok, AppliedAt: a.AppliedAt})
+		delete(byVersion, migration.Version)
+	}
+	for _, a := range byVersion {
+		statuses = append(statuses, Status{Version: a.Version, Name: a.Name, Applied: true, AppliedAt: a.AppliedAt, Unknown: true})
+	}
+	slices.SortFunc(statuses, func(a, b Status) int { return a.Version - b.Version })
+	return statuses, nil
+}
+
+// Check returns an error wrapping ErrSchemaBehind when migrations are pending.
+// It only reads, so it works for a database user without DDL rights.
+func (m *Migrator) Check(ctx context.Context) error {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+	if todo := pending(m.migrations, applied); len(todo) > 0 {
+		ids := make([]string, len(todo))
+		for i, migration := range todo {
+			ids[i] = migration.ID()
+		}
+		return fmt.Errorf("%w: %d pending migration(s) (%s); run \"migrate up\" or set DB_AUTO_MIGRATE=true",
+			ErrSchemaBehind, len(todo), strings.Join(ids, ", "))
+	}
+	return nil
+}
+
+// applied returns the recorded migrations, or none when schema_migrations
+// does not exist yet.
+func (m *Migrator) applied(ctx context.Context) ([]appliedMigration, error) {
+	var exists bool
+	if err := m.db.GetContext(ctx, &exists, m.dialect.tableExists); err != nil {
+		return nil, fmt.Errorf("failed to look up schema_migrations: %w", err)
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	var applied []appliedMigration
+	err := m.db.SelectContext(ctx, &applied, `SELECT version, name, applied_at FROM schema_migrations ORDER BY version`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	return applied, nil
+}
+
+// inTx runs a migration script and its bookkeeping statement atomically.
+func (m *Migrator) inTx(ctx context.Context, script, record string, args ...any) error {
+	tx, err := m.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// lock creates the bookkeeping tables if needed and takes the migration lock,
+// waiting for another instance to finish. The returned function releases it.
+func (m *Migrator) lock(ctx context.Context) (func(), error) {
+	if err := m.bootstrap(ctx); err != nil {
+		return nil, err
+	}
+
+	var lastHolder string
+	for {
+		// A holder that died without releasing stops heartbeating; take it over
+		_, err := m.db.ExecContext(ctx, m.dialect.clearStale, staleLockAfter.Seconds())
+		if err != nil {
+			return nil, fmt.Errorf("failed to clear stale migration lock: %w", err)
+		}
+
+		res, err := m.db.ExecContext(ctx, m.dialect.acquire, m.owner)
+		if err != nil {
+			return nil, fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		if n, _ := res.RowsAffected(); n == 1 {
+			break
+		}
+
+		var holder string
+		m.db.GetContext(ctx, &holder, `SELECT locked_by FROM schema_migrations_lock WHERE id = 1`)
+		if holder != lastHolder {
+			m.logger.Info("waiting for migration lock", "held_by", holder)
+			lastHolder = holder
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("migration lock held by %s: %w", holder, ctx.Err())
+		case <-time.After(lockPollInterval):
+		}
+	}
+
+	heartbeatCtx, stop := context.WithCancel(context.WithoutCancel(ctx))
+	go m.heartbeat(heartbeatCtx)
+
+	return func() {
+		stop()
+		releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+		if _, err := m.db.ExecContext(releaseCtx,
+			`DELETE FROM schema_migrations_lock WHERE id = 1 AND locked_by = $1`, m.owner); err != nil {
+			m.logger.Warn("failed to release migration lock", "error", err)
+		}
+	}, nil
+}
+
+// heartbeat keeps the lock fresh during long migrations.
+func (m *Migrator) heartbeat(ctx context.Context) {
+	ticker := time.NewTicker(staleLockAfter / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := m.db.ExecContext(ctx, m.dialect.heartbeat, m.owner); err != nil {
+				m.logger.Warn("failed to refresh migration lock", "error", err)
+			}
+		}
+	}
+}
+
+// bootstrap creates schema_migrations and schema_migrations_lock. Two
+// instances racing on CREATE TABLE IF NOT EXISTS can make one fail with a
+// duplicate catalog entry, so a failure is retried once.
+func (m *Migrator) bootstrap(ctx context.Context) error {
+	if _, err := m.db.ExecContext(ctx, m.dialect.bootstrap); err != nil {
+		if _, err := m.db.ExecContext(ctx, m.dialect.bootstrap); err != nil {
+			return fmt.Errorf("failed to create migration tables: %w", err)
+		}
+	}
+	return nil
+}
+
+// pending returns the migrations not yet recorded as applied, in order.
+func pending(all []Migration, applied []appliedMigration) []Migration {
+	done := make(map[int]bool, len(applied))
+	for _, a := range applied {
+		done[a.Version] = true
+	}
+	var todo []Migration
+	for _, migration := range all {
+		if !done[migration.Version] {
+			todo = append(todo, migration)
+		}
+	}
+	return todo
+}
+
+// revertPlan picks the newest steps applied migrations. It refuses to revert
+// a version this binary has no down script for.
+func revertPlan(all []Migration, applied []appliedMigration, steps int) ([]Migration, error) {
+	if steps < 1 {
+		return nil, fmt.Errorf("steps must be at least 1, got %d", steps)
+	}
+
+	known := make(map[int]Migration, len(all))
+	for _, migration := range all {
+		known[migration.Version] = migration
+	}
+
+	newestFirst := slices.Clone(applied)
+	slices.SortFunc(newestFirst, func(a, b appliedMigration) int { return b.Version - a.Version })
+
+	var plan []Migration
+	for _, a := range newestFirst[:min(steps, len(newestFirst))] {
+		migration, ok := known[a.Version]
+		if !ok {
+			return nil, fmt.Errorf("cannot revert %04d_%s: not known to this binary (applied by a newer release?)", a.Version, a.Name)
+		}
+		plan = append(plan, migration)
+	}
+	return plan, nil
+}
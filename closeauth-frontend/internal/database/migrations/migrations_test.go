@@ -0,0 +1,323 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"closeauth-frontend/internal/config"
+	"closeauth-frontend/internal/database"
+	"closeauth-frontend/internal/database/models"
+)
+
+func TestLoad_Embedded(t *testing.T) {
+	postgres, err := Load(config.DriverPostgres)
+	if err != nil {
+		t.Fatalf("Load(postgres) error = %v", err)
+	}
+	if len(postgres) == 0 {
+		t.Fatal("Load(postgres) returned no migrations")
+	}
+	for i, m := range postgres {
+		if i > 0 && m.Version <= postgres[i-1].Version {
+			t.Errorf("migrations out of order: %s after %s", m.ID(), postgres[i-1].ID())
+		}
+	}
+
+	// Every schema change must ship for both drivers
+	sqlite, err := Load(config.DriverSQLite)
+	if err != nil {
+		t.Fatalf("Load(sqlite) error = %v", err)
+	}
+	ids := func(ms []Migration) string {
+		var out []string
+		for _, m := range ms {
+			out = append(out, m.ID())
+		}
+		return strings.Join(out, ", ")
+	}
+	if ids(postgres) != ids(sqlite) {
+		t.Errorf("postgres migrations [%s] differ from sqlite [%s]", ids(postgres), ids(sqlite))
+	}
+
+	if _, err := Load("mysql"); err == nil {
+		t.Error("Load(mysql) succeeded, want error")
+	}
+}
+
+func TestMigrator_SQLite(t *testing.T) {
+	ctx := context.Background()
+	db, err := database.Connect(ctx, &config.DatabaseConfig{
+		Driver: config.DriverSQLite,
+		Path:   filepath.Join(t.TempDir(), "bff.db"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	m, err := New(db.DB, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.Check(ctx); !errors.Is(err, ErrSchemaBehind) {
+		t.Fatalf("Check() on an empty database = %v, want ErrSchemaBehind", err)
+	}
+	applied, err := m.Up(ctx)
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	if len(applied) != len(m.migrations) {
+		t.Errorf("Up() applied %d migrations, want %d", len(applied), len(m.migrations))
+	}
+	if err := m.Check(ctx); err != nil {
+		t.Errorf("Check() after Up = %v", err)
+	}
+	if again, err := m.Up(ctx); err != nil || len(again) != 0 {
+		t.Errorf("second Up() = %d migrations, %v; want none", len(again), err)
+	}
+
+	// The audit log refuses rewrites
+	if _, err := db.Exec(`INSERT INTO admin_audit_log (occurred_at, action, outcome, prev_hash, hash) VALUES (CURRENT_TIMESTAMP, 'x', 'success', 'p', 'h')`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`DELETE FROM admin_audit_log`); err == nil || !strings.Contains(err.Error(), "append-only") {
+		t.Errorf("DELETE from admin_audit_log error = %v, want append-only", err)
+	}
+
+	reverted, err := m.Down(ctx, 1)
+	if err != nil || len(reverted) != 1 {
+		t.Fatalf("Down(1) = %v, %v", reverted, err)
+	}
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := len(statuses) - 1
+	if len(statuses) != len(m.migrations) || statuses[last].Applied || !statuses[0].Applied || statuses[0].AppliedAt.IsZero() {
+		t.Errorf("Status() after Down(1) = %+v, want all applied but the newest", statuses)
+	}
+}
+
+func TestParse(t *testing.T) {
+	file := func(body string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(body)} }
+
+	tests := []struct {
+		name    string
+		files   fstest.MapFS
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "sorted by version",
+			files: fstest.MapFS{
+				"sql/0010_later.up.sql":   file("CREATE TABLE b ();"),
+				"sql/0010_later.down.sql": file("DROP TABLE b;"),
+				"sql/0002_first.up.sql":   file("CREATE TABLE a ();"),
+				"sql/0002_first.down.sql": file("DROP TABLE a;"),
+			},
+			want: []string{"0002_first", "0010_later"},
+		},
+		{
+			name:    "missing down",
+			files:   fstest.MapFS{"sql/0001_init.up.sql": file("CREATE TABLE a ();")},
+			wantErr: "needs non-empty",
+		},
+		{
+			name: "version reused",
+			files: fstest.MapFS{
+				"sql/0001_a.up.sql":   file("SELECT 1;"),
+				"sql/0001_a.down.sql": file("SELECT 1;"),
+				"sql/0001_b.up.sql":   file("SELECT 1;"),
+			},
+			wantErr: "used by both",
+		},
+		{
+			name:    "bad file name",
+			files:   fstest.MapFS{"sql/init.sql": file("SELECT 1;")},
+			wantErr: "name must look like",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := parse(tt.files, "sql")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parse() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse() error = %v", err)
+			}
+			var got []string
+			for _, m := range migrations {
+				got = append(got, m.ID())
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPending(t *testing.T) {
+	all := []Migration{{Version: 1, Name: "a"}, {Version: 2, Name: "b"}, {Version: 3, Name: "c"}}
+	applied := []appliedMigration{{Version: 1}, {Version: 3}}
+
+	got := pending(all, applied)
+	if len(got) != 1 || got[0].Version != 2 {
+		t.Errorf("pending() = %v, want only version 2", got)
+	}
+	if got := pending(all, nil); len(got) != 3 {
+		t.Errorf("pending() on a fresh database = %d migrations, want 3", len(got))
+	}
+}
+
+func TestRevertPlan(t *testing.T) {
+	all := []Migration{{Version: 1, Name: "a"}, {Version: 2, Name: "b"}, {Version: 3, Name: "c"}}
+	applied := []appliedMigration{{Version: 1}, {Version: 2}, {Version: 3}}
+
+	tests := []struct {
+		name    string
+		applied []appliedMigration
+		steps   int
+		want    []int
+		wantErr bool
+	}{
+		{"one", applied, 1, []int{3}, false},
+		{"newest first", applied, 2, []int{3, 2}, false},
+		{"more than applied", applied[:1], 5, []int{1}, false},
+		{"nothing applied", nil, 1, nil, false},
+		{"zero steps", applied, 0, nil, true},
+		{"unknown version", append(applied, appliedMigration{Version: 4, Name: "newer"}), 1, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := revertPlan(all, tt.applied, tt.steps)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("revertPlan() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var got []int
+			for _, m := range plan {
+				got = append(got, m.Version)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("revertPlan() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("revertPlan() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestOneDefaultThemeMigration_RepairsRows(t *testing.T) {
+	ctx := context.Background()
+	db, err := database.Connect(ctx, &config.DatabaseConfig{
+		Driver: config.DriverSQLite,
+		Path:   filepath.Join(t.TempDir(), "bff.db"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	m, err := New(db.DB, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.migrations = m.migrations[:2] // up to, not including, 0003_one_default_theme
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// acme has two active defaults, globex active themes but no default
+	db.MustExec(`INSERT INTO client_themes (client_id, theme_name, is_active, is_default) VALUES
+        ('acme', 'a', TRUE, TRUE), ('acme', 'b', TRUE, TRUE),
+        ('globex', 'c', FALSE, FALSE), ('globex', 'd', TRUE, FALSE), ('globex', 'e', TRUE, FALSE)`)
+
+	m.migrations, _ = Load(config.DriverSQLite)
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	var defaults []string
+	db.Select(&defaults, `SELECT theme_name FROM client_themes WHERE is_default AND is_active ORDER BY client_id`)
+	if strings.Join(defaults, ",") != "a,d" {
+		t.Errorf("active defaults after migration = %v, want the oldest per client: [a d]", defaults)
+	}
+	if _, err := db.Exec(`UPDATE client_themes SET is_default = TRUE WHERE theme_name = 'e'`); err == nil {
+		t.Error("a second active default was accepted after the migration")
+	}
+}
+
+func TestThemeVersionsMigration_Backfill(t *testing.T) {
+	ctx := context.Background()
+	db, err := database.Connect(ctx, &config.DatabaseConfig{
+		Driver: config.DriverSQLite,
+		Path:   filepath.Join(t.TempDir(), "bff.db"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	m, err := New(db.DB, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.migrations = m.migrations[:3] // up to, not including, 0004_theme_versions
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	db.MustExec(`INSERT INTO client_themes (client_id, theme_name, is_active, is_default, light_text_color, allow_mode_toggle) VALUES
+        ('acme', 'a', TRUE, TRUE, '#111111', FALSE), ('acme', 'b', FALSE, FALSE, NULL, TRUE)`)
+	db.MustExec(`INSERT INTO theme_configurations (theme_id, config_key, config_value, config_type) VALUES
+        (1, 'radius', '8', 'number'), (1, 'font', 'Inter', 'string')`)
+
+	m.migrations, _ = Load(config.DriverSQLite)
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	var versions []models.ThemeVersion
+	if err := db.Select(&versions, `SELECT * FROM theme_versions ORDER BY theme_id`); err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("backfilled %d versions, want one per theme", len(versions))
+	}
+	a := versions[0].Snapshot
+	if versions[0].Version != 1 || versions[0].Change != models.ThemeChangeBaseline ||
+		a.LightTextColor == nil || *a.LightTextColor != "#111111" || a.AllowModeToggle ||
+		len(a.Configurations) != 2 || a.Configurations[0].Key != "font" || a.Configurations[1].Type != "number" {
+		t.Errorf("baseline of a = %+v", versions[0])
+	}
+	if b := versions[1].Snapshot; b.LightTextColor != nil || !b.AllowModeToggle || len(b.Configurations) != 0 {
+		t.Errorf("baseline of b = %+v", b)
+	}
+
+	// History is append-only and outlives its theme
+	if _, err := db.Exec(`UPDATE theme_versions SET author = 'x'`); err == nil || !strings.Contains(err.Error(), "append-only") {
+		t.Errorf("UPDATE theme_versions error = %v, want append-only", err)
+	}
+	if _, err := db.Exec(`DELETE FROM theme_versions WHERE theme_id = 2`); err == nil || !strings.Contains(err.Error(), "append-only") {
+		t.Errorf("DELETE from theme_versions error = %v, want append-only", err)
+	}
+	db.MustExec(`DELETE FROM client_themes WHERE id = 2`)
+	var left int
+	db.Get(&left, `SELECT COUNT(*) FROM theme_versions`)
+	if left != 2 {
+		t.Errorf("versions after deleting theme b = %d, want 2", left)
+	}
+}
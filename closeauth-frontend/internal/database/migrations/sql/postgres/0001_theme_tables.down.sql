@@ -0,0 +1,5 @@
+-- Intentionally a no-op. 0001 adopts client_themes and theme_configurations
+-- as-is when the backend's Flyway schema already created them, so it cannot
+-- tell whether it owns them, and dropping them would destroy the backend's
+-- themes. To undo a fresh BFF-only install, drop the two tables by hand.
+SELECT 1;
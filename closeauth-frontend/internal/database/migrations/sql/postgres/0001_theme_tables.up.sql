@@ -0,0 +1,62 @@
+-- Theme tables, mirroring the backend's V1__INITIAL_SCHEMA so a database that
+-- was seeded from the backend is adopted as-is (IF NOT EXISTS). The BFF has
+-- its own database, so there is no foreign key to oauth2_registered_client.
+
+CREATE TABLE IF NOT EXISTS client_themes (
+    id                       BIGSERIAL PRIMARY KEY,
+    client_id                VARCHAR(100) NOT NULL,
+    theme_name               VARCHAR(100) NOT NULL,
+    is_active                BOOLEAN DEFAULT TRUE,
+    is_default               BOOLEAN DEFAULT FALSE,
+
+    logo_url                 VARCHAR(500),
+
+    light_primary_color      VARCHAR(7),
+    light_background_color   VARCHAR(7),
+    light_button_color       VARCHAR(7),
+    light_text_color         VARCHAR(7),
+
+    dark_primary_color       VARCHAR(7),
+    dark_background_color    VARCHAR(7),
+    dark_button_color        VARCHAR(7),
+    dark_text_color          VARCHAR(7),
+
+    default_mode             VARCHAR(10) CHECK (default_mode IN ('light', 'dark', 'system')),
+    allow_mode_toggle        BOOLEAN DEFAULT TRUE,
+
+    created_at               TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at               TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE (client_id, theme_name),
+
+    CONSTRAINT valid_light_colors CHECK (
+        light_primary_color ~ '^#[0-9A-Fa-f]{6}$' AND
+        light_background_color ~ '^#[0-9A-Fa-f]{6}$' AND
+        light_button_color ~ '^#[0-9A-Fa-f]{6}$' AND
+        light_text_color ~ '^#[0-9A-Fa-f]{6}$'
+    ),
+    CONSTRAINT valid_dark_colors CHECK (
+        dark_primary_color ~ '^#[0-9A-Fa-f]{6}$' AND
+        dark_background_color ~ '^#[0-9A-Fa-f]{6}$' AND
+        dark_button_color ~ '^#[0-9A-Fa-f]{6}$' AND
+        dark_text_color ~ '^#[0-9A-Fa-f]{6}$'
+    )
+);
+
+CREATE INDEX IF NOT EXISTS idx_client_themes_client_active ON client_themes(client_id, is_active);
+CREATE INDEX IF NOT EXISTS idx_client_themes_default ON client_themes(client_id, is_default);
+
+CREATE TABLE IF NOT EXISTS theme_configurations (
+    id              BIGSERIAL PRIMARY KEY,
+    theme_id        BIGINT NOT NULL,
+    config_key      VARCHAR(100) NOT NULL,
+    config_value    TEXT NOT NULL,
+    config_type     VARCHAR(50) DEFAULT 'string',
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE (theme_id, config_key),
+    FOREIGN KEY (theme_id) REFERENCES client_themes(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_theme_config_theme ON theme_configurations(theme_id);
@@ -0,0 +1,3 @@
+-- Destroys the audit trail; only for rolling back a fresh install.
+DROP TABLE IF EXISTS admin_audit_log;
+DROP FUNCTION IF EXISTS admin_audit_log_immutable();
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS uq_client_themes_active_default;
@@ -0,0 +1,18 @@
+-- At most one active default theme per client, which the theme write API
+-- relies on. Existing rows are repaired first: extra defaults keep the oldest,
+-- and a client with active themes but no default gets its oldest active one.
+
+UPDATE client_themes SET is_default = FALSE
+WHERE is_default = TRUE AND is_active = TRUE
+  AND id <> (SELECT MIN(d.id) FROM client_themes d
+             WHERE d.client_id = client_themes.client_id AND d.is_default = TRUE AND d.is_active = TRUE);
+
+UPDATE client_themes SET is_default = TRUE
+WHERE is_active = TRUE
+  AND id = (SELECT MIN(a.id) FROM client_themes a
+            WHERE a.client_id = client_themes.client_id AND a.is_active = TRUE)
+  AND NOT EXISTS (SELECT 1 FROM client_themes d
+                  WHERE d.client_id = client_themes.client_id AND d.is_default = TRUE AND d.is_active = TRUE);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_client_themes_active_default
+    ON client_themes(client_id) WHERE is_default AND is_active;
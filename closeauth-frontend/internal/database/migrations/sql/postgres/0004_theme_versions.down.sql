@@ -0,0 +1,4 @@
+-- Destroys the theme version history and any unpublished drafts.
+DROP TABLE IF EXISTS theme_drafts;
+DROP TABLE IF EXISTS theme_versions;
+DROP FUNCTION IF EXISTS theme_versions_immutable();
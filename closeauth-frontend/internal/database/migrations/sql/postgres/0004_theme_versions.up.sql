@@ -0,0 +1,62 @@
+-- Immutable theme version history and one unpublished draft per theme. Every
+-- change to a theme's content, configuration or activation appends a full
+-- snapshot. Versions outlive their theme: deleting one appends a final
+-- snapshot and leaves the history in place.
+
+CREATE TABLE IF NOT EXISTS theme_versions (
+    id              BIGSERIAL PRIMARY KEY,
+    theme_id        BIGINT       NOT NULL,
+    version         INTEGER      NOT NULL,
+    snapshot        JSONB        NOT NULL,
+    author          VARCHAR(255) NOT NULL DEFAULT '',
+    change_type     VARCHAR(50)  NOT NULL,
+    source_version  INTEGER,
+    created_at      TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE (theme_id, version)
+);
+
+CREATE TABLE IF NOT EXISTS theme_drafts (
+    theme_id        BIGINT       PRIMARY KEY REFERENCES client_themes(id) ON DELETE CASCADE,
+    snapshot        JSONB        NOT NULL,
+    base_version    INTEGER      NOT NULL,
+    author          VARCHAR(255) NOT NULL DEFAULT '',
+    created_at      TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at      TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Versions are never rewritten or deleted, and outlive their theme
+CREATE OR REPLACE FUNCTION theme_versions_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'theme_versions is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS theme_versions_no_update ON theme_versions;
+CREATE TRIGGER theme_versions_no_update
+    BEFORE UPDATE OR DELETE ON theme_versions
+    FOR EACH ROW EXECUTE FUNCTION theme_versions_immutable();
+
+-- Existing themes start from a baseline of their current content
+INSERT INTO theme_versions (theme_id, version, snapshot, change_type, created_at)
+SELECT t.id, 1, jsonb_build_object(
+           'logo_url', t.logo_url,
+           'light_primary_color', t.light_primary_color,
+           'light_background_color', t.light_background_color,
+           'light_button_color', t.light_button_color,
+           'light_text_color', t.light_text_color,
+           'dark_primary_color', t.dark_primary_color,
+           'dark_background_color', t.dark_background_color,
+           'dark_button_color', t.dark_button_color,
+           'dark_text_color', t.dark_text_color,
+           'default_mode', t.default_mode,
+           'allow_mode_toggle', COALESCE(t.allow_mode_toggle, FALSE),
+           'configurations', COALESCE((
+               SELECT jsonb_agg(jsonb_build_object(
+                          'key', c.config_key,
+                          'value', c.config_value,
+                          'type', COALESCE(c.config_type, 'string')) ORDER BY c.config_key)
+               FROM theme_configurations c WHERE c.theme_id = t.id), '[]'::jsonb)),
+       'baseline', COALESCE(t.updated_at, CURRENT_TIMESTAMP)
+FROM client_themes t
+WHERE NOT EXISTS (SELECT 1 FROM theme_versions v WHERE v.theme_id = t.id);
@@ -0,0 +1,41 @@
+-- Uploaded theme assets (logos and backgrounds), their served variants, and
+-- the content-addressed blobs behind them. Every variant's blob key has a row
+-- in asset_blobs, so deleting an asset drops the blobs nothing else uses in
+-- the same transaction and an upload cannot lose a blob to a concurrent
+-- delete. data is NULL for blobs kept under THEME_ASSET_DIR, and for a
+-- database blob while its upload is being stored.
+
+CREATE TABLE IF NOT EXISTS theme_assets (
+    id              BIGSERIAL PRIMARY KEY,
+    client_id       VARCHAR(100) NOT NULL,
+    kind            VARCHAR(20)  NOT NULL CHECK (kind IN ('logo', 'background')),
+    filename        VARCHAR(255) NOT NULL DEFAULT '',
+    content_type    VARCHAR(100) NOT NULL,
+    width           INTEGER      NOT NULL DEFAULT 0,
+    height          INTEGER      NOT NULL DEFAULT 0,
+    created_by      VARCHAR(255) NOT NULL DEFAULT '',
+    created_at      TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_theme_assets_client ON theme_assets(client_id, created_at);
+
+CREATE TABLE IF NOT EXISTS asset_blobs (
+    blob_key        VARCHAR(80)  PRIMARY KEY,
+    content_type    VARCHAR(100) NOT NULL,
+    data            BYTEA,
+    created_at      TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS theme_asset_variants (
+    asset_id        BIGINT       NOT NULL REFERENCES theme_assets(id) ON DELETE CASCADE,
+    name            VARCHAR(20)  NOT NULL,
+    blob_key        VARCHAR(80)  NOT NULL REFERENCES asset_blobs(blob_key),
+    content_type    VARCHAR(100) NOT NULL,
+    width           INTEGER      NOT NULL DEFAULT 0,
+    height          INTEGER      NOT NULL DEFAULT 0,
+    size_bytes      BIGINT       NOT NULL,
+
+    PRIMARY KEY (asset_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_theme_asset_variants_blob ON theme_asset_variants(blob_key);
@@ -0,0 +1,4 @@
+-- Unlike postgres/0001 this drops the tables: a SQLite data file belongs to
+-- the BFF alone, so there is no backend data to preserve.
+DROP TABLE IF EXISTS theme_configurations;
+DROP TABLE IF EXISTS client_themes;
@@ -0,0 +1,61 @@
+-- Theme tables, the SQLite equivalent of postgres/0001_theme_tables.
+
+CREATE TABLE IF NOT EXISTS client_themes (
+    id                       INTEGER PRIMARY KEY AUTOINCREMENT,
+    client_id                VARCHAR(100) NOT NULL,
+    theme_name               VARCHAR(100) NOT NULL,
+    is_active                BOOLEAN DEFAULT TRUE,
+    is_default               BOOLEAN DEFAULT FALSE,
+
+    logo_url                 VARCHAR(500),
+
+    light_primary_color      VARCHAR(7),
+    light_background_color   VARCHAR(7),
+    light_button_color       VARCHAR(7),
+    light_text_color         VARCHAR(7),
+
+    dark_primary_color       VARCHAR(7),
+    dark_background_color    VARCHAR(7),
+    dark_button_color        VARCHAR(7),
+    dark_text_color          VARCHAR(7),
+
+    default_mode             VARCHAR(10) CHECK (default_mode IN ('light', 'dark', 'system')),
+    allow_mode_toggle        BOOLEAN DEFAULT TRUE,
+
+    created_at               TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at               TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE (client_id, theme_name),
+
+    -- SQLite has no regular expressions; GLOB character classes do the same job
+    CONSTRAINT valid_light_colors CHECK (
+        light_primary_color GLOB '#[0-9A-Fa-f][0-9A-Fa-f][0-9A-Fa-f][0-9A-Fa-f][0-9A-Fa-f][0-9A-Fa-f]' AND
+        light_background_color GLOB '#[0-9A-Fa-f][0-9A-Fa-f][0-9A-Fa-f][0-9A-Fa-f][0-9A-Fa-f][0-9A-Fa-f]' AND
+        light_button_color GLOB '#[0-9A-Fa-f][0-9A-Fa-f][0-9A-Fa-f][0-9A-Fa-f][0-9A-Fa-f][0-9A-Fa-f]' AND
+        light_text_color GLOB '#[0-9A-Fa-f][0-9A-Fa-f][0-9A-Fa-f][0-9A-Fa-f][0-9A-Fa-f][0-9A-Fa-f]'
+    ),
+    CONSTRAINT valid_dark_colors CHECK (
+        dark_primary_color GLOB '#[0-9A-Fa-f][0-9A-Fa-f][0-9A-Fa-f][0-9A-Fa-f][0-9A-Fa-f][0-9A-Fa-f]' AND
+        dark_background_color GLOB '#[0-9A-Fa-f][0-9A-Fa-f][0-9A-Fa-f][0-9A-Fa-f][0-9A-Fa-f][0-9A-Fa-f]' AND
+        dark_button_color GLOB '#[0-9A-Fa-f][0-9A-Fa-f][0-9A-Fa-f][0-9A-Fa-f][0-9A-Fa-f][0-9A-Fa-f]' AND
+        dark_text_color GLOB '#[0-9A-Fa-f][0-9A-Fa-f][0-9A-Fa-f][0-9A-Fa-f][0-9A-Fa-f][0-9A-Fa-f]'
+    )
+);
+
+CREATE INDEX IF NOT EXISTS idx_client_themes_client_active ON client_themes(client_id, is_active);
+CREATE INDEX IF NOT EXISTS idx_client_themes_default ON client_themes(client_id, is_default);
+
+CREATE TABLE IF NOT EXISTS theme_configurations (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    theme_id        INTEGER NOT NULL,
+    config_key      VARCHAR(100) NOT NULL,
+    config_value    TEXT NOT NULL,
+    config_type     VARCHAR(50) DEFAULT 'string',
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE (theme_id, config_key),
+    FOREIGN KEY (theme_id) REFERENCES client_themes(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_theme_config_theme ON theme_configurations(theme_id);
@@ -0,0 +1,38 @@
+-- Hash-chained, append-only admin audit log, the SQLite equivalent of
+-- postgres/0002_admin_audit_log.
+
+CREATE TABLE IF NOT EXISTS admin_audit_log (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    occurred_at     TIMESTAMP    NOT NULL,
+    actor_id        VARCHAR(100) NOT NULL DEFAULT '',
+    actor_email     VARCHAR(255) NOT NULL DEFAULT '',
+    tenant_id       VARCHAR(100) NOT NULL DEFAULT '',
+    client_id       VARCHAR(100) NOT NULL DEFAULT '',
+    action          VARCHAR(100) NOT NULL,
+    target          VARCHAR(500) NOT NULL DEFAULT '',
+    request_id      VARCHAR(100) NOT NULL DEFAULT '',
+    ip_address      VARCHAR(64)  NOT NULL DEFAULT '',
+    before_summary  JSON,
+    after_summary   JSON,
+    outcome         VARCHAR(20)  NOT NULL,
+    status_code     INTEGER      NOT NULL DEFAULT 0,
+    prev_hash       CHAR(64)     NOT NULL,
+    hash            CHAR(64)     NOT NULL UNIQUE
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_audit_occurred ON admin_audit_log(occurred_at);
+CREATE INDEX IF NOT EXISTS idx_admin_audit_actor ON admin_audit_log(actor_id);
+CREATE INDEX IF NOT EXISTS idx_admin_audit_tenant ON admin_audit_log(tenant_id);
+
+-- Append-only: reject any attempt to rewrite history
+CREATE TRIGGER IF NOT EXISTS admin_audit_log_no_update
+    BEFORE UPDATE ON admin_audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'admin_audit_log is append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS admin_audit_log_no_delete
+    BEFORE DELETE ON admin_audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'admin_audit_log is append-only');
+END;
@@ -0,0 +1,3 @@
+-- Destroys the theme version history and any unpublished drafts.
+DROP TABLE IF EXISTS theme_drafts;
+DROP TABLE IF EXISTS theme_versions;
@@ -0,0 +1,63 @@
+-- Immutable theme version history and one unpublished draft per theme, the
+-- SQLite equivalent of postgres/0004_theme_versions.
+
+CREATE TABLE IF NOT EXISTS theme_versions (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    theme_id        INTEGER      NOT NULL,
+    version         INTEGER      NOT NULL,
+    snapshot        JSON         NOT NULL,
+    author          VARCHAR(255) NOT NULL DEFAULT '',
+    change_type     VARCHAR(50)  NOT NULL,
+    source_version  INTEGER,
+    created_at      TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE (theme_id, version)
+);
+
+CREATE TABLE IF NOT EXISTS theme_drafts (
+    theme_id        INTEGER      PRIMARY KEY,
+    snapshot        JSON         NOT NULL,
+    base_version    INTEGER      NOT NULL,
+    author          VARCHAR(255) NOT NULL DEFAULT '',
+    created_at      TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at      TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (theme_id) REFERENCES client_themes(id) ON DELETE CASCADE
+);
+
+-- Versions are never rewritten or deleted, and outlive their theme
+CREATE TRIGGER IF NOT EXISTS theme_versions_no_update
+    BEFORE UPDATE ON theme_versions
+BEGIN
+    SELECT RAISE(ABORT, 'theme_versions is append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS theme_versions_no_delete
+    BEFORE DELETE ON theme_versions
+BEGIN
+    SELECT RAISE(ABORT, 'theme_versions is append-only');
+END;
+
+-- Existing themes start from a baseline of their current content
+INSERT INTO theme_versions (theme_id, version, snapshot, change_type, created_at)
+SELECT t.id, 1, json_object(
+           'logo_url', t.logo_url,
+           'light_primary_color', t.light_primary_color,
+           'light_background_color', t.light_background_color,
+           'light_button_color', t.light_button_color,
+           'light_text_color', t.light_text_color,
+           'dark_primary_color', t.dark_primary_color,
+           'dark_background_color', t.dark_background_color,
+           'dark_button_color', t.dark_button_color,
+           'dark_text_color', t.dark_text_color,
+           'default_mode', t.default_mode,
+           'allow_mode_toggle', json(CASE WHEN t.allow_mode_toggle THEN 'true' ELSE 'false' END),
+           'configurations', json((
+               SELECT json_group_array(json_object(
+                          'key', c.config_key,
+                          'value', c.config_value,
+                          'type', COALESCE(c.config_type, 'string')))
+               FROM (SELECT * FROM theme_configurations WHERE theme_id = t.id ORDER BY config_key) c))),
+       'baseline', COALESCE(t.updated_at, CURRENT_TIMESTAMP)
+FROM client_themes t
+WHERE NOT EXISTS (SELECT 1 FROM theme_versions v WHERE v.theme_id = t.id);
@@ -0,0 +1,4 @@
+-- Destroys uploaded theme assets and their stored variants.
+DROP TABLE IF EXISTS theme_asset_variants;
+DROP TABLE IF EXISTS asset_blobs;
+DROP TABLE IF EXISTS theme_assets;
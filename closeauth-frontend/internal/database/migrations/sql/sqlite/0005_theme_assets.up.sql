@@ -0,0 +1,39 @@
+-- Uploaded theme assets, their served variants and content-addressed blobs,
+-- the SQLite equivalent of postgres/0005_theme_assets.
+
+CREATE TABLE IF NOT EXISTS theme_assets (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    client_id       VARCHAR(100) NOT NULL,
+    kind            VARCHAR(20)  NOT NULL CHECK (kind IN ('logo', 'background')),
+    filename        VARCHAR(255) NOT NULL DEFAULT '',
+    content_type    VARCHAR(100) NOT NULL,
+    width           INTEGER      NOT NULL DEFAULT 0,
+    height          INTEGER      NOT NULL DEFAULT 0,
+    created_by      VARCHAR(255) NOT NULL DEFAULT '',
+    created_at      TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_theme_assets_client ON theme_assets(client_id, created_at);
+
+CREATE TABLE IF NOT EXISTS asset_blobs (
+    blob_key        VARCHAR(80)  PRIMARY KEY,
+    content_type    VARCHAR(100) NOT NULL,
+    data            BLOB,
+    created_at      TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS theme_asset_variants (
+    asset_id        INTEGER      NOT NULL,
+    name            VARCHAR(20)  NOT NULL,
+    blob_key        VARCHAR(80)  NOT NULL,
+    content_type    VARCHAR(100) NOT NULL,
+    width           INTEGER      NOT NULL DEFAULT 0,
+    height          INTEGER      NOT NULL DEFAULT 0,
+    size_bytes      INTEGER      NOT NULL,
+
+    PRIMARY KEY (asset_id, name),
+    FOREIGN KEY (asset_id) REFERENCES theme_assets(id) ON DELETE CASCADE,
+    FOREIGN KEY (blob_key) REFERENCES asset_blobs(blob_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_theme_asset_variants_blob ON theme_asset_variants(blob_key);
@@ -0,0 +1,125 @@
+package models
+
+import (
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Audit outcomes
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+)
+
+// AuditEvent represents a row in the append-only admin_audit_log table.
+// Each row's Hash covers its own fields plus the previous row's hash, so any
+// edit or deletion breaks the chain from that row onwards.
+type AuditEvent struct {
+	ID         int64     `db:"id" json:"id"`
+	OccurredAt time.Time `db:"occurred_at" json:"occurred_at"`
+	ActorID    string    `db:"actor_id" json:"actor_id"`
+	ActorEmail string    `db:"actor_email" json:"actor_email"`
+	TenantID   string    `db:"tenant_id" json:"tenant_id,omitempty"` // {clientId} the action is scoped to
+	ClientID   string    `db:"client_id" json:"client_id,omitempty"` // OAuth client_id, when known
+	Action     string    `db:"action" json:"action"`                 // e.g. "themes.update"
+	Target     string    `db:"target" json:"target,omitempty"`       // e.g. "themes/12"
+	RequestID  string    `db:"request_id" json:"request_id,omitempty"`
+	IPAddress  string    `db:"ip_address" json:"ip_address,omitempty"`
+	Before     JSONText  `db:"before_summary" json:"before,omitempty"` // Redacted
+	After      JSONText  `db:"after_summary" json:"after,omitempty"`   // Redacted
+	Outcome    string    `db:"outcome" json:"outcome"`
+	StatusCode int       `db:"status_code" json:"status_code"`
+	PrevHash   string    `db:"prev_hash" json:"prev_hash"`
+	Hash       string    `db:"hash" json:"hash"`
+}
+
+// Widths of admin_audit_log's VARCHAR columns, in characters.
+const (
+	auditIDWidth     = 100 // actor_id, tenant_id, client_id, action, request_id
+	auditEmailWidth  = 255
+	auditTargetWidth = 500
+	auditIPWidth     = 64
+)
+
+// FitColumns truncates the text fields to their column widths, so a long URL
+// parameter or header cannot make the insert fail and lose the event. Call it
+// before ComputeHash.
+func (e *AuditEvent) FitColumns() {
+	e.ActorID = truncateRunes(e.ActorID, auditIDWidth)
+	e.ActorEmail = truncateRunes(e.ActorEmail, auditEmailWidth)
+	e.TenantID = truncateRunes(e.TenantID, auditIDWidth)
+	e.ClientID = truncateRunes(e.ClientID, auditIDWidth)
+	e.Action = truncateRunes(e.Action, auditIDWidth)
+	e.Target = truncateRunes(e.Target, auditTargetWidth)
+	e.RequestID = truncateRunes(e.RequestID, auditIDWidth)
+	e.IPAddress = truncateRunes(e.IPAddress, auditIPWidth)
+}
+
+// truncateRunes returns the first n characters of s.
+func truncateRunes(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	i := 0
+	for pos := range s {
+		if i == n {
+			return s[:pos]
+		}
+		i++
+	}
+	return s
+}
+
+// ComputeHash returns the chain hash for the event given the previous row's hash.
+// OccurredAt must already be truncated to the database's microsecond precision.
+func (e *AuditEvent) ComputeHash(prevHash string) string {
+	payload, _ := json.Marshal([]any{
+		e.OccurredAt.UTC().Format(time.RFC3339Nano),
+		e.ActorID, e.ActorEmail, e.TenantID, e.ClientID,
+		e.Action, e.Target, e.RequestID, e.IPAddress,
+		string(e.Before), string(e.After),
+		e.Outcome, e.StatusCode,
+	})
+
+	sum := sha256.Sum256(append([]byte(prevHash+"\n"), payload...))
+	return hex.EncodeToString(sum[:])
+}
+
+// JSONText is a nullable JSON column stored verbatim (the json type, not jsonb,
+// so the bytes covered by the audit hash survive a round trip).
+type JSONText []byte
+
+// Value implements driver.Valuer.
+func (j JSONText) Value() (driver.Value, error) {
+	if len(j) == 0 {
+		return nil, nil
+	}
+	return string(j), nil
+}
+
+// Scan implements sql.Scanner.
+func (j *JSONText) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*j = nil
+	case []byte:
+		*j = append((*j)[:0], v...)
+	case string:
+		*j = JSONText(v)
+	default:
+		return fmt.Errorf("unsupported type %T for JSONText", src)
+	}
+	return nil
+}
+
+// MarshalJSON emits the stored JSON as-is, or null when empty.
+func (j JSONText) MarshalJSON() ([]byte, error) {
+	if len(j) == 0 {
+		return []byte("null"), nil
+	}
+	return j, nil
+}
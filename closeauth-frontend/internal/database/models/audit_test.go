@@ -0,0 +1,69 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestAuditEvent_ComputeHash(t *testing.T) {
+	event := AuditEvent{
+		OccurredAt: time.Date(2026, 1, 2, 3, 4, 5, 6000, time.UTC),
+		ActorID:    "42",
+		Action:     "themes.update",
+		Target:     "themes/3",
+		After:      JSONText(`{"theme_name":"dark"}`),
+		Outcome:    AuditOutcomeSuccess,
+		StatusCode: 200,
+	}
+	prev := "0000000000000000000000000000000000000000000000000000000000000000"
+	hash := event.ComputeHash(prev)
+
+	if got := event.ComputeHash(prev); got != hash {
+		t.Fatalf("ComputeHash() is not deterministic: %s != %s", got, hash)
+	}
+
+	// Same instant read back in another location must hash identically
+	event.OccurredAt = event.OccurredAt.In(time.FixedZone("X", 3600))
+	if got := event.ComputeHash(prev); got != hash {
+		t.Errorf("ComputeHash() depends on time zone")
+	}
+
+	tampered := event
+	tampered.After = JSONText(`{"theme_name":"light"}`)
+	if tampered.ComputeHash(prev) == hash {
+		t.Error("changing a field should change the hash")
+	}
+	if event.ComputeHash(hash) == hash {
+		t.Error("changing the previous hash should change the hash")
+	}
+}
+
+func TestAuditEvent_FitColumns(t *testing.T) {
+	event := AuditEvent{
+		ActorEmail: strings.Repeat("é", 300),
+		TenantID:   strings.Repeat("t", 101),
+		ClientID:   "acme",
+		Target:     "themes/" + strings.Repeat("9", 600),
+		RequestID:  strings.Repeat("r", 128),
+	}
+	event.FitColumns()
+
+	tests := []struct {
+		field string
+		value string
+		want  int
+	}{
+		{"actor_email", event.ActorEmail, 255},
+		{"tenant_id", event.TenantID, 100},
+		{"client_id", event.ClientID, 4},
+		{"target", event.Target, 500},
+		{"request_id", event.RequestID, 100},
+	}
+	for _, tt := range tests {
+		if n := utf8.RuneCountInString(tt.value); n != tt.want || !utf8.ValidString(tt.value) {
+			t.Errorf("%s = %d characters, want %d", tt.field, n, tt.want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"regexp"
+	"time"
+)
+
+// What an uploaded theme asset is for; it decides the variant sizes.
+const (
+	AssetKindLogo       = "logo"
+	AssetKindBackground = "background"
+)
+
+// assetBlobKey is a variant's SHA-256 content hash and file extension.
+var assetBlobKey = regexp.MustCompile(`^[0-9a-f]{64}\.(png|jpg|svg)$`)
+
+// IsAssetBlobKey reports whether key is a well-formed blob key, so it is safe
+// to use as a file name or URL segment.
+func IsAssetBlobKey(key string) bool {
+	return assetBlobKey.MatchString(key)
+}
+
+// ThemeAsset represents a row in the theme_assets table: an image a client
+// uploaded for its login pages. The upload itself is not kept, only the
+// sanitized or re-encoded variants served from the BFF.
+type ThemeAsset struct {
+	ID          int64     `db:"id" json:"id"`
+	ClientID    string    `db:"client_id" json:"client_id"`
+	Kind        string    `db:"kind" json:"kind"`
+	Filename    string    `db:"filename" json:"filename"`
+	ContentType string    `db:"content_type" json:"content_type"` // of the upload, as sniffed
+	Width       int       `db:"width" json:"width"`
+	Height      int       `db:"height" json:"height"`
+	CreatedBy   string    `db:"created_by" json:"created_by"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+
+	Variants []ThemeAssetVariant `db:"-" json:"variants"` // smallest first
+}
+
+// ThemeAssetVariant represents the theme_asset_variants table: one rendition
+// of an asset, stored as a blob under Key.
+type ThemeAssetVariant struct {
+	AssetID     int64  `db:"asset_id" json:"asset_id"`
+	Name        string `db:"name" json:"name"`
+	Key         string `db:"blob_key" json:"key"`
+	ContentType string `db:"content_type" json:"content_type"`
+	Width       int    `db:"width" json:"width"`
+	Height      int    `db:"height" json:"height"`
+	Size        int64  `db:"size_bytes" json:"size"`
+}
+
+// AssetBlob is the content of a variant. Blobs are content-addressed, so
+// identical variants of different assets share one.
+type AssetBlob struct {
+	Key         string    `db:"blob_key"`
+	ContentType string    `db:"content_type"`
+	Data        []byte    `db:"data"`
+	CreatedAt   time.Time `db:"created_at"`
+}
@@ -0,0 +1,233 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Theme version changes, recorded in ThemeVersion.Change
+const (
+	ThemeChangeBaseline     = "baseline" // backfilled for themes that predate versioning
+	ThemeChangeCreate       = "create"
+	ThemeChangeUpdate       = "update"
+	ThemeChangeConfigCreate = "configuration.create"
+	ThemeChangeConfigUpdate = "configuration.update"
+	ThemeChangeConfigDelete = "configuration.delete"
+	ThemeChangeRollback     = "rollback"
+	ThemeChangePublish      = "publish"
+	ThemeChangeActivate     = "activate"
+	ThemeChangeDelete       = "delete" // the theme's last version, taken as it is deleted
+)
+
+// ThemeSnapshot is the editable content of a theme and its configuration at
+// one point in time: everything but the name and the active/default flags.
+// It is stored as JSON in theme_versions and theme_drafts.
+type ThemeSnapshot struct {
+	LogoURL *string `json:"logo_url"`
+
+	LightPrimaryColor    *string `json:"light_primary_color"`
+	LightBackgroundColor *string `json:"light_background_color"`
+	LightButtonColor     *string `json:"light_button_color"`
+	LightTextColor       *string `json:"light_text_color"`
+
+	DarkPrimaryColor    *string `json:"dark_primary_color"`
+	DarkBackgroundColor *string `json:"dark_background_color"`
+	DarkButtonColor     *string `json:"dark_button_color"`
+	DarkTextColor       *string `json:"dark_text_color"`
+
+	DefaultMode     *string `json:"default_mode"`
+	AllowModeToggle bool    `json:"allow_mode_toggle"`
+
+	Configurations []SnapshotConfig `json:"configurations"` // ordered by key
+}
+
+// SnapshotConfig is one configuration entry of a ThemeSnapshot.
+type SnapshotConfig struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Type  string `json:"type"`
+}
+
+// NewThemeSnapshot captures theme and its configuration, ordering the
+// configuration by key.
+func NewThemeSnapshot(theme *ClientTheme, configs []ThemeConfiguration) ThemeSnapshot {
+	s := ThemeSnapshot{
+		LogoURL:              theme.LogoURL,
+		LightPrimaryColor:    theme.LightPrimaryColor,
+		LightBackgroundColor: theme.LightBackgroundColor,
+		LightButtonColor:     theme.LightButtonColor,
+		LightTextColor:       theme.LightTextColor,
+		DarkPrimaryColor:     theme.DarkPrimaryColor,
+		DarkBackgroundColor:  theme.DarkBackgroundColor,
+		DarkButtonColor:      theme.DarkButtonColor,
+		DarkTextColor:        theme.DarkTextColor,
+		DefaultMode:          theme.DefaultMode,
+		AllowModeToggle:      theme.AllowModeToggle,
+		Configurations:       make([]SnapshotConfig, 0, len(configs)),
+	}
+	for _, c := range configs {
+		s.Configurations = append(s.Configurations, SnapshotConfig{Key: c.ConfigKey, Value: c.ConfigValue, Type: c.ConfigType})
+	}
+	s.SortConfigurations()
+	return s
+}
+
+// ApplyTo copies the snapshot's theme fields onto theme. The configuration
+// is left to the caller.
+func (s ThemeSnapshot) ApplyTo(theme *ClientTheme) {
+	theme.LogoURL = s.LogoURL
+	theme.LightPrimaryColor = s.LightPrimaryColor
+	theme.LightBackgroundColor = s.LightBackgroundColor
+	theme.LightButtonColor = s.LightButtonColor
+	theme.LightTextColor = s.LightTextColor
+	theme.DarkPrimaryColor = s.DarkPrimaryColor
+	theme.DarkBackgroundColor = s.DarkBackgroundColor
+	theme.DarkButtonColor = s.DarkButtonColor
+	theme.DarkTextColor = s.DarkTextColor
+	theme.DefaultMode = s.DefaultMode
+	theme.AllowModeToggle = s.AllowModeToggle
+}
+
+// SortConfigurations orders the configuration entries by key.
+func (s ThemeSnapshot) SortConfigurations() {
+	slices.SortFunc(s.Configurations, func(a, b SnapshotConfig) int { return strings.Compare(a.Key, b.Key) })
+}
+
+// Clone returns a copy that shares no memory with s.
+func (s ThemeSnapshot) Clone() ThemeSnapshot {
+	s.Configurations = append([]SnapshotConfig{}, s.Configurations...)
+	return s
+}
+
+// FieldChange is one difference between two snapshots. Field is a column
+// name, or "configurations.<key>" with the whole entry as From and To. A nil
+// From or To means the value was unset or the entry absent.
+type FieldChange struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+// Diff lists the changes from s to other, theme fields first in column order,
+// then configuration entries by key.
+func (s ThemeSnapshot) Diff(other ThemeSnapshot) []FieldChange {
+	changes := []FieldChange{}
+	fields := []struct {
+		name     string
+		from, to *string
+	}{
+		{"logo_url", s.LogoURL, other.LogoURL},
+		{"light_primary_color", s.LightPrimaryColor, other.LightPrimaryColor},
+		{"light_background_color", s.LightBackgroundColor, other.LightBackgroundColor},
+		{"light_button_color", s.LightButtonColor, other.LightButtonColor},
+		{"light_text_color", s.LightTextColor, other.LightTextColor},
+		{"dark_primary_color", s.DarkPrimaryColor, other.DarkPrimaryColor},
+		{"dark_background_color", s.DarkBackgroundColor, other.DarkBackgroundColor},
+		{"dark_button_color", s.DarkButtonColor, other.DarkButtonColor},
+		{"dark_text_color", s.DarkTextColor, other.DarkTextColor},
+		{"default_mode", s.DefaultMode, other.DefaultMode},
+	}
+	for _, f := range fields {
+		if safeString(f.from) != safeString(f.to) || (f.from == nil) != (f.to == nil) {
+			changes = append(changes, FieldChange{f.name, stringOrNil(f.from), stringOrNil(f.to)})
+		}
+	}
+	if s.AllowModeToggle != other.AllowModeToggle {
+		changes = append(changes, FieldChange{"allow_mode_toggle", s.AllowModeToggle, other.AllowModeToggle})
+	}
+
+	from := make(map[string]SnapshotConfig, len(s.Configurations))
+	for _, c := range s.Configurations {
+		from[c.Key] = c
+	}
+	to := make(map[string]SnapshotConfig, len(other.Configurations))
+	for _, c := range other.Configurations {
+		to[c.Key] = c
+	}
+	keys := slices.Sorted(maps.Keys(from))
+	for key := range to {
+		if _, ok := from[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		f, inFrom := from[key]
+		t, inTo := to[key]
+		switch {
+		case !inTo:
+			changes = append(changes, FieldChange{"configurations." + key, f, nil})
+		case !inFrom:
+			changes = append(changes, FieldChange{"configurations." + key, nil, t})
+		case f != t:
+			changes = append(changes, FieldChange{"configurations." + key, f, t})
+		}
+	}
+	return changes
+}
+
+func stringOrNil(s *string) any {
+	if s == nil {
+		return nil
+	}
+	return *s
+}
+
+// Value implements driver.Valuer.
+func (s ThemeSnapshot) Value() (driver.Value, error) {
+	body, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return string(body), nil
+}
+
+// Scan implements sql.Scanner.
+func (s *ThemeSnapshot) Scan(src any) error {
+	var body []byte
+	switch v := src.(type) {
+	case []byte:
+		body = v
+	case string:
+		body = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type %T for ThemeSnapshot", src)
+	}
+	*s = ThemeSnapshot{}
+	if err := json.Unmarshal(body, s); err != nil {
+		return fmt.Errorf("decoding theme snapshot: %w", err)
+	}
+	if s.Configurations == nil {
+		s.Configurations = []SnapshotConfig{}
+	}
+	return nil
+}
+
+// ThemeVersion represents a row in the append-only theme_versions table: the
+// content of a theme after one change. Versions are numbered per theme from 1.
+type ThemeVersion struct {
+	ID            int64         `db:"id" json:"id"`
+	ThemeID       int           `db:"theme_id" json:"theme_id"`
+	Version       int           `db:"version" json:"version"`
+	Snapshot      ThemeSnapshot `db:"snapshot" json:"snapshot"`
+	Author        string        `db:"author" json:"author"`
+	Change        string        `db:"change_type" json:"change"`
+	SourceVersion *int          `db:"source_version" json:"source_version,omitempty"` // restored by a rollback, or a published draft's base
+	CreatedAt     time.Time     `db:"created_at" json:"created_at"`
+}
+
+// ThemeDraft represents the theme_drafts table: unpublished edits to a theme,
+// at most one per theme, based on the version that was current when saved.
+type ThemeDraft struct {
+	ThemeID     int           `db:"theme_id" json:"theme_id"`
+	Snapshot    ThemeSnapshot `db:"snapshot" json:"snapshot"`
+	BaseVersion int           `db:"base_version" json:"base_version"`
+	Author      string        `db:"author" json:"author"`
+	CreatedAt   time.Time     `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time     `db:"updated_at" json:"updated_at"`
+}
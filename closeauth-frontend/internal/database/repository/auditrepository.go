@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"closeauth-frontend/internal/database"
+	"closeauth-frontend/internal/database/models"
+)
+
+// auditChainLockID serialises appends so each row links to its true predecessor.
+const auditChainLockID = 0x61756469 // "audi"
+
+const auditColumns = `id, occurred_at, actor_id, actor_email, tenant_id, client_id, action, target,
+               request_id, ip_address, before_summary, after_summary, outcome, status_code,
+               prev_hash, hash`
+
+// genesisHash is the prev_hash of the first audit row.
+var genesisHash = strings.Repeat("0", 64)
+
+// AuditFilter narrows audit queries. Zero values are ignored.
+type AuditFilter struct {
+	ActorID  string
+	TenantID string
+	Action   string
+	Outcome  string
+	From     time.Time
+	To       time.Time
+	Limit    int
+	Offset   int
+}
+
+type AuditRepository struct {
+	db *database.Database
+}
+
+var _ AuditStore = (*AuditRepository)(nil)
+
+func NewAuditRepository(db *database.Database) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// Append links the event to the end of the chain and inserts it.
+// ID, PrevHash and Hash are filled in on success.
+func (r *AuditRepository) Append(ctx context.Context, event *models.AuditEvent) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin audit transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// SQLite transactions already hold the database write lock (_txlock=immediate)
+	if !r.db.SQLite() {
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, auditChainLockID); err != nil {
+			return fmt.Errorf("failed to lock audit chain: %w", err)
+		}
+	}
+
+	prevHash := genesisHash
+	err = tx.GetContext(ctx, &prevHash, `SELECT hash FROM admin_audit_log ORDER BY id DESC LIMIT 1`)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to read audit chain head: %w", err)
+	}
+
+	event.OccurredAt = event.OccurredAt.UTC().Truncate(time.Microsecond)
+	event.PrevHash = prevHash
+	event.Hash = event.ComputeHash(prevHash)
+
+	query := `
+        INSERT INTO admin_audit_log (occurred_at, actor_id, actor_email, tenant_id, client_id, action, target,
+                                     request_id, ip_address, before_summary, after_summary, outcome, status_code,
+                                     prev_hash, hash)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+        RETURNING id
+    `
+	err = tx.GetContext(ctx, &event.ID, query,
+		event.OccurredAt, event.ActorID, event.ActorEmail, event.TenantID, event.ClientID, event.Action, event.Target,
+		event.RequestID, event.IPAddress, event.Before, event.After, event.Outcome, event.StatusCode,
+		event.PrevHash, event.Hash,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert audit event: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit audit event: %w", err)
+	}
+	return nil
+}
+
+// List returns matching events, newest first, along with the total match count.
+func (r *AuditRepository) List(ctx context.Context, filter AuditFilter) ([]models.AuditEvent, int, error) {
+	where, args := auditWhere(filter)
+
+	var total int
+	if err := r.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM admin_audit_log`+where, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit events: %w", err)
+	}
+
+	query := `SELECT ` + auditColumns + ` FROM admin_audit_log` + where + ` ORDER BY id DESC`
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit, filter.Offset)
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+	}
+
+	var events []models.AuditEvent
+	if err := r.db.SelectContext(ctx, &events, query, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to list audit events: %w", err)
+	}
+
+	return events, total, nil
+}
+
+// VerifyChain walks the whole chain and returns the ID of the first row whose
+// hash does not match, or 0 if the chain is intact.
+func (r *AuditRepository) VerifyChain(ctx context.Context) (int64, error) {
+	rows, err := r.db.QueryxContext(ctx, `SELECT `+auditColumns+` FROM admin_audit_log ORDER BY id ASC`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read audit chain: %w", err)
+	}
+	defer rows.Close()
+
+	prevHash := genesisHash
+	for rows.Next() {
+		var event models.AuditEvent
+		if err := rows.StructScan(&event); err != nil {
+			return 0, fmt.Errorf("failed to scan audit event: %w", err)
+		}
+		if event.PrevHash != prevHash || event.ComputeHash(prevHash) != event.Hash {
+			return event.ID, nil
+		}
+		prevHash = event.Hash
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read audit chain: %w", err)
+	}
+
+	return 0, nil
+}
+
+func auditWhere(filter AuditFilter) (string, []any) {
+	var conditions []string
+	var args []any
+	add := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.ActorID != "" {
+		add("actor_id = $%d", filter.ActorID)
+	}
+	if filter.TenantID != "" {
+		add("tenant_id = $%d", filter.TenantID)
+	}
+	if filter.Action != "" {
+		add("action = $%d", filter.Action)
+	}
+	if filter.Outcome != "" {
+		add("outcome = $%d", filter.Outcome)
+	}
+	// UTC, like the stored values, so SQLite's text comparison agrees with Postgres
+	if !filter.From.IsZero() {
+		add("occurred_at >= $%d", filter.From.UTC())
+	}
+	if !filter.To.IsZero() {
+		add("occurred_at < $%d", filter.To.UTC())
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
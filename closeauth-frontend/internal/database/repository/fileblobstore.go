@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"closeauth-frontend/internal/database/models"
+)
+
+// blobContentTypes maps a blob key's extension to the type it is served as.
+var blobContentTypes = map[string]string{
+	".png": "image/png",
+	".jpg": "image/jpeg",
+	".svg": "image/svg+xml",
+}
+
+// FileBlobStore is a BlobStore keeping each blob in its own file under dir,
+// sharded by the first two characters of the key. The content type is implied
+// by the key's extension. Writes go through a temporary file and a rename, so
+// readers never see a partial blob.
+type FileBlobStore struct {
+	dir string
+}
+
+var _ BlobStore = (*FileBlobStore)(nil)
+
+// NewFileBlobStore returns a store rooted at dir, creating it if needed.
+func NewFileBlobStore(dir string) (*FileBlobStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create asset directory: %w", err)
+	}
+	return &FileBlobStore{dir: dir}, nil
+}
+
+// path returns the file holding key, which must be a valid blob key so it
+// cannot name anything outside dir.
+func (s *FileBlobStore) path(key string) (string, error) {
+	if !models.IsAssetBlobKey(key) {
+		return "", &ValidationError{"blob_key", "must be a content hash with a png, jpg or svg extension"}
+	}
+	return filepath.Join(s.dir, key[:2], key), nil
+}
+
+// PutBlob writes a blob unless its file already exists
+func (s *FileBlobStore) PutBlob(ctx context.Context, blob *models.AssetBlob) error {
+	path, err := s.path(blob.Key)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("failed to store blob %s: %w", blob.Key, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to store blob %s: %w", blob.Key, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(blob.Data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to store blob %s: %w", blob.Key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to store blob %s: %w", blob.Key, err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o640); err != nil {
+		return fmt.Errorf("failed to store blob %s: %w", blob.Key, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to store blob %s: %w", blob.Key, err)
+	}
+	return nil
+}
+
+// GetBlob reads a blob's file
+func (s *FileBlobStore) GetBlob(ctx context.Context, key string) (*models.AssetBlob, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, fmt.Errorf("blob %s not found: %w", key, ErrNotFound)
+	}
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("blob %s not found: %w", key, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blob %s: %w", key, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blob %s: %w", key, err)
+	}
+	return &models.AssetBlob{
+		Key:         key,
+		ContentType: blobContentTypes[filepath.Ext(key)],
+		Data:        data,
+		CreatedAt:   info.ModTime().UTC(),
+	}, nil
+}
+
+// DeleteBlob removes a blob's file
+func (s *FileBlobStore) DeleteBlob(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete blob %s: %w", key, err)
+	}
+	return nil
+}
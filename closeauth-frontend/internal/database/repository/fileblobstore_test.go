@@ -0,0 +1,21 @@
+package repository_test
+
+import (
+	"testing"
+
+	"closeauth-frontend/internal/database/repository"
+	"closeauth-frontend/internal/database/repository/storetest"
+)
+
+func TestFileBlobStore_Conformance(t *testing.T) {
+	storetest.BlobStore(t, func(t *testing.T) repository.BlobStore { return newFileBlobStore(t) })
+}
+
+// newFileBlobStore returns a store in a temporary directory.
+func newFileBlobStore(t *testing.T) *repository.FileBlobStore {
+	store, err := repository.NewFileBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"closeauth-frontend/internal/database/models"
+)
+
+// MemoryAssetStore is a thread-safe, in-memory AssetStore and BlobStore with
+// the same semantics as AssetRepository. It backs unit tests and
+// database-less runs.
+type MemoryAssetStore struct {
+	mu     sync.RWMutex
+	assets map[int64]models.ThemeAsset
+	blobs  map[string]models.AssetBlob
+	nextID int64
+}
+
+var (
+	_ AssetStore = (*MemoryAssetStore)(nil)
+	_ BlobStore  = (*MemoryAssetStore)(nil)
+)
+
+func NewMemoryAssetStore() *MemoryAssetStore {
+	return &MemoryAssetStore{
+		assets: make(map[int64]models.ThemeAsset),
+		blobs:  make(map[string]models.AssetBlob),
+	}
+}
+
+// CreateAsset stores a copy of an asset and its variants
+func (m *MemoryAssetStore) CreateAsset(ctx context.Context, asset *models.ThemeAsset) error {
+	if err := ValidateAsset(asset); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextID++
+	asset.ID = m.nextID
+	asset.CreatedAt = time.Now().UTC()
+	for i := range asset.Variants {
+		asset.Variants[i].AssetID = asset.ID
+	}
+	m.assets[asset.ID] = copyAsset(*asset)
+	return nil
+}
+
+// ListAssets retrieves a client's assets, newest first
+func (m *MemoryAssetStore) ListAssets(ctx context.Context, clientID string) ([]models.ThemeAsset, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var assets []models.ThemeAsset
+	for _, asset := range m.assets {
+		if asset.ClientID == clientID {
+			assets = append(assets, copyAsset(asset))
+		}
+	}
+	slices.SortFunc(assets, func(a, b models.ThemeAsset) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.ID, a.ID)
+	})
+	return assets, nil
+}
+
+// FindAsset retrieves one of a client's assets
+func (m *MemoryAssetStore) FindAsset(ctx context.Context, clientID string, assetID int64) (*models.ThemeAsset, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	asset, ok := m.assets[assetID]
+	if !ok || asset.ClientID != clientID {
+		return nil, fmt.Errorf("asset with ID %d not found for client %s: %w", assetID, clientID, ErrNotFound)
+	}
+	asset = copyAsset(asset)
+	return &asset, nil
+}
+
+// DeleteAsset deletes an asset and the blobs nothing else refers to
+func (m *MemoryAssetStore) DeleteAsset(ctx context.Context, clientID string, assetID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	asset, ok := m.assets[assetID]
+	if !ok || asset.ClientID != clientID {
+		return fmt.Errorf("asset with ID %d not found for client %s: %w", assetID, clientID, ErrNotFound)
+	}
+	delete(m.assets, assetID)
+
+	referenced := make(map[string]bool)
+	for _, other := range m.assets {
+		for _, v := range other.Variants {
+			referenced[v.Key] = true
+		}
+	}
+	for _, v := range asset.Variants {
+		if !referenced[v.Key] {
+			delete(m.blobs, v.Key)
+		}
+	}
+	return nil
+}
+
+// PutBlob stores a copy of a blob unless its key exists
+func (m *MemoryAssetStore) PutBlob(ctx context.Context, blob *models.AssetBlob) error {
+	if !models.IsAssetBlobKey(blob.Key) {
+		return &ValidationError{"blob_key", "must be a content hash with a png, jpg or svg extension"}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.blobs[blob.Key]; ok {
+		return nil
+	}
+	stored := *blob
+	stored.Data = slices.Clone(blob.Data)
+	stored.CreatedAt = time.Now().UTC()
+	m.blobs[blob.Key] = stored
+	return nil
+}
+
+// GetBlob retrieves a blob by key
+func (m *MemoryAssetStore) GetBlob(ctx context.Context, key string) (*models.AssetBlob, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	blob, ok := m.blobs[key]
+	if !ok {
+		return nil, fmt.Errorf("blob %s not found: %w", key, ErrNotFound)
+	}
+	blob.Data = slices.Clone(blob.Data)
+	return &blob, nil
+}
+
+// DeleteBlob deletes a blob by key
+func (m *MemoryAssetStore) DeleteBlob(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.blobs, key)
+	return nil
+}
+
+// copyAsset returns asset with its own variant slice, smallest first.
+func copyAsset(asset models.ThemeAsset) models.ThemeAsset {
+	asset.Variants = slices.Clone(asset.Variants)
+	slices.SortFunc(asset.Variants, func(a, b models.ThemeAssetVariant) int {
+		if c := cmp.Compare(a.Width, b.Width); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return asset
+}
@@ -0,0 +1,18 @@
+package repository_test
+
+import (
+	"testing"
+
+	"closeauth-frontend/internal/database/repository"
+	"closeauth-frontend/internal/database/repository/storetest"
+)
+
+func TestMemoryAssetStore_Conformance(t *testing.T) {
+	storetest.AssetStore(t, func(t *testing.T) (repository.AssetStore, repository.BlobStore) {
+		store := repository.NewMemoryAssetStore()
+		return store, store
+	})
+	storetest.BlobStore(t, func(t *testing.T) repository.BlobStore {
+		return repository.NewMemoryAssetStore()
+	})
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"closeauth-frontend/internal/database/models"
+)
+
+// MemoryAuditStore is a thread-safe, in-memory AuditStore with the same
+// semantics as AuditRepository. It backs unit tests and database-less runs.
+type MemoryAuditStore struct {
+	mu     sync.RWMutex
+	events []models.AuditEvent // in chain order; IDs are positions + 1
+}
+
+var _ AuditStore = (*MemoryAuditStore)(nil)
+
+func NewMemoryAuditStore() *MemoryAuditStore {
+	return &MemoryAuditStore{}
+}
+
+// Append links the event to the end of the chain and stores a copy
+func (m *MemoryAuditStore) Append(ctx context.Context, event *models.AuditEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	prevHash := genesisHash
+	if n := len(m.events); n > 0 {
+		prevHash = m.events[n-1].Hash
+	}
+
+	event.ID = int64(len(m.events)) + 1
+	event.OccurredAt = event.OccurredAt.UTC().Truncate(time.Microsecond)
+	event.PrevHash = prevHash
+	event.Hash = event.ComputeHash(prevHash)
+	m.events = append(m.events, copyAuditEvent(*event))
+	return nil
+}
+
+// List returns matching events, newest first, along with the total match count
+func (m *MemoryAuditStore) List(ctx context.Context, filter AuditFilter) ([]models.AuditEvent, int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var matched []models.AuditEvent
+	for _, event := range slices.Backward(m.events) {
+		if auditMatches(&event, filter) {
+			matched = append(matched, copyAuditEvent(event))
+		}
+	}
+
+	total := len(matched)
+	if filter.Limit > 0 {
+		start := min(filter.Offset, total)
+		matched = matched[start:min(start+filter.Limit, total)]
+	}
+	return matched, total, nil
+}
+
+// VerifyChain walks the chain and returns the ID of the first broken event
+func (m *MemoryAuditStore) VerifyChain(ctx context.Context) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	prevHash := genesisHash
+	for _, event := range m.events {
+		if event.PrevHash != prevHash || event.ComputeHash(prevHash) != event.Hash {
+			return event.ID, nil
+		}
+		prevHash = event.Hash
+	}
+	return 0, nil
+}
+
+// auditMatches applies filter like auditWhere does in SQL.
+func auditMatches(event *models.AuditEvent, filter AuditFilter) bool {
+	switch {
+	case filter.ActorID != "" && event.ActorID != filter.ActorID,
+		filter.TenantID != "" && event.TenantID != filter.TenantID,
+		filter.Action != "" && event.Action != filter.Action,
+		filter.Outcome != "" && event.Outcome != filter.Outcome,
+		!filter.From.IsZero() && event.OccurredAt.Before(filter.From),
+		!filter.To.IsZero() && !event.OccurredAt.Before(filter.To):
+		return false
+	}
+	return true
+}
+
+func copyAuditEvent(event models.AuditEvent) models.AuditEvent {
+	event.Before = slices.Clone(event.Before)
+	event.After = slices.Clone(event.After)
+	return event
+}
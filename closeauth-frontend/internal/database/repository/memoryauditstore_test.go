@@ -0,0 +1,14 @@
+package repository_test
+
+import (
+	"testing"
+
+	"closeauth-frontend/internal/database/repository"
+	"closeauth-frontend/internal/database/repository/storetest"
+)
+
+func TestMemoryAuditStore_Conformance(t *testing.T) {
+	storetest.AuditStore(t, func(t *testing.T) repository.AuditStore {
+		return repository.NewMemoryAuditStore()
+	})
+}
@@ -0,0 +1,694 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"closeauth-frontend/internal/database/models"
+)
+
+// MemoryThemeStore is a thread-safe, in-memory ThemeStore with the same
+// semantics as ThemeRepository. It backs unit tests and database-less runs.
+type MemoryThemeStore struct {
+	mu       sync.RWMutex
+	themes   map[int]models.ClientTheme
+	configs  map[int]models.ThemeConfiguration
+	versions map[int][]models.ThemeVersion // by theme, oldest first
+	drafts   map[int]models.ThemeDraft     // by theme
+	nextID   int
+}
+
+var _ ThemeStore = (*MemoryThemeStore)(nil)
+
+func NewMemoryThemeStore() *MemoryThemeStore {
+	return &MemoryThemeStore{
+		themes:   make(map[int]models.ClientTheme),
+		configs:  make(map[int]models.ThemeConfiguration),
+		versions: make(map[int][]models.ThemeVersion),
+		drafts:   make(map[int]models.ThemeDraft),
+	}
+}
+
+// AddTheme stores a copy of theme, assigning ID and timestamps, and returns it.
+// Like the client_themes table, (client_id, theme_name) must be unique.
+func (m *MemoryThemeStore) AddTheme(theme models.ClientTheme) (models.ClientTheme, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, existing := range m.themes {
+		if existing.ClientID == theme.ClientID && existing.ThemeName == theme.ThemeName {
+			return models.ClientTheme{}, &ConflictError{fmt.Sprintf("theme '%s' already exists for client %s", theme.ThemeName, theme.ClientID)}
+		}
+	}
+
+	m.nextID++
+	now := time.Now().UTC()
+	theme.ID = m.nextID
+	theme.CreatedAt, theme.UpdatedAt = now, now
+	m.themes[theme.ID] = theme
+	return theme, nil
+}
+
+// AddConfiguration stores a configuration entry for an existing theme.
+// Like theme_configurations, (theme_id, config_key) must be unique.
+func (m *MemoryThemeStore) AddConfiguration(config models.ThemeConfiguration) (models.ThemeConfiguration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.addConfiguration(config)
+}
+
+// addConfiguration is AddConfiguration for callers holding m.mu.
+func (m *MemoryThemeStore) addConfiguration(config models.ThemeConfiguration) (models.ThemeConfiguration, error) {
+	if _, ok := m.themes[config.ThemeID]; !ok {
+		return models.ThemeConfiguration{}, fmt.Errorf("theme with ID %d not found: %w", config.ThemeID, ErrNotFound)
+	}
+	for _, existing := range m.configs {
+		if existing.ThemeID == config.ThemeID && existing.ConfigKey == config.ConfigKey {
+			return models.ThemeConfiguration{}, &ConflictError{fmt.Sprintf("configuration '%s' already exists for theme %d", config.ConfigKey, config.ThemeID)}
+		}
+	}
+
+	m.nextID++
+	now := time.Now().UTC()
+	config.ID = m.nextID
+	config.CreatedAt, config.UpdatedAt = now, now
+	if config.ConfigType == "" {
+		config.ConfigType = "string"
+	}
+	m.configs[config.ID] = config
+	return config, nil
+}
+
+// FindByClientID retrieves all active themes for a client
+func (m *MemoryThemeStore) FindByClientID(ctx context.Context, clientID string) ([]models.ClientTheme, error) {
+	return m.activeThemes(func(t models.ClientTheme) bool { return t.ClientID == clientID }), nil
+}
+
+// FindDefaultTheme retrieves the default theme for a client
+func (m *MemoryThemeStore) FindDefaultTheme(ctx context.Context, clientID string) (*models.ClientTheme, error) {
+	themes := m.activeThemes(func(t models.ClientTheme) bool { return t.ClientID == clientID && t.IsDefault })
+	if len(themes) == 0 {
+		return nil, fmt.Errorf("no default theme found for client %s: %w", clientID, ErrNotFound)
+	}
+	return &themes[0], nil
+}
+
+// FindByClientIDAndName retrieves a specific theme by name
+func (m *MemoryThemeStore) FindByClientIDAndName(ctx context.Context, clientID, themeName string) (*models.ClientTheme, error) {
+	themes := m.activeThemes(func(t models.ClientTheme) bool { return t.ClientID == clientID && t.ThemeName == themeName })
+	if len(themes) == 0 {
+		return nil, fmt.Errorf("theme '%s' not found for client %s: %w", themeName, clientID, ErrNotFound)
+	}
+	return &themes[0], nil
+}
+
+// FindThemeByID retrieves a theme by its ID
+func (m *MemoryThemeStore) FindThemeByID(ctx context.Context, themeID int) (*models.ClientTheme, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	theme, ok := m.themes[themeID]
+	if !ok {
+		return nil, fmt.Errorf("theme with ID %d not found: %w", themeID, ErrNotFound)
+	}
+	return &theme, nil
+}
+
+// FindConfigurationsByThemeID retrieves all configurations for a theme
+func (m *MemoryThemeStore) FindConfigurationsByThemeID(ctx context.Context, themeID int) ([]models.ThemeConfiguration, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var configs []models.ThemeConfiguration
+	for _, config := range m.configs {
+		if config.ThemeID == themeID {
+			configs = append(configs, config)
+		}
+	}
+	slices.SortFunc(configs, func(a, b models.ThemeConfiguration) int { return cmp.Compare(a.ConfigKey, b.ConfigKey) })
+	return configs, nil
+}
+
+// FindThemeWithConfig retrieves a theme with all its configurations
+func (m *MemoryThemeStore) FindThemeWithConfig(ctx context.Context, clientID, themeName string) (*models.ClientThemeWithConfig, error) {
+	theme, err := m.FindByClientIDAndName(ctx, clientID, themeName)
+	if err != nil {
+		return nil, err
+	}
+	configs, err := m.FindConfigurationsByThemeID(ctx, theme.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	configMap := make(map[string]models.ThemeConfiguration, len(configs))
+	for _, config := range configs {
+		configMap[config.ConfigKey] = config
+	}
+	return &models.ClientThemeWithConfig{Theme: *theme, Configurations: configMap}, nil
+}
+
+// GetConfigValue retrieves a specific configuration value
+func (m *MemoryThemeStore) GetConfigValue(ctx context.Context, themeID int, configKey string) (*models.ThemeConfiguration, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, config := range m.configs {
+		if config.ThemeID == themeID && config.ConfigKey == configKey {
+			return &config, nil
+		}
+	}
+	return nil, fmt.Errorf("configuration '%s' not found for theme %d: %w", configKey, themeID, ErrNotFound)
+}
+
+// ListAllThemes retrieves all active themes (useful for admin dashboard)
+func (m *MemoryThemeStore) ListAllThemes(ctx context.Context, limit, offset int) ([]models.ClientTheme, error) {
+	themes := m.activeThemes(func(models.ClientTheme) bool { return true })
+	if offset >= len(themes) {
+		return nil, nil
+	}
+	themes = themes[offset:]
+	if limit < len(themes) {
+		themes = themes[:limit]
+	}
+	return themes, nil
+}
+
+// CountThemesByClientID counts total themes for a client
+func (m *MemoryThemeStore) CountThemesByClientID(ctx context.Context, clientID string) (int, error) {
+	return len(m.activeThemes(func(t models.ClientTheme) bool { return t.ClientID == clientID })), nil
+}
+
+// activeThemes returns matching active themes in ThemeRepository's order:
+// client, default first, then name.
+func (m *MemoryThemeStore) activeThemes(match func(models.ClientTheme) bool) []models.ClientTheme {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var themes []models.ClientTheme
+	for _, theme := range m.themes {
+		if theme.IsActive && match(theme) {
+			themes = append(themes, theme)
+		}
+	}
+	sortThemes(themes)
+	return themes
+}
+
+// sortThemes orders themes by client, default first, then name.
+func sortThemes(themes []models.ClientTheme) {
+	slices.SortFunc(themes, func(a, b models.ClientTheme) int {
+		if c := cmp.Compare(a.ClientID, b.ClientID); c != 0 {
+			return c
+		}
+		if a.IsDefault != b.IsDefault {
+			if a.IsDefault {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(a.ThemeName, b.ThemeName)
+	})
+}
+
+// FindAllByClientID retrieves every theme for a client, including inactive ones
+func (m *MemoryThemeStore) FindAllByClientID(ctx context.Context, clientID string) ([]models.ClientTheme, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var themes []models.ClientTheme
+	for _, theme := range m.themes {
+		if theme.ClientID == clientID {
+			themes = append(themes, theme)
+		}
+	}
+	sortThemes(themes)
+	return themes, nil
+}
+
+// FindConfigurationByID retrieves one configuration entry of a theme
+func (m *MemoryThemeStore) FindConfigurationByID(ctx context.Context, themeID, configID int) (*models.ThemeConfiguration, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	config, ok := m.configs[configID]
+	if !ok || config.ThemeID != themeID {
+		return nil, fmt.Errorf("configuration %d not found for theme %d: %w", configID, themeID, ErrNotFound)
+	}
+	return &config, nil
+}
+
+// CreateTheme inserts a theme, making it the active default when requested
+// or when the client has none yet
+func (m *MemoryThemeStore) CreateTheme(ctx context.Context, theme *models.ClientTheme) error {
+	if theme.DefaultMode == nil {
+		light := "light"
+		theme.DefaultMode = &light
+	}
+	if err := ValidateTheme(theme); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	hasDefault := false
+	for _, existing := range m.themes {
+		if existing.ClientID != theme.ClientID {
+			continue
+		}
+		if existing.ThemeName == theme.ThemeName {
+			return &ConflictError{fmt.Sprintf("theme '%s' already exists for client %s", theme.ThemeName, theme.ClientID)}
+		}
+		hasDefault = hasDefault || (existing.IsActive && existing.IsDefault)
+	}
+
+	theme.IsDefault = theme.IsDefault || !hasDefault
+	theme.IsActive = theme.IsDefault
+	now := time.Now().UTC()
+	if theme.IsDefault {
+		m.deactivate(theme.ClientID, 0, now)
+	}
+
+	m.nextID++
+	theme.ID = m.nextID
+	theme.CreatedAt, theme.UpdatedAt = now, now
+	m.themes[theme.ID] = *theme
+	m.recordVersion(ctx, theme.ID, models.ThemeChangeCreate, nil)
+	return nil
+}
+
+// UpdateTheme saves a theme's logo, colours and mode settings
+func (m *MemoryThemeStore) UpdateTheme(ctx context.Context, theme *models.ClientTheme) error {
+	if err := ValidateTheme(theme); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	updated, err := m.updateTheme(ctx, theme)
+	if err != nil {
+		return err
+	}
+	*theme = updated
+	return nil
+}
+
+// ActivateTheme makes a theme the client's active default, deactivating the others
+func (m *MemoryThemeStore) ActivateTheme(ctx context.Context, clientID string, themeID int) (*models.ClientTheme, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	theme, err := m.activateTheme(ctx, clientID, themeID)
+	if err != nil {
+		return nil, err
+	}
+	return &theme, nil
+}
+
+// UpdateAndActivateTheme saves a theme and makes it the client's active default
+func (m *MemoryThemeStore) UpdateAndActivateTheme(ctx context.Context, theme *models.ClientTheme) error {
+	if err := ValidateTheme(theme); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, err := m.updateTheme(ctx, theme); err != nil {
+		return err
+	}
+	activated, err := m.activateTheme(ctx, theme.ClientID, theme.ID)
+	if err != nil {
+		return err
+	}
+	*theme = activated
+	return nil
+}
+
+// updateTheme saves the editable fields of a theme and records a version.
+// The caller holds m.mu.
+func (m *MemoryThemeStore) updateTheme(ctx context.Context, theme *models.ClientTheme) (models.ClientTheme, error) {
+	stored, ok := m.themes[theme.ID]
+	if !ok || stored.ClientID != theme.ClientID {
+		return models.ClientTheme{}, fmt.Errorf("theme with ID %d not found for client %s: %w", theme.ID, theme.ClientID, ErrNotFound)
+	}
+
+	stored.LogoURL = theme.LogoURL
+	stored.LightPrimaryColor = theme.LightPrimaryColor
+	stored.LightBackgroundColor = theme.LightBackgroundColor
+	stored.LightButtonColor = theme.LightButtonColor
+	stored.LightTextColor = theme.LightTextColor
+	stored.DarkPrimaryColor = theme.DarkPrimaryColor
+	stored.DarkBackgroundColor = theme.DarkBackgroundColor
+	stored.DarkButtonColor = theme.DarkButtonColor
+	stored.DarkTextColor = theme.DarkTextColor
+	stored.DefaultMode = theme.DefaultMode
+	stored.AllowModeToggle = theme.AllowModeToggle
+	stored.UpdatedAt = time.Now().UTC()
+	m.themes[stored.ID] = stored
+	m.recordVersion(ctx, stored.ID, models.ThemeChangeUpdate, nil)
+	return stored, nil
+}
+
+// activateTheme makes a theme its client's active default and records a
+// version. The caller holds m.mu.
+func (m *MemoryThemeStore) activateTheme(ctx context.Context, clientID string, themeID int) (models.ClientTheme, error) {
+	theme, ok := m.themes[themeID]
+	if !ok || theme.ClientID != clientID {
+		return models.ClientTheme{}, fmt.Errorf("theme with ID %d not found for client %s: %w", themeID, clientID, ErrNotFound)
+	}
+
+	now := time.Now().UTC()
+	m.deactivate(clientID, themeID, now)
+	theme.IsActive, theme.IsDefault, theme.UpdatedAt = true, true, now
+	m.themes[themeID] = theme
+	m.recordVersion(ctx, themeID, models.ThemeChangeActivate, nil)
+	return theme, nil
+}
+
+// DeleteTheme deletes an inactive theme and its configuration, keeping its
+// version history
+func (m *MemoryThemeStore) DeleteTheme(ctx context.Context, clientID string, themeID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	theme, ok := m.themes[themeID]
+	if !ok || theme.ClientID != clientID {
+		return fmt.Errorf("theme with ID %d not found for client %s: %w", themeID, clientID, ErrNotFound)
+	}
+	if theme.IsActive {
+		return &ConflictError{"cannot delete the active theme, activate another theme first"}
+	}
+
+	m.recordVersion(ctx, themeID, models.ThemeChangeDelete, nil)
+	delete(m.themes, themeID)
+	for id, config := range m.configs {
+		if config.ThemeID == themeID {
+			delete(m.configs, id)
+		}
+	}
+	delete(m.drafts, themeID)
+	return nil
+}
+
+// CreateConfiguration inserts a configuration entry for an existing theme
+func (m *MemoryThemeStore) CreateConfiguration(ctx context.Context, config *models.ThemeConfiguration) error {
+	if config.ConfigType == "" {
+		config.ConfigType = "string"
+	}
+	if err := ValidateConfiguration(config); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	saved, err := m.addConfiguration(*config)
+	if err != nil {
+		return err
+	}
+	m.touch(saved.ThemeID, saved.CreatedAt)
+	m.recordVersion(ctx, saved.ThemeID, models.ThemeChangeConfigCreate, nil)
+	*config = saved
+	return nil
+}
+
+// UpdateConfiguration saves a configuration entry's value and type
+func (m *MemoryThemeStore) UpdateConfiguration(ctx context.Context, config *models.ThemeConfiguration) error {
+	if config.ConfigType == "" {
+		config.ConfigType = "string"
+	}
+	if err := validateConfigValue(config.ConfigType, config.ConfigValue); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.configs[config.ID]
+	if !ok || stored.ThemeID != config.ThemeID {
+		return fmt.Errorf("configuration %d not found for theme %d: %w", config.ID, config.ThemeID, ErrNotFound)
+	}
+	stored.ConfigValue = config.ConfigValue
+	stored.ConfigType = config.ConfigType
+	stored.UpdatedAt = time.Now().UTC()
+	m.configs[stored.ID] = stored
+	m.touch(stored.ThemeID, stored.UpdatedAt)
+	m.recordVersion(ctx, stored.ThemeID, models.ThemeChangeConfigUpdate, nil)
+
+	*config = stored
+	return nil
+}
+
+// DeleteConfiguration deletes one configuration entry of a theme
+func (m *MemoryThemeStore) DeleteConfiguration(ctx context.Context, themeID, configID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	config, ok := m.configs[configID]
+	if !ok || config.ThemeID != themeID {
+		return fmt.Errorf("configuration %d not found for theme %d: %w", configID, themeID, ErrNotFound)
+	}
+	delete(m.configs, configID)
+	m.touch(themeID, time.Now().UTC())
+	m.recordVersion(ctx, themeID, models.ThemeChangeConfigDelete, nil)
+	return nil
+}
+
+// deactivate clears the active and default flags of a client's themes other
+// than keepID. The caller holds m.mu.
+func (m *MemoryThemeStore) deactivate(clientID string, keepID int, now time.Time) {
+	for id, theme := range m.themes {
+		if theme.ClientID == clientID && id != keepID && (theme.IsActive || theme.IsDefault) {
+			theme.IsActive, theme.IsDefault, theme.UpdatedAt = false, false, now
+			m.themes[id] = theme
+		}
+	}
+}
+
+// ListThemeVersions retrieves a theme's history, newest first
+func (m *MemoryThemeStore) ListThemeVersions(ctx context.Context, clientID string, themeID int) ([]models.ThemeVersion, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, err := m.clientTheme(clientID, themeID); err != nil {
+		return nil, err
+	}
+	stored := m.versions[themeID]
+	versions := make([]models.ThemeVersion, 0, len(stored))
+	for i := len(stored) - 1; i >= 0; i-- {
+		versions = append(versions, cloneVersion(stored[i]))
+	}
+	return versions, nil
+}
+
+// FindThemeVersion retrieves one version of a theme
+func (m *MemoryThemeStore) FindThemeVersion(ctx context.Context, clientID string, themeID, version int) (*models.ThemeVersion, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, err := m.clientTheme(clientID, themeID); err != nil {
+		return nil, err
+	}
+	return m.findVersion(themeID, version)
+}
+
+// RollbackTheme restores an earlier version as a new one
+func (m *MemoryThemeStore) RollbackTheme(ctx context.Context, clientID string, themeID, version int) (*models.ThemeVersion, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	theme, err := m.clientTheme(clientID, themeID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := m.findVersion(themeID, version)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.applySnapshot(theme, &target.Snapshot); err != nil {
+		return nil, err
+	}
+	return m.recordVersion(ctx, themeID, models.ThemeChangeRollback, &version), nil
+}
+
+// FindThemeDraft retrieves a theme's unpublished draft
+func (m *MemoryThemeStore) FindThemeDraft(ctx context.Context, clientID string, themeID int) (*models.ThemeDraft, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, err := m.clientTheme(clientID, themeID); err != nil {
+		return nil, err
+	}
+	draft, ok := m.drafts[themeID]
+	if !ok {
+		return nil, fmt.Errorf("no draft found for theme %d: %w", themeID, ErrNotFound)
+	}
+	draft.Snapshot = draft.Snapshot.Clone()
+	return &draft, nil
+}
+
+// SaveThemeDraft creates or replaces a theme's draft
+func (m *MemoryThemeStore) SaveThemeDraft(ctx context.Context, clientID string, draft *models.ThemeDraft) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	theme, err := m.clientTheme(clientID, draft.ThemeID)
+	if err != nil {
+		return err
+	}
+	if err := validateSnapshot(&theme, &draft.Snapshot); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	stored, ok := m.drafts[draft.ThemeID]
+	if !ok {
+		// A replaced draft keeps the base it was started from
+		stored = models.ThemeDraft{ThemeID: draft.ThemeID, BaseVersion: len(m.versions[draft.ThemeID]), CreatedAt: now}
+	}
+	stored.Snapshot = draft.Snapshot.Clone()
+	stored.Author = AuthorFromContext(ctx)
+	stored.UpdatedAt = now
+	m.drafts[draft.ThemeID] = stored
+
+	*draft = stored
+	draft.Snapshot = stored.Snapshot.Clone()
+	return nil
+}
+
+// DiscardThemeDraft deletes a theme's draft
+func (m *MemoryThemeStore) DiscardThemeDraft(ctx context.Context, clientID string, themeID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, err := m.clientTheme(clientID, themeID)
+	if _, ok := m.drafts[themeID]; err != nil || !ok {
+		return fmt.Errorf("no draft found for theme %d: %w", themeID, ErrNotFound)
+	}
+	delete(m.drafts, themeID)
+	return nil
+}
+
+// PublishThemeDraft applies a theme's draft as a new version
+func (m *MemoryThemeStore) PublishThemeDraft(ctx context.Context, clientID string, themeID int) (*models.ThemeVersion, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	theme, err := m.clientTheme(clientID, themeID)
+	if err != nil {
+		return nil, err
+	}
+	draft, ok := m.drafts[themeID]
+	if !ok {
+		return nil, fmt.Errorf("no draft found for theme %d: %w", themeID, ErrNotFound)
+	}
+	if latest := len(m.versions[themeID]); latest != draft.BaseVersion {
+		return nil, &ConflictError{fmt.Sprintf("theme %d has changed since version %d the draft is based on", themeID, draft.BaseVersion)}
+	}
+
+	snapshot := draft.Snapshot.Clone()
+	if err := m.applySnapshot(theme, &snapshot); err != nil {
+		return nil, err
+	}
+	published := m.recordVersion(ctx, themeID, models.ThemeChangePublish, &draft.BaseVersion)
+	delete(m.drafts, themeID)
+	return published, nil
+}
+
+// clientTheme returns a theme owned by clientID. The caller holds m.mu.
+func (m *MemoryThemeStore) clientTheme(clientID string, themeID int) (models.ClientTheme, error) {
+	theme, ok := m.themes[themeID]
+	if !ok || theme.ClientID != clientID {
+		return models.ClientTheme{}, fmt.Errorf("theme with ID %d not found for client %s: %w", themeID, clientID, ErrNotFound)
+	}
+	return theme, nil
+}
+
+// findVersion returns a copy of one version. The caller holds m.mu.
+func (m *MemoryThemeStore) findVersion(themeID, version int) (*models.ThemeVersion, error) {
+	versions := m.versions[themeID]
+	if version < 1 || version > len(versions) {
+		return nil, fmt.Errorf("version %d not found for theme %d: %w", version, themeID, ErrNotFound)
+	}
+	v := cloneVersion(versions[version-1])
+	return &v, nil
+}
+
+// touch bumps a theme's UpdatedAt after a configuration change. The caller
+// holds m.mu.
+func (m *MemoryThemeStore) touch(themeID int, now time.Time) {
+	if theme, ok := m.themes[themeID]; ok {
+		theme.UpdatedAt = now
+		m.themes[themeID] = theme
+	}
+}
+
+// recordVersion appends a snapshot of the theme as stored. The caller holds m.mu.
+func (m *MemoryThemeStore) recordVersion(ctx context.Context, themeID int, change string, source *int) *models.ThemeVersion {
+	theme := m.themes[themeID]
+	var configs []models.ThemeConfiguration
+	for _, config := range m.configs {
+		if config.ThemeID == themeID {
+			configs = append(configs, config)
+		}
+	}
+
+	m.nextID++
+	v := models.ThemeVersion{
+		ID:            int64(m.nextID),
+		ThemeID:       themeID,
+		Version:       len(m.versions[themeID]) + 1,
+		Snapshot:      models.NewThemeSnapshot(&theme, configs),
+		Author:        AuthorFromContext(ctx),
+		Change:        change,
+		SourceVersion: source,
+		CreatedAt:     time.Now().UTC(),
+	}
+	m.versions[themeID] = append(m.versions[themeID], v)
+
+	v = cloneVersion(v)
+	return &v
+}
+
+// applySnapshot validates snapshot and writes it over theme, replacing its
+// configuration. The caller holds m.mu.
+func (m *MemoryThemeStore) applySnapshot(theme models.ClientTheme, snapshot *models.ThemeSnapshot) error {
+	if err := validateSnapshot(&theme, snapshot); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	snapshot.ApplyTo(&theme)
+	theme.UpdatedAt = now
+	m.themes[theme.ID] = theme
+
+	for id, config := range m.configs {
+		if config.ThemeID == theme.ID {
+			delete(m.configs, id)
+		}
+	}
+	for _, c := range snapshot.Configurations {
+		m.nextID++
+		m.configs[m.nextID] = models.ThemeConfiguration{
+			ID: m.nextID, ThemeID: theme.ID,
+			ConfigKey: c.Key, ConfigValue: c.Value, ConfigType: c.Type,
+			CreatedAt: now, UpdatedAt: now,
+		}
+	}
+	return nil
+}
+
+func cloneVersion(v models.ThemeVersion) models.ThemeVersion {
+	v.Snapshot = v.Snapshot.Clone()
+	if v.SourceVersion != nil {
+		source := *v.SourceVersion
+		v.SourceVersion = &source
+	}
+	return v
+}
@@ -0,0 +1,15 @@
+package repository_test
+
+import (
+	"testing"
+
+	"closeauth-frontend/internal/database/repository"
+	"closeauth-frontend/internal/database/repository/storetest"
+)
+
+func TestMemoryThemeStore_Conformance(t *testing.T) {
+	storetest.ThemeStore(t, func(t *testing.T) (repository.ThemeStore, storetest.ThemeSeeder) {
+		store := repository.NewMemoryThemeStore()
+		return store, store
+	})
+}
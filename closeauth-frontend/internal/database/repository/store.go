@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"closeauth-frontend/internal/database/models"
+)
+
+var (
+	// ErrNotFound is wrapped by every store lookup that matches no row.
+	ErrNotFound = errors.New("not found")
+
+	// ErrConflict is matched, through a ConflictError, when a write would
+	// duplicate a unique name or key, or would break an invariant such as
+	// deleting the active theme.
+	ErrConflict = errors.New("conflict")
+)
+
+// ConflictError is the ErrConflict a write fails with; Message is fit to
+// show the admin.
+type ConflictError struct {
+	Message string
+}
+
+func (e *ConflictError) Error() string {
+	return e.Message
+}
+
+// Is makes a ConflictError match ErrConflict.
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// ValidationError reports a field rejected before a write.
+type ValidationError struct {
+	Field  string // column name, e.g. light_primary_color
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s %s", e.Field, e.Reason)
+}
+
+type authorKey struct{}
+
+// WithAuthor returns a copy of ctx naming the admin that theme versions and
+// drafts written with it are attributed to.
+func WithAuthor(ctx context.Context, author string) context.Context {
+	return context.WithValue(ctx, authorKey{}, author)
+}
+
+// AuthorFromContext returns the author stored by WithAuthor, or "".
+func AuthorFromContext(ctx context.Context) string {
+	author, _ := ctx.Value(authorKey{}).(string)
+	return author
+}
+
+// ThemeStore reads and writes client themes and their extended configuration.
+//
+// Implementations must behave identically; storetest.ThemeStore is the
+// shared conformance suite. Only active themes are returned by client or name
+// lookups and listings; FindAllByClientID and FindThemeByID ignore is_active.
+//
+// Writes keep exactly one active theme per client, which is also its default:
+// the first theme a client creates and every activated theme become the
+// active default, and all of the client's other themes are deactivated.
+//
+// Every write that changes a theme's content, configuration or activation, and
+// every deletion, also appends an immutable ThemeVersion, in the same
+// transaction, attributed to the author in ctx (see WithAuthor). A deleted
+// theme's history is kept. Seeded fixtures start without versions.
+type ThemeStore interface {
+	// FindByClientID returns a client's active themes, default first, then by name.
+	FindByClientID(ctx context.Context, clientID string) ([]models.ClientTheme, error)
+
+	// FindDefaultTheme returns the client's active default theme.
+	FindDefaultTheme(ctx context.Context, clientID string) (*models.ClientTheme, error)
+
+	// FindByClientIDAndName returns an active theme by name.
+	FindByClientIDAndName(ctx context.Context, clientID, themeName string) (*models.ClientTheme, error)
+
+	// FindThemeByID returns a theme whether or not it is active.
+	FindThemeByID(ctx context.Context, themeID int) (*models.ClientTheme, error)
+
+	// FindConfigurationsByThemeID returns a theme's configuration, ordered by key.
+	FindConfigurationsByThemeID(ctx context.Context, themeID int) ([]models.ThemeConfiguration, error)
+
+	// FindThemeWithConfig returns an active theme with its configuration keyed by config_key.
+	FindThemeWithConfig(ctx context.Context, clientID, themeName string) (*models.ClientThemeWithConfig, error)
+
+	// GetConfigValue returns one configuration entry.
+	GetConfigValue(ctx context.Context, themeID int, configKey string) (*models.ThemeConfiguration, error)
+
+	// ListAllThemes pages through every active theme ordered by client, default
+	// first, then name.
+	ListAllThemes(ctx context.Context, limit, offset int) ([]models.ClientTheme, error)
+
+	// CountThemesByClientID counts a client's active themes.
+	CountThemesByClientID(ctx context.Context, clientID string) (int, error)
+
+	// FindAllByClientID returns all of a client's themes, active or not,
+	// default first, then by name.
+	FindAllByClientID(ctx context.Context, clientID string) ([]models.ClientTheme, error)
+
+	// FindConfigurationByID returns one configuration entry of a theme.
+	FindConfigurationByID(ctx context.Context, themeID, configID int) (*models.ThemeConfiguration, error)
+
+	// CreateTheme validates and inserts theme, filling in ID, flags and
+	// timestamps. It is created inactive unless IsDefault is set or the client
+	// has no active default yet, in which case it becomes the active default.
+	CreateTheme(ctx context.Context, theme *models.ClientTheme) error
+
+	// UpdateTheme validates and saves the editable fields of the theme with
+	// theme.ID owned by theme.ClientID: logo, colours and mode settings. The
+	// name and the active/default flags are left alone; theme is refreshed
+	// from the stored row.
+	UpdateTheme(ctx context.Context, theme *models.ClientTheme) error
+
+	// ActivateTheme makes a theme its client's active default and deactivates
+	// the client's other themes.
+	ActivateTheme(ctx context.Context, clientID string, themeID int) (*models.ClientTheme, error)
+
+	// UpdateAndActivateTheme is UpdateTheme followed by ActivateTheme in one
+	// transaction: either both take effect or neither does.
+	UpdateAndActivateTheme(ctx context.Context, theme *models.ClientTheme) error
+
+	// DeleteTheme deletes an inactive theme and its configuration, recording
+	// a ThemeChangeDelete version. Deleting the active theme fails with
+	// ErrConflict; activate another one first.
+	DeleteTheme(ctx context.Context, clientID string, themeID int) error
+
+	// CreateConfiguration validates and inserts a configuration entry for an
+	// existing theme, filling in ID and timestamps. ConfigType defaults to string.
+	CreateConfiguration(ctx context.Context, config *models.ThemeConfiguration) error
+
+	// UpdateConfiguration validates and saves the value and type of the entry
+	// with config.ID under config.ThemeID; config is refreshed from the stored row.
+	UpdateConfiguration(ctx context.Context, config *models.ThemeConfiguration) error
+
+	// DeleteConfiguration deletes one configuration entry of a theme.
+	DeleteConfiguration(ctx context.Context, themeID, configID int) error
+
+	// ListThemeVersions returns the history of one of a client's themes,
+	// newest first.
+	ListThemeVersions(ctx context.Context, clientID string, themeID int) ([]models.ThemeVersion, error)
+
+	// FindThemeVersion returns one version of one of a client's themes.
+	FindThemeVersion(ctx context.Context, clientID string, themeID, version int) (*models.ThemeVersion, error)
+
+	// RollbackTheme restores the content and configuration of an earlier
+	// version in one transaction and records the result as a new version.
+	// Configuration entries are recreated, so their IDs change.
+	RollbackTheme(ctx context.Context, clientID string, themeID, version int) (*models.ThemeVersion, error)
+
+	// FindThemeDraft returns a theme's unpublished draft.
+	FindThemeDraft(ctx context.Context, clientID string, themeID int) (*models.ThemeDraft, error)
+
+	// SaveThemeDraft validates and creates or replaces the draft of
+	// draft.ThemeID, filling in the base version, author and timestamps. A new
+	// draft is based on the theme's latest version; a replaced one keeps its base.
+	SaveThemeDraft(ctx context.Context, clientID string, draft *models.ThemeDraft) error
+
+	// DiscardThemeDraft deletes a theme's draft.
+	DiscardThemeDraft(ctx context.Context, clientID string, themeID int) error
+
+	// PublishThemeDraft applies a theme's draft like RollbackTheme, records it
+	// as a new version and deletes the draft. It fails with ErrConflict if a
+	// version was recorded after the draft's base.
+	PublishThemeDraft(ctx context.Context, clientID string, themeID int) (*models.ThemeVersion, error)
+}
+
+// AssetStore records uploaded theme assets and their variants. The variant
+// content lives in a BlobStore; storetest.AssetStore is the conformance suite.
+type AssetStore interface {
+	// CreateAsset validates and inserts asset with its variants, filling in
+	// ID, each variant's AssetID and CreatedAt. Once it returns no DeleteAsset
+	// removes the variants' blobs, so the caller puts them afterwards: a blob
+	// another asset shared may have been deleted just before.
+	CreateAsset(ctx context.Context, asset *models.ThemeAsset) error
+
+	// ListAssets returns a client's assets with their variants, newest first.
+	ListAssets(ctx context.Context, clientID string) ([]models.ThemeAsset, error)
+
+	// FindAsset returns one of a client's assets with its variants.
+	FindAsset(ctx context.Context, clientID string, assetID int64) (*models.ThemeAsset, error)
+
+	// DeleteAsset deletes one of a client's assets and, in the same
+	// transaction, the blobs no remaining variant refers to.
+	DeleteAsset(ctx context.Context, clientID string, assetID int64) error
+}
+
+// BlobStore holds variant content under its content-hash key. Because keys
+// are content hashes, a blob is never rewritten; storetest.BlobStore is the
+// conformance suite.
+type BlobStore interface {
+	// PutBlob stores blob unless its key already exists.
+	PutBlob(ctx context.Context, blob *models.AssetBlob) error
+
+	// GetBlob returns a blob with its content type and creation time.
+	GetBlob(ctx context.Context, key string) (*models.AssetBlob, error)
+
+	// DeleteBlob deletes a blob; deleting a missing one is not an error.
+	DeleteBlob(ctx context.Context, key string) error
+}
+
+// AuditStore keeps the hash-chained admin audit log; storetest.AuditStore is
+// the conformance suite.
+type AuditStore interface {
+	// Append links the event to the end of the chain and inserts it.
+	// ID, PrevHash and Hash are filled in on success.
+	Append(ctx context.Context, event *models.AuditEvent) error
+
+	// List returns matching events, newest first, along with the total match count.
+	List(ctx context.Context, filter AuditFilter) ([]models.AuditEvent, int, error)
+
+	// VerifyChain walks the whole chain and returns the ID of the first event
+	// whose hash does not match, or 0 if the chain is intact.
+	VerifyChain(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,267 @@
+package storetest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"testing"
+
+	"closeauth-frontend/internal/database/models"
+	"closeauth-frontend/internal/database/repository"
+)
+
+// AssetStoreFactory returns an empty asset store and the blob store holding
+// its variant content.
+type AssetStoreFactory func(t *testing.T) (repository.AssetStore, repository.BlobStore)
+
+// BlobStoreFactory returns an empty blob store.
+type BlobStoreFactory func(t *testing.T) repository.BlobStore
+
+// blobKey is the key of content with extension ext.
+func blobKey(content, ext string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:]) + ext
+}
+
+// asset builds a logo fixture whose variants are named after their widths.
+func asset(clientID string, keys ...string) *models.ThemeAsset {
+	a := &models.ThemeAsset{
+		ClientID:    clientID,
+		Kind:        models.AssetKindLogo,
+		Filename:    "logo.png",
+		ContentType: "image/png",
+		Width:       512,
+		Height:      256,
+		CreatedBy:   "admin@example.com",
+	}
+	for i, key := range keys {
+		width := 128 << i
+		a.Variants = append(a.Variants, models.ThemeAssetVariant{
+			Name:        []string{"sm", "md", "lg"}[i],
+			Key:         key,
+			ContentType: "image/png",
+			Width:       width,
+			Height:      width / 2,
+			Size:        int64(100 * (i + 1)),
+		})
+	}
+	return a
+}
+
+// putBlobs stores a's variants with their keys as content, as an upload
+// does after CreateAsset.
+func putBlobs(t *testing.T, blobs repository.BlobStore, a *models.ThemeAsset) {
+	t.Helper()
+	for _, v := range a.Variants {
+		if err := blobs.PutBlob(context.Background(), &models.AssetBlob{Key: v.Key, ContentType: v.ContentType, Data: []byte(v.Key)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func variantKeys(a *models.ThemeAsset) []string {
+	keys := make([]string, len(a.Variants))
+	for i, v := range a.Variants {
+		keys[i] = v.Key
+	}
+	return keys
+}
+
+// AssetStore runs the conformance suite against the store newStore returns.
+func AssetStore(t *testing.T, newStore AssetStoreFactory) {
+	ctx := context.Background()
+	small, medium, shared := blobKey("small", ".png"), blobKey("medium", ".png"), blobKey("shared", ".png")
+
+	t.Run("CreateAndFind", func(t *testing.T) {
+		store, _ := newStore(t)
+
+		// Variants are returned smallest first whatever the insertion order
+		created := asset("acme", small, medium)
+		created.Variants[0], created.Variants[1] = created.Variants[1], created.Variants[0]
+		if err := store.CreateAsset(ctx, created); err != nil {
+			t.Fatal(err)
+		}
+		if created.ID == 0 || created.CreatedAt.IsZero() || created.Variants[0].AssetID != created.ID {
+			t.Fatalf("CreateAsset() = %+v, want ID, CreatedAt and variant AssetIDs filled in", created)
+		}
+
+		got, err := store.FindAsset(ctx, "acme", created.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Kind != models.AssetKindLogo || got.Filename != "logo.png" || got.Width != 512 || got.CreatedBy != "admin@example.com" {
+			t.Errorf("FindAsset() = %+v, want the stored fields", got)
+		}
+		if keys := variantKeys(got); !slices.Equal(keys, []string{small, medium}) {
+			t.Errorf("FindAsset() variants = %v, want smallest first", keys)
+		}
+		if v := got.Variants[1]; v.Name != "md" || v.Width != 256 || v.Height != 128 || v.Size != 200 || v.ContentType != "image/png" {
+			t.Errorf("FindAsset() variant = %+v, want the stored fields", v)
+		}
+
+		if _, err := store.FindAsset(ctx, "globex", created.ID); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("FindAsset(other client) error = %v, want ErrNotFound", err)
+		}
+		if _, err := store.FindAsset(ctx, "acme", created.ID+100); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("FindAsset(missing) error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("CreateAsset validates", func(t *testing.T) {
+		store, _ := newStore(t)
+
+		unknownKind := asset("acme", small)
+		unknownKind.Kind = "favicon"
+		repeated := asset("acme", small, medium)
+		repeated.Variants[1].Name = "sm"
+		tests := []struct {
+			name  string
+			asset *models.ThemeAsset
+			field string
+		}{
+			{"no client", asset("", small), "client_id"},
+			{"unknown kind", unknownKind, "kind"},
+			{"no variants", asset("acme"), "variants"},
+			{"repeated variant", repeated, "name"},
+			{"bad key", asset("acme", "../../etc/passwd"), "blob_key"},
+		}
+		for _, tt := range tests {
+			var verr *repository.ValidationError
+			if err := store.CreateAsset(ctx, tt.asset); !errors.As(err, &verr) || verr.Field != tt.field {
+				t.Errorf("CreateAsset(%s) error = %v, want a validation error on %s", tt.name, err, tt.field)
+			}
+		}
+		if assets, err := store.ListAssets(ctx, "acme"); err != nil || len(assets) != 0 {
+			t.Errorf("ListAssets() = %d assets, %v; want nothing stored", len(assets), err)
+		}
+	})
+
+	t.Run("ListAssets", func(t *testing.T) {
+		store, _ := newStore(t)
+
+		var ids []int64
+		for _, a := range []*models.ThemeAsset{asset("acme", small), asset("globex", medium), asset("acme", medium, shared)} {
+			if err := store.CreateAsset(ctx, a); err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, a.ID)
+		}
+
+		assets, err := store.ListAssets(ctx, "acme")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(assets) != 2 || assets[0].ID != ids[2] || assets[1].ID != ids[0] {
+			t.Fatalf("ListAssets() = %+v, want acme's assets newest first", assets)
+		}
+		if keys := variantKeys(&assets[0]); !slices.Equal(keys, []string{medium, shared}) {
+			t.Errorf("ListAssets() variants = %v, want each asset's own, smallest first", keys)
+		}
+
+		if assets, err := store.ListAssets(ctx, "unknown"); err != nil || len(assets) != 0 {
+			t.Errorf("ListAssets(unknown) = %d assets, %v; want none and no error", len(assets), err)
+		}
+	})
+
+	t.Run("DeleteAsset", func(t *testing.T) {
+		store, blobs := newStore(t)
+
+		first, second := asset("acme", small, shared), asset("acme", shared)
+		for _, a := range []*models.ThemeAsset{first, second} {
+			if err := store.CreateAsset(ctx, a); err != nil {
+				t.Fatal(err)
+			}
+			putBlobs(t, blobs, a)
+		}
+
+		if err := store.DeleteAsset(ctx, "globex", first.ID); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("DeleteAsset(other client) error = %v, want ErrNotFound", err)
+		}
+
+		// The shared blob is still used by the second asset
+		if err := store.DeleteAsset(ctx, "acme", first.ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := blobs.GetBlob(ctx, small); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("GetBlob(unshared) error = %v, want ErrNotFound", err)
+		}
+		if blob, err := blobs.GetBlob(ctx, shared); err != nil || string(blob.Data) != shared {
+			t.Errorf("GetBlob(shared) = %v, %v; want it kept", blob, err)
+		}
+		if _, err := store.FindAsset(ctx, "acme", first.ID); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("FindAsset(deleted) error = %v, want ErrNotFound", err)
+		}
+
+		if err := store.DeleteAsset(ctx, "acme", second.ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := blobs.GetBlob(ctx, shared); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("GetBlob(shared) after its last user was deleted error = %v, want ErrNotFound", err)
+		}
+		if err := store.DeleteAsset(ctx, "acme", second.ID); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("DeleteAsset(again) error = %v, want ErrNotFound", err)
+		}
+
+		// Uploading deleted content again stores it again
+		again := asset("globex", small)
+		if err := store.CreateAsset(ctx, again); err != nil {
+			t.Fatal(err)
+		}
+		putBlobs(t, blobs, again)
+		if blob, err := blobs.GetBlob(ctx, small); err != nil || string(blob.Data) != small {
+			t.Errorf("GetBlob(uploaded again) = %v, %v; want the content back", blob, err)
+		}
+	})
+}
+
+// BlobStore runs the conformance suite against the store newStore returns.
+func BlobStore(t *testing.T, newStore BlobStoreFactory) {
+	ctx := context.Background()
+
+	t.Run("PutGetDelete", func(t *testing.T) {
+		store := newStore(t)
+		key := blobKey("<svg/>", ".svg")
+
+		if err := store.PutBlob(ctx, &models.AssetBlob{Key: key, ContentType: "image/svg+xml", Data: []byte("<svg/>")}); err != nil {
+			t.Fatal(err)
+		}
+		// Keys are content hashes, so a second put is a no-op
+		if err := store.PutBlob(ctx, &models.AssetBlob{Key: key, ContentType: "image/svg+xml", Data: []byte("<svg/>")}); err != nil {
+			t.Fatalf("PutBlob(existing) error = %v", err)
+		}
+
+		got, err := store.GetBlob(ctx, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Key != key || got.ContentType != "image/svg+xml" || string(got.Data) != "<svg/>" || got.CreatedAt.IsZero() {
+			t.Errorf("GetBlob() = %+v, want the stored blob", got)
+		}
+
+		if err := store.DeleteBlob(ctx, key); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.GetBlob(ctx, key); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("GetBlob(deleted) error = %v, want ErrNotFound", err)
+		}
+		if err := store.DeleteBlob(ctx, key); err != nil {
+			t.Errorf("DeleteBlob(missing) error = %v, want none", err)
+		}
+	})
+
+	t.Run("rejects malformed keys", func(t *testing.T) {
+		store := newStore(t)
+
+		for _, key := range []string{"", "../secret.png", blobKey("x", ".html"), "ABC.png"} {
+			var verr *repository.ValidationError
+			if err := store.PutBlob(ctx, &models.AssetBlob{Key: key, ContentType: "image/png", Data: []byte("x")}); !errors.As(err, &verr) {
+				t.Errorf("PutBlob(%q) error = %v, want a validation error", key, err)
+			}
+			if _, err := store.GetBlob(ctx, key); !errors.Is(err, repository.ErrNotFound) {
+				t.Errorf("GetBlob(%q) error = %v, want ErrNotFound", key, err)
+			}
+		}
+	})
+}
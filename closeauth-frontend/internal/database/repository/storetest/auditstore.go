@@ -0,0 +1,96 @@
+package storetest
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"closeauth-frontend/internal/database/models"
+	"closeauth-frontend/internal/database/repository"
+)
+
+// AuditStoreFactory returns an empty audit store.
+type AuditStoreFactory func(t *testing.T) repository.AuditStore
+
+// AuditStore runs the conformance suite against the store newStore returns.
+func AuditStore(t *testing.T, newStore AuditStoreFactory) {
+	ctx := context.Background()
+
+	t.Run("AppendAndList", func(t *testing.T) {
+		store := newStore(t)
+
+		start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+		var ids []int64
+		prevHash := ""
+		for i, actor := range []string{"alice", "bob", "alice"} {
+			event := &models.AuditEvent{
+				OccurredAt: start.Add(time.Duration(i) * time.Hour),
+				ActorID:    actor,
+				TenantID:   "acme",
+				Action:     "themes.update",
+				Outcome:    models.AuditOutcomeSuccess,
+				After:      models.JSONText(`{"theme_name":"dark"}`),
+			}
+			if err := store.Append(ctx, event); err != nil {
+				t.Fatalf("Append() error = %v", err)
+			}
+			if event.ID == 0 || event.Hash == "" || (prevHash != "" && event.PrevHash != prevHash) {
+				t.Fatalf("Append() = %+v, want ID and hash filled in and linked to the previous event", event)
+			}
+			ids = append(ids, event.ID)
+			prevHash = event.Hash
+		}
+
+		tests := []struct {
+			name      string
+			filter    repository.AuditFilter
+			wantTotal int
+			wantIDs   []int64
+		}{
+			{"all, newest first", repository.AuditFilter{}, 3, []int64{ids[2], ids[1], ids[0]}},
+			{"actor", repository.AuditFilter{ActorID: "alice"}, 2, []int64{ids[2], ids[0]}},
+			{"no match", repository.AuditFilter{TenantID: "globex"}, 0, nil},
+			{"paged", repository.AuditFilter{Limit: 1, Offset: 1}, 3, []int64{ids[1]}},
+			{"past the end", repository.AuditFilter{Limit: 2, Offset: 5}, 3, nil},
+			// A non-UTC bound must compare by instant, not by text
+			{"time range", repository.AuditFilter{
+				From: start.Add(time.Hour).In(time.FixedZone("CET", 3600)),
+				To:   start.Add(2 * time.Hour),
+			}, 1, []int64{ids[1]}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				events, total, err := store.List(ctx, tt.filter)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var got []int64
+				for _, e := range events {
+					got = append(got, e.ID)
+				}
+				if total != tt.wantTotal || !slices.Equal(got, tt.wantIDs) {
+					t.Errorf("List() = %v (total %d), want %v (total %d)", got, total, tt.wantIDs, tt.wantTotal)
+				}
+			})
+		}
+
+		events, _, err := store.List(ctx, repository.AuditFilter{Limit: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e := events[0]; e.ActorID != "alice" || e.TenantID != "acme" || string(e.After) != `{"theme_name":"dark"}` || !e.OccurredAt.Equal(start.Add(2*time.Hour)) {
+			t.Errorf("List() event = %+v, want the stored fields", e)
+		}
+
+		if brokenAt, err := store.VerifyChain(ctx); err != nil || brokenAt != 0 {
+			t.Errorf("VerifyChain() = %d, %v; want an intact chain", brokenAt, err)
+		}
+	})
+
+	t.Run("VerifyChain on an empty log", func(t *testing.T) {
+		if brokenAt, err := newStore(t).VerifyChain(ctx); err != nil || brokenAt != 0 {
+			t.Errorf("VerifyChain() = %d, %v; want an intact chain", brokenAt, err)
+		}
+	})
+}
@@ -0,0 +1,739 @@
+// Package storetest holds conformance suites shared by every implementation
+// of a repository store interface. Each implementation's tests call the suite
+// with a factory returning an empty store.
+package storetest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"closeauth-frontend/internal/database/models"
+	"closeauth-frontend/internal/database/repository"
+)
+
+// ThemeSeeder inserts fixtures into the store under test, assigning IDs.
+type ThemeSeeder interface {
+	AddTheme(theme models.ClientTheme) (models.ClientTheme, error)
+	AddConfiguration(config models.ThemeConfiguration) (models.ThemeConfiguration, error)
+}
+
+// ThemeStoreFactory returns an empty store and a seeder writing to it.
+type ThemeStoreFactory func(t *testing.T) (repository.ThemeStore, ThemeSeeder)
+
+func ptr(s string) *string { return &s }
+
+// theme builds a fixture with valid colours so it satisfies the SQL constraints.
+func theme(clientID, name string, active, isDefault bool) models.ClientTheme {
+	return models.ClientTheme{
+		ClientID:             clientID,
+		ThemeName:            name,
+		IsActive:             active,
+		IsDefault:            isDefault,
+		LightPrimaryColor:    ptr("#3b82f6"),
+		LightBackgroundColor: ptr("#ffffff"),
+		LightButtonColor:     ptr("#3b82f6"),
+		LightTextColor:       ptr("#1f2937"),
+		DarkPrimaryColor:     ptr("#60a5fa"),
+		DarkBackgroundColor:  ptr("#1f2937"),
+		DarkButtonColor:      ptr("#3b82f6"),
+		DarkTextColor:        ptr("#f9fafb"),
+		DefaultMode:          ptr("system"),
+		AllowModeToggle:      true,
+	}
+}
+
+func names(themes []models.ClientTheme) []string {
+	out := make([]string, len(themes))
+	for i, t := range themes {
+		out[i] = t.ClientID + "/" + t.ThemeName
+	}
+	return out
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// seed loads the fixtures shared by the subtests:
+//
+//	acme:   default (default), dark, retired (inactive), zebra
+//	globex: old-default (inactive default), plain
+func seed(t *testing.T, seeder ThemeSeeder) map[string]models.ClientTheme {
+	t.Helper()
+	fixtures := []models.ClientTheme{
+		theme("acme", "zebra", true, false),
+		theme("acme", "default", true, true),
+		theme("acme", "retired", false, false),
+		theme("acme", "dark", true, false),
+		theme("globex", "old-default", false, true),
+		theme("globex", "plain", true, false),
+	}
+	seeded := make(map[string]models.ClientTheme)
+	for _, f := range fixtures {
+		saved, err := seeder.AddTheme(f)
+		if err != nil {
+			t.Fatalf("AddTheme(%s/%s): %v", f.ClientID, f.ThemeName, err)
+		}
+		if saved.ID == 0 {
+			t.Fatalf("AddTheme(%s/%s) did not assign an ID", f.ClientID, f.ThemeName)
+		}
+		seeded[f.ClientID+"/"+f.ThemeName] = saved
+	}
+
+	themeID := seeded["acme/default"].ID
+	for _, c := range []models.ThemeConfiguration{
+		{ThemeID: themeID, ConfigKey: "font_family", ConfigValue: "Inter", ConfigType: "string"},
+		{ThemeID: themeID, ConfigKey: "border_radius", ConfigValue: "8", ConfigType: "number"},
+	} {
+		if _, err := seeder.AddConfiguration(c); err != nil {
+			t.Fatalf("AddConfiguration(%s): %v", c.ConfigKey, err)
+		}
+	}
+	return seeded
+}
+
+// ThemeStore runs the conformance suite against the store newStore returns.
+func ThemeStore(t *testing.T, newStore ThemeStoreFactory) {
+	ctx := context.Background()
+
+	t.Run("FindByClientID", func(t *testing.T) {
+		store, seeder := newStore(t)
+		seed(t, seeder)
+
+		themes, err := store.FindByClientID(ctx, "acme")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []string{"acme/default", "acme/dark", "acme/zebra"}
+		if got := names(themes); !equal(got, want) {
+			t.Errorf("FindByClientID() = %v, want %v (active only, default first, then by name)", got, want)
+		}
+
+		themes, err = store.FindByClientID(ctx, "unknown")
+		if err != nil || len(themes) != 0 {
+			t.Errorf("FindByClientID(unknown) = %v, %v; want no themes and no error", names(themes), err)
+		}
+	})
+
+	t.Run("FindDefaultTheme", func(t *testing.T) {
+		store, seeder := newStore(t)
+		seed(t, seeder)
+
+		got, err := store.FindDefaultTheme(ctx, "acme")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.ThemeName != "default" || got.LightPrimaryColor == nil || *got.LightPrimaryColor != "#3b82f6" {
+			t.Errorf("FindDefaultTheme() = %+v, want the acme default with its colours", got)
+		}
+
+		// globex's only default is inactive
+		if _, err := store.FindDefaultTheme(ctx, "globex"); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("FindDefaultTheme(globex) error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("FindByClientIDAndName", func(t *testing.T) {
+		store, seeder := newStore(t)
+		seed(t, seeder)
+
+		got, err := store.FindByClientIDAndName(ctx, "acme", "dark")
+		if err != nil || got.ThemeName != "dark" || got.ClientID != "acme" {
+			t.Errorf("FindByClientIDAndName(acme, dark) = %+v, %v", got, err)
+		}
+		for _, name := range []string{"retired", "plain", "missing"} {
+			if _, err := store.FindByClientIDAndName(ctx, "acme", name); !errors.Is(err, repository.ErrNotFound) {
+				t.Errorf("FindByClientIDAndName(acme, %s) error = %v, want ErrNotFound", name, err)
+			}
+		}
+	})
+
+	t.Run("FindThemeByID", func(t *testing.T) {
+		store, seeder := newStore(t)
+		seeded := seed(t, seeder)
+
+		// Inactive themes are still reachable by ID
+		retired := seeded["acme/retired"]
+		got, err := store.FindThemeByID(ctx, retired.ID)
+		if err != nil || got.ThemeName != "retired" || got.IsActive {
+			t.Errorf("FindThemeByID(retired) = %+v, %v", got, err)
+		}
+		if _, err := store.FindThemeByID(ctx, -1); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("FindThemeByID(-1) error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("Configurations", func(t *testing.T) {
+		store, seeder := newStore(t)
+		seeded := seed(t, seeder)
+		themeID := seeded["acme/default"].ID
+
+		configs, err := store.FindConfigurationsByThemeID(ctx, themeID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(configs) != 2 || configs[0].ConfigKey != "border_radius" || configs[1].ConfigKey != "font_family" {
+			t.Errorf("FindConfigurationsByThemeID() = %+v, want border_radius then font_family", configs)
+		}
+
+		value, err := store.GetConfigValue(ctx, themeID, "font_family")
+		if err != nil || value.ConfigValue != "Inter" || value.ConfigType != "string" {
+			t.Errorf("GetConfigValue(font_family) = %+v, %v", value, err)
+		}
+		if _, err := store.GetConfigValue(ctx, themeID, "missing"); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("GetConfigValue(missing) error = %v, want ErrNotFound", err)
+		}
+
+		withConfig, err := store.FindThemeWithConfig(ctx, "acme", "default")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if withConfig.Theme.ID != themeID || withConfig.Configurations["border_radius"].ConfigValue != "8" {
+			t.Errorf("FindThemeWithConfig() = %+v", withConfig)
+		}
+		if _, err := store.FindThemeWithConfig(ctx, "acme", "retired"); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("FindThemeWithConfig(retired) error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("ListAllThemes", func(t *testing.T) {
+		store, seeder := newStore(t)
+		seed(t, seeder)
+
+		all := []string{"acme/default", "acme/dark", "acme/zebra", "globex/plain"}
+		tests := []struct {
+			limit, offset int
+			want          []string
+		}{
+			{10, 0, all},
+			{2, 0, all[:2]},
+			{2, 2, all[2:]},
+			{2, 3, all[3:]},
+			{2, 10, nil},
+		}
+		for _, tt := range tests {
+			themes, err := store.ListAllThemes(ctx, tt.limit, tt.offset)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := names(themes); !equal(got, tt.want) {
+				t.Errorf("ListAllThemes(%d, %d) = %v, want %v", tt.limit, tt.offset, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("CountThemesByClientID", func(t *testing.T) {
+		store, seeder := newStore(t)
+		seed(t, seeder)
+
+		for clientID, want := range map[string]int{"acme": 3, "globex": 1, "unknown": 0} {
+			if got, err := store.CountThemesByClientID(ctx, clientID); err != nil || got != want {
+				t.Errorf("CountThemesByClientID(%s) = %d, %v; want %d", clientID, got, err, want)
+			}
+		}
+	})
+
+	t.Run("FindAllByClientID", func(t *testing.T) {
+		store, seeder := newStore(t)
+		seed(t, seeder)
+
+		themes, err := store.FindAllByClientID(ctx, "acme")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []string{"acme/default", "acme/dark", "acme/retired", "acme/zebra"}
+		if got := names(themes); !equal(got, want) {
+			t.Errorf("FindAllByClientID() = %v, want %v (inactive included)", got, want)
+		}
+	})
+
+	t.Run("CreateTheme", func(t *testing.T) {
+		store, _ := newStore(t)
+
+		first := theme("initech", "first", false, false)
+		first.DefaultMode = nil
+		if err := store.CreateTheme(ctx, &first); err != nil {
+			t.Fatal(err)
+		}
+		if first.ID == 0 || !first.IsActive || !first.IsDefault || first.DefaultMode == nil || *first.DefaultMode != "light" {
+			t.Errorf("first theme = %+v, want the active default in light mode", first)
+		}
+
+		second := theme("initech", "second", true, false)
+		if err := store.CreateTheme(ctx, &second); err != nil {
+			t.Fatal(err)
+		}
+		if second.IsActive || second.IsDefault {
+			t.Errorf("second theme active=%v default=%v, want neither", second.IsActive, second.IsDefault)
+		}
+
+		third := theme("initech", "third", false, true)
+		if err := store.CreateTheme(ctx, &third); err != nil {
+			t.Fatal(err)
+		}
+		assertActiveDefault(t, store, "initech", "third")
+
+		dup := theme("initech", "second", false, false)
+		if err := store.CreateTheme(ctx, &dup); !errors.Is(err, repository.ErrConflict) {
+			t.Errorf("CreateTheme(duplicate name) error = %v, want ErrConflict", err)
+		}
+
+		for name, mutate := range map[string]func(*models.ClientTheme){
+			"colour":    func(th *models.ClientTheme) { th.DarkTextColor = ptr("white") },
+			"short hex": func(th *models.ClientTheme) { th.LightPrimaryColor = ptr("#fff") },
+			"mode":      func(th *models.ClientTheme) { th.DefaultMode = ptr("sepia") },
+			"no name":   func(th *models.ClientTheme) { th.ThemeName = " " },
+			"long logo": func(th *models.ClientTheme) { th.LogoURL = ptr("https://x/" + strings.Repeat("a", 500)) },
+			"no client": func(th *models.ClientTheme) { th.ClientID = "" },
+		} {
+			bad := theme("initech", "bad-"+strings.ReplaceAll(name, " ", "-"), false, false)
+			mutate(&bad)
+			var invalid *repository.ValidationError
+			if err := store.CreateTheme(ctx, &bad); !errors.As(err, &invalid) {
+				t.Errorf("CreateTheme(bad %s) error = %v, want a ValidationError", name, err)
+			}
+		}
+	})
+
+	t.Run("UpdateTheme", func(t *testing.T) {
+		store, seeder := newStore(t)
+		seeded := seed(t, seeder)
+
+		dark := seeded["acme/dark"]
+		dark.LightPrimaryColor = ptr("#ABCDEF")
+		dark.DefaultMode = ptr("dark")
+		dark.ThemeName = "renamed" // not editable
+		dark.IsDefault = true      // flags change through ActivateTheme only
+		if err := store.UpdateTheme(ctx, &dark); err != nil {
+			t.Fatal(err)
+		}
+		if dark.ThemeName != "dark" || dark.IsDefault || *dark.LightPrimaryColor != "#ABCDEF" || *dark.DefaultMode != "dark" {
+			t.Errorf("UpdateTheme() = %+v, want new colour and mode only", dark)
+		}
+		stored, err := store.FindThemeByID(ctx, dark.ID)
+		if err != nil || *stored.LightPrimaryColor != "#ABCDEF" {
+			t.Errorf("stored theme = %+v, %v", stored, err)
+		}
+		if def, err := store.FindDefaultTheme(ctx, "acme"); err != nil || def.ThemeName != "default" {
+			t.Errorf("FindDefaultTheme() after update = %+v, %v; want default unchanged", def, err)
+		}
+
+		other := seeded["acme/zebra"]
+		other.ClientID = "globex"
+		if err := store.UpdateTheme(ctx, &other); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("UpdateTheme(other client's theme) error = %v, want ErrNotFound", err)
+		}
+
+		bad := seeded["acme/zebra"]
+		bad.LightButtonColor = ptr("#12345G")
+		var invalid *repository.ValidationError
+		if err := store.UpdateTheme(ctx, &bad); !errors.As(err, &invalid) || invalid.Field != "light_button_color" {
+			t.Errorf("UpdateTheme(bad colour) error = %v, want a light_button_color ValidationError", err)
+		}
+	})
+
+	t.Run("ActivateTheme", func(t *testing.T) {
+		store, seeder := newStore(t)
+		seeded := seed(t, seeder)
+
+		activated, err := store.ActivateTheme(ctx, "acme", seeded["acme/retired"].ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !activated.IsActive || !activated.IsDefault || activated.ThemeName != "retired" {
+			t.Errorf("ActivateTheme() = %+v", activated)
+		}
+		assertActiveDefault(t, store, "acme", "retired")
+
+		// Another client's theme is not found, and nothing changes
+		if _, err := store.ActivateTheme(ctx, "globex", seeded["acme/dark"].ID); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("ActivateTheme(other client's theme) error = %v, want ErrNotFound", err)
+		}
+		assertActiveDefault(t, store, "globex", "")
+		if themes, _ := store.FindByClientID(ctx, "globex"); len(themes) != 1 {
+			t.Errorf("globex active themes = %v, want plain untouched", names(themes))
+		}
+	})
+
+	t.Run("UpdateAndActivateTheme", func(t *testing.T) {
+		store, seeder := newStore(t)
+		seeded := seed(t, seeder)
+
+		// A failed write leaves both the theme and the active default alone
+		bad := seeded["acme/retired"]
+		bad.LightButtonColor = ptr("#12345G")
+		var invalid *repository.ValidationError
+		if err := store.UpdateAndActivateTheme(ctx, &bad); !errors.As(err, &invalid) {
+			t.Errorf("UpdateAndActivateTheme(bad colour) error = %v, want a ValidationError", err)
+		}
+		other := seeded["acme/retired"]
+		other.ClientID = "globex"
+		if err := store.UpdateAndActivateTheme(ctx, &other); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("UpdateAndActivateTheme(other client's theme) error = %v, want ErrNotFound", err)
+		}
+		if def, err := store.FindDefaultTheme(ctx, "acme"); err != nil || def.ThemeName != "default" {
+			t.Errorf("FindDefaultTheme() after failed writes = %+v, %v; want default unchanged", def, err)
+		}
+		if stored, err := store.FindThemeByID(ctx, bad.ID); err != nil || stored.IsActive || stored.LightButtonColor == nil || *stored.LightButtonColor == "#12345G" {
+			t.Errorf("stored theme after failed writes = %+v, %v; want it unchanged", stored, err)
+		}
+
+		retired := seeded["acme/retired"]
+		retired.LightPrimaryColor = ptr("#ABCDEF")
+		if err := store.UpdateAndActivateTheme(ctx, &retired); err != nil {
+			t.Fatal(err)
+		}
+		if !retired.IsActive || !retired.IsDefault || *retired.LightPrimaryColor != "#ABCDEF" {
+			t.Errorf("UpdateAndActivateTheme() = %+v, want the new colour, active and default", retired)
+		}
+		assertActiveDefault(t, store, "acme", "retired")
+		if versions, err := store.ListThemeVersions(ctx, "acme", retired.ID); err != nil || len(versions) != 2 ||
+			versions[0].Change != models.ThemeChangeActivate || versions[1].Change != models.ThemeChangeUpdate {
+			t.Errorf("ListThemeVersions() = %+v, %v; want the update and the activation recorded", versions, err)
+		}
+	})
+
+	t.Run("DeleteTheme", func(t *testing.T) {
+		store, seeder := newStore(t)
+		seeded := seed(t, seeder)
+		defaultID := seeded["acme/default"].ID
+
+		if err := store.DeleteTheme(ctx, "acme", defaultID); !errors.Is(err, repository.ErrConflict) {
+			t.Errorf("DeleteTheme(active) error = %v, want ErrConflict", err)
+		}
+		if _, err := store.ActivateTheme(ctx, "acme", seeded["acme/dark"].ID); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.DeleteTheme(ctx, "globex", defaultID); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("DeleteTheme(other client) error = %v, want ErrNotFound", err)
+		}
+		if err := store.DeleteTheme(ctx, "acme", defaultID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.FindThemeByID(ctx, defaultID); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("FindThemeByID(deleted) error = %v, want ErrNotFound", err)
+		}
+		if configs, _ := store.FindConfigurationsByThemeID(ctx, defaultID); len(configs) != 0 {
+			t.Errorf("configurations of deleted theme = %+v, want none", configs)
+		}
+	})
+
+	t.Run("ConfigurationWrites", func(t *testing.T) {
+		store, seeder := newStore(t)
+		seeded := seed(t, seeder)
+		themeID := seeded["acme/dark"].ID
+
+		config := models.ThemeConfiguration{ThemeID: themeID, ConfigKey: "font_family", ConfigValue: "Inter"}
+		if err := store.CreateConfiguration(ctx, &config); err != nil {
+			t.Fatal(err)
+		}
+		if config.ID == 0 || config.ConfigType != "string" {
+			t.Errorf("CreateConfiguration() = %+v, want an ID and type string", config)
+		}
+		got, err := store.FindConfigurationByID(ctx, themeID, config.ID)
+		if err != nil || got.ConfigValue != "Inter" {
+			t.Errorf("FindConfigurationByID() = %+v, %v", got, err)
+		}
+		if _, err := store.FindConfigurationByID(ctx, seeded["acme/zebra"].ID, config.ID); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("FindConfigurationByID(wrong theme) error = %v, want ErrNotFound", err)
+		}
+
+		dup := models.ThemeConfiguration{ThemeID: themeID, ConfigKey: "font_family", ConfigValue: "Arial"}
+		if err := store.CreateConfiguration(ctx, &dup); !errors.Is(err, repository.ErrConflict) {
+			t.Errorf("CreateConfiguration(duplicate key) error = %v, want ErrConflict", err)
+		}
+		orphan := models.ThemeConfiguration{ThemeID: -1, ConfigKey: "x", ConfigValue: "y"}
+		if err := store.CreateConfiguration(ctx, &orphan); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("CreateConfiguration(missing theme) error = %v, want ErrNotFound", err)
+		}
+		for _, bad := range []models.ThemeConfiguration{
+			{ThemeID: themeID, ConfigKey: "a", ConfigValue: "1", ConfigType: "boolean"},
+			{ThemeID: themeID, ConfigKey: "b", ConfigValue: "wide", ConfigType: "number"},
+			{ThemeID: themeID, ConfigKey: "c", ConfigValue: "{", ConfigType: "json"},
+			{ThemeID: themeID, ConfigKey: "d", ConfigValue: "javascript:alert(1)", ConfigType: "url"},
+			{ThemeID: themeID, ConfigKey: "", ConfigValue: "x"},
+		} {
+			var invalid *repository.ValidationError
+			if err := store.CreateConfiguration(ctx, &bad); !errors.As(err, &invalid) {
+				t.Errorf("CreateConfiguration(%+v) error = %v, want a ValidationError", bad, err)
+			}
+		}
+
+		config.ConfigValue, config.ConfigType = "1.5", "number"
+		config.ConfigKey = "ignored"
+		if err := store.UpdateConfiguration(ctx, &config); err != nil {
+			t.Fatal(err)
+		}
+		if config.ConfigKey != "font_family" || config.ConfigValue != "1.5" || config.ConfigType != "number" {
+			t.Errorf("UpdateConfiguration() = %+v", config)
+		}
+		missing := models.ThemeConfiguration{ID: config.ID, ThemeID: seeded["acme/zebra"].ID, ConfigValue: "x"}
+		if err := store.UpdateConfiguration(ctx, &missing); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("UpdateConfiguration(wrong theme) error = %v, want ErrNotFound", err)
+		}
+
+		if err := store.DeleteConfiguration(ctx, themeID, config.ID); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.DeleteConfiguration(ctx, themeID, config.ID); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("second DeleteConfiguration() error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("VersionHistory", func(t *testing.T) {
+		store, seeder := newStore(t)
+		seed(t, seeder)
+		ctx := repository.WithAuthor(ctx, "alice@example.com")
+
+		created := theme("acme", "versioned", false, false)
+		if err := store.CreateTheme(ctx, &created); err != nil {
+			t.Fatal(err)
+		}
+		updated := created
+		updated.LightTextColor = ptr("#000000")
+		if err := store.UpdateTheme(ctx, &updated); err != nil {
+			t.Fatal(err)
+		}
+		config := models.ThemeConfiguration{ThemeID: created.ID, ConfigKey: "radius", ConfigValue: "8", ConfigType: "number"}
+		if err := store.CreateConfiguration(ctx, &config); err != nil {
+			t.Fatal(err)
+		}
+		config.ConfigValue = "12"
+		if err := store.UpdateConfiguration(ctx, &config); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.DeleteConfiguration(ctx, created.ID, config.ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.ActivateTheme(ctx, "acme", created.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		versions, err := store.ListThemeVersions(ctx, "acme", created.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var changes []string
+		for _, v := range versions {
+			changes = append(changes, v.Change)
+		}
+		want := []string{"activate", "configuration.delete", "configuration.update", "configuration.create", "update", "create"}
+		if !equal(changes, want) {
+			t.Fatalf("ListThemeVersions() changes = %v, want %v (newest first)", changes, want)
+		}
+		if v := versions[0]; v.Version != 6 || v.Author != "alice@example.com" || v.CreatedAt.IsZero() || v.ThemeID != created.ID {
+			t.Errorf("newest version = %+v", v)
+		}
+		if s := versions[2].Snapshot; len(s.Configurations) != 1 || s.Configurations[0].Value != "12" || *s.LightTextColor != "#000000" {
+			t.Errorf("configuration.update snapshot = %+v", s)
+		}
+		if s := versions[5].Snapshot; *s.LightTextColor != "#1f2937" || len(s.Configurations) != 0 {
+			t.Errorf("create snapshot = %+v", s)
+		}
+
+		got, err := store.FindThemeVersion(ctx, "acme", created.ID, 2)
+		if err != nil || got.Change != "update" {
+			t.Errorf("FindThemeVersion(2) = %+v, %v", got, err)
+		}
+		if _, err := store.FindThemeVersion(ctx, "acme", created.ID, 7); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("FindThemeVersion(7) error = %v, want ErrNotFound", err)
+		}
+		if _, err := store.ListThemeVersions(ctx, "globex", created.ID); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("ListThemeVersions(other client) error = %v, want ErrNotFound", err)
+		}
+
+		// A failed write records nothing
+		bad := updated
+		bad.DarkTextColor = ptr("black")
+		store.UpdateTheme(ctx, &bad)
+		if versions, _ := store.ListThemeVersions(ctx, "acme", created.ID); len(versions) != 6 {
+			t.Errorf("versions after a rejected update = %d, want 6", len(versions))
+		}
+	})
+
+	t.Run("RollbackTheme", func(t *testing.T) {
+		store, seeder := newStore(t)
+		seed(t, seeder)
+
+		created := theme("acme", "versioned", false, false)
+		if err := store.CreateTheme(ctx, &created); err != nil {
+			t.Fatal(err)
+		}
+		config := models.ThemeConfiguration{ThemeID: created.ID, ConfigKey: "font_family", ConfigValue: "Inter"}
+		if err := store.CreateConfiguration(ctx, &config); err != nil {
+			t.Fatal(err)
+		}
+		changed := created
+		changed.LightPrimaryColor, changed.LogoURL = ptr("#ff0000"), ptr("/logo.png")
+		if err := store.UpdateTheme(ctx, &changed); err != nil {
+			t.Fatal(err)
+		}
+
+		restored, err := store.RollbackTheme(ctx, "acme", created.ID, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if restored.Version != 4 || restored.Change != "rollback" || restored.SourceVersion == nil || *restored.SourceVersion != 1 {
+			t.Errorf("RollbackTheme() = %+v, want version 4 restoring 1", restored)
+		}
+		stored, err := store.FindThemeByID(ctx, created.ID)
+		if err != nil || *stored.LightPrimaryColor != "#3b82f6" || stored.LogoURL != nil || stored.ThemeName != "versioned" {
+			t.Errorf("theme after rollback = %+v, %v", stored, err)
+		}
+		if configs, _ := store.FindConfigurationsByThemeID(ctx, created.ID); len(configs) != 0 {
+			t.Errorf("configuration after rollback = %+v, want none as in version 1", configs)
+		}
+
+		// Rolling forward again restores the configuration too
+		if _, err := store.RollbackTheme(ctx, "acme", created.ID, 3); err != nil {
+			t.Fatal(err)
+		}
+		configs, _ := store.FindConfigurationsByThemeID(ctx, created.ID)
+		if len(configs) != 1 || configs[0].ConfigKey != "font_family" || configs[0].ConfigValue != "Inter" {
+			t.Errorf("configuration after rolling forward = %+v", configs)
+		}
+		if stored, _ := store.FindThemeByID(ctx, created.ID); *stored.LightPrimaryColor != "#ff0000" {
+			t.Errorf("theme after rolling forward = %+v", stored)
+		}
+
+		if _, err := store.RollbackTheme(ctx, "acme", created.ID, 99); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("RollbackTheme(missing version) error = %v, want ErrNotFound", err)
+		}
+		if _, err := store.RollbackTheme(ctx, "globex", created.ID, 1); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("RollbackTheme(other client) error = %v, want ErrNotFound", err)
+		}
+		if versions, _ := store.ListThemeVersions(ctx, "acme", created.ID); len(versions) != 5 {
+			t.Errorf("versions = %d, want 5", len(versions))
+		}
+	})
+
+	t.Run("ThemeDrafts", func(t *testing.T) {
+		store, seeder := newStore(t)
+		seed(t, seeder)
+		ctx := repository.WithAuthor(ctx, "bob@example.com")
+
+		created := theme("acme", "versioned", false, false)
+		if err := store.CreateTheme(ctx, &created); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.FindThemeDraft(ctx, "acme", created.ID); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("FindThemeDraft(none) error = %v, want ErrNotFound", err)
+		}
+
+		snapshot := models.NewThemeSnapshot(&created, nil)
+		snapshot.LightTextColor = ptr("#111111")
+		snapshot.Configurations = []models.SnapshotConfig{{Key: "radius", Value: "4", Type: "number"}, {Key: "font", Value: "Inter"}}
+		draft := models.ThemeDraft{ThemeID: created.ID, Snapshot: snapshot}
+		if err := store.SaveThemeDraft(ctx, "acme", &draft); err != nil {
+			t.Fatal(err)
+		}
+		if draft.BaseVersion != 1 || draft.Author != "bob@example.com" || draft.UpdatedAt.IsZero() {
+			t.Errorf("SaveThemeDraft() = %+v, want base version 1 by bob", draft)
+		}
+
+		// The published theme is untouched until the draft is published
+		if stored, _ := store.FindThemeByID(ctx, created.ID); *stored.LightTextColor != "#1f2937" {
+			t.Errorf("theme with an unpublished draft = %+v", stored)
+		}
+		got, err := store.FindThemeDraft(ctx, "acme", created.ID)
+		if err != nil || *got.Snapshot.LightTextColor != "#111111" || len(got.Snapshot.Configurations) != 2 ||
+			got.Snapshot.Configurations[0].Key != "font" || got.Snapshot.Configurations[0].Type != "string" {
+			t.Errorf("FindThemeDraft() = %+v, %v; want configuration sorted and typed", got, err)
+		}
+
+		invalid := models.ThemeDraft{ThemeID: created.ID, Snapshot: snapshot.Clone()}
+		invalid.Snapshot.Configurations = append(invalid.Snapshot.Configurations, models.SnapshotConfig{Key: "font", Value: "Arial"})
+		var verr *repository.ValidationError
+		if err := store.SaveThemeDraft(ctx, "acme", &invalid); !errors.As(err, &verr) {
+			t.Errorf("SaveThemeDraft(repeated key) error = %v, want a ValidationError", err)
+		}
+		if err := store.SaveThemeDraft(ctx, "globex", &models.ThemeDraft{ThemeID: created.ID, Snapshot: snapshot}); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("SaveThemeDraft(other client) error = %v, want ErrNotFound", err)
+		}
+
+		published, err := store.PublishThemeDraft(ctx, "acme", created.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if published.Version != 2 || published.Change != "publish" || *published.SourceVersion != 1 || published.Author != "bob@example.com" {
+			t.Errorf("PublishThemeDraft() = %+v", published)
+		}
+		if stored, _ := store.FindThemeByID(ctx, created.ID); *stored.LightTextColor != "#111111" {
+			t.Errorf("theme after publish = %+v", stored)
+		}
+		if configs, _ := store.FindConfigurationsByThemeID(ctx, created.ID); len(configs) != 2 {
+			t.Errorf("configuration after publish = %+v", configs)
+		}
+		if _, err := store.FindThemeDraft(ctx, "acme", created.ID); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("FindThemeDraft(published) error = %v, want ErrNotFound", err)
+		}
+
+		// A draft overtaken by a published change conflicts, even once re-saved
+		stale := models.ThemeDraft{ThemeID: created.ID, Snapshot: snapshot.Clone()}
+		if err := store.SaveThemeDraft(ctx, "acme", &stale); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.RollbackTheme(ctx, "acme", created.ID, 1); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.SaveThemeDraft(ctx, "acme", &stale); err != nil || stale.BaseVersion != 2 {
+			t.Errorf("re-saved draft = %+v, %v; want base version 2 kept", stale, err)
+		}
+		if _, err := store.PublishThemeDraft(ctx, "acme", created.ID); !errors.Is(err, repository.ErrConflict) {
+			t.Errorf("PublishThemeDraft(stale) error = %v, want ErrConflict", err)
+		}
+
+		if err := store.DiscardThemeDraft(ctx, "globex", created.ID); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("DiscardThemeDraft(other client) error = %v, want ErrNotFound", err)
+		}
+		if err := store.DiscardThemeDraft(ctx, "acme", created.ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.PublishThemeDraft(ctx, "acme", created.ID); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("PublishThemeDraft(discarded) error = %v, want ErrNotFound", err)
+		}
+
+		// Drafts go with their theme; its history is kept but no longer listed
+		if err := store.SaveThemeDraft(ctx, "acme", &stale); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.DeleteTheme(ctx, "acme", created.ID); err != nil {
+			t.Fatalf("DeleteTheme() with history and a draft: %v", err)
+		}
+		if _, err := store.ListThemeVersions(ctx, "acme", created.ID); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("ListThemeVersions(deleted) error = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+// assertActiveDefault checks that want is the client's only active theme and
+// its default, or that the client has no active default when want is empty.
+func assertActiveDefault(t *testing.T, store repository.ThemeStore, clientID, want string) {
+	t.Helper()
+	ctx := context.Background()
+
+	got, err := store.FindDefaultTheme(ctx, clientID)
+	if want == "" {
+		if !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("FindDefaultTheme(%s) = %+v, %v; want ErrNotFound", clientID, got, err)
+		}
+		return
+	}
+	if err != nil || got.ThemeName != want {
+		t.Errorf("FindDefaultTheme(%s) = %+v, %v; want %s", clientID, got, err, want)
+	}
+	active, err := store.FindByClientID(ctx, clientID)
+	if err != nil || len(active) != 1 {
+		t.Errorf("active themes of %s = %v, %v; want only %s", clientID, names(active), err, want)
+	}
+}
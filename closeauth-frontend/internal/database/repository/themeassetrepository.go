@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"closeauth-frontend/internal/database"
+	"closeauth-frontend/internal/database/models"
+)
+
+const assetColumns = `id, client_id, kind, filename, content_type, width, height, created_by, created_at`
+
+const variantColumns = `asset_id, name, blob_key, content_type, width, height, size_bytes`
+
+// AssetRepository is the SQL AssetStore. asset_blobs records every variant's
+// blob key, and theme_asset_variants references it, so an asset's blobs are
+// claimed and released in the same transactions as the asset.
+//
+// Without an external store AssetRepository is also the BlobStore, keeping
+// variant content in asset_blobs for deployments without shared storage for a
+// FileBlobStore. With one, asset_blobs only records keys and the content is
+// deleted from the external store.
+type AssetRepository struct {
+	db       *database.Database
+	external BlobStore
+}
+
+var (
+	_ AssetStore = (*AssetRepository)(nil)
+	_ BlobStore  = (*AssetRepository)(nil)
+)
+
+// NewAssetRepository returns a repository whose variant content is in
+// external, or in asset_blobs when external is nil.
+func NewAssetRepository(db *database.Database, external BlobStore) *AssetRepository {
+	return &AssetRepository{db: db, external: external}
+}
+
+// CreateAsset inserts an asset and its variants in one transaction, recording
+// blob keys not yet in asset_blobs without content
+func (r *AssetRepository) CreateAsset(ctx context.Context, asset *models.ThemeAsset) error {
+	if err := ValidateAsset(asset); err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+        INSERT INTO theme_assets (client_id, kind, filename, content_type, width, height, created_by)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING id, created_at
+    `
+	row := tx.QueryRowxContext(ctx, query, asset.ClientID, asset.Kind, asset.Filename, asset.ContentType,
+		asset.Width, asset.Height, asset.CreatedBy)
+	if err := row.Scan(&asset.ID, &asset.CreatedAt); err != nil {
+		return fmt.Errorf("failed to create asset: %w", err)
+	}
+
+	for i := range asset.Variants {
+		v := &asset.Variants[i]
+		v.AssetID = asset.ID
+		_, err := tx.ExecContext(ctx, `
+            INSERT INTO asset_blobs (blob_key, content_type)
+            VALUES ($1, $2)
+            ON CONFLICT (blob_key) DO NOTHING
+        `, v.Key, v.ContentType)
+		if err != nil {
+			return fmt.Errorf("failed to claim blob %s: %w", v.Key, err)
+		}
+		_, err = tx.NamedExecContext(ctx, `
+            INSERT INTO theme_asset_variants (`+variantColumns+`)
+            VALUES (:asset_id, :name, :blob_key, :content_type, :width, :height, :size_bytes)
+        `, v)
+		if err != nil {
+			return fmt.Errorf("failed to create asset variant %s: %w", v.Name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit asset: %w", err)
+	}
+	return nil
+}
+
+// ListAssets retrieves a client's assets, newest first
+func (r *AssetRepository) ListAssets(ctx context.Context, clientID string) ([]models.ThemeAsset, error) {
+	var assets []models.ThemeAsset
+	query := `SELECT ` + assetColumns + ` FROM theme_assets WHERE client_id = $1 ORDER BY created_at DESC, id DESC`
+	if err := r.db.SelectContext(ctx, &assets, query, clientID); err != nil {
+		return nil, fmt.Errorf("failed to list assets for client %s: %w", clientID, err)
+	}
+
+	var variants []models.ThemeAssetVariant
+	query = `
+        SELECT v.asset_id, v.name, v.blob_key, v.content_type, v.width, v.height, v.size_bytes
+        FROM theme_asset_variants v
+        JOIN theme_assets a ON a.id = v.asset_id
+        WHERE a.client_id = $1
+        ORDER BY v.asset_id, v.width, v.name
+    `
+	if err := r.db.SelectContext(ctx, &variants, query, clientID); err != nil {
+		return nil, fmt.Errorf("failed to list asset variants for client %s: %w", clientID, err)
+	}
+
+	byAsset := make(map[int64][]models.ThemeAssetVariant)
+	for _, v := range variants {
+		byAsset[v.AssetID] = append(byAsset[v.AssetID], v)
+	}
+	for i := range assets {
+		assets[i].Variants = byAsset[assets[i].ID]
+	}
+	return assets, nil
+}
+
+// FindAsset retrieves one of a client's assets
+func (r *AssetRepository) FindAsset(ctx context.Context, clientID string, assetID int64) (*models.ThemeAsset, error) {
+	return findAsset(ctx, r.db, clientID, assetID)
+}
+
+// DeleteAsset deletes an asset, its variants (ON DELETE CASCADE) and the
+// blobs no other variant refers to. External blobs are deleted last, before
+// the commit: until then the transaction holds their asset_blobs rows, so a
+// concurrent upload of the same content waits and stores them again.
+func (r *AssetRepository) DeleteAsset(ctx context.Context, clientID string, assetID int64) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	asset, err := findAsset(ctx, tx, clientID, assetID)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM theme_assets WHERE id = $1`, assetID); err != nil {
+		return fmt.Errorf("failed to delete asset: %w", err)
+	}
+
+	var orphans []string
+	for _, v := range asset.Variants {
+		result, err := tx.ExecContext(ctx, `
+            DELETE FROM asset_blobs
+            WHERE blob_key = $1 AND NOT EXISTS (SELECT 1 FROM theme_asset_variants WHERE blob_key = $1)
+        `, v.Key)
+		if err != nil {
+			return fmt.Errorf("failed to delete blob %s: %w", v.Key, err)
+		}
+		if n, err := result.RowsAffected(); err == nil && n > 0 {
+			orphans = append(orphans, v.Key)
+		}
+	}
+	// A file left behind is only wasted space and is reused by the next upload
+	// of the same content, while failing here would keep the asset with some
+	// of its files gone, so errors are ignored
+	if r.external != nil {
+		for _, key := range orphans {
+			r.external.DeleteBlob(ctx, key)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit asset deletion: %w", err)
+	}
+	return nil
+}
+
+// PutBlob inserts a blob or fills in the content of a key CreateAsset
+// recorded; an existing key with content already holds the same content
+func (r *AssetRepository) PutBlob(ctx context.Context, blob *models.AssetBlob) error {
+	if !models.IsAssetBlobKey(blob.Key) {
+		return &ValidationError{"blob_key", "must be a content hash with a png, jpg or svg extension"}
+	}
+	_, err := r.db.ExecContext(ctx, `
+        INSERT INTO asset_blobs (blob_key, content_type, data)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (blob_key) DO UPDATE SET content_type = excluded.content_type, data = excluded.data
+        WHERE asset_blobs.data IS NULL
+    `, blob.Key, blob.ContentType, blob.Data)
+	if err != nil {
+		return fmt.Errorf("failed to store blob %s: %w", blob.Key, err)
+	}
+	return nil
+}
+
+// GetBlob retrieves a blob by key
+func (r *AssetRepository) GetBlob(ctx context.Context, key string) (*models.AssetBlob, error) {
+	var blob models.AssetBlob
+	query := `SELECT blob_key, content_type, data, created_at FROM asset_blobs WHERE blob_key = $1 AND data IS NOT NULL`
+	err := r.db.GetContext(ctx, &blob, query, key)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("blob %s not found: %w", key, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blob %s: %w", key, err)
+	}
+	return &blob, nil
+}
+
+// DeleteBlob deletes a blob by key; one a variant refers to is refused by
+// the foreign key
+func (r *AssetRepository) DeleteBlob(ctx context.Context, key string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM asset_blobs WHERE blob_key = $1`, key); err != nil {
+		return fmt.Errorf("failed to delete blob %s: %w", key, err)
+	}
+	return nil
+}
+
+// findAsset reads an asset and its variants, smallest first.
+func findAsset(ctx context.Context, q sqlx.QueryerContext, clientID string, assetID int64) (*models.ThemeAsset, error) {
+	var asset models.ThemeAsset
+	query := `SELECT ` + assetColumns + ` FROM theme_assets WHERE id = $1 AND client_id = $2`
+	err := sqlx.GetContext(ctx, q, &asset, query, assetID, clientID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("asset with ID %d not found for client %s: %w", assetID, clientID, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find asset: %w", err)
+	}
+
+	query = `SELECT ` + variantColumns + ` FROM theme_asset_variants WHERE asset_id = $1 ORDER BY width, name`
+	if err := sqlx.SelectContext(ctx, q, &asset.Variants, query, assetID); err != nil {
+		return nil, fmt.Errorf("failed to find variants of asset %d: %w", assetID, err)
+	}
+	return &asset, nil
+}
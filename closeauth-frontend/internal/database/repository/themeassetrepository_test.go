@@ -0,0 +1,74 @@
+package repository_test
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+
+	"closeauth-frontend/internal/database"
+	"closeauth-frontend/internal/database/migrations"
+	"closeauth-frontend/internal/database/repository"
+	"closeauth-frontend/internal/database/repository/storetest"
+)
+
+// TestAssetRepository_Conformance runs the AssetStore and BlobStore suites
+// against Postgres when TEST_DATABASE_DSN is set (see
+// TestThemeRepository_Conformance).
+func TestAssetRepository_Conformance(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN not set")
+	}
+
+	db, err := sqlx.Connect("postgres", dsn)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := migrations.New(db, slog.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	newRepository := func(t *testing.T) *repository.AssetRepository {
+		if _, err := db.Exec(`TRUNCATE theme_asset_variants, theme_assets, asset_blobs RESTART IDENTITY CASCADE`); err != nil {
+			t.Fatalf("truncate: %v", err)
+		}
+		return repository.NewAssetRepository(&database.Database{DB: db}, nil)
+	}
+	storetest.AssetStore(t, func(t *testing.T) (repository.AssetStore, repository.BlobStore) {
+		store := newRepository(t)
+		return store, store
+	})
+	storetest.BlobStore(t, func(t *testing.T) repository.BlobStore { return newRepository(t) })
+	t.Run("FileBlobStore", func(t *testing.T) {
+		storetest.AssetStore(t, func(t *testing.T) (repository.AssetStore, repository.BlobStore) {
+			newRepository(t)
+			files := newFileBlobStore(t)
+			return repository.NewAssetRepository(&database.Database{DB: db}, files), files
+		})
+	})
+}
+
+func TestAssetRepository_SQLiteConformance(t *testing.T) {
+	storetest.AssetStore(t, func(t *testing.T) (repository.AssetStore, repository.BlobStore) {
+		store := repository.NewAssetRepository(openSQLite(t), nil)
+		return store, store
+	})
+	storetest.BlobStore(t, func(t *testing.T) repository.BlobStore {
+		return repository.NewAssetRepository(openSQLite(t), nil)
+	})
+	t.Run("FileBlobStore", func(t *testing.T) {
+		storetest.AssetStore(t, func(t *testing.T) (repository.AssetStore, repository.BlobStore) {
+			files := newFileBlobStore(t)
+			return repository.NewAssetRepository(openSQLite(t), files), files
+		})
+	})
+}
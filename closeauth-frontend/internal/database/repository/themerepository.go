@@ -3,8 +3,12 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
 	"closeauth-frontend/internal/database"
 	"closeauth-frontend/internal/database/models"
 )
@@ -17,6 +21,8 @@ func NewThemeRepository(db *database.Database) *ThemeRepository {
 	return &ThemeRepository{db: db}
 }
 
+var _ ThemeStore = (*ThemeRepository)(nil)
+
 // FindByClientID retrieves all active themes for a client
 func (r *ThemeRepository) FindByClientID(ctx context.Context, clientID string) ([]models.ClientTheme, error) {
 	var themes []models.ClientTheme
@@ -53,7 +59,7 @@ func (r *ThemeRepository) FindDefaultTheme(ctx context.Context, clientID string)
 
 	err := r.db.GetContext(ctx, &theme, query, clientID)
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("no default theme found for client %s", clientID)
+		return nil, fmt.Errorf("no default theme found for client %s: %w", clientID, ErrNotFound)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to find default theme: %w", err)
@@ -76,7 +82,7 @@ func (r *ThemeRepository) FindByClientIDAndName(ctx context.Context, clientID, t
 
 	err := r.db.GetContext(ctx, &theme, query, clientID, themeName)
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("theme '%s' not found for client %s", themeName, clientID)
+		return nil, fmt.Errorf("theme '%s' not found for client %s: %w", themeName, clientID, ErrNotFound)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to find theme: %w", err)
@@ -99,7 +105,7 @@ func (r *ThemeRepository) FindThemeByID(ctx context.Context, themeID int) (*mode
 
 	err := r.db.GetContext(ctx, &theme, query, themeID)
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("theme with ID %d not found", themeID)
+		return nil, fmt.Errorf("theme with ID %d not found: %w", themeID, ErrNotFound)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to find theme: %w", err)
@@ -163,7 +169,7 @@ func (r *ThemeRepository) GetConfigValue(ctx context.Context, themeID int, confi
 
 	err := r.db.GetContext(ctx, &config, query, themeID, configKey)
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("configuration '%s' not found for theme %d", configKey, themeID)
+		return nil, fmt.Errorf("configuration '%s' not found for theme %d: %w", configKey, themeID, ErrNotFound)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get configuration: %w", err)
@@ -206,3 +212,426 @@ func (r *ThemeRepository) CountThemesByClientID(ctx context.Context, clientID st
 
 	return count, nil
 }
+
+const themeColumns = `id, client_id, theme_name, is_active, is_default, logo_url,
+               light_primary_color, light_background_color, light_button_color, light_text_color,
+               dark_primary_color, dark_background_color, dark_button_color, dark_text_color,
+               default_mode, allow_mode_toggle, created_at, updated_at`
+
+const configColumns = `id, theme_id, config_key, config_value, config_type, created_at, updated_at`
+
+// FindAllByClientID retrieves every theme for a client, including inactive ones
+func (r *ThemeRepository) FindAllByClientID(ctx context.Context, clientID string) ([]models.ClientTheme, error) {
+	var themes []models.ClientTheme
+	query := `SELECT ` + themeColumns + `
+        FROM client_themes
+        WHERE client_id = $1
+        ORDER BY is_default DESC, theme_name ASC
+    `
+
+	err := r.db.SelectContext(ctx, &themes, query, clientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find themes for client %s: %w", clientID, err)
+	}
+
+	return themes, nil
+}
+
+// FindConfigurationByID retrieves one configuration entry of a theme
+func (r *ThemeRepository) FindConfigurationByID(ctx context.Context, themeID, configID int) (*models.ThemeConfiguration, error) {
+	var config models.ThemeConfiguration
+	query := `SELECT ` + configColumns + ` FROM theme_configurations WHERE id = $1 AND theme_id = $2`
+
+	err := r.db.GetContext(ctx, &config, query, configID, themeID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("configuration %d not found for theme %d: %w", configID, themeID, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	return &config, nil
+}
+
+// CreateTheme inserts a theme, making it the active default when requested
+// or when the client has none yet
+func (r *ThemeRepository) CreateTheme(ctx context.Context, theme *models.ClientTheme) error {
+	if theme.DefaultMode == nil {
+		light := "light"
+		theme.DefaultMode = &light
+	}
+	if err := ValidateTheme(theme); err != nil {
+		return err
+	}
+
+	tx, err := r.beginClientTx(ctx, theme.ClientID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var hasDefault bool
+	err = tx.GetContext(ctx, &hasDefault, `
+        SELECT EXISTS (SELECT 1 FROM client_themes WHERE client_id = $1 AND is_active = true AND is_default = true)
+    `, theme.ClientID)
+	if err != nil {
+		return fmt.Errorf("failed to look up default theme: %w", err)
+	}
+
+	theme.IsDefault = theme.IsDefault || !hasDefault
+	theme.IsActive = theme.IsDefault
+	if theme.IsDefault {
+		if err := deactivateThemes(ctx, tx, theme.ClientID, 0); err != nil {
+			return err
+		}
+	}
+
+	query := `
+        INSERT INTO client_themes (client_id, theme_name, is_active, is_default, logo_url,
+               light_primary_color, light_background_color, light_button_color, light_text_color,
+               dark_primary_color, dark_background_color, dark_button_color, dark_text_color,
+               default_mode, allow_mode_toggle)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+        RETURNING id, created_at, updated_at
+    `
+	err = tx.QueryRowxContext(ctx, query,
+		theme.ClientID, theme.ThemeName, theme.IsActive, theme.IsDefault, theme.LogoURL,
+		theme.LightPrimaryColor, theme.LightBackgroundColor, theme.LightButtonColor, theme.LightTextColor,
+		theme.DarkPrimaryColor, theme.DarkBackgroundColor, theme.DarkButtonColor, theme.DarkTextColor,
+		theme.DefaultMode, theme.AllowModeToggle,
+	).Scan(&theme.ID, &theme.CreatedAt, &theme.UpdatedAt)
+	if isUniqueViolation(err) {
+		return &ConflictError{fmt.Sprintf("theme '%s' already exists for client %s", theme.ThemeName, theme.ClientID)}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create theme: %w", err)
+	}
+	if _, err := recordVersion(ctx, tx, theme.ID, models.ThemeChangeCreate, nil); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit theme: %w", err)
+	}
+	return nil
+}
+
+// UpdateTheme saves a theme's logo, colours and mode settings
+func (r *ThemeRepository) UpdateTheme(ctx context.Context, theme *models.ClientTheme) error {
+	if err := ValidateTheme(theme); err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	updated, err := updateTheme(ctx, tx, theme)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit theme: %w", err)
+	}
+	*theme = *updated
+	return nil
+}
+
+// ActivateTheme makes a theme the client's active default, deactivating the others
+func (r *ThemeRepository) ActivateTheme(ctx context.Context, clientID string, themeID int) (*models.ClientTheme, error) {
+	tx, err := r.beginClientTx(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	theme, err := activateTheme(ctx, tx, clientID, themeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit theme activation: %w", err)
+	}
+	return theme, nil
+}
+
+// UpdateAndActivateTheme saves a theme and makes it the client's active
+// default in one transaction
+func (r *ThemeRepository) UpdateAndActivateTheme(ctx context.Context, theme *models.ClientTheme) error {
+	if err := ValidateTheme(theme); err != nil {
+		return err
+	}
+
+	tx, err := r.beginClientTx(ctx, theme.ClientID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := updateTheme(ctx, tx, theme); err != nil {
+		return err
+	}
+	activated, err := activateTheme(ctx, tx, theme.ClientID, theme.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit theme: %w", err)
+	}
+	*theme = *activated
+	return nil
+}
+
+// DeleteTheme deletes an inactive theme; its configuration goes with it (ON
+// DELETE CASCADE). A final version records the theme as deleted, and the
+// history stays.
+func (r *ThemeRepository) DeleteTheme(ctx context.Context, clientID string, themeID int) error {
+	tx, err := r.beginClientTx(ctx, clientID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var active bool
+	err = tx.GetContext(ctx, &active, `SELECT is_active FROM client_themes WHERE id = $1 AND client_id = $2`, themeID, clientID)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("theme with ID %d not found for client %s: %w", themeID, clientID, ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find theme: %w", err)
+	}
+	if active {
+		return &ConflictError{"cannot delete the active theme, activate another theme first"}
+	}
+
+	if _, err := recordVersion(ctx, tx, themeID, models.ThemeChangeDelete, nil); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM client_themes WHERE id = $1`, themeID); err != nil {
+		return fmt.Errorf("failed to delete theme: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit theme deletion: %w", err)
+	}
+	return nil
+}
+
+// CreateConfiguration inserts a configuration entry for an existing theme
+func (r *ThemeRepository) CreateConfiguration(ctx context.Context, config *models.ThemeConfiguration) error {
+	if config.ConfigType == "" {
+		config.ConfigType = "string"
+	}
+	if err := ValidateConfiguration(config); err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := lockTheme(ctx, tx, "", config.ThemeID); err != nil {
+		return err
+	}
+
+	query := `
+        INSERT INTO theme_configurations (theme_id, config_key, config_value, config_type)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id, created_at, updated_at
+    `
+	err = tx.QueryRowxContext(ctx, query, config.ThemeID, config.ConfigKey, config.ConfigValue, config.ConfigType).
+		Scan(&config.ID, &config.CreatedAt, &config.UpdatedAt)
+	if isUniqueViolation(err) {
+		return &ConflictError{fmt.Sprintf("configuration '%s' already exists for theme %d", config.ConfigKey, config.ThemeID)}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create configuration: %w", err)
+	}
+	if _, err := recordVersion(ctx, tx, config.ThemeID, models.ThemeChangeConfigCreate, nil); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit configuration: %w", err)
+	}
+	return nil
+}
+
+// UpdateConfiguration saves a configuration entry's value and type
+func (r *ThemeRepository) UpdateConfiguration(ctx context.Context, config *models.ThemeConfiguration) error {
+	if config.ConfigType == "" {
+		config.ConfigType = "string"
+	}
+	if err := validateConfigValue(config.ConfigType, config.ConfigValue); err != nil {
+		return err
+	}
+
+	query := `
+        UPDATE theme_configurations
+        SET config_value = $3, config_type = $4, updated_at = CURRENT_TIMESTAMP
+        WHERE id = $1 AND theme_id = $2
+        RETURNING ` + configColumns
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := lockTheme(ctx, tx, "", config.ThemeID); err != nil {
+		return err
+	}
+	var updated models.ThemeConfiguration
+	err = tx.GetContext(ctx, &updated, query, config.ID, config.ThemeID, config.ConfigValue, config.ConfigType)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("configuration %d not found for theme %d: %w", config.ID, config.ThemeID, ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update configuration: %w", err)
+	}
+	if _, err := recordVersion(ctx, tx, config.ThemeID, models.ThemeChangeConfigUpdate, nil); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit configuration: %w", err)
+	}
+	*config = updated
+	return nil
+}
+
+// DeleteConfiguration deletes one configuration entry of a theme
+func (r *ThemeRepository) DeleteConfiguration(ctx context.Context, themeID, configID int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := lockTheme(ctx, tx, "", themeID); err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(ctx, `DELETE FROM theme_configurations WHERE id = $1 AND theme_id = $2`, configID, themeID)
+	if err != nil {
+		return fmt.Errorf("failed to delete configuration: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("configuration %d not found for theme %d: %w", configID, themeID, ErrNotFound)
+	}
+	if _, err := recordVersion(ctx, tx, themeID, models.ThemeChangeConfigDelete, nil); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit configuration deletion: %w", err)
+	}
+	return nil
+}
+
+// beginClientTx starts a transaction that serialises theme writes for one
+// client, so concurrent activations cannot both leave a default behind.
+// SQLite transactions already hold the database write lock (_txlock=immediate).
+func (r *ThemeRepository) beginClientTx(ctx context.Context, clientID string) (*sqlx.Tx, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	if !r.db.SQLite() {
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('client_themes:' || $1))`, clientID); err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("failed to lock themes for client %s: %w", clientID, err)
+		}
+	}
+	return tx, nil
+}
+
+// updateTheme saves the editable fields of a theme and records the update
+// as a new version.
+func updateTheme(ctx context.Context, tx *sqlx.Tx, theme *models.ClientTheme) (*models.ClientTheme, error) {
+	query := `
+        UPDATE client_themes
+        SET logo_url = $3,
+            light_primary_color = $4, light_background_color = $5, light_button_color = $6, light_text_color = $7,
+            dark_primary_color = $8, dark_background_color = $9, dark_button_color = $10, dark_text_color = $11,
+            default_mode = $12, allow_mode_toggle = $13, updated_at = CURRENT_TIMESTAMP
+        WHERE id = $1 AND client_id = $2
+        RETURNING ` + themeColumns
+
+	var updated models.ClientTheme
+	err := tx.GetContext(ctx, &updated, query,
+		theme.ID, theme.ClientID, theme.LogoURL,
+		theme.LightPrimaryColor, theme.LightBackgroundColor, theme.LightButtonColor, theme.LightTextColor,
+		theme.DarkPrimaryColor, theme.DarkBackgroundColor, theme.DarkButtonColor, theme.DarkTextColor,
+		theme.DefaultMode, theme.AllowModeToggle,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("theme with ID %d not found for client %s: %w", theme.ID, theme.ClientID, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update theme: %w", err)
+	}
+	if _, err := recordVersion(ctx, tx, theme.ID, models.ThemeChangeUpdate, nil); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// activateTheme makes a theme its client's active default and records a
+// version. tx must come from beginClientTx.
+func activateTheme(ctx context.Context, tx *sqlx.Tx, clientID string, themeID int) (*models.ClientTheme, error) {
+	// Others first, so the one-default-per-client index never sees two
+	if err := deactivateThemes(ctx, tx, clientID, themeID); err != nil {
+		return nil, err
+	}
+
+	var theme models.ClientTheme
+	query := `
+        UPDATE client_themes
+        SET is_active = true, is_default = true, updated_at = CURRENT_TIMESTAMP
+        WHERE id = $1 AND client_id = $2
+        RETURNING ` + themeColumns
+	err := tx.GetContext(ctx, &theme, query, themeID, clientID)
+	if err == sql.ErrNoRows {
+		// The caller's rollback undoes the deactivation
+		return nil, fmt.Errorf("theme with ID %d not found for client %s: %w", themeID, clientID, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to activate theme: %w", err)
+	}
+	if _, err := recordVersion(ctx, tx, themeID, models.ThemeChangeActivate, nil); err != nil {
+		return nil, err
+	}
+	return &theme, nil
+}
+
+// deactivateThemes clears the active and default flags of a client's themes
+// other than keepID.
+func deactivateThemes(ctx context.Context, tx *sqlx.Tx, clientID string, keepID int) error {
+	_, err := tx.ExecContext(ctx, `
+        UPDATE client_themes
+        SET is_active = false, is_default = false, updated_at = CURRENT_TIMESTAMP
+        WHERE client_id = $1 AND id <> $2 AND (is_active = true OR is_default = true)
+    `, clientID, keepID)
+	if err != nil {
+		return fmt.Errorf("failed to deactivate themes for client %s: %w", clientID, err)
+	}
+	return nil
+}
+
+// isUniqueViolation reports whether err is a unique constraint failure from
+// Postgres (SQLSTATE 23505) or SQLite (SQLITE_CONSTRAINT_UNIQUE).
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "23505"
+	}
+	var sqliteErr interface{ Code() int }
+	return errors.As(err, &sqliteErr) && sqliteErr.Code() == 2067
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"closeauth-frontend/internal/database/models"
+)
+
+var (
+	// DefaultModes are the accepted values of ClientTheme.DefaultMode.
+	DefaultModes = []string{"light", "dark", "system"}
+
+	// ConfigTypes are the accepted values of ThemeConfiguration.ConfigType.
+	ConfigTypes = []string{"string", "url", "json", "number", "css"}
+
+	// AssetKinds are the accepted values of ThemeAsset.Kind.
+	AssetKinds = []string{models.AssetKindLogo, models.AssetKindBackground}
+)
+
+var hexColor = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// ValidateTheme checks a theme against the rules the client_themes table and
+// Spring's theme DTOs enforce. Unset colours and mode are allowed.
+func ValidateTheme(theme *models.ClientTheme) error {
+	if theme.ClientID == "" {
+		return &ValidationError{"client_id", "is required"}
+	}
+	if err := validateLength("theme_name", theme.ThemeName, 100); err != nil {
+		return err
+	}
+	if theme.LogoURL != nil && len(*theme.LogoURL) > 500 {
+		return &ValidationError{"logo_url", "must not exceed 500 characters"}
+	}
+
+	colors := []struct {
+		field string
+		value *string
+	}{
+		{"light_primary_color", theme.LightPrimaryColor},
+		{"light_background_color", theme.LightBackgroundColor},
+		{"light_button_color", theme.LightButtonColor},
+		{"light_text_color", theme.LightTextColor},
+		{"dark_primary_color", theme.DarkPrimaryColor},
+		{"dark_background_color", theme.DarkBackgroundColor},
+		{"dark_button_color", theme.DarkButtonColor},
+		{"dark_text_color", theme.DarkTextColor},
+	}
+	for _, c := range colors {
+		if c.value != nil && !hexColor.MatchString(*c.value) {
+			return &ValidationError{c.field, "must be a hex colour like #1a2b3c"}
+		}
+	}
+
+	if theme.DefaultMode != nil && !slices.Contains(DefaultModes, *theme.DefaultMode) {
+		return &ValidationError{"default_mode", "must be one of " + strings.Join(DefaultModes, ", ")}
+	}
+	return nil
+}
+
+// ValidateConfiguration checks a configuration entry, including that its
+// value parses as its type. An empty ConfigType counts as string.
+func ValidateConfiguration(config *models.ThemeConfiguration) error {
+	if err := validateLength("config_key", config.ConfigKey, 100); err != nil {
+		return err
+	}
+	return validateConfigValue(config.ConfigType, config.ConfigValue)
+}
+
+// ValidateAsset checks an asset and its variants before they are recorded.
+func ValidateAsset(asset *models.ThemeAsset) error {
+	if asset.ClientID == "" {
+		return &ValidationError{"client_id", "is required"}
+	}
+	if !slices.Contains(AssetKinds, asset.Kind) {
+		return &ValidationError{"kind", "must be one of " + strings.Join(AssetKinds, ", ")}
+	}
+	if len(asset.Filename) > 255 {
+		return &ValidationError{"filename", "must not exceed 255 characters"}
+	}
+	if asset.ContentType == "" {
+		return &ValidationError{"content_type", "is required"}
+	}
+	if len(asset.Variants) == 0 {
+		return &ValidationError{"variants", "must not be empty"}
+	}
+	seen := make(map[string]bool, len(asset.Variants))
+	for _, v := range asset.Variants {
+		if err := validateLength("name", v.Name, 20); err != nil {
+			return err
+		}
+		if seen[v.Name] {
+			return &ValidationError{"name", "'" + v.Name + "' is repeated"}
+		}
+		seen[v.Name] = true
+		if !models.IsAssetBlobKey(v.Key) {
+			return &ValidationError{"blob_key", "must be a content hash with a png, jpg or svg extension"}
+		}
+	}
+	return nil
+}
+
+// validateSnapshot checks a snapshot about to be applied to theme: its theme
+// fields as they would be saved, and its configuration entries, which must
+// have unique keys. Entries without a type become strings.
+func validateSnapshot(theme *models.ClientTheme, snapshot *models.ThemeSnapshot) error {
+	candidate := *theme
+	snapshot.ApplyTo(&candidate)
+	if err := ValidateTheme(&candidate); err != nil {
+		return err
+	}
+
+	snapshot.SortConfigurations()
+	for i := range snapshot.Configurations {
+		c := &snapshot.Configurations[i]
+		if c.Type == "" {
+			c.Type = "string"
+		}
+		if err := ValidateConfiguration(&models.ThemeConfiguration{ConfigKey: c.Key, ConfigValue: c.Value, ConfigType: c.Type}); err != nil {
+			return err
+		}
+		if i > 0 && snapshot.Configurations[i-1].Key == c.Key {
+			return &ValidationError{"config_key", "'" + c.Key + "' is repeated"}
+		}
+	}
+	return nil
+}
+
+// validateConfigValue checks a configuration value against its type.
+func validateConfigValue(configType, value string) error {
+	if value == "" {
+		return &ValidationError{"config_value", "is required"}
+	}
+
+	switch configType {
+	case "", "string", "css":
+	case "number":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return &ValidationError{"config_value", "must be a number"}
+		}
+	case "json":
+		if !json.Valid([]byte(value)) {
+			return &ValidationError{"config_value", "must be valid JSON"}
+		}
+	case "url":
+		if !validURL(value) {
+			return &ValidationError{"config_value", "must be an http(s) URL or an absolute path"}
+		}
+	default:
+		return &ValidationError{"config_type", "must be one of " + strings.Join(ConfigTypes, ", ")}
+	}
+	return nil
+}
+
+func validateLength(field, value string, max int) error {
+	if strings.TrimSpace(value) == "" {
+		return &ValidationError{field, "is required"}
+	}
+	if len(value) > max {
+		return &ValidationError{field, "must not exceed " + strconv.Itoa(max) + " characters"}
+	}
+	return nil
+}
+
+// validURL accepts absolute http(s) URLs and same-origin absolute paths.
+func validURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	if u.Scheme == "" && u.Host == "" {
+		return strings.HasPrefix(raw, "/") && !strings.HasPrefix(raw, "//")
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,316 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"closeauth-frontend/internal/database/models"
+)
+
+const versionColumns = `id, theme_id, version, snapshot, author, change_type, source_version, created_at`
+
+const draftColumns = `theme_id, snapshot, base_version, author, created_at, updated_at`
+
+// ListThemeVersions retrieves a theme's history, newest first
+func (r *ThemeRepository) ListThemeVersions(ctx context.Context, clientID string, themeID int) ([]models.ThemeVersion, error) {
+	if err := checkThemeOwner(ctx, r.db, clientID, themeID); err != nil {
+		return nil, err
+	}
+
+	var versions []models.ThemeVersion
+	query := `SELECT ` + versionColumns + ` FROM theme_versions WHERE theme_id = $1 ORDER BY version DESC`
+	if err := r.db.SelectContext(ctx, &versions, query, themeID); err != nil {
+		return nil, fmt.Errorf("failed to list versions of theme %d: %w", themeID, err)
+	}
+	return versions, nil
+}
+
+// FindThemeVersion retrieves one version of a theme
+func (r *ThemeRepository) FindThemeVersion(ctx context.Context, clientID string, themeID, version int) (*models.ThemeVersion, error) {
+	if err := checkThemeOwner(ctx, r.db, clientID, themeID); err != nil {
+		return nil, err
+	}
+	return findVersion(ctx, r.db, themeID, version)
+}
+
+// RollbackTheme restores an earlier version as a new one
+func (r *ThemeRepository) RollbackTheme(ctx context.Context, clientID string, themeID, version int) (*models.ThemeVersion, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	theme, err := lockTheme(ctx, tx, clientID, themeID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := findVersion(ctx, tx, themeID, version)
+	if err != nil {
+		return nil, err
+	}
+	if err := applySnapshot(ctx, tx, theme, &target.Snapshot); err != nil {
+		return nil, err
+	}
+	restored, err := recordVersion(ctx, tx, themeID, models.ThemeChangeRollback, &version)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit theme rollback: %w", err)
+	}
+	return restored, nil
+}
+
+// FindThemeDraft retrieves a theme's unpublished draft
+func (r *ThemeRepository) FindThemeDraft(ctx context.Context, clientID string, themeID int) (*models.ThemeDraft, error) {
+	if err := checkThemeOwner(ctx, r.db, clientID, themeID); err != nil {
+		return nil, err
+	}
+	return findDraft(ctx, r.db, themeID)
+}
+
+// SaveThemeDraft creates or replaces a theme's draft
+func (r *ThemeRepository) SaveThemeDraft(ctx context.Context, clientID string, draft *models.ThemeDraft) error {
+	theme, err := r.FindThemeByID(ctx, draft.ThemeID)
+	if err == nil && theme.ClientID != clientID {
+		err = fmt.Errorf("theme with ID %d not found for client %s: %w", draft.ThemeID, clientID, ErrNotFound)
+	}
+	if err != nil {
+		return err
+	}
+	if err := validateSnapshot(theme, &draft.Snapshot); err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	latest, err := latestVersion(ctx, tx, draft.ThemeID)
+	if err != nil {
+		return err
+	}
+	// A replaced draft keeps the base it was started from
+	query := `
+        INSERT INTO theme_drafts (theme_id, snapshot, base_version, author)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (theme_id) DO UPDATE
+        SET snapshot = excluded.snapshot, author = excluded.author, updated_at = CURRENT_TIMESTAMP
+        RETURNING base_version, author, created_at, updated_at
+    `
+	err = tx.QueryRowxContext(ctx, query, draft.ThemeID, draft.Snapshot, latest, AuthorFromContext(ctx)).
+		Scan(&draft.BaseVersion, &draft.Author, &draft.CreatedAt, &draft.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save draft of theme %d: %w", draft.ThemeID, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit theme draft: %w", err)
+	}
+	return nil
+}
+
+// DiscardThemeDraft deletes a theme's draft
+func (r *ThemeRepository) DiscardThemeDraft(ctx context.Context, clientID string, themeID int) error {
+	res, err := r.db.ExecContext(ctx, `
+        DELETE FROM theme_drafts
+        WHERE theme_id = $1 AND theme_id IN (SELECT id FROM client_themes WHERE client_id = $2)
+    `, themeID, clientID)
+	if err != nil {
+		return fmt.Errorf("failed to discard draft of theme %d: %w", themeID, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("no draft found for theme %d: %w", themeID, ErrNotFound)
+	}
+	return nil
+}
+
+// PublishThemeDraft applies a theme's draft as a new version
+func (r *ThemeRepository) PublishThemeDraft(ctx context.Context, clientID string, themeID int) (*models.ThemeVersion, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	theme, err := lockTheme(ctx, tx, clientID, themeID)
+	if err != nil {
+		return nil, err
+	}
+	draft, err := findDraft(ctx, tx, themeID)
+	if err != nil {
+		return nil, err
+	}
+	latest, err := latestVersion(ctx, tx, themeID)
+	if err != nil {
+		return nil, err
+	}
+	if latest != draft.BaseVersion {
+		return nil, &ConflictError{fmt.Sprintf("theme %d has changed since version %d the draft is based on", themeID, draft.BaseVersion)}
+	}
+
+	if err := applySnapshot(ctx, tx, theme, &draft.Snapshot); err != nil {
+		return nil, err
+	}
+	published, err := recordVersion(ctx, tx, themeID, models.ThemeChangePublish, &draft.BaseVersion)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM theme_drafts WHERE theme_id = $1`, themeID); err != nil {
+		return nil, fmt.Errorf("failed to delete published draft: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit theme publication: %w", err)
+	}
+	return published, nil
+}
+
+// lockTheme touches a theme's updated_at, which also holds its row lock until
+// the transaction ends so concurrent writes number their versions in turn. An
+// empty clientID matches any owner.
+func lockTheme(ctx context.Context, tx *sqlx.Tx, clientID string, themeID int) (*models.ClientTheme, error) {
+	var theme models.ClientTheme
+	query := `UPDATE client_themes SET updated_at = CURRENT_TIMESTAMP WHERE id = $1 RETURNING ` + themeColumns
+	err := tx.GetContext(ctx, &theme, query, themeID)
+	if err == nil && clientID != "" && theme.ClientID != clientID {
+		err = sql.ErrNoRows // Rolled back with the transaction
+	}
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("theme with ID %d not found: %w", themeID, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock theme: %w", err)
+	}
+	return &theme, nil
+}
+
+// checkThemeOwner fails with ErrNotFound unless the theme belongs to clientID.
+func checkThemeOwner(ctx context.Context, q sqlx.QueryerContext, clientID string, themeID int) error {
+	var owner string
+	err := sqlx.GetContext(ctx, q, &owner, `SELECT client_id FROM client_themes WHERE id = $1`, themeID)
+	if err == sql.ErrNoRows || (err == nil && owner != clientID) {
+		return fmt.Errorf("theme with ID %d not found for client %s: %w", themeID, clientID, ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find theme: %w", err)
+	}
+	return nil
+}
+
+func findVersion(ctx context.Context, q sqlx.QueryerContext, themeID, version int) (*models.ThemeVersion, error) {
+	var v models.ThemeVersion
+	query := `SELECT ` + versionColumns + ` FROM theme_versions WHERE theme_id = $1 AND version = $2`
+	err := sqlx.GetContext(ctx, q, &v, query, themeID, version)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("version %d not found for theme %d: %w", version, themeID, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find theme version: %w", err)
+	}
+	return &v, nil
+}
+
+func findDraft(ctx context.Context, q sqlx.QueryerContext, themeID int) (*models.ThemeDraft, error) {
+	var draft models.ThemeDraft
+	err := sqlx.GetContext(ctx, q, &draft, `SELECT `+draftColumns+` FROM theme_drafts WHERE theme_id = $1`, themeID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("no draft found for theme %d: %w", themeID, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find theme draft: %w", err)
+	}
+	return &draft, nil
+}
+
+// latestVersion returns a theme's newest version number, or 0 if it has none.
+func latestVersion(ctx context.Context, tx *sqlx.Tx, themeID int) (int, error) {
+	var latest int
+	err := tx.GetContext(ctx, &latest, `SELECT COALESCE(MAX(version), 0) FROM theme_versions WHERE theme_id = $1`, themeID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find latest version of theme %d: %w", themeID, err)
+	}
+	return latest, nil
+}
+
+// recordVersion appends a snapshot of the theme as written so far in tx.
+func recordVersion(ctx context.Context, tx *sqlx.Tx, themeID int, change string, source *int) (*models.ThemeVersion, error) {
+	var theme models.ClientTheme
+	if err := tx.GetContext(ctx, &theme, `SELECT `+themeColumns+` FROM client_themes WHERE id = $1`, themeID); err != nil {
+		return nil, fmt.Errorf("failed to snapshot theme %d: %w", themeID, err)
+	}
+	var configs []models.ThemeConfiguration
+	if err := tx.SelectContext(ctx, &configs, `SELECT `+configColumns+` FROM theme_configurations WHERE theme_id = $1`, themeID); err != nil {
+		return nil, fmt.Errorf("failed to snapshot configuration of theme %d: %w", themeID, err)
+	}
+	latest, err := latestVersion(ctx, tx, themeID)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &models.ThemeVersion{
+		ThemeID:       themeID,
+		Version:       latest + 1,
+		Snapshot:      models.NewThemeSnapshot(&theme, configs),
+		Author:        AuthorFromContext(ctx),
+		Change:        change,
+		SourceVersion: source,
+	}
+	query := `
+        INSERT INTO theme_versions (theme_id, version, snapshot, author, change_type, source_version)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING id, created_at
+    `
+	err = tx.QueryRowxContext(ctx, query, v.ThemeID, v.Version, v.Snapshot, v.Author, v.Change, v.SourceVersion).
+		Scan(&v.ID, &v.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record version of theme %d: %w", themeID, err)
+	}
+	return v, nil
+}
+
+// applySnapshot validates snapshot and writes it over the locked theme,
+// replacing its configuration.
+func applySnapshot(ctx context.Context, tx *sqlx.Tx, theme *models.ClientTheme, snapshot *models.ThemeSnapshot) error {
+	if err := validateSnapshot(theme, snapshot); err != nil {
+		return err
+	}
+
+	snapshot.ApplyTo(theme)
+	_, err := tx.ExecContext(ctx, `
+        UPDATE client_themes
+        SET logo_url = $2,
+            light_primary_color = $3, light_background_color = $4, light_button_color = $5, light_text_color = $6,
+            dark_primary_color = $7, dark_background_color = $8, dark_button_color = $9, dark_text_color = $10,
+            default_mode = $11, allow_mode_toggle = $12, updated_at = CURRENT_TIMESTAMP
+        WHERE id = $1
+    `, theme.ID, theme.LogoURL,
+		theme.LightPrimaryColor, theme.LightBackgroundColor, theme.LightButtonColor, theme.LightTextColor,
+		theme.DarkPrimaryColor, theme.DarkBackgroundColor, theme.DarkButtonColor, theme.DarkTextColor,
+		theme.DefaultMode, theme.AllowModeToggle,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to restore theme %d: %w", theme.ID, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM theme_configurations WHERE theme_id = $1`, theme.ID); err != nil {
+		return fmt.Errorf("failed to clear configuration of theme %d: %w", theme.ID, err)
+	}
+	for _, c := range snapshot.Configurations {
+		_, err := tx.ExecContext(ctx, `
+            INSERT INTO theme_configurations (theme_id, config_key, config_value, config_type)
+            VALUES ($1, $2, $3, $4)
+        `, theme.ID, c.Key, c.Value, c.Type)
+		if err != nil {
+			return fmt.Errorf("failed to restore configuration '%s' of theme %d: %w", c.Key, theme.ID, err)
+		}
+	}
+	return nil
+}
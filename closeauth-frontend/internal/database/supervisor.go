@@ -0,0 +1,250 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"closeauth-frontend/internal/config"
+)
+
+// State is the supervisor's view of the database.
+type State string
+
+const (
+	// StateConnecting means no connection has been established yet.
+	StateConnecting State = "connecting"
+
+	// StateConnected means the pool is open and answered the last ping.
+	StateConnected State = "connected"
+
+	// StateDisconnected means an established connection was lost.
+	StateDisconnected State = "disconnected"
+)
+
+// errSupervisorClosed fails a connection attempt that finishes after Close.
+var errSupervisorClosed = errors.New("database supervisor closed")
+
+// defaultMinBackoff is the first reconnection delay; it doubles up to the configured cap.
+const defaultMinBackoff = time.Second
+
+// Status is a snapshot of the supervisor for health checks and diagnostics.
+type Status struct {
+	State     State     `json:"state"`
+	Since     time.Time `json:"since"`
+	Attempts  int       `json:"attempts"` // failed attempts since the last state change
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// SupervisorOptions customises a Supervisor.
+type SupervisorOptions struct {
+	// OnConnect runs on every fresh pool before it is handed out, e.g. a schema
+	// check. An error closes the pool and counts as a failed attempt.
+	OnConnect func(context.Context, *Database) error
+
+	// OnStateChange is called after every state transition.
+	OnStateChange func(State)
+
+	// OnAttempt is called after every connection or reconnection attempt.
+	OnAttempt func(error)
+}
+
+// Supervisor owns the database connection. It connects in the background with
+// exponential backoff, pings a connected pool periodically and hides it while
+// unreachable, so callers either get a working *Database or nil — never a
+// permanently disabled one.
+type Supervisor struct {
+	cfg    *config.DatabaseConfig
+	opts   SupervisorOptions
+	logger *slog.Logger
+
+	// Swappable for tests
+	connect    func(context.Context, *config.DatabaseConfig) (*Database, error)
+	ping       func(context.Context, *Database) error
+	minBackoff time.Duration
+
+	mu       sync.RWMutex
+	pool     *Database // kept across outages; database/sql reconnects it
+	closed   bool
+	state    State
+	since    time.Time
+	attempts int
+	lastErr  error
+}
+
+// NewSupervisor creates a supervisor in StateConnecting. Call Connect for a
+// synchronous first attempt and Run to keep it connected.
+func NewSupervisor(cfg *config.DatabaseConfig, logger *slog.Logger, opts SupervisorOptions) *Supervisor {
+	return &Supervisor{
+		cfg:     cfg,
+		opts:    opts,
+		logger:  logger.With("component", "database"),
+		connect: Connect,
+		ping: func(ctx context.Context, db *Database) error {
+			return db.PingContext(ctx)
+		},
+		minBackoff: defaultMinBackoff,
+		state:      StateConnecting,
+		since:      time.Now(),
+	}
+}
+
+// DB returns the database while it is connected, otherwise nil. It is safe to
+// call on a nil Supervisor.
+func (s *Supervisor) DB() *Database {
+	if s == nil {
+		return nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.state != StateConnected {
+		return nil
+	}
+	return s.pool
+}
+
+// Status returns the current state. A nil Supervisor reports StateConnecting.
+func (s *Supervisor) Status() Status {
+	if s == nil {
+		return Status{State: StateConnecting}
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	status := Status{State: s.state, Since: s.since, Attempts: s.attempts}
+	if s.lastErr != nil {
+		status.LastError = s.lastErr.Error()
+	}
+	return status
+}
+
+// Connect makes one attempt to open (or, after an outage, re-verify) the pool.
+func (s *Supervisor) Connect(ctx context.Context) error {
+	s.mu.RLock()
+	pool := s.pool
+	s.mu.RUnlock()
+
+	var err error
+	if pool != nil {
+		err = s.ping(ctx, pool)
+	} else {
+		pool, err = s.open(ctx)
+	}
+	if s.opts.OnAttempt != nil {
+		s.opts.OnAttempt(err)
+	}
+	if err != nil {
+		s.fail(err)
+		return err
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		pool.Close()
+		return errSupervisorClosed
+	}
+	s.pool = pool
+	s.mu.Unlock()
+	s.transition(StateConnected, nil)
+	return nil
+}
+
+func (s *Supervisor) open(ctx context.Context) (*Database, error) {
+	pool, err := s.connect(ctx, s.cfg)
+	if err != nil {
+		return nil, err
+	}
+	if s.opts.OnConnect != nil {
+		if err := s.opts.OnConnect(ctx, pool); err != nil {
+			pool.Close()
+			return nil, err
+		}
+	}
+	return pool, nil
+}
+
+// Run keeps the database connected until ctx ends. The pool stays open for
+// the requests still in flight; call Close once they are done.
+func (s *Supervisor) Run(ctx context.Context) {
+	backoff := s.minBackoff
+	for {
+		var wait time.Duration
+		if s.Status().State == StateConnected {
+			wait = s.cfg.PingInterval
+		} else {
+			// Jitter keeps restarted replicas from reconnecting in lockstep
+			wait = backoff/2 + rand.N(backoff/2+1)
+			backoff = min(backoff*2, s.cfg.ReconnectMaxBackoff)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+
+		attemptCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		if db := s.DB(); db != nil {
+			if err := s.ping(attemptCtx, db); err != nil && ctx.Err() == nil {
+				s.transition(StateDisconnected, err)
+			}
+		} else if s.Connect(attemptCtx) == nil {
+			backoff = s.minBackoff
+		}
+		cancel()
+	}
+}
+
+// Close closes the pool, if any, and fails later connection attempts.
+func (s *Supervisor) Close() error {
+	s.mu.Lock()
+	pool := s.pool
+	s.pool = nil
+	s.closed = true
+	s.mu.Unlock()
+	if pool == nil {
+		return nil
+	}
+	return pool.Close()
+}
+
+// fail records a failed attempt without changing state.
+func (s *Supervisor) fail(err error) {
+	s.mu.Lock()
+	s.attempts++
+	s.lastErr = err
+	attempts := s.attempts
+	s.mu.Unlock()
+
+	// Log the first failure loudly, then only occasionally
+	if attempts == 1 || attempts%10 == 0 {
+		s.logger.Warn("database unavailable, retrying", "attempts", attempts, "error", err)
+	}
+}
+
+func (s *Supervisor) transition(state State, err error) {
+	s.mu.Lock()
+	if s.state == state {
+		s.mu.Unlock()
+		return
+	}
+	previous := s.state
+	s.state = state
+	s.since = time.Now()
+	s.attempts = 0
+	s.lastErr = err
+	s.mu.Unlock()
+
+	switch state {
+	case StateConnected:
+		s.logger.Info("database connected", "previous_state", previous)
+	default:
+		s.logger.Error("database connection lost", "error", err)
+	}
+	if s.opts.OnStateChange != nil {
+		s.opts.OnStateChange(state)
+	}
+}
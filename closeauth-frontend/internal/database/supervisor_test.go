@@ -0,0 +1,196 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"closeauth-frontend/internal/config"
+)
+
+// fakeDB returns a pool that never dials; Close is the only call made on it.
+func fakeDB(t *testing.T) *Database {
+	t.Helper()
+	db, err := sqlx.Open("postgres", "host=127.0.0.1 port=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Database{db}
+}
+
+type stateLog struct {
+	mu     sync.Mutex
+	states []State
+}
+
+func (l *stateLog) record(s State) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.states = append(l.states, s)
+}
+
+func (l *stateLog) snapshot() []State {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]State(nil), l.states...)
+}
+
+func newTestSupervisor(opts SupervisorOptions) *Supervisor {
+	cfg := &config.DatabaseConfig{PingInterval: 5 * time.Millisecond, ReconnectMaxBackoff: 5 * time.Millisecond}
+	s := NewSupervisor(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)), opts)
+	s.minBackoff = time.Millisecond
+	return s
+}
+
+func waitForState(t *testing.T, s *Supervisor, want State) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for s.Status().State != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("state = %q, want %q", s.Status().State, want)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSupervisor_ConnectsInBackground(t *testing.T) {
+	var states stateLog
+	s := newTestSupervisor(SupervisorOptions{OnStateChange: states.record})
+
+	var calls atomic.Int32
+	s.connect = func(context.Context, *config.DatabaseConfig) (*Database, error) {
+		if calls.Add(1) < 3 {
+			return nil, errors.New("connection refused")
+		}
+		return fakeDB(t), nil
+	}
+	s.ping = func(context.Context, *Database) error { return nil }
+
+	if err := s.Connect(context.Background()); err == nil {
+		t.Fatal("first Connect() succeeded, want error")
+	}
+	if s.DB() != nil {
+		t.Fatal("DB() is non-nil before connecting")
+	}
+	if got := s.Status(); got.State != StateConnecting || got.Attempts != 1 || got.LastError == "" {
+		t.Errorf("Status() = %+v, want connecting with one failed attempt", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	waitForState(t, s, StateConnected)
+	if s.DB() == nil {
+		t.Error("DB() is nil after connecting")
+	}
+	if got := states.snapshot(); len(got) != 1 || got[0] != StateConnected {
+		t.Errorf("state changes = %v, want [connected]", got)
+	}
+}
+
+func TestSupervisor_SurvivesOutage(t *testing.T) {
+	var states stateLog
+	s := newTestSupervisor(SupervisorOptions{OnStateChange: states.record})
+
+	var connects atomic.Int32
+	s.connect = func(context.Context, *config.DatabaseConfig) (*Database, error) {
+		connects.Add(1)
+		return fakeDB(t), nil
+	}
+	var down atomic.Bool
+	s.ping = func(context.Context, *Database) error {
+		if down.Load() {
+			return errors.New("connection reset")
+		}
+		return nil
+	}
+
+	if err := s.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	down.Store(true)
+	waitForState(t, s, StateDisconnected)
+	if s.DB() != nil {
+		t.Error("DB() is non-nil while disconnected")
+	}
+
+	down.Store(false)
+	waitForState(t, s, StateConnected)
+	if n := connects.Load(); n != 1 {
+		t.Errorf("connect called %d times, want 1 (the pool is reused after an outage)", n)
+	}
+	want := []State{StateConnected, StateDisconnected, StateConnected}
+	if got := states.snapshot(); len(got) != len(want) || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("state changes = %v, want %v", got, want)
+	}
+}
+
+func TestSupervisor_OnConnectErrorRejectsPool(t *testing.T) {
+	errBehind := errors.New("schema behind")
+	s := newTestSupervisor(SupervisorOptions{
+		OnConnect: func(context.Context, *Database) error { return errBehind },
+	})
+	s.connect = func(context.Context, *config.DatabaseConfig) (*Database, error) { return fakeDB(t), nil }
+
+	if err := s.Connect(context.Background()); !errors.Is(err, errBehind) {
+		t.Fatalf("Connect() error = %v, want %v", err, errBehind)
+	}
+	if s.DB() != nil || s.Status().State != StateConnecting {
+		t.Errorf("pool handed out after OnConnect failed: %+v", s.Status())
+	}
+}
+
+func TestSupervisor_NilIsDisconnected(t *testing.T) {
+	var s *Supervisor
+	if s.DB() != nil {
+		t.Error("nil Supervisor returned a database")
+	}
+	if got := s.Status().State; got != StateConnecting {
+		t.Errorf("nil Supervisor state = %q, want %q", got, StateConnecting)
+	}
+}
+
+func TestSupervisor_Close(t *testing.T) {
+	s := newTestSupervisor(SupervisorOptions{})
+	s.connect = func(context.Context, *config.DatabaseConfig) (*Database, error) { return fakeDB(t), nil }
+	s.ping = func(context.Context, *Database) error { return nil }
+
+	if err := s.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Run leaves the pool to Close, for requests still in flight
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+	cancel()
+	<-done
+	if s.DB() == nil {
+		t.Fatal("DB() is nil after Run returned, want the pool kept until Close")
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if s.DB() != nil {
+		t.Error("DB() is non-nil after Close")
+	}
+	if err := s.Connect(context.Background()); err == nil || s.DB() != nil {
+		t.Errorf("Connect() after Close = %v, want an error and no pool", err)
+	}
+}
@@ -0,0 +1,152 @@
+// Package health runs the dependency checks behind the readiness probe.
+//
+// Results are cached for a short TTL and concurrent probes share a single run,
+// so orchestrators polling /readyz do not translate into load on Spring.
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Status is the state of a single component or of the whole service.
+type Status string
+
+const (
+	StatusUp       Status = "up"
+	StatusDegraded Status = "degraded"
+	StatusDown     Status = "down"
+	StatusDisabled Status = "disabled"
+)
+
+// Result is what a Check reports.
+type Result struct {
+	Status  Status         `json:"status"`
+	Error   string         `json:"error,omitempty"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// Up returns a passing result with optional details.
+func Up(details map[string]any) Result {
+	return Result{Status: StatusUp, Details: details}
+}
+
+// Down returns a failing result for err.
+func Down(err error) Result {
+	return Result{Status: StatusDown, Error: err.Error()}
+}
+
+// Check is one named dependency check. A critical check that is down makes
+// the service not ready; a non-critical one only degrades it.
+type Check struct {
+	Name     string
+	Critical bool
+	Run      func(ctx context.Context) Result
+}
+
+// Component is a check result as it appears in a Report.
+type Component struct {
+	Result
+	Critical   bool  `json:"critical"`
+	DurationMs int64 `json:"duration_ms"`
+}
+
+// Report is the per-component readiness breakdown.
+type Report struct {
+	Status     Status               `json:"status"`
+	CheckedAt  time.Time            `json:"checked_at"`
+	Components map[string]Component `json:"components"`
+}
+
+// Ready reports whether every critical component is available.
+func (r *Report) Ready() bool {
+	return r.Status != StatusDown
+}
+
+// Checker runs checks and caches the aggregated report.
+type Checker struct {
+	checks  []Check
+	ttl     time.Duration
+	timeout time.Duration
+	now     func() time.Time
+
+	mu     sync.Mutex
+	cached *Report
+}
+
+// NewChecker creates a Checker. Each check gets at most timeout to complete and
+// a report is reused for ttl.
+func NewChecker(ttl, timeout time.Duration, checks ...Check) *Checker {
+	return &Checker{
+		checks:  checks,
+		ttl:     ttl,
+		timeout: timeout,
+		now:     time.Now,
+	}
+}
+
+// Report returns the cached report, re-running the checks when it has expired.
+// Callers arriving while a run is in progress wait for it instead of starting another.
+func (c *Checker) Report(ctx context.Context) *Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cached != nil && c.now().Sub(c.cached.CheckedAt) < c.ttl {
+		return c.cached
+	}
+
+	// Probes are cancelled when the client disconnects; the shared result
+	// must not be poisoned by that, so only the per-check timeout applies.
+	ctx = context.WithoutCancel(ctx)
+
+	components := make(map[string]Component, len(c.checks))
+	var wg sync.WaitGroup
+	var resultsMu sync.Mutex
+	for _, check := range c.checks {
+		wg.Add(1)
+		go func(check Check) {
+			defer wg.Done()
+			component := c.run(ctx, check)
+			resultsMu.Lock()
+			components[check.Name] = component
+			resultsMu.Unlock()
+		}(check)
+	}
+	wg.Wait()
+
+	c.cached = &Report{
+		Status:     aggregate(components),
+		CheckedAt:  c.now(),
+		Components: components,
+	}
+	return c.cached
+}
+
+func (c *Checker) run(ctx context.Context, check Check) Component {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	result := check.Run(ctx)
+	return Component{
+		Result:     result,
+		Critical:   check.Critical,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+}
+
+// aggregate is down when a critical component is down and degraded when any
+// other component is not up.
+func aggregate(components map[string]Component) Status {
+	status := StatusUp
+	for _, c := range components {
+		switch {
+		case c.Status == StatusDown && c.Critical:
+			return StatusDown
+		case c.Status == StatusDown || c.Status == StatusDegraded:
+			status = StatusDegraded
+		}
+	}
+	return status
+}
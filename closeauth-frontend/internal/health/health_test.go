@@ -0,0 +1,77 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func staticCheck(name string, critical bool, result Result) Check {
+	return Check{Name: name, Critical: critical, Run: func(context.Context) Result { return result }}
+}
+
+func TestChecker_Aggregate(t *testing.T) {
+	down := Down(errors.New("unreachable"))
+
+	tests := []struct {
+		name   string
+		checks []Check
+		want   Status
+	}{
+		{"all up", []Check{staticCheck("spring", true, Up(nil)), staticCheck("db", false, Up(nil))}, StatusUp},
+		{"disabled optional", []Check{staticCheck("spring", true, Up(nil)), staticCheck("db", false, Result{Status: StatusDisabled})}, StatusUp},
+		{"optional down", []Check{staticCheck("spring", true, Up(nil)), staticCheck("db", false, down)}, StatusDegraded},
+		{"degraded", []Check{staticCheck("discovery", false, Result{Status: StatusDegraded})}, StatusDegraded},
+		{"critical down", []Check{staticCheck("spring", true, down), staticCheck("db", false, Up(nil))}, StatusDown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := NewChecker(0, time.Second, tt.checks...).Report(context.Background())
+			if report.Status != tt.want {
+				t.Errorf("Status = %q, want %q", report.Status, tt.want)
+			}
+			if report.Ready() != (tt.want != StatusDown) {
+				t.Errorf("Ready() = %v for status %q", report.Ready(), tt.want)
+			}
+			if len(report.Components) != len(tt.checks) {
+				t.Errorf("got %d components, want %d", len(report.Components), len(tt.checks))
+			}
+		})
+	}
+}
+
+func TestChecker_CachesReport(t *testing.T) {
+	var runs atomic.Int32
+	checker := NewChecker(time.Minute, time.Second, Check{Name: "spring", Run: func(context.Context) Result {
+		runs.Add(1)
+		return Up(nil)
+	}})
+	now := time.Now()
+	checker.now = func() time.Time { return now }
+
+	checker.Report(context.Background())
+	checker.Report(context.Background())
+	if got := runs.Load(); got != 1 {
+		t.Fatalf("checks ran %d times within TTL, want 1", got)
+	}
+
+	now = now.Add(2 * time.Minute)
+	checker.Report(context.Background())
+	if got := runs.Load(); got != 2 {
+		t.Errorf("checks ran %d times after TTL, want 2", got)
+	}
+}
+
+func TestChecker_Timeout(t *testing.T) {
+	checker := NewChecker(0, 10*time.Millisecond, Check{Name: "slow", Critical: true, Run: func(ctx context.Context) Result {
+		<-ctx.Done()
+		return Down(ctx.Err())
+	}})
+
+	report := checker.Report(context.Background())
+	if report.Status != StatusDown || report.Components["slow"].Error == "" {
+		t.Errorf("slow check should time out and report down, got %+v", report.Components["slow"])
+	}
+}
@@ -0,0 +1,26 @@
+// Package httprec wraps http.ResponseWriter so middleware can see what the
+// handler wrote.
+package httprec
+
+import "net/http"
+
+// StatusRecorder captures the status code written by the wrapped handler.
+type StatusRecorder struct {
+	http.ResponseWriter
+	Status int
+}
+
+// NewStatusRecorder wraps w; Status is 200 until the handler writes another.
+func NewStatusRecorder(w http.ResponseWriter) *StatusRecorder {
+	return &StatusRecorder{ResponseWriter: w, Status: http.StatusOK}
+}
+
+func (r *StatusRecorder) WriteHeader(status int) {
+	r.Status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer (Flush, etc.).
+func (r *StatusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
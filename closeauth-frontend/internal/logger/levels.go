@@ -0,0 +1,318 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ──────────────────────────────────────────────────────────────────────────────
+// Per-component log levels
+//
+// A component is the value of the "component" or "handler" attribute a logger
+// carries (SpringClient uses component=spring_client, request handlers use
+// handler=<name>). An override for a component replaces the global level for
+// its records; the most recently attached name wins when a logger has several.
+// Overrides can revert automatically so a forgotten debug level does not stay
+// on in production.
+// ──────────────────────────────────────────────────────────────────────────────
+
+// componentKeys are the attribute keys that name a component.
+var componentKeys = map[string]bool{"component": true, "handler": true}
+
+// overrides is an immutable snapshot swapped atomically on every change so the
+// logging hot path never takes a lock.
+type overrides struct {
+	levels map[string]slog.Level
+	min    slog.Level // lowest override level, meaningful only when levels is non-empty
+}
+
+var (
+	current atomic.Pointer[overrides]
+
+	// mu serialises writers and guards pending.
+	mu sync.Mutex
+
+	// pending holds the revert timers, keyed by component ("" is the global level).
+	pending = map[string]*revert{}
+)
+
+type revert struct {
+	timer   *time.Timer
+	at      time.Time
+	level   slog.Level
+	present bool // whether the component had an override before
+}
+
+// ParseLevel parses debug, info, warn (or warning) and error, case-insensitively.
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, fmt.Errorf("unknown log level %q", name)
+}
+
+// LevelName formats a level the way ParseLevel accepts it.
+func LevelName(l slog.Level) string {
+	return strings.ToLower(l.String())
+}
+
+// SetLevelFor changes the global level. When revertAfter is positive the
+// previous level is restored once it elapses.
+func SetLevelFor(name string, revertAfter time.Duration) error {
+	l, err := ParseLevel(name)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	schedule("", level.Level(), true, revertAfter)
+	level.Set(l)
+	return nil
+}
+
+// SetComponentLevel overrides the level for one component. When revertAfter
+// is positive the previous state is restored once it elapses.
+func SetComponentLevel(component, name string, revertAfter time.Duration) error {
+	if component == "" {
+		return fmt.Errorf("component is required")
+	}
+	l, err := ParseLevel(name)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	prev, present := componentLevel(component)
+	schedule(component, prev, present, revertAfter)
+	storeOverride(component, l, true)
+	return nil
+}
+
+// ClearComponentLevel removes a component override and any pending revert.
+func ClearComponentLevel(component string) {
+	mu.Lock()
+	defer mu.Unlock()
+	cancelRevert(component)
+	storeOverride(component, 0, false)
+}
+
+// SetComponentLevels replaces every component override, cancelling pending
+// reverts. Used at startup and on configuration reload.
+func SetComponentLevels(levels map[string]string) error {
+	parsed := make(map[string]slog.Level, len(levels))
+	for component, name := range levels {
+		l, err := ParseLevel(name)
+		if err != nil {
+			return fmt.Errorf("component %q: %w", component, err)
+		}
+		parsed[component] = l
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for component := range pending {
+		if component != "" {
+			cancelRevert(component)
+		}
+	}
+	publish(parsed)
+	return nil
+}
+
+// LevelState describes the effective levels and when temporary ones revert.
+type LevelState struct {
+	Global     string            `json:"global"`
+	Components map[string]string `json:"components"`
+
+	// Reverts maps "global" or a component name to when its override expires
+	Reverts map[string]time.Time `json:"reverts,omitempty"`
+}
+
+// Levels returns the current global level and component overrides.
+func Levels() LevelState {
+	mu.Lock()
+	defer mu.Unlock()
+
+	state := LevelState{
+		Global:     LevelName(level.Level()),
+		Components: map[string]string{},
+	}
+	if o := current.Load(); o != nil {
+		for component, l := range o.levels {
+			state.Components[component] = LevelName(l)
+		}
+	}
+	for component, r := range pending {
+		if state.Reverts == nil {
+			state.Reverts = map[string]time.Time{}
+		}
+		if component == "" {
+			component = "global"
+		}
+		state.Reverts[component] = r.at
+	}
+	return state
+}
+
+// schedule arms (or, for revertAfter <= 0, cancels) the revert for key. An
+// override replacing a pending one keeps the original state to revert to.
+// Must be called with mu held.
+func schedule(key string, prev slog.Level, present bool, revertAfter time.Duration) {
+	r, ok := pending[key]
+	if ok {
+		r.timer.Stop()
+		prev, present = r.level, r.present
+		delete(pending, key)
+	}
+	if revertAfter <= 0 {
+		return
+	}
+
+	r = &revert{at: time.Now().Add(revertAfter), level: prev, present: present}
+	r.timer = time.AfterFunc(revertAfter, func() {
+		mu.Lock()
+		if pending[key] != r {
+			mu.Unlock()
+			return // superseded or cancelled
+		}
+		delete(pending, key)
+		if key == "" {
+			level.Set(r.level)
+		} else {
+			storeOverride(key, r.level, r.present)
+		}
+		mu.Unlock()
+
+		if key == "" {
+			slog.Info("temporary log level reverted", "level", LevelName(r.level))
+		} else {
+			slog.Info("temporary component log level reverted", "target", key, "override_removed", !r.present)
+		}
+	})
+	pending[key] = r
+}
+
+// cancelRevert must be called with mu held.
+func cancelRevert(key string) {
+	if r, ok := pending[key]; ok {
+		r.timer.Stop()
+		delete(pending, key)
+	}
+}
+
+func componentLevel(component string) (slog.Level, bool) {
+	if o := current.Load(); o != nil {
+		l, ok := o.levels[component]
+		return l, ok
+	}
+	return 0, false
+}
+
+// storeOverride must be called with mu held.
+func storeOverride(component string, l slog.Level, present bool) {
+	levels := map[string]slog.Level{}
+	if o := current.Load(); o != nil {
+		levels = maps.Clone(o.levels)
+	}
+	if present {
+		levels[component] = l
+	} else {
+		delete(levels, component)
+	}
+	publish(levels)
+}
+
+func publish(levels map[string]slog.Level) {
+	o := &overrides{levels: levels}
+	first := true
+	for _, l := range levels {
+		if first || l < o.min {
+			o.min, first = l, false
+		}
+	}
+	current.Store(o)
+}
+
+// componentHandler gates records on the global level or the override for the
+// component the logger (or, failing that, the record) names.
+type componentHandler struct {
+	next  slog.Handler
+	names []string // component/handler values attached via With, oldest first
+}
+
+func newComponentHandler(next slog.Handler) *componentHandler {
+	return &componentHandler{next: next}
+}
+
+// minLevel is the level below which nothing from this handler can be logged.
+// When the logger itself is not named, a record may still name a component in
+// its own attributes, so the lowest override is let through to Handle.
+func (h *componentHandler) minLevel() (slog.Level, bool) {
+	o := current.Load()
+	if o == nil || len(o.levels) == 0 {
+		return level.Level(), true
+	}
+	for i := len(h.names) - 1; i >= 0; i-- {
+		if l, ok := o.levels[h.names[i]]; ok {
+			return l, true
+		}
+	}
+	return min(level.Level(), o.min), false
+}
+
+func (h *componentHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	threshold, _ := h.minLevel()
+	return l >= threshold && h.next.Enabled(ctx, l)
+}
+
+func (h *componentHandler) Handle(ctx context.Context, record slog.Record) error {
+	if _, exact := h.minLevel(); !exact && record.Level < recordLevel(record) {
+		return nil
+	}
+	return h.next.Handle(ctx, record)
+}
+
+// recordLevel is the threshold for a record from an unnamed logger: the
+// override for a component named in its attributes, else the global level.
+func recordLevel(record slog.Record) slog.Level {
+	threshold := level.Level()
+	record.Attrs(func(a slog.Attr) bool {
+		if componentKeys[a.Key] {
+			if l, ok := componentLevel(a.Value.String()); ok {
+				threshold = l
+				return false
+			}
+		}
+		return true
+	})
+	return threshold
+}
+
+func (h *componentHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	names := h.names
+	for _, a := range attrs {
+		if componentKeys[a.Key] {
+			names = append(names[:len(names):len(names)], a.Value.String())
+		}
+	}
+	return &componentHandler{next: h.next.WithAttrs(attrs), names: names}
+}
+
+func (h *componentHandler) WithGroup(name string) slog.Handler {
+	return &componentHandler{next: h.next.WithGroup(name), names: h.names}
+}
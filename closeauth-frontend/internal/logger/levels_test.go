@@ -0,0 +1,147 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newLevelTestLogger(buf *bytes.Buffer) *slog.Logger {
+	t := slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+	return slog.New(newComponentHandler(t))
+}
+
+func resetLevels(t *testing.T) {
+	t.Cleanup(func() {
+		SetLevel("info")
+		SetComponentLevels(nil)
+	})
+}
+
+func TestComponentHandler_Overrides(t *testing.T) {
+	resetLevels(t)
+	SetLevel("info")
+	if err := SetComponentLevels(map[string]string{"spring_client": "debug", "audit": "error"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		log  func(l *slog.Logger)
+		want bool
+	}{
+		{"global debug dropped", func(l *slog.Logger) { l.Debug("msg") }, false},
+		{"global info kept", func(l *slog.Logger) { l.Info("msg") }, true},
+		{"component debug kept", func(l *slog.Logger) { l.With("component", "spring_client").Debug("msg") }, true},
+		{"component raised", func(l *slog.Logger) { l.With("component", "audit").Warn("msg") }, false},
+		{"other component uses global", func(l *slog.Logger) { l.With("component", "token_manager").Debug("msg") }, false},
+		{"latest name wins", func(l *slog.Logger) {
+			l.With("component", "spring_client").With("handler", "audit").Warn("msg")
+		}, false},
+		{"handler attribute", func(l *slog.Logger) { l.With("handler", "spring_client").Debug("msg") }, true},
+		{"record attribute", func(l *slog.Logger) { l.Debug("msg", "component", "spring_client") }, true},
+		{"record attribute other", func(l *slog.Logger) { l.Debug("msg", "component", "token_manager") }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tt.log(newLevelTestLogger(&buf))
+			if got := buf.Len() > 0; got != tt.want {
+				t.Errorf("logged = %v, want %v (%s)", got, tt.want, strings.TrimSpace(buf.String()))
+			}
+		})
+	}
+}
+
+func TestComponentHandler_NoOverridesUsesGlobal(t *testing.T) {
+	resetLevels(t)
+	SetLevel("warn")
+
+	var buf bytes.Buffer
+	l := newLevelTestLogger(&buf)
+	if l.Enabled(context.Background(), slog.LevelInfo) {
+		t.Error("info enabled with global level warn")
+	}
+	l.Warn("msg")
+	if buf.Len() == 0 {
+		t.Error("warn record dropped")
+	}
+}
+
+func TestSetComponentLevel_Reverts(t *testing.T) {
+	resetLevels(t)
+	if err := SetComponentLevel("token_manager", "warn", 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetComponentLevel("token_manager", "debug", 20*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	// A second temporary change keeps the original level to revert to
+	if err := SetComponentLevel("token_manager", "error", 20*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if got := Levels(); got.Components["token_manager"] != "error" || got.Reverts["token_manager"].IsZero() {
+		t.Fatalf("Levels() = %+v, want token_manager=error with a revert", got)
+	}
+
+	waitFor(t, func() bool { return Levels().Components["token_manager"] == "warn" })
+	if got := Levels(); len(got.Reverts) != 0 {
+		t.Errorf("Reverts = %v after revert, want none", got.Reverts)
+	}
+}
+
+func TestSetComponentLevel_RevertRemovesNewOverride(t *testing.T) {
+	resetLevels(t)
+	if err := SetComponentLevel("spring_client", "debug", 10*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { _, ok := Levels().Components["spring_client"]; return !ok })
+}
+
+func TestSetLevelFor_Reverts(t *testing.T) {
+	resetLevels(t)
+	SetLevel("warn")
+	if err := SetLevelFor("debug", 10*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if got := Levels().Global; got != "debug" {
+		t.Fatalf("Global = %q, want debug", got)
+	}
+	waitFor(t, func() bool { return Levels().Global == "warn" })
+}
+
+func TestSetLevel_CancelsRevert(t *testing.T) {
+	resetLevels(t)
+	SetLevel("warn")
+	SetLevelFor("debug", 10*time.Millisecond)
+	SetLevel("error")
+
+	time.Sleep(30 * time.Millisecond)
+	if got := Levels().Global; got != "error" {
+		t.Errorf("Global = %q, want error (revert should have been cancelled)", got)
+	}
+}
+
+func TestSetComponentLevels_Invalid(t *testing.T) {
+	resetLevels(t)
+	if err := SetComponentLevels(map[string]string{"spring_client": "loud"}); err == nil {
+		t.Error("SetComponentLevels() accepted an unknown level")
+	}
+	if err := SetComponentLevel("", "debug", 0); err == nil {
+		t.Error("SetComponentLevel() accepted an empty component")
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("condition not met; levels = %+v", Levels())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
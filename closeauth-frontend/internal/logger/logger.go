@@ -8,27 +8,21 @@ import (
 	"strings"
 )
 
-// Init initializes the slog logger with clean, readable output.
-// Call this before any other initialization in main().
-func Init(level, format string) {
-	var logLevel slog.Level
+// level is the global minimum level. componentHandler reads it on every
+// record so SetLevel takes effect immediately, including for loggers derived
+// with With(); per-component overrides live in levels.go.
+var level slog.LevelVar
 
-	switch strings.ToLower(level) {
-	case "debug":
-		logLevel = slog.LevelDebug
-	case "info":
-		logLevel = slog.LevelInfo
-	case "warn", "warning":
-		logLevel = slog.LevelWarn
-	case "error":
-		logLevel = slog.LevelError
-	default:
-		logLevel = slog.LevelInfo
-		slog.Warn("Invalid log level, defaulting to INFO", "provided", level)
+// Init initializes the slog logger with clean, readable output. Every record
+// passes through a RedactingHandler so credentials never reach the output.
+// Call this before any other initialization in main().
+func Init(logLevel, format string, redactOpts RedactOptions) {
+	if err := SetLevel(logLevel); err != nil {
+		slog.Warn("Invalid log level, defaulting to INFO", "provided", logLevel)
 	}
 
 	opts := &slog.HandlerOptions{
-		Level:     logLevel,
+		Level:     slog.LevelDebug, // gated by componentHandler
 		AddSource: true,
 		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
 			if a.Key == slog.TimeKey {
@@ -56,7 +50,20 @@ func Init(level, format string) {
 		handler = slog.NewTextHandler(os.Stdout, opts)
 	}
 
-	slog.SetDefault(slog.New(handler))
+	slog.SetDefault(slog.New(newComponentHandler(NewRedactingHandler(traceHandler{handler}, redactOpts))))
+}
+
+// SetLevel changes the global minimum level at runtime and cancels any
+// pending revert from SetLevelFor. Unknown levels leave INFO in place and
+// return an error.
+func SetLevel(name string) error {
+	l, err := ParseLevel(name)
+
+	mu.Lock()
+	defer mu.Unlock()
+	cancelRevert("")
+	level.Set(l)
+	return err
 }
 
 // shortenFilePath returns the last 2 path components (e.g. "server/routes.go")
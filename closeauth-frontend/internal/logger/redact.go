@@ -0,0 +1,161 @@
+package logger
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"closeauth-frontend/internal/redact"
+)
+
+// RedactOptions configures the redacting handler installed by Init.
+type RedactOptions struct {
+	// ExtraKeys are masked in addition to the built-in sensitive keys
+	ExtraKeys []string
+
+	// HashEmails replaces email addresses with "email:<hash>" so log lines for
+	// the same user can still be correlated without storing the address
+	HashEmails bool
+}
+
+// RedactingHandler masks sensitive attribute values before they reach the
+// wrapped handler: values under sensitive keys (at any group depth or inside
+// structs/maps), credentials embedded in strings and, optionally, emails.
+type RedactingHandler struct {
+	next       slog.Handler
+	matcher    *redact.Matcher
+	hashEmails bool
+}
+
+// NewRedactingHandler wraps next with redaction.
+func NewRedactingHandler(next slog.Handler, opts RedactOptions) *RedactingHandler {
+	return &RedactingHandler{
+		next:       next,
+		matcher:    redact.NewMatcher(opts.ExtraKeys...),
+		hashEmails: opts.HashEmails,
+	}
+}
+
+func (h *RedactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *RedactingHandler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, h.redactString(record.Message), record.PC)
+	record.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(h.redactAttr(a))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+func (h *RedactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = h.redactAttr(a)
+	}
+	return &RedactingHandler{next: h.next.WithAttrs(redacted), matcher: h.matcher, hashEmails: h.hashEmails}
+}
+
+func (h *RedactingHandler) WithGroup(name string) slog.Handler {
+	return &RedactingHandler{next: h.next.WithGroup(name), matcher: h.matcher, hashEmails: h.hashEmails}
+}
+
+func (h *RedactingHandler) redactAttr(a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+
+	if h.matcher.IsSensitiveKey(a.Key) {
+		return slog.String(a.Key, redact.Placeholder)
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindGroup:
+		group := a.Value.Group()
+		redacted := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			redacted[i] = h.redactAttr(ga)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(redacted...)}
+	case slog.KindString:
+		return slog.String(a.Key, h.redactString(a.Value.String()))
+	case slog.KindAny:
+		return slog.Any(a.Key, h.redactAny(a.Value.Any()))
+	default:
+		return a
+	}
+}
+
+// redactAny masks composite values by walking their JSON form, so struct
+// fields such as Password are caught whatever their Go type.
+func (h *RedactingHandler) redactAny(v any) any {
+	switch t := v.(type) {
+	case nil:
+		return nil
+	case error:
+		return h.redactString(t.Error())
+	case fmt.Stringer:
+		return h.redactString(t.String())
+	case []byte:
+		return h.redactString(string(t))
+	}
+
+	switch reflect.Indirect(reflect.ValueOf(v)).Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+	default:
+		return h.redactString(fmt.Sprint(v))
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("<unloggable %T>", v)
+	}
+	var decoded any
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return fmt.Sprintf("<unloggable %T>", v)
+	}
+	return h.redactDecoded(h.matcher.Value(decoded))
+}
+
+// redactDecoded applies email hashing to values already passed through the matcher.
+func (h *RedactingHandler) redactDecoded(v any) any {
+	if !h.hashEmails {
+		return v
+	}
+	switch t := v.(type) {
+	case map[string]any:
+		for k, val := range t {
+			t[k] = h.redactDecoded(val)
+		}
+	case []any:
+		for i, val := range t {
+			t[i] = h.redactDecoded(val)
+		}
+	case string:
+		return hashEmails(t)
+	}
+	return v
+}
+
+func (h *RedactingHandler) redactString(s string) string {
+	s = h.matcher.String(s)
+	if h.hashEmails {
+		s = hashEmails(s)
+	}
+	return s
+}
+
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+
+// hashEmails replaces each email address with a short, stable, case-insensitive hash.
+func hashEmails(s string) string {
+	return emailPattern.ReplaceAllStringFunc(s, func(email string) string {
+		sum := sha256.Sum256([]byte(strings.ToLower(email)))
+		return "email:" + hex.EncodeToString(sum[:6])
+	})
+}
@@ -0,0 +1,30 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+
+	"closeauth-frontend/internal/tracing"
+)
+
+// traceHandler adds trace_id and span_id to records logged with a context
+// that carries an active span (slog.InfoContext and friends).
+type traceHandler struct {
+	slog.Handler
+}
+
+func (h traceHandler) Handle(ctx context.Context, record slog.Record) error {
+	if attrs := tracing.LogAttrs(ctx); attrs != nil {
+		record = record.Clone()
+		record.AddAttrs(attrs...)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h traceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return traceHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h traceHandler) WithGroup(name string) slog.Handler {
+	return traceHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,213 @@
+// Package metrics exposes the BFF's Prometheus metrics.
+//
+// Collectors are package-level so any layer can record without threading a
+// registry through constructors; only this package talks to Prometheus.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"closeauth-frontend/internal/httprec"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "closeauth_bff"
+
+// OAuth flow events recorded by OAuthEvent.
+const (
+	OAuthLoginSuccess   = "login_success"
+	OAuthLoginFailure   = "login_failure"
+	OAuthConsentApprove = "consent_approve"
+	OAuthConsentDeny    = "consent_deny"
+	OAuthContextExpired = "context_expired"
+)
+
+// Registry holds every BFF collector plus the Go runtime and process collectors.
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests served, by route pattern and status.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency, by route pattern.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	springRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "spring_requests_total",
+		Help:      "Requests to the Spring Authorization Server, by endpoint and status (\"error\" for transport failures).",
+	}, []string{"endpoint", "method", "status"})
+
+	springDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "spring_request_duration_seconds",
+		Help:      "Spring Authorization Server latency, by endpoint.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"endpoint", "method"})
+
+	springErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "spring_errors_total",
+		Help:      "Spring requests that failed in transport or returned 5xx.",
+	}, []string{"endpoint", "kind"})
+
+	tokenRefreshes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "token_refreshes_total",
+		Help:      "client_credentials token fetches by TokenManager, by result.",
+	}, []string{"result"})
+
+	oauthEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "oauth_flow_events_total",
+		Help:      "OAuth flow outcomes (login, consent, expired context).",
+	}, []string{"event"})
+
+	csrfRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "csrf_rejections_total",
+		Help:      "Requests rejected by CSRF validation, by reason.",
+	}, []string{"reason"})
+
+	rateLimitHits = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limit_hits_total",
+		Help:      "Requests rejected by a rate limiter, by limiter (\"spring\" for upstream 429s).",
+	}, []string{"limiter"})
+
+	dbConnected = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "database_connected",
+		Help:      "1 while the database is connected, 0 while it is unavailable.",
+	})
+
+	dbTransitions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "database_state_transitions_total",
+		Help:      "Database supervisor state changes, by new state.",
+	}, []string{"state"})
+
+	dbConnectAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "database_connect_attempts_total",
+		Help:      "Database connection attempts by the supervisor, by result.",
+	}, []string{"result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests, httpDuration,
+		springRequests, springDuration, springErrors,
+		tokenRefreshes, oauthEvents, csrfRejections, rateLimitHits,
+		dbConnected, dbTransitions, dbConnectAttempts,
+	)
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// RegisterDB exports sql.DBStats (open/idle/in-use connections, waits) for db.
+func RegisterDB(db *sql.DB, name string) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// DatabaseState records a database supervisor state change.
+func DatabaseState(state string, connected bool) {
+	dbTransitions.WithLabelValues(state).Inc()
+	if connected {
+		dbConnected.Set(1)
+	} else {
+		dbConnected.Set(0)
+	}
+}
+
+// DatabaseConnectAttempt records one supervisor connection attempt.
+func DatabaseConnectAttempt(err error) {
+	if err != nil {
+		dbConnectAttempts.WithLabelValues("failure").Inc()
+		return
+	}
+	dbConnectAttempts.WithLabelValues("success").Inc()
+}
+
+// HTTPMiddleware records request count and latency by chi route pattern.
+// Requests that match no route are grouped under "unmatched" to bound cardinality.
+func HTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := httprec.NewStatusRecorder(w)
+
+		next.ServeHTTP(rec, r)
+
+		route := "unmatched"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil {
+			if pattern := rctx.RoutePattern(); pattern != "" {
+				route = pattern
+			}
+		}
+
+		httpRequests.WithLabelValues(r.Method, route, strconv.Itoa(rec.Status)).Inc()
+		httpDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+	})
+}
+
+// ObserveSpringRequest records one call to Spring. err is a transport error, if any.
+func ObserveSpringRequest(endpoint, method string, status int, err error, duration time.Duration) {
+	springDuration.WithLabelValues(endpoint, method).Observe(duration.Seconds())
+
+	if err != nil {
+		springRequests.WithLabelValues(endpoint, method, "error").Inc()
+		springErrors.WithLabelValues(endpoint, "transport").Inc()
+		return
+	}
+
+	springRequests.WithLabelValues(endpoint, method, strconv.Itoa(status)).Inc()
+	if status >= 500 {
+		springErrors.WithLabelValues(endpoint, "server_error").Inc()
+	}
+	if status == http.StatusTooManyRequests {
+		RateLimitHit("spring")
+	}
+}
+
+// TokenRefresh records a TokenManager token fetch.
+func TokenRefresh(err error) {
+	if err != nil {
+		tokenRefreshes.WithLabelValues("failure").Inc()
+		return
+	}
+	tokenRefreshes.WithLabelValues("success").Inc()
+}
+
+// OAuthEvent records an OAuth flow outcome (one of the OAuth* constants).
+func OAuthEvent(event string) {
+	oauthEvents.WithLabelValues(event).Inc()
+}
+
+// CSRFRejected records a CSRF validation failure.
+func CSRFRejected(reason string) {
+	csrfRejections.WithLabelValues(reason).Inc()
+}
+
+// RateLimitHit records a request rejected by the named limiter.
+func RateLimitHit(limiter string) {
+	rateLimitHits.WithLabelValues(limiter).Inc()
+}
@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestHTTPMiddleware_LabelsByRoutePattern(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(HTTPMiddleware)
+	r.Get("/api/admin/clients/{clientId}/roles", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	for _, id := range []string{"1", "2", "3"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/admin/clients/"+id+"/roles", nil))
+	}
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/nope", nil))
+
+	if got := testutil.ToFloat64(httpRequests.WithLabelValues("GET", "/api/admin/clients/{clientId}/roles", "201")); got != 3 {
+		t.Errorf("requests for route pattern = %v, want 3", got)
+	}
+	if got := testutil.ToFloat64(httpRequests.WithLabelValues("GET", "unmatched", "404")); got != 1 {
+		t.Errorf("unmatched requests = %v, want 1", got)
+	}
+}
+
+func TestObserveSpringRequest(t *testing.T) {
+	ObserveSpringRequest("/oauth2/token", "POST", 0, errors.New("dial tcp: refused"), time.Millisecond)
+	ObserveSpringRequest("/oauth2/token", "POST", 503, nil, time.Millisecond)
+	ObserveSpringRequest("/login", "POST", 429, nil, time.Millisecond)
+
+	if got := testutil.ToFloat64(springErrors.WithLabelValues("/oauth2/token", "transport")); got != 1 {
+		t.Errorf("transport errors = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(springErrors.WithLabelValues("/oauth2/token", "server_error")); got != 1 {
+		t.Errorf("server errors = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(rateLimitHits.WithLabelValues("spring")); got != 1 {
+		t.Errorf("rate limit hits = %v, want 1", got)
+	}
+}
+
+func TestHandler_ExposesCollectors(t *testing.T) {
+	TokenRefresh(nil)
+	OAuthEvent(OAuthLoginSuccess)
+	CSRFRejected("missing")
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := rec.Body.String()
+	for _, want := range []string{
+		`closeauth_bff_token_refreshes_total{result="success"}`,
+		`closeauth_bff_oauth_flow_events_total{event="login_success"}`,
+		`closeauth_bff_csrf_rejections_total{reason="missing"}`,
+		"go_goroutines",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics output missing %s", want)
+		}
+	}
+}
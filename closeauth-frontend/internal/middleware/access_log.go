@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"closeauth-frontend/internal/requestid"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// AccessLog returns middleware that writes one structured slog line per
+// request. The session is identified only by a hash of its id, never by the
+// cookie value. 5xx responses log at ERROR and 4xx at WARN.
+func AccessLog(logger *slog.Logger) func(http.Handler) http.Handler {
+	logger = logger.With("component", "access_log")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := &accessLogRecorder{ResponseWriter: w, status: http.StatusOK}
+
+			next.ServeHTTP(rec, r)
+
+			route := ""
+			if rctx := chi.RouteContext(r.Context()); rctx != nil {
+				route = rctx.RoutePattern()
+			}
+
+			attrs := []slog.Attr{
+				slog.String("request_id", requestid.FromContext(r.Context())),
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.String("route", route),
+				slog.Int("status", rec.status),
+				slog.Int64("bytes", rec.bytes),
+				slog.Int64("duration_ms", time.Since(start).Milliseconds()),
+				slog.String("remote_ip", remoteHost(r.RemoteAddr)),
+			}
+			if clientID := accessLogClientID(r); clientID != "" {
+				attrs = append(attrs, slog.String("client_id", clientID))
+			}
+			if session, err := GetSession(r); err == nil && session.SessionID != "" {
+				attrs = append(attrs, slog.String("session", HashSessionID(session.SessionID)))
+			}
+
+			level := slog.LevelInfo
+			switch {
+			case rec.status >= 500:
+				level = slog.LevelError
+			case rec.status >= 400:
+				level = slog.LevelWarn
+			}
+			logger.LogAttrs(r.Context(), level, "request completed", attrs...)
+		})
+	}
+}
+
+// HashSessionID returns a short, non-reversible identifier for a session so
+// log lines can be grouped per session without exposing the id itself.
+func HashSessionID(sessionID string) string {
+	sum := sha256.Sum256([]byte(sessionID))
+	return hex.EncodeToString(sum[:8])
+}
+
+// accessLogClientID resolves the OAuth/admin client a request concerns: the
+// {clientId} route parameter, a client_id query parameter or the OAuth flow cookie.
+func accessLogClientID(r *http.Request) string {
+	if id := chi.URLParam(r, "clientId"); id != "" {
+		return id
+	}
+	if id := r.URL.Query().Get("client_id"); id != "" {
+		return id
+	}
+	if ctx, err := GetOAuthContext(r); err == nil {
+		return ctx.ClientID
+	}
+	return ""
+}
+
+func remoteHost(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
+// accessLogRecorder captures status and body size.
+type accessLogRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (r *accessLogRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *accessLogRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (r *accessLogRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"closeauth-frontend/internal/requestid"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	r := chi.NewRouter()
+	r.Use(requestid.Middleware)
+	r.Use(AccessLog(logger))
+	r.Get("/api/admin/clients/{clientId}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("missing"))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/clients/7", nil)
+	req.Header.Set(requestid.Header, "abc-123")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("access log is not a single JSON line: %v\n%s", err, buf.String())
+	}
+
+	want := map[string]any{
+		"level":      "WARN",
+		"request_id": "abc-123",
+		"route":      "/api/admin/clients/{clientId}",
+		"status":     float64(http.StatusNotFound),
+		"bytes":      float64(len("missing")),
+		"client_id":  "7",
+	}
+	for key, value := range want {
+		if entry[key] != value {
+			t.Errorf("%s = %v, want %v", key, entry[key], value)
+		}
+	}
+	if _, ok := entry["duration_ms"]; !ok {
+		t.Error("duration_ms missing")
+	}
+}
+
+func TestHashSessionID(t *testing.T) {
+	a, b := HashSessionID("session-a"), HashSessionID("session-b")
+	if a == b {
+		t.Error("different sessions should hash differently")
+	}
+	if a != HashSessionID("session-a") {
+		t.Error("hash should be stable")
+	}
+	if len(a) != 16 || a == "session-a" {
+		t.Errorf("HashSessionID = %q, want 16 hex chars", a)
+	}
+}
@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Admin API permissions, checked per route by RequirePermission.
+const (
+	PermClientsRead          = "clients:read"
+	PermClientsWrite         = "clients:write"
+	PermThemesWrite          = "themes:write"
+	PermRegistrationsRead    = "registrations:read"
+	PermRegistrationsApprove = "registrations:approve"
+	PermAuditRead            = "audit:read"
+
+	// PermAll grants every permission (SUPER_ADMIN).
+	PermAll = "*"
+)
+
+// rolePermissions maps Spring's GlobalRoleEnum names to BFF permissions.
+// Roles not listed (e.g. END_USER) grant nothing.
+var rolePermissions = map[string][]string{
+	"SUPER_ADMIN": {PermAll},
+	"CLIENT_ADMIN": {
+		PermClientsRead,
+		PermClientsWrite,
+		PermThemesWrite,
+		PermRegistrationsRead,
+		PermRegistrationsApprove,
+		PermAuditRead,
+	},
+}
+
+// rolePriority orders roles for display when a user holds several.
+var rolePriority = []string{"SUPER_ADMIN", "CLIENT_ADMIN"}
+
+// ResolvePermissions merges explicitly granted permissions with those implied by
+// roles. The result is sorted and de-duplicated so it can be compared/fingerprinted.
+func ResolvePermissions(roles, explicit []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	add := func(p string) {
+		if p != "" && !seen[p] {
+			seen[p] = true
+			result = append(result, p)
+		}
+	}
+
+	for _, role := range roles {
+		for _, p := range rolePermissions[strings.ToUpper(strings.TrimPrefix(role, "ROLE_"))] {
+			add(p)
+		}
+	}
+	for _, p := range explicit {
+		add(p)
+	}
+
+	sort.Strings(result)
+	return result
+}
+
+// PrimaryRole returns the most privileged known role, or the first role if none is known.
+func PrimaryRole(roles []string) string {
+	for _, known := range rolePriority {
+		for _, role := range roles {
+			if strings.EqualFold(strings.TrimPrefix(role, "ROLE_"), known) {
+				return known
+			}
+		}
+	}
+	if len(roles) > 0 {
+		return roles[0]
+	}
+	return ""
+}
+
+// HasPermission reports whether the session grants the permission, either
+// exactly, via a resource wildcard ("themes:*") or via PermAll.
+func (s *Session) HasPermission(permission string) bool {
+	resource, _, _ := strings.Cut(permission, ":")
+	for _, p := range s.Permissions {
+		if p == permission || p == PermAll || p == resource+":*" {
+			return true
+		}
+	}
+	return false
+}
+
+// RequirePermission returns middleware that rejects sessions lacking the
+// permission with 403 and names the missing permission. Must run after RequireAuth.
+func RequirePermission(permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			session, err := GetSession(r)
+			if err != nil {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusUnauthorized)
+				json.NewEncoder(w).Encode(map[string]string{"error": "unauthorized"})
+				return
+			}
+
+			if !session.HasPermission(permission) {
+				slog.WarnContext(r.Context(), "permission denied",
+					"component", "authz",
+					"user_id", session.UserID,
+					"role", session.Role,
+					"permission", permission,
+					"method", r.Method,
+					"path", r.URL.Path,
+				)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusForbidden)
+				json.NewEncoder(w).Encode(map[string]string{
+					"error":              "forbidden",
+					"missing_permission": permission,
+				})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestResolvePermissions(t *testing.T) {
+	tests := []struct {
+		name     string
+		roles    []string
+		explicit []string
+		want     []string
+	}{
+		{"super admin", []string{"SUPER_ADMIN"}, nil, []string{PermAll}},
+		{"client admin with ROLE_ prefix", []string{"ROLE_CLIENT_ADMIN"}, nil, []string{
+			PermAuditRead, PermClientsRead, PermClientsWrite, PermRegistrationsApprove, PermRegistrationsRead, PermThemesWrite,
+		}},
+		{"end user", []string{"END_USER"}, nil, nil},
+		{"explicit grants merged and deduplicated", []string{"END_USER"}, []string{PermThemesWrite, PermClientsRead, PermThemesWrite}, []string{
+			PermClientsRead, PermThemesWrite,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ResolvePermissions(tt.roles, tt.explicit); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ResolvePermissions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSession_HasPermission(t *testing.T) {
+	session := &Session{Permissions: []string{PermClientsRead, "themes:*"}}
+
+	for perm, want := range map[string]bool{
+		PermClientsRead:          true,
+		PermThemesWrite:          true,
+		PermClientsWrite:         false,
+		PermRegistrationsApprove: false,
+	} {
+		if got := session.HasPermission(perm); got != want {
+			t.Errorf("HasPermission(%q) = %v, want %v", perm, got, want)
+		}
+	}
+
+	if !(&Session{Permissions: []string{PermAll}}).HasPermission(PermRegistrationsApprove) {
+		t.Error("PermAll should grant every permission")
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	handler := RequirePermission(PermThemesWrite)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	serve := func(permissions []string) *httptest.ResponseRecorder {
+		session := &Session{
+			SessionID:   "sid",
+			Email:       "admin@example.com",
+			Permissions: permissions,
+			ExpiresAt:   time.Now().Add(time.Hour).Unix(),
+		}
+		data, _ := json.Marshal(session)
+		encrypted, err := Encrypt(data, GetEncryptionKey())
+		if err != nil {
+			t.Fatalf("Encrypt() error = %v", err)
+		}
+
+		req := httptest.NewRequest(http.MethodPut, "/api/admin/clients/c1/themes/1", nil)
+		req.AddCookie(&http.Cookie{Name: SessionCookieName, Value: base64.StdEncoding.EncodeToString(encrypted)})
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := serve([]string{PermThemesWrite}); rec.Code != http.StatusNoContent {
+		t.Errorf("with permission: status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+
+	rec := serve([]string{PermClientsRead})
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("without permission: status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+	var body map[string]string
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body["missing_permission"] != PermThemesWrite {
+		t.Errorf("missing_permission = %q, want %q", body["missing_permission"], PermThemesWrite)
+	}
+}
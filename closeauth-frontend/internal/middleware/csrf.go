@@ -1,11 +1,20 @@
 package middleware
 
 import (
+	"context"
+	"crypto/hmac"
 	"crypto/rand"
-	"crypto/subtle"
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"closeauth-frontend/internal/metrics"
 )
 
 const (
@@ -13,127 +22,336 @@ const (
 	CSRFCookieName  = "csrf_token"
 	CSRFHeaderName  = "X-CSRF-Token"
 	CSRFFormField   = "csrf_token"
+
+	// PreSessionCookieName holds a random browser id that anonymous CSRF tokens
+	// are bound to before any session or OAuth flow exists (login CSRF defence).
+	PreSessionCookieName = "bff_presession"
 )
 
-// CSRFTokenMiddleware generates a CSRF token and sets it as an httpOnly cookie
-// on every request. The token is available server-side for validation.
-func CSRFTokenMiddleware(isProduction bool) func(http.Handler) http.Handler {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Check if token already exists in cookie
-			if _, err := r.Cookie(CSRFCookieName); err != nil {
-				// Generate new token
-				token, err := generateCSRFToken()
-				if err != nil {
-					http.Error(w, "Internal server error", http.StatusInternalServerError)
-					return
-				}
+// CSRF token binding scopes, strongest first in resolution order.
+const (
+	csrfScopeFlow       = "f" // OAuth authorization flow (oauth_context.flow_id)
+	csrfScopeSession    = "s" // Admin session (bff_session.sid + role)
+	csrfScopePreSession = "p" // Anonymous browser (bff_presession)
+)
 
-				http.SetCookie(w, &http.Cookie{
-					Name:     CSRFCookieName,
-					Value:    token,
-					Path:     "/",
-					HttpOnly: true,
-					Secure:   isProduction,
-					SameSite: http.SameSiteLaxMode,
-				})
-			}
+// CSRFConfig controls CSRF token signing and cookie attributes.
+type CSRFConfig struct {
+	// IsProduction sets the Secure flag on CSRF cookies
+	IsProduction bool
 
-			next.ServeHTTP(w, r)
-		})
+	// MaxAge is how long an issued token stays valid
+	MaxAge time.Duration
+
+	// TokenLength is the number of random bytes per token
+	TokenLength int
+
+	// SigningKey is the HMAC key. Defaults to a key derived from the cookie encryption key.
+	SigningKey []byte
+}
+
+// csrfConfig is the active configuration. Set at startup via ConfigureCSRF.
+var csrfConfig = CSRFConfig{
+	MaxAge:      12 * time.Hour,
+	TokenLength: CSRFTokenLength,
+}
+
+// ConfigureCSRF sets the CSRF configuration. Called once at startup.
+func ConfigureCSRF(cfg CSRFConfig) {
+	if cfg.MaxAge <= 0 {
+		cfg.MaxAge = csrfConfig.MaxAge
+	}
+	if cfg.TokenLength <= 0 {
+		cfg.TokenLength = CSRFTokenLength
 	}
+	csrfConfig = cfg
 }
 
-// CSRFValidationMiddleware validates the CSRF token on state-changing requests (POST/PUT/DELETE).
-// Accepts the token from either the X-CSRF-Token header (SPA fetch) or csrf_token form field (native forms).
-func CSRFValidationMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Only validate on state-changing methods
-		if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
-			next.ServeHTTP(w, r)
-			return
-		}
+// csrfBinding identifies what a token is bound to. The id never leaves the
+// server in clear text: it lives in encrypted cookies and is only mixed into the HMAC.
+type csrfBinding struct {
+	scope string
+	id    string
+}
 
-		// Get expected token from cookie
-		cookie, err := r.Cookie(CSRFCookieName)
-		if err != nil {
-			http.Error(w, `{"error": "CSRF token missing"}`, http.StatusForbidden)
-			return
-		}
-		expectedToken := cookie.Value
-
-		// Get submitted token from header OR form field
-		submittedToken := r.Header.Get(CSRFHeaderName)
-		if submittedToken == "" {
-			// Try form field (for native form POSTs like consent)
-			if err := r.ParseForm(); err == nil {
-				submittedToken = r.FormValue(CSRFFormField)
+type csrfTokenKey struct{}
+
+var (
+	errCSRFMalformed = errors.New("CSRF token malformed")
+	errCSRFExpired   = errors.New("CSRF token expired")
+	errCSRFInvalid   = errors.New("CSRF token invalid")
+)
+
+// CSRFTokenMiddleware makes sure every request carries a CSRF token bound to the
+// browser's current session, OAuth flow or pre-session. The cookie is (re)issued
+// when missing, expired, past half its lifetime, or bound to something else.
+// The effective token is stored in the request context (see CSRFToken).
+func CSRFTokenMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bindings := csrfBindings(r)
+		if len(bindings) == 0 {
+			// First visit — mint a pre-session id so anonymous pages get a bound token
+			preSessionID, err := randomToken(csrfConfig.TokenLength)
+			if err != nil {
+				writeCSRFError(w, "Internal server error", http.StatusInternalServerError)
+				return
 			}
+			setPreSessionCookie(w, preSessionID)
+			bindings = []csrfBinding{{scope: csrfScopePreSession, id: preSessionID}}
 		}
 
-		if submittedToken == "" {
-			http.Error(w, `{"error": "CSRF token not provided"}`, http.StatusForbidden)
-			return
+		token := ""
+		if cookie, err := r.Cookie(CSRFCookieName); err == nil {
+			if issuedAt, err := verifyCSRFToken(cookie.Value, bindings[0]); err == nil &&
+				time.Since(issuedAt) < csrfConfig.MaxAge/2 {
+				token = cookie.Value
+			}
 		}
 
-		// Constant-time comparison to prevent timing attacks
-		if subtle.ConstantTimeCompare([]byte(expectedToken), []byte(submittedToken)) != 1 {
-			http.Error(w, `{"error": "CSRF token invalid"}`, http.StatusForbidden)
-			return
+		if token == "" {
+			var err error
+			if token, err = issueCSRFToken(w, bindings[0]); err != nil {
+				writeCSRFError(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
 		}
 
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), csrfTokenKey{}, token)))
 	})
 }
 
+// CSRFValidationMiddleware validates the CSRF token on state-changing requests (POST/PUT/DELETE).
+// Accepts the token from either the X-CSRF-Token header (SPA fetch) or csrf_token form field (native forms).
+// The token must carry a valid HMAC for one of the browser's current bindings and be within its max age.
+func CSRFValidationMiddleware(next http.Handler) http.Handler {
+	return csrfValidator(csrfScopeFlow, csrfScopeSession, csrfScopePreSession)(next)
+}
+
+// RequirePreSessionCSRF guards login endpoints against login CSRF: the token must
+// have been issued to this browser's pre-session or OAuth flow, i.e. before it
+// authenticated. Tokens bound to an existing admin session are rejected.
+func RequirePreSessionCSRF(next http.Handler) http.Handler {
+	return csrfValidator(csrfScopeFlow, csrfScopePreSession)(next)
+}
+
+func csrfValidator(scopes ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Only validate on state-changing methods
+			if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Get submitted token from header OR form field
+			submittedToken := r.Header.Get(CSRFHeaderName)
+			if submittedToken == "" {
+				// Try form field (for native form POSTs like consent)
+				if err := r.ParseForm(); err == nil {
+					submittedToken = r.FormValue(CSRFFormField)
+				}
+			}
+
+			if submittedToken == "" {
+				metrics.CSRFRejected("missing")
+				writeCSRFError(w, "CSRF token not provided", http.StatusForbidden)
+				return
+			}
+
+			lastErr := errCSRFInvalid
+			for _, b := range csrfBindings(r) {
+				if !containsScope(scopes, b.scope) {
+					continue
+				}
+				if _, err := verifyCSRFToken(submittedToken, b); err == nil {
+					next.ServeHTTP(w, r)
+					return
+				} else if errors.Is(err, errCSRFExpired) {
+					lastErr = err
+				}
+			}
+
+			if lastErr == errCSRFExpired {
+				metrics.CSRFRejected("expired")
+			} else {
+				metrics.CSRFRejected("invalid")
+			}
+			writeCSRFError(w, lastErr.Error(), http.StatusForbidden)
+		})
+	}
+}
+
 // HandleCSRFToken is the handler for GET /api/csrf.
 // Returns the CSRF token as JSON so the SPA can include it in fetch headers.
 func HandleCSRFToken(w http.ResponseWriter, r *http.Request) {
-	// Read current token from cookie (set by middleware)
-	cookie, err := r.Cookie(CSRFCookieName)
+	token := CSRFToken(r)
+	if token == "" {
+		writeCSRFError(w, "Failed to generate CSRF token", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"token": token})
+}
+
+// CSRFToken returns the token CSRFTokenMiddleware resolved for this request.
+func CSRFToken(r *http.Request) string {
+	token, _ := r.Context().Value(csrfTokenKey{}).(string)
+	return token
+}
+
+// RotateSessionCSRFToken issues a fresh token bound to a newly created or
+// re-privileged session. Call after SetSession on login or role change.
+// The pre-session id is replaced too, so tokens minted before login stop working.
+// The new token is also returned in the X-CSRF-Token response header.
+func RotateSessionCSRFToken(w http.ResponseWriter, session *Session) (string, error) {
+	preSessionID, err := randomToken(csrfConfig.TokenLength)
 	if err != nil {
-		// Generate a new token if none exists
-		token, err := generateCSRFToken()
-		if err != nil {
-			http.Error(w, `{"error": "Failed to generate CSRF token"}`, http.StatusInternalServerError)
-			return
-		}
+		return "", fmt.Errorf("generate pre-session id: %w", err)
+	}
+	setPreSessionCookie(w, preSessionID)
+
+	return issueCSRFToken(w, csrfBinding{scope: csrfScopeSession, id: session.csrfBindingID()})
+}
 
-		// Set cookie for future validation
+// RotateFlowCSRFToken issues a fresh token bound to the OAuth flow. Call after
+// SaveOAuthContext when the flow changes hands (e.g. after the user logs in).
+func RotateFlowCSRFToken(w http.ResponseWriter, ctx *OAuthContext) (string, error) {
+	return issueCSRFToken(w, csrfBinding{scope: csrfScopeFlow, id: ctx.FlowID})
+}
+
+// ClearCSRFToken removes the CSRF cookie and the pre-session id (used during
+// logout), so tokens issued before logout cannot be replayed afterwards.
+func ClearCSRFToken(w http.ResponseWriter) {
+	for _, name := range []string{CSRFCookieName, PreSessionCookieName} {
 		http.SetCookie(w, &http.Cookie{
-			Name:     CSRFCookieName,
-			Value:    token,
+			Name:     name,
+			Value:    "",
 			Path:     "/",
+			MaxAge:   -1,
 			HttpOnly: true,
-			Secure:   false, // Will be overridden by middleware on production
+			Secure:   csrfConfig.IsProduction,
 			SameSite: http.SameSiteLaxMode,
 		})
-
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]string{"token": token})
-		return
 	}
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"token": cookie.Value})
+// csrfBindings lists every binding the request can currently prove, strongest first.
+func csrfBindings(r *http.Request) []csrfBinding {
+	var bindings []csrfBinding
+	if oauthCtx, err := GetOAuthContext(r); err == nil && oauthCtx.FlowID != "" {
+		bindings = append(bindings, csrfBinding{scope: csrfScopeFlow, id: oauthCtx.FlowID})
+	}
+	if session, err := GetSession(r); err == nil && !session.IsExpired() && session.SessionID != "" {
+		bindings = append(bindings, csrfBinding{scope: csrfScopeSession, id: session.csrfBindingID()})
+	}
+	if cookie, err := r.Cookie(PreSessionCookieName); err == nil && cookie.Value != "" {
+		bindings = append(bindings, csrfBinding{scope: csrfScopePreSession, id: cookie.Value})
+	}
+	return bindings
 }
 
-// ClearCSRFToken removes the CSRF cookie(used during logout).
-func ClearCSRFToken(w http.ResponseWriter) {
+// issueCSRFToken signs a new token for the binding and sets it as cookie and response header.
+func issueCSRFToken(w http.ResponseWriter, b csrfBinding) (string, error) {
+	nonce, err := randomToken(csrfConfig.TokenLength)
+	if err != nil {
+		return "", fmt.Errorf("generate CSRF nonce: %w", err)
+	}
+
+	payload := b.scope + "." + nonce + "." + strconv.FormatInt(time.Now().Unix(), 10)
+	token := payload + "." + signCSRFPayload(payload, b.id)
+
 	http.SetCookie(w, &http.Cookie{
 		Name:     CSRFCookieName,
-		Value:    "",
+		Value:    token,
 		Path:     "/",
-		MaxAge:   -1,
+		MaxAge:   int(csrfConfig.MaxAge.Seconds()),
 		HttpOnly: true,
+		Secure:   csrfConfig.IsProduction,
+		SameSite: http.SameSiteLaxMode,
 	})
+	w.Header().Set(CSRFHeaderName, token)
+
+	return token, nil
+}
+
+// verifyCSRFToken checks the token's scope, age and HMAC against the binding.
+// Returns the token's issue time on success.
+func verifyCSRFToken(token string, b csrfBinding) (time.Time, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 4 {
+		return time.Time{}, errCSRFMalformed
+	}
+
+	scope, issued, mac := parts[0], parts[2], parts[3]
+	if scope != b.scope {
+		return time.Time{}, errCSRFInvalid
+	}
+
+	expected := signCSRFPayload(strings.Join(parts[:3], "."), b.id)
+	if !hmac.Equal([]byte(mac), []byte(expected)) {
+		return time.Time{}, errCSRFInvalid
+	}
+
+	unix, err := strconv.ParseInt(issued, 10, 64)
+	if err != nil {
+		return time.Time{}, errCSRFMalformed
+	}
+	issuedAt := time.Unix(unix, 0)
+	if time.Since(issuedAt) > csrfConfig.MaxAge {
+		return time.Time{}, errCSRFExpired
+	}
+
+	return issuedAt, nil
+}
+
+func signCSRFPayload(payload, bindingID string) string {
+	mac := hmac.New(sha256.New, csrfSigningKey())
+	mac.Write([]byte(payload))
+	mac.Write([]byte{0})
+	mac.Write([]byte(bindingID))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func csrfSigningKey() []byte {
+	if len(csrfConfig.SigningKey) > 0 {
+		return csrfConfig.SigningKey
+	}
+	derived := sha256.Sum256(append([]byte("closeauth-csrf:"), GetEncryptionKey()...))
+	return derived[:]
+}
+
+func setPreSessionCookie(w http.ResponseWriter, id string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     PreSessionCookieName,
+		Value:    id,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   csrfConfig.IsProduction,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func containsScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func writeCSRFError(w http.ResponseWriter, message string, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
 }
 
-// generateCSRFToken generates a cryptographically secure random token.
-func generateCSRFToken() (string, error) {
-	bytes := make([]byte, CSRFTokenLength)
+// randomToken generates a cryptographically secure random URL-safe token.
+func randomToken(length int) (string, error) {
+	bytes := make([]byte, length)
 	if _, err := rand.Read(bytes); err != nil {
 		return "", err
 	}
-	return base64.URLEncoding.EncodeToString(bytes), nil
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
 }
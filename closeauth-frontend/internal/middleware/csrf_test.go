@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// csrfBrowser is a minimal cookie jar that replays Set-Cookie headers like a browser.
+type csrfBrowser struct {
+	cookies map[string]*http.Cookie
+}
+
+func newCSRFBrowser() *csrfBrowser {
+	return &csrfBrowser{cookies: make(map[string]*http.Cookie)}
+}
+
+func (b *csrfBrowser) do(handler http.Handler, method, target, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	for _, c := range b.cookies {
+		req.AddCookie(c)
+	}
+	if token != "" {
+		req.Header.Set(CSRFHeaderName, token)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	for _, c := range rec.Result().Cookies() {
+		if c.MaxAge < 0 {
+			delete(b.cookies, c.Name)
+		} else {
+			b.cookies[c.Name] = c
+		}
+	}
+	return rec
+}
+
+func (b *csrfBrowser) token(t *testing.T, handler http.Handler) string {
+	t.Helper()
+	rec := b.do(handler, http.MethodGet, "/api/csrf", "")
+	token := rec.Header().Get(CSRFHeaderName)
+	if token == "" {
+		token = b.cookies[CSRFCookieName].Value
+	}
+	return token
+}
+
+func csrfTestHandler(validator func(http.Handler) http.Handler) http.Handler {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	return CSRFTokenMiddleware(validator(ok))
+}
+
+func TestCSRF_PreSessionTokenValidates(t *testing.T) {
+	handler := csrfTestHandler(CSRFValidationMiddleware)
+	browser := newCSRFBrowser()
+
+	token := browser.token(t, handler)
+	if browser.cookies[PreSessionCookieName] == nil {
+		t.Fatal("first visit should set a pre-session cookie")
+	}
+
+	if rec := browser.do(handler, http.MethodPost, "/api/thing", token); rec.Code != http.StatusNoContent {
+		t.Fatalf("POST with valid token = %d, want %d: %s", rec.Code, http.StatusNoContent, rec.Body.String())
+	}
+	if rec := browser.do(handler, http.MethodPost, "/api/thing", ""); rec.Code != http.StatusForbidden {
+		t.Errorf("POST without token = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+}
+
+func TestCSRF_TokenBoundToBrowser(t *testing.T) {
+	handler := csrfTestHandler(CSRFValidationMiddleware)
+	victim := newCSRFBrowser()
+	attacker := newCSRFBrowser()
+
+	attackerToken := attacker.token(t, handler)
+	victim.token(t, handler)
+
+	if rec := victim.do(handler, http.MethodPost, "/api/thing", attackerToken); rec.Code != http.StatusForbidden {
+		t.Errorf("POST with another browser's token = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+}
+
+func TestCSRF_ExpiredToken(t *testing.T) {
+	defer ConfigureCSRF(csrfConfig)
+	ConfigureCSRF(CSRFConfig{MaxAge: time.Minute})
+
+	binding := csrfBinding{scope: csrfScopePreSession, id: "browser-1"}
+	payload := "p.nonce." + "1000"
+	token := payload + "." + signCSRFPayload(payload, binding.id)
+
+	if _, err := verifyCSRFToken(token, binding); err != errCSRFExpired {
+		t.Errorf("verifyCSRFToken() error = %v, want %v", err, errCSRFExpired)
+	}
+}
+
+func TestCSRF_RotatedOnLogin(t *testing.T) {
+	handler := csrfTestHandler(CSRFValidationMiddleware)
+	browser := newCSRFBrowser()
+	preLoginToken := browser.token(t, handler)
+
+	// Simulate the login handler: set session + rotate
+	login := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session := &Session{Email: "admin@example.com", Role: "Admin", ExpiresAt: time.Now().Add(time.Hour).Unix()}
+		if err := SetSession(w, session, false); err != nil {
+			t.Fatalf("SetSession() error = %v", err)
+		}
+		if _, err := RotateSessionCSRFToken(w, session); err != nil {
+			t.Fatalf("RotateSessionCSRFToken() error = %v", err)
+		}
+	})
+	rec := browser.do(login, http.MethodPost, "/api/admin/login", "")
+	sessionToken := rec.Header().Get(CSRFHeaderName)
+	if sessionToken == "" || sessionToken == preLoginToken {
+		t.Fatal("login should announce a new CSRF token")
+	}
+
+	if rec := browser.do(handler, http.MethodPost, "/api/thing", preLoginToken); rec.Code != http.StatusForbidden {
+		t.Errorf("POST with pre-login token = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+	if rec := browser.do(handler, http.MethodPost, "/api/thing", sessionToken); rec.Code != http.StatusNoContent {
+		t.Errorf("POST with session token = %d, want %d: %s", rec.Code, http.StatusNoContent, rec.Body.String())
+	}
+
+	// Login endpoints only accept tokens minted before authentication
+	preSession := csrfTestHandler(RequirePreSessionCSRF)
+	if rec := browser.do(preSession, http.MethodPost, "/api/admin/login", sessionToken); rec.Code != http.StatusForbidden {
+		t.Errorf("login POST with session token = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+}
+
+func TestCSRF_RoleChangeInvalidatesToken(t *testing.T) {
+	session := &Session{SessionID: "sid-1", Role: "Viewer"}
+	binding := csrfBinding{scope: csrfScopeSession, id: session.csrfBindingID()}
+
+	rec := httptest.NewRecorder()
+	token, err := issueCSRFToken(rec, binding)
+	if err != nil {
+		t.Fatalf("issueCSRFToken() error = %v", err)
+	}
+
+	session.Role = "Admin"
+	promoted := csrfBinding{scope: csrfScopeSession, id: session.csrfBindingID()}
+	if _, err := verifyCSRFToken(token, promoted); err == nil {
+		t.Error("token issued before a role change should not validate afterwards")
+	}
+}
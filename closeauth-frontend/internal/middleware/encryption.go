@@ -6,13 +6,20 @@ import (
 	"crypto/rand"
 	"fmt"
 	"io"
-	"os"
 )
 
-// GetEncryptionKey returns the 32-byte AES-256 key from environment.
+// encryptionKey is set once at startup by SetEncryptionKey.
+var encryptionKey string
+
+// SetEncryptionKey configures the cookie encryption key (middleware.encryption_key).
+func SetEncryptionKey(key string) {
+	encryptionKey = key
+}
+
+// GetEncryptionKey returns the 32-byte AES-256 key.
 // Pads or truncates to exactly 32 bytes.
 func GetEncryptionKey() []byte {
-	key := os.Getenv("OAUTH_CONTEXT_ENCRYPTION_KEY")
+	key := encryptionKey
 	if key == "" {
 		key = "default-32-byte-key-change-me!!" // Development only!
 	}
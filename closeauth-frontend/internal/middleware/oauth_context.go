@@ -31,14 +31,23 @@ type OAuthContext struct {
 	Scope           string `json:"scope"`
 	State           string `json:"state"`
 	Timestamp       int64  `json:"timestamp"`
+	FlowID          string `json:"flow_id,omitempty"`           // Random id, binds CSRF tokens to this flow
 	SpringSessionID string `json:"spring_session_id,omitempty"` // JSESSIONID for session continuity
 	Username        string `json:"username,omitempty"`          // Set after login
 }
 
 // SaveOAuthContext encrypts and stores the OAuth context in a cookie.
 // TTL is determined by the oauthContextTTL package variable (synced from Spring).
+// A FlowID is generated for new flows and preserved when an existing context is re-saved.
 func SaveOAuthContext(w http.ResponseWriter, ctx *OAuthContext, isProduction bool) error {
 	ctx.Timestamp = time.Now().Unix()
+	if ctx.FlowID == "" {
+		id, err := randomToken(16)
+		if err != nil {
+			return fmt.Errorf("generate oauth flow id: %w", err)
+		}
+		ctx.FlowID = id
+	}
 
 	jsonData, err := json.Marshal(ctx)
 	if err != nil {
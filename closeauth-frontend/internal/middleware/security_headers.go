@@ -0,0 +1,177 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// cspNonceKey is the context key for the per-request CSP nonce.
+type cspNonceKey struct{}
+
+// Placeholders substituted into RoutePolicy.CSP on every request.
+const (
+	cspNoncePlaceholder          = "{nonce}"
+	cspFrameAncestorsPlaceholder = "{frame-ancestors}"
+)
+
+// spaCSP is the strict policy for pages rendered by the Vue SPA.
+// form-action is deliberately omitted: the consent form POST redirects to the
+// client's redirect_uri, which browsers would otherwise block.
+const spaCSP = "default-src 'self'; " +
+	"script-src 'self' 'nonce-{nonce}'; " +
+	"style-src 'self' 'nonce-{nonce}'; " +
+	"img-src 'self' data: https:; " +
+	"font-src 'self' data:; " +
+	"connect-src 'self'; " +
+	"object-src 'none'; " +
+	"base-uri 'self'; " +
+	"frame-ancestors {frame-ancestors}"
+
+// apiCSP locks down JSON and redirect responses, which never render content.
+const apiCSP = "default-src 'none'; frame-ancestors 'none'; base-uri 'none'"
+
+// RoutePolicy describes the security headers applied to requests whose path
+// begins with Prefix.
+type RoutePolicy struct {
+	Prefix string
+
+	// CSP is the Content-Security-Policy template. "{nonce}" is replaced by the
+	// per-request nonce and "{frame-ancestors}" by the resolved source list.
+	CSP string
+
+	// ReferrerPolicy is sent as the Referrer-Policy header.
+	ReferrerPolicy string
+
+	// AllowEmbedding lets clients listed in SecurityHeadersConfig.EmbeddedLoginOrigins
+	// frame the page. All other pages get frame-ancestors 'none'.
+	AllowEmbedding bool
+}
+
+// DefaultRoutePolicies returns the policies for the BFF's route tiers, most
+// specific prefix first.
+func DefaultRoutePolicies() []RoutePolicy {
+	return []RoutePolicy{
+		// OAuth2 redirects and JSON APIs carry codes/state in URLs — never leak them
+		{Prefix: "/closeauth/", CSP: apiCSP, ReferrerPolicy: "no-referrer"},
+		{Prefix: "/api/", CSP: apiCSP, ReferrerPolicy: "no-referrer"},
+
+		// Hosted login/consent/register pages
+		{Prefix: "/oauth/", CSP: spaCSP, ReferrerPolicy: "no-referrer", AllowEmbedding: true},
+
+		// Admin SPA and static assets
+		{Prefix: "/", CSP: spaCSP, ReferrerPolicy: "strict-origin-when-cross-origin"},
+	}
+}
+
+// SecurityHeadersConfig configures SecurityHeadersMiddleware.
+type SecurityHeadersConfig struct {
+	// IsProduction enables HSTS (only meaningful behind TLS)
+	IsProduction bool
+
+	// HSTSMaxAge is the Strict-Transport-Security max-age in seconds
+	HSTSMaxAge int
+
+	// EmbeddedLoginOrigins maps a client_id to the origins allowed to frame its OAuth pages
+	EmbeddedLoginOrigins map[string][]string
+
+	// Policies is matched in order; the first matching prefix wins.
+	// Defaults to DefaultRoutePolicies() when empty.
+	Policies []RoutePolicy
+}
+
+// SecurityHeadersMiddleware sets CSP, HSTS, X-Frame-Options, Referrer-Policy and
+// Permissions-Policy per route. When the matched policy uses a nonce, a fresh one
+// is generated and stored in the request context for the SPA handler (see CSPNonce).
+func SecurityHeadersMiddleware(cfg SecurityHeadersConfig) func(http.Handler) http.Handler {
+	policies := cfg.Policies
+	if len(policies) == 0 {
+		policies = DefaultRoutePolicies()
+	}
+
+	hsts := ""
+	if cfg.IsProduction && cfg.HSTSMaxAge > 0 {
+		hsts = fmt.Sprintf("max-age=%d; includeSubDomains", cfg.HSTSMaxAge)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			policy := matchRoutePolicy(policies, r.URL.Path)
+
+			h := w.Header()
+			h.Set("X-Content-Type-Options", "nosniff")
+			h.Set("Permissions-Policy", "camera=(), microphone=(), geolocation=(), payment=(), usb=()")
+			if policy.ReferrerPolicy != "" {
+				h.Set("Referrer-Policy", policy.ReferrerPolicy)
+			}
+			if hsts != "" {
+				h.Set("Strict-Transport-Security", hsts)
+			}
+
+			frameAncestors := "'none'"
+			if policy.AllowEmbedding {
+				if origins := cfg.EmbeddedLoginOrigins[embeddingClientID(r)]; len(origins) > 0 {
+					frameAncestors = "'self' " + strings.Join(origins, " ")
+				}
+			}
+			// X-Frame-Options cannot express an origin list; only send it when framing is fully denied
+			if frameAncestors == "'none'" {
+				h.Set("X-Frame-Options", "DENY")
+			}
+
+			csp := strings.ReplaceAll(policy.CSP, cspFrameAncestorsPlaceholder, frameAncestors)
+			if strings.Contains(csp, cspNoncePlaceholder) {
+				nonce, err := generateCSPNonce()
+				if err != nil {
+					http.Error(w, "Internal server error", http.StatusInternalServerError)
+					return
+				}
+				csp = strings.ReplaceAll(csp, cspNoncePlaceholder, nonce)
+				r = r.WithContext(context.WithValue(r.Context(), cspNonceKey{}, nonce))
+			}
+			if csp != "" {
+				h.Set("Content-Security-Policy", csp)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// CSPNonce returns the nonce generated for this request, or "" if the route's
+// policy does not use one.
+func CSPNonce(ctx context.Context) string {
+	nonce, _ := ctx.Value(cspNonceKey{}).(string)
+	return nonce
+}
+
+func matchRoutePolicy(policies []RoutePolicy, path string) RoutePolicy {
+	for _, p := range policies {
+		if strings.HasPrefix(path, p.Prefix) {
+			return p
+		}
+	}
+	return RoutePolicy{CSP: apiCSP}
+}
+
+// embeddingClientID identifies the OAuth client whose page is being rendered.
+// The encrypted OAuth context wins over the client_id query parameter so a
+// framing origin cannot borrow another client's embedding permission.
+func embeddingClientID(r *http.Request) string {
+	if oauthCtx, err := GetOAuthContext(r); err == nil {
+		return oauthCtx.ClientID
+	}
+	return r.URL.Query().Get("client_id")
+}
+
+// generateCSPNonce generates a base64-encoded 128-bit nonce.
+func generateCSPNonce() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(bytes), nil
+}
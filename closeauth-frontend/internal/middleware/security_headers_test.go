@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func serveWithSecurityHeaders(t *testing.T, cfg SecurityHeadersConfig, target string) (*httptest.ResponseRecorder, string) {
+	t.Helper()
+
+	var seenNonce string
+	handler := SecurityHeadersMiddleware(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seenNonce = CSPNonce(r.Context())
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec, seenNonce
+}
+
+func TestSecurityHeaders_SPANonce(t *testing.T) {
+	rec, nonce := serveWithSecurityHeaders(t, SecurityHeadersConfig{}, "/admin/dashboard")
+
+	if nonce == "" {
+		t.Fatal("CSPNonce() should be set for SPA routes")
+	}
+	csp := rec.Header().Get("Content-Security-Policy")
+	if !strings.Contains(csp, "script-src 'self' 'nonce-"+nonce+"'") {
+		t.Errorf("CSP = %q, want script-src with request nonce", csp)
+	}
+	if !strings.Contains(csp, "frame-ancestors 'none'") {
+		t.Errorf("CSP = %q, want frame-ancestors 'none'", csp)
+	}
+	if got := rec.Header().Get("X-Frame-Options"); got != "DENY" {
+		t.Errorf("X-Frame-Options = %q, want DENY", got)
+	}
+
+	_, second := serveWithSecurityHeaders(t, SecurityHeadersConfig{}, "/admin/dashboard")
+	if second == nonce {
+		t.Error("CSPNonce() should differ between requests")
+	}
+}
+
+func TestSecurityHeaders_APIHasNoNonce(t *testing.T) {
+	rec, nonce := serveWithSecurityHeaders(t, SecurityHeadersConfig{}, "/api/health")
+
+	if nonce != "" {
+		t.Errorf("CSPNonce() = %q, want empty for API routes", nonce)
+	}
+	if got := rec.Header().Get("Content-Security-Policy"); !strings.HasPrefix(got, "default-src 'none'") {
+		t.Errorf("CSP = %q, want default-src 'none'", got)
+	}
+	if got := rec.Header().Get("Referrer-Policy"); got != "no-referrer" {
+		t.Errorf("Referrer-Policy = %q, want no-referrer", got)
+	}
+}
+
+func TestSecurityHeaders_EmbeddedLogin(t *testing.T) {
+	cfg := SecurityHeadersConfig{
+		EmbeddedLoginOrigins: map[string][]string{"embedded-app": {"https://app.example.com"}},
+	}
+
+	tests := []struct {
+		name          string
+		target        string
+		wantAncestors string
+		wantFrameDeny bool
+	}{
+		{"embedded client", "/oauth/login?client_id=embedded-app", "frame-ancestors 'self' https://app.example.com", false},
+		{"other client", "/oauth/login?client_id=other-app", "frame-ancestors 'none'", true},
+		{"admin page ignores embedding", "/admin/login?client_id=embedded-app", "frame-ancestors 'none'", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, _ := serveWithSecurityHeaders(t, cfg, tt.target)
+
+			if csp := rec.Header().Get("Content-Security-Policy"); !strings.Contains(csp, tt.wantAncestors) {
+				t.Errorf("CSP = %q, want %q", csp, tt.wantAncestors)
+			}
+			if got := rec.Header().Get("X-Frame-Options") == "DENY"; got != tt.wantFrameDeny {
+				t.Errorf("X-Frame-Options DENY = %v, want %v", got, tt.wantFrameDeny)
+			}
+		})
+	}
+}
+
+func TestSecurityHeaders_HSTSOnlyInProduction(t *testing.T) {
+	rec, _ := serveWithSecurityHeaders(t, SecurityHeadersConfig{HSTSMaxAge: 600}, "/")
+	if got := rec.Header().Get("Strict-Transport-Security"); got != "" {
+		t.Errorf("Strict-Transport-Security = %q, want empty outside production", got)
+	}
+
+	rec, _ = serveWithSecurityHeaders(t, SecurityHeadersConfig{IsProduction: true, HSTSMaxAge: 600}, "/")
+	if got := rec.Header().Get("Strict-Transport-Security"); got != "max-age=600; includeSubDomains" {
+		t.Errorf("Strict-Transport-Security = %q, want max-age=600; includeSubDomains", got)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -12,13 +13,16 @@ const SessionCookieName = "bff_session"
 
 // Session represents the authenticated user's session data stored in an encrypted cookie.
 type Session struct {
-	UserID      string `json:"user_id,omitempty"`
-	Email       string `json:"email"`
-	Username    string `json:"username,omitempty"`
-	Role        string `json:"role,omitempty"`
-	AccessToken string `json:"access_token"` // User JWT for X-User-Token forwarding
-	ExpiresAt   int64  `json:"expires_at"`   // Unix timestamp
-	CreatedAt   int64  `json:"created_at"`   // Unix timestamp
+	SessionID   string   `json:"sid,omitempty"` // Random id, binds CSRF tokens to this session
+	UserID      string   `json:"user_id,omitempty"`
+	Email       string   `json:"email"`
+	Username    string   `json:"username,omitempty"`
+	Role        string   `json:"role,omitempty"`        // Primary role for display
+	Roles       []string `json:"roles,omitempty"`       // Spring GlobalRoleEnum names
+	Permissions []string `json:"permissions,omitempty"` // Resolved BFF permissions (sorted)
+	AccessToken string   `json:"access_token"`          // User JWT for X-User-Token forwarding
+	ExpiresAt   int64    `json:"expires_at"`            // Unix timestamp
+	CreatedAt   int64    `json:"created_at"`            // Unix timestamp
 }
 
 // IsExpired returns true if the session has expired.
@@ -26,9 +30,23 @@ func (s *Session) IsExpired() bool {
 	return time.Now().Unix() > s.ExpiresAt
 }
 
+// csrfBindingID is the identity CSRF tokens are bound to. Role and permissions are
+// part of it so a privilege change invalidates tokens issued under the old grants.
+func (s *Session) csrfBindingID() string {
+	return s.SessionID + ":" + s.Role + ":" + strings.Join(s.Permissions, ",")
+}
+
 // SetSession encrypts and stores the session in an httpOnly cookie.
+// A new SessionID is generated unless the caller is re-saving an existing session.
 func SetSession(w http.ResponseWriter, session *Session, isProduction bool) error {
 	session.CreatedAt = time.Now().Unix()
+	if session.SessionID == "" {
+		id, err := randomToken(16)
+		if err != nil {
+			return fmt.Errorf("generate session id: %w", err)
+		}
+		session.SessionID = id
+	}
 
 	jsonData, err := json.Marshal(session)
 	if err != nil {
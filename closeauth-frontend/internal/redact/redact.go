@@ -0,0 +1,145 @@
+// Package redact strips secrets from data before it is persisted or logged.
+package redact
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// Placeholder replaces every redacted value.
+const Placeholder = "[REDACTED]"
+
+// sensitiveKeyFragments match keys case-insensitively, ignoring '_' and '-'
+// (so "client_secret", "clientSecret" and "Client-Secret" all match "secret").
+var sensitiveKeyFragments = []string{
+	"password",
+	"passwd",
+	"secret",
+	"token",
+	"otp",
+	"authorization",
+	"cookie",
+	"jsessionid",
+	"credential",
+	"privatekey",
+	"apikey",
+}
+
+var keyNormalizer = strings.NewReplacer("_", "", "-", "")
+
+func normalizeKey(key string) string {
+	return keyNormalizer.Replace(strings.ToLower(key))
+}
+
+// Matcher decides which keys are sensitive. The zero value is not usable; use NewMatcher.
+type Matcher struct {
+	fragments []string
+}
+
+// NewMatcher returns a Matcher for the built-in sensitive keys plus extraKeys,
+// which are matched the same way (case-insensitive fragments).
+func NewMatcher(extraKeys ...string) *Matcher {
+	fragments := append([]string(nil), sensitiveKeyFragments...)
+	for _, key := range extraKeys {
+		if key = normalizeKey(strings.TrimSpace(key)); key != "" {
+			fragments = append(fragments, key)
+		}
+	}
+	return &Matcher{fragments: fragments}
+}
+
+var defaultMatcher = NewMatcher()
+
+// IsSensitiveKey reports whether values stored under key must never be persisted.
+func IsSensitiveKey(key string) bool {
+	return defaultMatcher.IsSensitiveKey(key)
+}
+
+// IsSensitiveKey reports whether values stored under key must be masked.
+func (m *Matcher) IsSensitiveKey(key string) bool {
+	normalized := normalizeKey(key)
+	for _, fragment := range m.fragments {
+		if strings.Contains(normalized, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// Value returns a copy of a decoded JSON value with sensitive keys redacted at any depth.
+func Value(v any) any {
+	return defaultMatcher.Value(v)
+}
+
+// Value returns a copy of a decoded JSON value with sensitive keys redacted at any depth.
+func (m *Matcher) Value(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(t))
+		for k, val := range t {
+			if m.IsSensitiveKey(k) {
+				out[k] = Placeholder
+			} else {
+				out[k] = m.Value(val)
+			}
+		}
+		return out
+	case []any:
+		out := make([]any, len(t))
+		for i, val := range t {
+			out[i] = m.Value(val)
+		}
+		return out
+	case string:
+		return m.String(t)
+	default:
+		return v
+	}
+}
+
+var (
+	// authSchemePattern matches credentials after an HTTP auth scheme
+	authSchemePattern = regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9._~+/=-]+`)
+
+	// jwtPattern matches compact JWS/JWT tokens
+	jwtPattern = regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+
+	// keyValuePattern matches key=value, key: value and "key":"value" pairs
+	keyValuePattern = regexp.MustCompile(`([A-Za-z][A-Za-z0-9_.-]*)("?\s*[=:]\s*"?)([^\s"&;,}]+)`)
+)
+
+// String masks credentials embedded in free text: auth headers, JWTs and
+// key=value pairs (query strings, cookies, JSON fragments) with sensitive keys.
+func (m *Matcher) String(s string) string {
+	s = authSchemePattern.ReplaceAllString(s, "$1 "+Placeholder)
+	s = jwtPattern.ReplaceAllString(s, Placeholder)
+	return keyValuePattern.ReplaceAllStringFunc(s, func(pair string) string {
+		parts := keyValuePattern.FindStringSubmatch(pair)
+		if !m.IsSensitiveKey(parts[1]) || parts[3] == Placeholder {
+			return pair
+		}
+		return parts[1] + parts[2] + Placeholder
+	})
+}
+
+// JSON redacts a JSON document. Empty input yields nil. Input that is not JSON
+// or whose redacted form exceeds maxSize bytes (when maxSize > 0) is replaced by
+// a small placeholder object so the original bytes are never kept.
+func JSON(body []byte, maxSize int) []byte {
+	if len(body) == 0 {
+		return nil
+	}
+
+	var decoded any
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		out, _ := json.Marshal(map[string]any{"_omitted": "non-JSON body", "size": len(body)})
+		return out
+	}
+
+	out, err := json.Marshal(Value(decoded))
+	if err != nil || (maxSize > 0 && len(out) > maxSize) {
+		out, _ = json.Marshal(map[string]any{"_omitted": "body too large", "size": len(body)})
+	}
+	return out
+}
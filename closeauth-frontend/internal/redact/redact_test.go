@@ -0,0 +1,72 @@
+package redact
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsSensitiveKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"password", true},
+		{"client_secret", true},
+		{"clientSecret", true},
+		{"access_token", true},
+		{"Authorization", true},
+		{"JSESSIONID", true},
+		{"otp", true},
+		{"clientName", false},
+		{"email", false},
+		{"redirect_uris", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := IsSensitiveKey(tt.key); got != tt.want {
+				t.Errorf("IsSensitiveKey(%q) = %v, want %v", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		maxSize int
+		want    string
+	}{
+		{"empty", "", 0, ""},
+		{"flat", `{"clientName":"app","client_secret":"s3cr3t"}`, 0, `{"clientName":"app","client_secret":"[REDACTED]"}`},
+		{"nested", `{"data":{"items":[{"password":"x","id":1}]}}`, 0, `{"data":{"items":[{"id":1,"password":"[REDACTED]"}]}}`},
+		{"not json", `password=x`, 0, `{"_omitted":"non-JSON body","size":10}`},
+		{"too large", `{"description":"` + strings.Repeat("a", 100) + `"}`, 50, `{"_omitted":"body too large","size":118}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(JSON([]byte(tt.body), tt.maxSize)); got != tt.want {
+				t.Errorf("JSON() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatcher_String(t *testing.T) {
+	m := NewMatcher("national_id")
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Authorization: Bearer abc.def", "Authorization: [REDACTED] [REDACTED]"},
+		{"JSESSIONID=abc123; Path=/; HttpOnly", "JSESSIONID=[REDACTED]; Path=/; HttpOnly"},
+		{"grant_type=password&password=pw&client_id=app", "grant_type=password&password=[REDACTED]&client_id=app"},
+		{`{"nationalId":"123","name":"x"}`, `{"nationalId":"[REDACTED]","name":"x"}`},
+		{"redirect to https://app.example/cb?code=abc", "redirect to https://app.example/cb?code=abc"},
+	}
+	for _, tt := range tests {
+		if got := m.String(tt.in); got != tt.want {
+			t.Errorf("String(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
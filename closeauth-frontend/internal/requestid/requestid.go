@@ -0,0 +1,67 @@
+// Package requestid carries the X-Request-ID correlation id through a request,
+// from the browser to the BFF logs, audit records and Spring.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// Header is the correlation header honoured on input and echoed on output.
+const Header = "X-Request-ID"
+
+// maxLength bounds client-supplied ids so they cannot bloat logs. It matches
+// the width of admin_audit_log.request_id.
+const maxLength = 100
+
+type contextKey struct{}
+
+// FromContext returns the request id stored in ctx, or "".
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// NewContext returns a copy of ctx carrying id.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// Middleware honours a well-formed incoming X-Request-ID or generates one,
+// stores it in the request context and sets it on the response.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(Header)
+		if !valid(id) {
+			id = generate()
+		}
+
+		w.Header().Set(Header, id)
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), id)))
+	})
+}
+
+// valid accepts 1-100 characters from a conservative set so ids are safe to
+// log and to forward as a header.
+func valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func generate() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,44 @@
+package requestid
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string
+		honoured bool
+	}{
+		{"honours well-formed id", "support-ticket-42", true},
+		{"generates when missing", "", false},
+		{"rejects header injection", "abc\r\nX-Evil: 1", false},
+		{"honours id of the maximum length", strings.Repeat("a", 100), true},
+		{"rejects overlong id", strings.Repeat("a", 101), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen string
+			handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seen = FromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.incoming != "" {
+				req.Header.Set(Header, tt.incoming)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if seen == "" || rec.Header().Get(Header) != seen {
+				t.Fatalf("context id %q, response header %q", seen, rec.Header().Get(Header))
+			}
+			if (seen == tt.incoming) != tt.honoured {
+				t.Errorf("id = %q, honoured = %v, want %v", seen, seen == tt.incoming, tt.honoured)
+			}
+		})
+	}
+}
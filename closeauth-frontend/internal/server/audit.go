@@ -0,0 +1,152 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"closeauth-frontend/internal/database/models"
+	"closeauth-frontend/internal/middleware"
+	"closeauth-frontend/internal/redact"
+	"closeauth-frontend/internal/requestid"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	// auditSummaryMaxSize caps each redacted before/after summary.
+	auditSummaryMaxSize = 16 << 10
+
+	// auditSnapshotTimeout bounds the extra Spring read for a "before" summary.
+	auditSnapshotTimeout = 2 * time.Second
+)
+
+// auditVerbs maps HTTP methods to the verb used in audit action names.
+var auditVerbs = map[string]string{
+	http.MethodPost:   "create",
+	http.MethodPut:    "update",
+	http.MethodPatch:  "update",
+	http.MethodDelete: "delete",
+}
+
+// auditVerbSegments are trailing route segments that name the action itself.
+var auditVerbSegments = map[string]bool{
+	"activate": true,
+	"approve":  true,
+	"reject":   true,
+	"rollback": true,
+	"publish":  true,
+}
+
+// auditActionFor derives an action ("themes.update") and target ("themes/12")
+// from the matched route, relative to /admin/clients/{clientId}.
+// A trailing verb segment names the action,
+// e.g. PATCH .../themes/{themeId}/activate → "themes.activate".
+func auditActionFor(r *http.Request) (action, target string) {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil {
+		return strings.ToLower(r.Method), r.URL.Path
+	}
+
+	pattern := rctx.RoutePattern()
+	if _, rest, ok := strings.Cut(pattern, "{clientId}"); ok {
+		pattern = rest
+	} else {
+		pattern = strings.TrimPrefix(pattern, "/api/admin")
+	}
+	segments := strings.Split(strings.Trim(pattern, "/"), "/")
+
+	verb := auditVerbs[r.Method]
+	if n := len(segments); n >= 2 && auditVerbSegments[segments[n-1]] {
+		verb = segments[n-1]
+		segments = segments[:n-1]
+	}
+
+	resource := ""
+	parts := make([]string, 0, len(segments))
+	for _, seg := range segments {
+		if isRouteParam(seg) {
+			parts = append(parts, rctx.URLParam(strings.Trim(seg, "{}")))
+			continue
+		}
+		resource = seg
+		parts = append(parts, seg)
+	}
+
+	return resource + "." + verb, strings.Join(parts, "/")
+}
+
+func isRouteParam(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+// recordAudit fills in actor and request details and queues the event for
+// writing. Audit failures are logged but never fail, or slow down, the admin
+// request that triggered them.
+func (s *Server) recordAudit(r *http.Request, event *models.AuditEvent) {
+	event.OccurredAt = time.Now()
+	event.RequestID = requestid.FromContext(r.Context())
+	event.IPAddress = remoteIP(r)
+	if session, err := middleware.GetSession(r); err == nil {
+		event.ActorID = session.UserID
+		event.ActorEmail = session.Email
+		if event.ClientID == "" && event.TenantID != "" {
+			event.ClientID = s.clientAccess.OAuthClientID(session.SessionID, event.TenantID)
+		}
+	}
+	if event.StatusCode >= 200 && event.StatusCode < 300 {
+		event.Outcome = models.AuditOutcomeSuccess
+	} else {
+		event.Outcome = models.AuditOutcomeFailure
+	}
+	event.FitColumns()
+
+	logger := s.logger.With(
+		"component", "audit",
+		"action", event.Action,
+		"target", event.Target,
+		"tenant_id", event.TenantID,
+		"actor_id", event.ActorID,
+		"outcome", event.Outcome,
+		"status", event.StatusCode,
+	)
+
+	if s.auditQueue == nil {
+		s.writeAudit(event, logger)
+		return
+	}
+	s.auditQueue.Enqueue(event, logger)
+}
+
+// writeAudit appends an event to the audit log.
+func (s *Server) writeAudit(event *models.AuditEvent, logger *slog.Logger) {
+	repo := s.auditStore()
+	if repo == nil {
+		logger.Info("audit event (not persisted, database unavailable)")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), auditWriteTimeout)
+	defer cancel()
+
+	if err := repo.Append(ctx, event); err != nil {
+		logger.Error("failed to persist audit event", "error", err)
+	}
+}
+
+// auditSummary redacts a JSON body for storage in an audit event.
+func auditSummary(body []byte) models.JSONText {
+	return redact.JSON(body, auditSummaryMaxSize)
+}
+
+// remoteIP returns the peer address without its port.
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"closeauth-frontend/internal/database/models"
+)
+
+const (
+	// auditQueueSize bounds the events waiting to be written; beyond it new
+	// events are logged and dropped rather than slowing admin requests down.
+	auditQueueSize = 1024
+
+	// auditWriteTimeout bounds each audit write.
+	auditWriteTimeout = 5 * time.Second
+)
+
+// auditEntry is a finished event and the logger carrying its fields.
+type auditEntry struct {
+	event  *models.AuditEvent
+	logger *slog.Logger
+}
+
+// auditQueue writes audit events off the request path, one at a time in
+// arrival order.
+type auditQueue struct {
+	write func(*models.AuditEvent, *slog.Logger)
+
+	mu      sync.RWMutex
+	closed  bool
+	entries chan auditEntry
+	done    chan struct{}
+}
+
+func newAuditQueue(size int, write func(*models.AuditEvent, *slog.Logger)) *auditQueue {
+	q := &auditQueue{
+		write:   write,
+		entries: make(chan auditEntry, size),
+		done:    make(chan struct{}),
+	}
+	go q.run()
+	return q
+}
+
+func (q *auditQueue) run() {
+	defer close(q.done)
+	for entry := range q.entries {
+		q.write(entry.event, entry.logger)
+	}
+}
+
+// Enqueue hands an event to the writer without blocking. Once the queue is
+// closed events are written synchronously instead.
+func (q *auditQueue) Enqueue(event *models.AuditEvent, logger *slog.Logger) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		q.write(event, logger)
+		return
+	}
+	select {
+	case q.entries <- auditEntry{event, logger}:
+	default:
+		logger.Error("audit queue full, event dropped", "queue_size", cap(q.entries))
+	}
+}
+
+// Close stops accepting events and waits until the queued ones are written
+// or ctx is done.
+func (q *auditQueue) Close(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.entries)
+	}
+	q.mu.Unlock()
+
+	select {
+	case <-q.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
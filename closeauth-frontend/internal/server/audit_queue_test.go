@@ -0,0 +1,59 @@
+package server
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"runtime"
+	"slices"
+	"sync"
+	"testing"
+
+	"closeauth-frontend/internal/database/models"
+)
+
+func TestAuditQueue(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	var (
+		mu      sync.Mutex
+		written []string
+	)
+	release := make(chan struct{})
+	q := newAuditQueue(2, func(event *models.AuditEvent, _ *slog.Logger) {
+		if event.Action == "blocker.update" {
+			<-release
+		}
+		mu.Lock()
+		written = append(written, event.Action)
+		mu.Unlock()
+	})
+
+	// The writer is stuck on the first event, so the queue fills up and the
+	// fourth event is dropped rather than blocking the caller
+	q.Enqueue(&models.AuditEvent{Action: "blocker.update"}, logger)
+	for len(q.entries) != 0 {
+		runtime.Gosched()
+	}
+	for _, action := range []string{"themes.create", "themes.update", "themes.delete"} {
+		q.Enqueue(&models.AuditEvent{Action: action}, logger)
+	}
+	close(release)
+
+	if err := q.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"blocker.update", "themes.create", "themes.update"}
+	if !slices.Equal(written, want) {
+		t.Errorf("written = %v, want %v", written, want)
+	}
+
+	// After Close events are written synchronously
+	q.Enqueue(&models.AuditEvent{Action: "themes.activate"}, logger)
+	if last := written[len(written)-1]; last != "themes.activate" {
+		t.Errorf("event after Close written as %q, want it written synchronously", last)
+	}
+	if err := q.Close(context.Background()); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"closeauth-frontend/internal/database/models"
+	"closeauth-frontend/internal/database/repository"
+	"closeauth-frontend/internal/requestid"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestAuditActionFor(t *testing.T) {
+	tests := []struct {
+		method     string
+		pattern    string
+		path       string
+		wantAction string
+		wantTarget string
+	}{
+		{http.MethodPost, "/roles", "/roles", "roles.create", "roles"},
+		{http.MethodPut, "/roles/{roleId}", "/roles/7", "roles.update", "roles/7"},
+		{http.MethodDelete, "/themes/{themeId}", "/themes/3", "themes.delete", "themes/3"},
+		{http.MethodPut, "/registration-config", "/registration-config", "registration-config.update", "registration-config"},
+		{http.MethodPatch, "/themes/{themeId}/activate", "/themes/3/activate", "themes.activate", "themes/3"},
+		{http.MethodPost, "/themes/{themeId}/configurations", "/themes/3/configurations", "configurations.create", "themes/3/configurations"},
+		{http.MethodPost, "/pending-registrations/{email}/approve", "/pending-registrations/a@b.c/approve", "pending-registrations.approve", "pending-registrations/a@b.c"},
+		{http.MethodPost, "/themes/{themeId}/versions/{version}/rollback", "/themes/3/versions/2/rollback", "versions.rollback", "themes/3/versions/2"},
+		{http.MethodPost, "/themes/{themeId}/draft/publish", "/themes/3/draft/publish", "draft.publish", "themes/3/draft"},
+		{http.MethodPost, "/themes/{themeId}/preview", "/themes/3/preview", "preview.create", "themes/3/preview"},
+		{http.MethodPost, "/assets", "/assets", "assets.create", "assets"},
+		{http.MethodDelete, "/assets/{assetId}", "/assets/9", "assets.delete", "assets/9"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.pattern, func(t *testing.T) {
+			var action, target string
+			r := chi.NewRouter()
+			r.Route("/api/admin/clients/{clientId}", func(r chi.Router) {
+				r.Method(tt.method, tt.pattern, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					action, target = auditActionFor(r)
+				}))
+			})
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, "/api/admin/clients/1"+tt.path, nil))
+
+			if action != tt.wantAction || target != tt.wantTarget {
+				t.Errorf("auditActionFor() = (%q, %q), want (%q, %q)", action, target, tt.wantAction, tt.wantTarget)
+			}
+		})
+	}
+}
+
+func TestRecordAudit_LongRequestID(t *testing.T) {
+	store := repository.NewMemoryAuditStore()
+	s := &Server{audits: store, logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	handler := requestid.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.recordAudit(r, &models.AuditEvent{Action: "themes.update", Target: "themes/3", StatusCode: http.StatusForbidden})
+	}))
+
+	req := httptest.NewRequest(http.MethodPut, "/api/admin/clients/acme/themes/3", nil)
+	req.Header.Set(requestid.Header, strings.Repeat("a", 128))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	events, total, err := store.List(t.Context(), repository.AuditFilter{})
+	if err != nil || total != 1 {
+		t.Fatalf("List() = %d events, %v; want the denied update recorded", total, err)
+	}
+	if id := events[0].RequestID; id != rec.Header().Get(requestid.Header) || len(id) > 100 {
+		t.Errorf("request_id = %q, want the id echoed to the client, at most 100 characters", id)
+	}
+}
@@ -0,0 +1,150 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"closeauth-frontend/internal/middleware"
+	"closeauth-frontend/internal/spring"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// clientLookupFunc returns the clients the admin behind userToken may access.
+type clientLookupFunc func(ctx context.Context, userToken string) ([]spring.OwnedClient, error)
+
+// clientAccessCache caches each session's accessible client IDs so the ownership
+// check does not cost a Spring round trip on every /admin/clients/{clientId} call.
+type clientAccessCache struct {
+	lookup clientLookupFunc
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]clientAccessEntry // keyed by Session.SessionID
+}
+
+// clientAccessEntry maps every accepted identifier of an accessible client
+// (internal id and OAuth client_id) to its OAuth client_id.
+type clientAccessEntry struct {
+	clients   map[string]string
+	expiresAt time.Time
+}
+
+// maxClientAccessEntries bounds the cache; expired entries are pruned beyond it.
+const maxClientAccessEntries = 10000
+
+func newClientAccessCache(lookup clientLookupFunc, ttl time.Duration) *clientAccessCache {
+	return &clientAccessCache{
+		lookup:  lookup,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]clientAccessEntry),
+	}
+}
+
+// CanAccess reports whether the session may access clientID, resolving and
+// caching the session's accessible clients on a miss.
+func (c *clientAccessCache) CanAccess(ctx context.Context, session *middleware.Session, clientID string) (bool, error) {
+	now := c.now()
+
+	c.mu.Lock()
+	entry, ok := c.entries[session.SessionID]
+	c.mu.Unlock()
+
+	if !ok || now.After(entry.expiresAt) {
+		clients, err := c.lookup(ctx, session.AccessToken)
+		if err != nil {
+			return false, err
+		}
+
+		// Both the internal id (used in Spring paths) and the OAuth client_id are accepted
+		entry = clientAccessEntry{
+			clients:   make(map[string]string, len(clients)*2),
+			expiresAt: now.Add(c.ttl),
+		}
+		for _, client := range clients {
+			for _, id := range []string{client.ID, client.ClientID} {
+				if id != "" {
+					entry.clients[id] = client.ClientID
+				}
+			}
+		}
+
+		if c.ttl > 0 && session.SessionID != "" {
+			c.mu.Lock()
+			if len(c.entries) >= maxClientAccessEntries {
+				c.pruneLocked(now)
+			}
+			c.entries[session.SessionID] = entry
+			c.mu.Unlock()
+		}
+	}
+
+	_, allowed := entry.clients[clientID]
+	return allowed, nil
+}
+
+// OAuthClientID returns the cached OAuth client_id for an accessible client, or "".
+func (c *clientAccessCache) OAuthClientID(sessionID, clientID string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.entries[sessionID].clients[clientID]
+}
+
+// Invalidate drops the cached clients for a session, e.g. after it creates a client.
+func (c *clientAccessCache) Invalidate(sessionID string) {
+	c.mu.Lock()
+	delete(c.entries, sessionID)
+	c.mu.Unlock()
+}
+
+func (c *clientAccessCache) pruneLocked(now time.Time) {
+	for id, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, id)
+		}
+	}
+}
+
+// RequireClientAccess rejects requests whose {clientId} URL parameter is not
+// owned by the logged-in admin, regardless of what Spring would allow.
+// Must run after RequireAuth.
+func (c *clientAccessCache) RequireClientAccess(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session, err := middleware.GetSession(r)
+		if err != nil {
+			jsonError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		clientID := chi.URLParam(r, "clientId")
+		allowed, err := c.CanAccess(r.Context(), session, clientID)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "failed to resolve accessible clients",
+				"component", "authz",
+				"user_id", session.UserID,
+				"error", err,
+			)
+			jsonError(w, "Unable to verify client access", http.StatusServiceUnavailable)
+			return
+		}
+
+		if !allowed {
+			slog.WarnContext(r.Context(), "client access denied",
+				"component", "authz",
+				"user_id", session.UserID,
+				"client_id", clientID,
+				"method", r.Method,
+				"path", r.URL.Path,
+			)
+			jsonError(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"closeauth-frontend/internal/middleware"
+	"closeauth-frontend/internal/spring"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func sessionCookies(t *testing.T) (*middleware.Session, []*http.Cookie) {
+	t.Helper()
+	session := &middleware.Session{
+		UserID:      "42",
+		Email:       "admin@example.com",
+		AccessToken: "user-jwt",
+		ExpiresAt:   time.Now().Add(time.Hour).Unix(),
+	}
+	rec := httptest.NewRecorder()
+	if err := middleware.SetSession(rec, session, false); err != nil {
+		t.Fatalf("SetSession() error = %v", err)
+	}
+	return session, rec.Result().Cookies()
+}
+
+func TestRequireClientAccess(t *testing.T) {
+	calls := 0
+	var lookupErr error
+	cache := newClientAccessCache(func(ctx context.Context, userToken string) ([]spring.OwnedClient, error) {
+		calls++
+		if userToken != "user-jwt" {
+			t.Errorf("lookup token = %q, want user-jwt", userToken)
+		}
+		return []spring.OwnedClient{{ID: "1", ClientID: "my-client"}}, lookupErr
+	}, time.Minute)
+
+	r := chi.NewRouter()
+	r.Route("/api/admin/clients/{clientId}", func(r chi.Router) {
+		r.Use(cache.RequireClientAccess)
+		r.Get("/roles", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})
+	})
+
+	session, cookies := sessionCookies(t)
+	do := func(clientID string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/admin/clients/"+clientID+"/roles", nil)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	tests := []struct {
+		name     string
+		clientID string
+		want     int
+	}{
+		{"owned internal id", "1", http.StatusNoContent},
+		{"owned client_id", "my-client", http.StatusNoContent},
+		{"other tenant", "2", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := do(tt.clientID); got != tt.want {
+				t.Errorf("status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	if calls != 1 {
+		t.Errorf("lookup called %d times, want 1 (cached)", calls)
+	}
+	if got := cache.OAuthClientID(session.SessionID, "1"); got != "my-client" {
+		t.Errorf("OAuthClientID() = %q, want my-client", got)
+	}
+
+	// Invalidation forces a fresh lookup, and lookup failures fail closed
+	cache.Invalidate(session.SessionID)
+	lookupErr = errors.New("spring down")
+	if got := do("1"); got != http.StatusServiceUnavailable {
+		t.Errorf("status on lookup failure = %d, want %d", got, http.StatusServiceUnavailable)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/clients/1/roles", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status without session = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestClientAccessCache_Expiry(t *testing.T) {
+	calls := 0
+	cache := newClientAccessCache(func(ctx context.Context, userToken string) ([]spring.OwnedClient, error) {
+		calls++
+		return []spring.OwnedClient{{ID: "1", ClientID: "my-client"}}, nil
+	}, time.Minute)
+
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	session := &middleware.Session{SessionID: "sid-1", AccessToken: "t"}
+
+	cache.CanAccess(context.Background(), session, "1")
+	now = now.Add(2 * time.Minute)
+	cache.CanAccess(context.Background(), session, "1")
+
+	if calls != 2 {
+		t.Errorf("lookup called %d times, want 2 after expiry", calls)
+	}
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -8,8 +9,12 @@ import (
 	"net/url"
 	"time"
 
+	"closeauth-frontend/internal/database/models"
 	"closeauth-frontend/internal/middleware"
+	"closeauth-frontend/internal/requestid"
 	"closeauth-frontend/internal/spring"
+
+	"github.com/go-chi/chi/v5"
 )
 
 // ──────────────────────────────────────────────────────────────────────────────
@@ -18,7 +23,7 @@ import (
 
 // handleAdminLoginImpl processes admin login and sets an encrypted session cookie.
 func (s *Server) handleAdminLoginImpl(w http.ResponseWriter, r *http.Request) {
-	logger := s.logger.With("handler", "admin_login")
+	logger := s.requestLogger(r, "admin_login")
 
 	var req struct {
 		Username string `json:"username"`
@@ -75,32 +80,65 @@ func (s *Server) handleAdminLoginImpl(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Roles/permissions come from the login response, falling back to the JWT claims
+	roles, explicitPermissions := loginResp.Roles, loginResp.Permissions
+	if len(roles) == 0 {
+		claims, err := spring.ParseUserTokenClaims(loginResp.AccessToken)
+		if err != nil {
+			logger.Warn("failed to read roles from user token", "error", err)
+		} else {
+			roles = claims.Roles
+			if len(explicitPermissions) == 0 {
+				explicitPermissions = claims.Permissions
+			}
+		}
+	}
+
+	permissions := middleware.ResolvePermissions(roles, explicitPermissions)
+	if len(permissions) == 0 {
+		logger.Warn("admin login rejected, no admin permissions", "user_id", loginResp.UserID, "roles", roles)
+		jsonError(w, "Your account is not authorized for the admin console", http.StatusForbidden)
+		return
+	}
+	role := middleware.PrimaryRole(roles)
+
 	// Login successful — set encrypted session cookie
 	session := &middleware.Session{
 		UserID:      fmt.Sprintf("%d", loginResp.UserID),
 		Email:       loginResp.Email,
 		Username:    req.Username,
-		Role:        "Admin",
+		Role:        role,
+		Roles:       roles,
+		Permissions: permissions,
 		AccessToken: loginResp.AccessToken,
 		ExpiresAt:   time.Now().Add(24 * time.Hour).Unix(),
 	}
 
-	if err := middleware.SetSession(w, session, s.springConfig.IsProduction()); err != nil {
+	if err := middleware.SetSession(w, session, s.secureCookies()); err != nil {
 		logger.Error("failed to set session", "error", err)
 		jsonError(w, "Failed to create session", http.StatusInternalServerError)
 		return
 	}
 
+	// Rotate CSRF token — pre-login tokens must not survive authentication
+	if _, err := middleware.RotateSessionCSRFToken(w, session); err != nil {
+		logger.Error("failed to rotate CSRF token", "error", err)
+		jsonError(w, "Failed to create session", http.StatusInternalServerError)
+		return
+	}
+
 	logger.Info("admin login successful", "email", loginResp.Email)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"user": map[string]interface{}{
-			"email":     loginResp.Email,
-			"username":  req.Username,
-			"firstName": loginResp.FirstName,
-			"lastName":  loginResp.LastName,
-			"role":      "Admin",
+			"email":       loginResp.Email,
+			"username":    req.Username,
+			"firstName":   loginResp.FirstName,
+			"lastName":    loginResp.LastName,
+			"role":        role,
+			"roles":       roles,
+			"permissions": permissions,
 		},
 	})
 }
@@ -186,19 +224,52 @@ func (s *Server) handleForgotPasswordResetImpl(w http.ResponseWriter, r *http.Re
 // the Spring response back to the client.
 func (s *Server) proxyToSpring(w http.ResponseWriter, r *http.Request, method, targetURL, userToken string) {
 	body, err := readBody(r)
-	s.logger.Info("Body :", string(body))
 	if err != nil {
 		jsonError(w, "Invalid request", http.StatusBadRequest)
 		return
 	}
 
+	// Mutations are audited with a redacted before/after summary
+	var audit *models.AuditEvent
+	if method != http.MethodGet {
+		action, target := auditActionFor(r)
+		audit = &models.AuditEvent{
+			TenantID: chi.URLParam(r, "clientId"),
+			Action:   action,
+			Target:   target,
+		}
+		if method == http.MethodPut || method == http.MethodDelete {
+			// Best effort: a slow read must not hold up the mutation itself
+			ctx, cancel := context.WithTimeout(r.Context(), auditSnapshotTimeout)
+			before, err := s.springClient.ProxyAdminAuth(ctx, http.MethodGet, targetURL, nil, userToken)
+			cancel()
+			if err == nil && before.StatusCode == http.StatusOK {
+				audit.Before = auditSummary(before.Body)
+			}
+		}
+		defer s.recordAudit(r, audit)
+	}
+
 	result, err := s.springClient.ProxyAdminAuth(r.Context(), method, targetURL, body, userToken)
 	if err != nil {
 		s.logger.Error("proxy to Spring failed", "url", targetURL, "error", err)
+		if audit != nil {
+			audit.StatusCode = http.StatusServiceUnavailable
+			audit.After = auditSummary(body)
+		}
 		jsonError(w, "Service unavailable", http.StatusServiceUnavailable)
 		return
 	}
 
+	if audit != nil {
+		audit.StatusCode = result.StatusCode
+		if result.StatusCode >= 200 && result.StatusCode < 300 {
+			audit.After = auditSummary(result.Body)
+		} else {
+			audit.After = auditSummary(body) // The change that was attempted
+		}
+	}
+
 	if result.StatusCode >= 300 && result.StatusCode < 400 {
 		s.logger.Error("unexpected redirect from Spring", "url", targetURL, "status", result.StatusCode)
 		jsonError(w, "Service temporarily unavailable", http.StatusServiceUnavailable)
@@ -230,8 +301,6 @@ func extractSpringErrorMessage(body []byte) string {
 		return ""
 	}
 
-	fmt.Println("unexpected response from Spring:", string(body))
-
 	var parsed struct {
 		// CustomApiResponse / generic
 		Message string `json:"message"`
@@ -259,10 +328,17 @@ func getUserToken(r *http.Request) string {
 	return session.AccessToken
 }
 
+// jsonError writes {"error": message}. The request id set on the response by
+// requestid.Middleware is echoed so users can quote it in support tickets.
 func jsonError(w http.ResponseWriter, message string, status int) {
+	body := map[string]string{"error": message}
+	if id := w.Header().Get(requestid.Header); id != "" {
+		body["request_id"] = id
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(map[string]string{"error": message})
+	json.NewEncoder(w).Encode(body)
 }
 
 func readBody(r *http.Request) ([]byte, error) {
@@ -0,0 +1,199 @@
+package server
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"closeauth-frontend/internal/database/models"
+	"closeauth-frontend/internal/database/repository"
+	"closeauth-frontend/internal/middleware"
+)
+
+// ──────────────────────────────────────────────────────────────────────────────
+// Admin Audit Log Handlers
+//
+// Query parameters (all optional): actor, tenant, action, outcome,
+// from/to (RFC 3339), page (1-based) and size.
+// ──────────────────────────────────────────────────────────────────────────────
+
+const (
+	auditDefaultPageSize = 50
+	auditMaxPageSize     = 200
+	auditMaxPage         = 1 << 20 // keeps the offset, (page-1)*size, far from overflowing
+	auditExportLimit     = 10000
+)
+
+func (s *Server) handleListAudit(w http.ResponseWriter, r *http.Request) {
+	audit, filter, ok := s.auditFilter(w, r)
+	if !ok {
+		return
+	}
+
+	page := queryInt(r, "page", 1)
+	size := queryInt(r, "size", auditDefaultPageSize)
+	if page < 1 || page > auditMaxPage || size < 1 || size > auditMaxPageSize {
+		jsonError(w, fmt.Sprintf("page must be between 1 and %d and size between 1 and %d", auditMaxPage, auditMaxPageSize), http.StatusBadRequest)
+		return
+	}
+	filter.Limit = size
+	filter.Offset = (page - 1) * size
+
+	events, total, err := audit.List(r.Context(), filter)
+	if err != nil {
+		s.logger.Error("failed to list audit events", "handler", "admin_audit", "error", err)
+		jsonError(w, "Failed to load audit log", http.StatusInternalServerError)
+		return
+	}
+	if events == nil {
+		events = []models.AuditEvent{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"items": events,
+		"page":  page,
+		"size":  size,
+		"total": total,
+	})
+}
+
+func (s *Server) handleExportAudit(w http.ResponseWriter, r *http.Request) {
+	audit, filter, ok := s.auditFilter(w, r)
+	if !ok {
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "json" {
+		jsonError(w, "format must be csv or json", http.StatusBadRequest)
+		return
+	}
+
+	filter.Limit = auditExportLimit
+	events, _, err := audit.List(r.Context(), filter)
+	if err != nil {
+		s.logger.Error("failed to export audit events", "handler", "admin_audit_export", "error", err)
+		jsonError(w, "Failed to export audit log", http.StatusInternalServerError)
+		return
+	}
+
+	filename := fmt.Sprintf("audit-%s.%s", time.Now().UTC().Format("20060102T150405Z"), format)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	if format == "json" {
+		if events == nil {
+			events = []models.AuditEvent{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(events)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	writeAuditCSV(w, events)
+}
+
+func (s *Server) handleVerifyAudit(w http.ResponseWriter, r *http.Request) {
+	audit := s.auditStore()
+	if audit == nil {
+		jsonError(w, "Audit log unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	brokenAt, err := audit.VerifyChain(r.Context())
+	if err != nil {
+		s.logger.Error("failed to verify audit chain", "handler", "admin_audit_verify", "error", err)
+		jsonError(w, "Failed to verify audit log", http.StatusInternalServerError)
+		return
+	}
+	if brokenAt != 0 {
+		s.logger.Error("audit chain broken", "handler", "admin_audit_verify", "event_id", brokenAt)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"intact":    brokenAt == 0,
+		"broken_at": brokenAt,
+	})
+}
+
+// auditFilter returns the audit store and parses the shared query
+// parameters. Admins without full access are limited to their own actions.
+// It writes an error response and returns false when the request cannot be
+// served.
+func (s *Server) auditFilter(w http.ResponseWriter, r *http.Request) (repository.AuditStore, repository.AuditFilter, bool) {
+	var filter repository.AuditFilter
+
+	audit := s.auditStore()
+	if audit == nil {
+		jsonError(w, "Audit log unavailable", http.StatusServiceUnavailable)
+		return nil, filter, false
+	}
+
+	session, err := middleware.GetSession(r)
+	if err != nil {
+		jsonError(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, filter, false
+	}
+
+	q := r.URL.Query()
+	filter.ActorID = q.Get("actor")
+	filter.TenantID = q.Get("tenant")
+	filter.Action = q.Get("action")
+	filter.Outcome = q.Get("outcome")
+	if !session.HasPermission(middleware.PermAll) {
+		filter.ActorID = session.UserID
+	}
+
+	for name, dst := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		if raw := q.Get(name); raw != "" {
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				jsonError(w, fmt.Sprintf("%s must be an RFC 3339 timestamp", name), http.StatusBadRequest)
+				return nil, filter, false
+			}
+			*dst = t
+		}
+	}
+
+	return audit, filter, true
+}
+
+var auditCSVHeader = []string{
+	"id", "occurred_at", "actor_id", "actor_email", "tenant_id", "client_id", "action", "target",
+	"request_id", "ip_address", "outcome", "status_code", "before", "after", "prev_hash", "hash",
+}
+
+func writeAuditCSV(w http.ResponseWriter, events []models.AuditEvent) {
+	cw := csv.NewWriter(w)
+	cw.Write(auditCSVHeader)
+	for _, e := range events {
+		cw.Write([]string{
+			strconv.FormatInt(e.ID, 10),
+			e.OccurredAt.UTC().Format(time.RFC3339Nano),
+			e.ActorID, e.ActorEmail, e.TenantID, e.ClientID, e.Action, e.Target,
+			e.RequestID, e.IPAddress, e.Outcome, strconv.Itoa(e.StatusCode),
+			string(e.Before), string(e.After), e.PrevHash, e.Hash,
+		})
+	}
+	cw.Flush()
+}
+
+func queryInt(r *http.Request, name string, fallback int) int {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return fallback
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil {
+		return -1
+	}
+	return v
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"closeauth-frontend/internal/database/models"
+	"closeauth-frontend/internal/database/repository"
+	"closeauth-frontend/internal/middleware"
+)
+
+func TestAuditHandlers(t *testing.T) {
+	store := repository.NewMemoryAuditStore()
+	s := &Server{audits: store, logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+
+	// recordAudit fills in the actor from the session (synchronously without a queue)
+	session, cookies := sessionCookies(t)
+	req := httptest.NewRequest(http.MethodPut, "/api/admin/roles/3", nil)
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	s.recordAudit(req, &models.AuditEvent{Action: "roles.update", Target: "roles/3", StatusCode: http.StatusOK})
+	s.recordAudit(req, &models.AuditEvent{Action: "roles.delete", Target: "roles/3", StatusCode: http.StatusForbidden})
+	if err := store.Append(req.Context(), &models.AuditEvent{OccurredAt: time.Now(), ActorID: "7", Action: "themes.create", Outcome: models.AuditOutcomeSuccess}); err != nil {
+		t.Fatal(err)
+	}
+
+	get := func(handler http.HandlerFunc, path string, permissions ...string) *httptest.ResponseRecorder {
+		session.Permissions = permissions
+		rec := httptest.NewRecorder()
+		if err := middleware.SetSession(rec, session, false); err != nil {
+			t.Fatal(err)
+		}
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for _, c := range rec.Result().Cookies() {
+			req.AddCookie(c)
+		}
+		rec = httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+
+	tests := []struct {
+		name        string
+		path        string
+		permissions []string
+		wantActions []string
+		wantTotal   int
+	}{
+		{"own actions only", "/api/admin/audit", nil, []string{"roles.delete", "roles.update"}, 2},
+		{"full access", "/api/admin/audit", []string{middleware.PermAll}, []string{"themes.create", "roles.delete", "roles.update"}, 3},
+		{"filtered", "/api/admin/audit?outcome=failure", []string{middleware.PermAll}, []string{"roles.delete"}, 1},
+		{"paged", "/api/admin/audit?size=1&page=2", []string{middleware.PermAll}, []string{"roles.delete"}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := get(s.handleListAudit, tt.path, tt.permissions...)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d %s", rec.Code, rec.Body)
+			}
+			var body struct {
+				Items []models.AuditEvent `json:"items"`
+				Total int                 `json:"total"`
+			}
+			json.NewDecoder(rec.Body).Decode(&body)
+			var actions []string
+			for _, e := range body.Items {
+				actions = append(actions, e.Action)
+			}
+			if strings.Join(actions, ",") != strings.Join(tt.wantActions, ",") || body.Total != tt.wantTotal {
+				t.Errorf("list = %v (total %d), want %v (total %d)", actions, body.Total, tt.wantActions, tt.wantTotal)
+			}
+		})
+	}
+
+	if rec := get(s.handleListAudit, "/api/admin/audit?from=yesterday"); rec.Code != http.StatusBadRequest {
+		t.Errorf("bad from = %d, want 400", rec.Code)
+	}
+	if rec := get(s.handleListAudit, "/api/admin/audit?size=200&page=9223372036854775807", middleware.PermAll); rec.Code != http.StatusBadRequest {
+		t.Errorf("huge page = %d, want 400", rec.Code)
+	}
+
+	rec := get(s.handleExportAudit, "/api/admin/audit/export", middleware.PermAll)
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if rec.Header().Get("Content-Type") != "text/csv; charset=utf-8" || len(lines) != 4 || !strings.HasPrefix(lines[0], "id,occurred_at") {
+		t.Errorf("CSV export = %q, want a header and three rows", lines)
+	}
+	if !strings.Contains(lines[2], "admin@example.com") {
+		t.Errorf("CSV row = %q, want the actor's email", lines[2])
+	}
+
+	rec = get(s.handleVerifyAudit, "/api/admin/audit/verify", middleware.PermAll)
+	if body := strings.TrimSpace(rec.Body.String()); body != `{"broken_at":0,"intact":true}` {
+		t.Errorf("verify = %s, want an intact chain", body)
+	}
+}
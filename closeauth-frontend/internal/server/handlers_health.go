@@ -0,0 +1,136 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"closeauth-frontend/internal/config"
+	"closeauth-frontend/internal/health"
+)
+
+// ──────────────────────────────────────────────────────────────────────────────
+// Liveness / Readiness Probes
+//
+// /healthz only proves the process is serving requests. /readyz (and the
+// legacy /api/health) returns 503 when a critical dependency is unavailable
+// but reports only the overall status; the per-dependency breakdown, with
+// URLs and error text, is served on the ops listener.
+// ──────────────────────────────────────────────────────────────────────────────
+
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(map[string]string{"status": string(health.StatusUp)})
+}
+
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	report := s.health.Report(r.Context())
+	writeReadiness(w, report, map[string]string{"status": string(report.Status)})
+}
+
+// handleReadyzDetails is /readyz with each component's result, for the ops
+// listener only.
+func (s *Server) handleReadyzDetails(w http.ResponseWriter, r *http.Request) {
+	report := s.health.Report(r.Context())
+	writeReadiness(w, report, report)
+}
+
+func writeReadiness(w http.ResponseWriter, report *health.Report, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if !report.Ready() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(body)
+}
+
+// newHealthChecker wires the readiness checks for the server's dependencies.
+func (s *Server) newHealthChecker(cfg *config.HealthConfig) *health.Checker {
+	return health.NewChecker(cfg.CacheTTL, cfg.CheckTimeout,
+		health.Check{Name: "spring", Critical: true, Run: s.checkSpring},
+		health.Check{Name: "token", Critical: true, Run: s.checkToken},
+		health.Check{Name: "jwks", Critical: true, Run: s.checkJWKS},
+		health.Check{Name: "discovery", Run: s.checkDiscovery},
+		health.Check{Name: "database", Critical: cfg.DatabaseRequired, Run: s.checkDatabase},
+	)
+}
+
+func (s *Server) checkSpring(ctx context.Context) health.Result {
+	if err := s.springClient.CheckReachable(ctx); err != nil {
+		return health.Down(err)
+	}
+	return health.Up(map[string]any{"url": s.springConfig.OAuth2ServerURL})
+}
+
+// checkToken goes through TokenManager, so a cached client_credentials token
+// satisfies it without a call to Spring.
+func (s *Server) checkToken(ctx context.Context) health.Result {
+	if _, err := s.springClient.GetAccessToken(ctx); err != nil {
+		return health.Down(err)
+	}
+	return health.Up(nil)
+}
+
+func (s *Server) checkJWKS(ctx context.Context) health.Result {
+	keys, err := s.springClient.FetchJWKS(ctx)
+	if err != nil {
+		return health.Down(err)
+	}
+	return health.Up(map[string]any{"keys": keys})
+}
+
+// checkDiscovery never fails readiness: without discovery the BFF runs on
+// env-var defaults, which is worth surfacing but still serves traffic.
+// Discovery only runs at startup, so its age is reported but not judged.
+func (s *Server) checkDiscovery(context.Context) health.Result {
+	d := s.springConfig.Discovered
+	if d == nil || d.FetchedAt.IsZero() {
+		return health.Result{Status: health.StatusDegraded, Error: "discovery has not run"}
+	}
+
+	details := map[string]any{
+		"fetched_at":  d.FetchedAt.UTC().Format(time.RFC3339),
+		"age_seconds": int64(time.Since(d.FetchedAt).Seconds()),
+	}
+	if d.OIDC != nil {
+		details["issuer"] = d.OIDC.Issuer
+	}
+
+	if !d.Available {
+		return health.Result{Status: health.StatusDegraded, Error: "discovery incomplete, using env-var defaults", Details: details}
+	}
+	return health.Up(details)
+}
+
+// checkDatabase reports the supervisor's state. While the database is down
+// the supervisor keeps reconnecting, so an optional database only disables
+// theme features until it is back.
+func (s *Server) checkDatabase(ctx context.Context) health.Result {
+	status := s.db.Status()
+	details := map[string]any{"state": status.State}
+	if !status.Since.IsZero() {
+		details["since"] = status.Since.UTC().Format(time.RFC3339)
+	}
+	if status.Attempts > 0 {
+		details["reconnect_attempts"] = status.Attempts
+	}
+
+	db := s.db.DB()
+	if db == nil {
+		msg := fmt.Sprintf("database %s", status.State)
+		if status.LastError != "" {
+			msg += ": " + status.LastError
+		}
+		if s.healthCfg.DatabaseRequired {
+			return health.Result{Status: health.StatusDown, Error: msg, Details: details}
+		}
+		return health.Result{Status: health.StatusDisabled, Error: msg + ", theme features disabled", Details: details}
+	}
+	if err := db.PingContext(ctx); err != nil {
+		return health.Result{Status: health.StatusDown, Error: err.Error(), Details: details}
+	}
+	return health.Up(details)
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"closeauth-frontend/internal/config"
+	"closeauth-frontend/internal/health"
+	"closeauth-frontend/internal/spring"
+)
+
+func TestCheckDiscovery(t *testing.T) {
+	fetched := time.Now().Add(-48 * time.Hour)
+
+	tests := []struct {
+		name       string
+		discovered *spring.DiscoveredConfig
+		want       health.Status
+	}{
+		{"not run", nil, health.StatusDegraded},
+		{"incomplete", &spring.DiscoveredConfig{FetchedAt: fetched}, health.StatusDegraded},
+		// Discovery only runs at startup, so an old result is still up
+		{"available", &spring.DiscoveredConfig{Available: true, FetchedAt: fetched, OIDC: &spring.OIDCDiscovery{}}, health.StatusUp},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Server{springConfig: &spring.Config{Discovered: tt.discovered}}
+			if got := s.checkDiscovery(context.Background()); got.Status != tt.want {
+				t.Errorf("checkDiscovery() = %q (%s), want %q", got.Status, got.Error, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckDatabase_NotConnected(t *testing.T) {
+	optional := &Server{healthCfg: &config.HealthConfig{}}
+	if got := optional.checkDatabase(context.Background()); got.Status != health.StatusDisabled {
+		t.Errorf("optional database: status = %q, want %q", got.Status, health.StatusDisabled)
+	}
+
+	required := &Server{healthCfg: &config.HealthConfig{DatabaseRequired: true}}
+	if got := required.checkDatabase(context.Background()); got.Status != health.StatusDown {
+		t.Errorf("required database: status = %q, want %q", got.Status, health.StatusDown)
+	}
+}
+
+func TestHandleReadyz_PublicStatusOnly(t *testing.T) {
+	s := &Server{health: health.NewChecker(0, time.Second,
+		health.Check{Name: "spring", Critical: true, Run: func(context.Context) health.Result {
+			return health.Down(errors.New("dial tcp 10.0.0.5:9000: connection refused"))
+		}},
+	)}
+
+	rec := httptest.NewRecorder()
+	s.handleReadyz(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("public status = %d, want 503", rec.Code)
+	}
+	if body := strings.TrimSpace(rec.Body.String()); body != `{"status":"down"}` {
+		t.Errorf("public body = %s, want only the status", body)
+	}
+
+	rec = httptest.NewRecorder()
+	s.handleReadyzDetails(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable || !strings.Contains(rec.Body.String(), "10.0.0.5") {
+		t.Errorf("ops readyz = %d %s, want 503 with the component error", rec.Code, rec.Body)
+	}
+}
@@ -1,12 +1,20 @@
 package server
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
+	"closeauth-frontend/internal/database/models"
+	"closeauth-frontend/internal/database/repository"
+	"closeauth-frontend/internal/metrics"
 	"closeauth-frontend/internal/middleware"
+	"closeauth-frontend/internal/themecss"
 )
 
 // ──────────────────────────────────────────────────────────────────────────────
@@ -16,7 +24,7 @@ import (
 // handleOAuthLoginImpl processes OAuth login form submission from the Vue SPA.
 // Returns JSON with redirect_url — Vue does window.location.href to resume the OAuth flow.
 func (s *Server) handleOAuthLoginImpl(w http.ResponseWriter, r *http.Request) {
-	logger := s.logger.With("handler", "oauth_login")
+	logger := s.requestLogger(r, "oauth_login")
 
 	// Parse JSON body
 	var req struct {
@@ -33,12 +41,13 @@ func (s *Server) handleOAuthLoginImpl(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	logger.Debug("[OAuthLogin] incoming login request", "body", req)
+	logger.Debug("[OAuthLogin] incoming login request", "username", req.Username)
 
 	// Get OAuth context for JSESSIONID
 	oauthCtx, err := middleware.GetOAuthContext(r)
 	if err != nil {
 		logger.Warn("no OAuth context found for login", "error", err)
+		metrics.OAuthEvent(metrics.OAuthContextExpired)
 		jsonError(w, "OAuth session expired. Please restart the authorization flow.", http.StatusBadRequest)
 		return
 	}
@@ -69,7 +78,7 @@ func (s *Server) handleOAuthLoginImpl(w http.ResponseWriter, r *http.Request) {
 		)
 
 	// Forward Spring cookies to browser
-	forwardSpringCookies(w, result.Cookies, s.springConfig.IsProduction())
+	forwardSpringCookies(w, result.Cookies, s.secureCookies())
 
 	// Handle authentication failure
 	if result.StatusCode == http.StatusUnauthorized || result.StatusCode == http.StatusForbidden {
@@ -87,6 +96,7 @@ func (s *Server) handleOAuthLoginImpl(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 		logger.Warn("OAuth login failed", "username", req.Username, "status", result.StatusCode)
+		metrics.OAuthEvent(metrics.OAuthLoginFailure)
 		jsonError(w, errorMsg, http.StatusUnauthorized)
 		return
 	}
@@ -94,11 +104,13 @@ func (s *Server) handleOAuthLoginImpl(w http.ResponseWriter, r *http.Request) {
 	// Authentication successful (200, 302, 303)
 	if result.StatusCode != http.StatusOK && result.StatusCode != http.StatusFound && result.StatusCode != http.StatusSeeOther {
 		logger.Error("unexpected response from Spring login", "status", result.StatusCode)
+		metrics.OAuthEvent(metrics.OAuthLoginFailure)
 		jsonError(w, "Authentication failed", http.StatusInternalServerError)
 		return
 	}
 
 	logger.Info("OAuth login successful", "username", req.Username, "client_id", oauthCtx.ClientID)
+	metrics.OAuthEvent(metrics.OAuthLoginSuccess)
 
 	// Update OAuth context with username and new JSESSIONID
 	oauthCtx.Username = req.Username
@@ -109,8 +121,12 @@ func (s *Server) handleOAuthLoginImpl(w http.ResponseWriter, r *http.Request) {
 			break
 		}
 	}
-	if err := middleware.SaveOAuthContext(w, oauthCtx, s.springConfig.IsProduction()); err != nil {
+	// New flow id rotates the flow-bound CSRF token after authentication
+	oauthCtx.FlowID = ""
+	if err := middleware.SaveOAuthContext(w, oauthCtx, s.secureCookies()); err != nil {
 		logger.Warn("failed to update OAuth context", "error", err)
+	} else if _, err := middleware.RotateFlowCSRFToken(w, oauthCtx); err != nil {
+		logger.Warn("failed to rotate CSRF token", "error", err)
 	}
 
 	// Build redirect URL back to /closeauth/oauth2/authorize to continue the flow
@@ -130,12 +146,18 @@ func (s *Server) handleOAuthLoginImpl(w http.ResponseWriter, r *http.Request) {
 }
 
 // handleOAuthThemeImpl returns theme data for a client_id from the database.
+// With a valid preview token it returns the previewed theme instead, flagged
+// with "preview" so the page can show a banner.
 func (s *Server) handleOAuthThemeImpl(w http.ResponseWriter, r *http.Request) {
 	clientID := r.URL.Query().Get("client_id")
 	if clientID == "" {
 		jsonError(w, "client_id is required", http.StatusBadRequest)
 		return
 	}
+	grant, ok := s.themePreviewGrant(w, r, clientID)
+	if !ok {
+		return
+	}
 
 	// Fetch client info from Spring for client name/logo
 	var clientName, logoURL string
@@ -153,38 +175,34 @@ func (s *Server) handleOAuthThemeImpl(w http.ResponseWriter, r *http.Request) {
 		clientName = clientID
 	}
 
+	if grant != nil {
+		preview, ok := s.loadThemePreview(w, r, *grant)
+		if !ok {
+			return
+		}
+		token := r.URL.Query().Get(themePreviewParam)
+		resp := oauthThemeResponse(clientID, clientName, logoURL, preview.theme, themePreviewStylesheetURL(clientID, token))
+		resp["preview"] = true
+		resp["preview_draft"] = preview.draft
+		resp["preview_expires_at"] = grant.ExpiresAt.UTC()
+
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+		return
+	}
+
 	// Try to get from DB
-	if s.themeRepo != nil {
-		theme, err := s.themeRepo.FindDefaultTheme(r.Context(), clientID)
+	if themes := s.themeStore(); themes != nil {
+		theme, err := themes.FindDefaultTheme(r.Context(), clientID)
 		if err == nil {
-			// Use logo from Spring if available, else from theme DB
-			themeLogoURL := ""
-			if theme.LogoURL != nil {
-				themeLogoURL = *theme.LogoURL
-			}
-			if logoURL == "" {
-				logoURL = themeLogoURL
+			// Only a complete render may be advertised under an immutable version
+			stylesheetURL := themeStylesheetURL(clientID, nil)
+			if css, cacheable := s.renderThemeStylesheet(r.Context(), themes, theme); cacheable {
+				stylesheetURL = themeStylesheetURL(clientID, css)
 			}
-
-			defaultMode := "light"
-			if theme.DefaultMode != nil {
-				defaultMode = *theme.DefaultMode
-			}
-
-			resp := map[string]interface{}{
-				"client_id":         clientID,
-				"client_name":       clientName,
-				"logo_url":          logoURL,
-				"default_mode":      defaultMode,
-				"allow_mode_toggle": theme.AllowModeToggle,
-				"colors": map[string]interface{}{
-					"light": theme.GetLightColors(),
-					"dark":  theme.GetDarkColors(),
-				},
-			}
-
 			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(resp)
+			json.NewEncoder(w).Encode(oauthThemeResponse(clientID, clientName, logoURL, theme, stylesheetURL))
 			return
 		}
 		s.logger.Debug("no theme found in DB, using defaults", "client_id", clientID)
@@ -199,20 +217,68 @@ func (s *Server) handleOAuthThemeImpl(w http.ResponseWriter, r *http.Request) {
 		"default_mode":      "light",
 		"allow_mode_toggle": true,
 		"colors": map[string]interface{}{
-			"light": map[string]string{"primary": "#3b82f6", "background": "#ffffff", "button": "#3b82f6", "text": "#1f2937"},
-			"dark":  map[string]string{"primary": "#60a5fa", "background": "#1f2937", "button": "#3b82f6", "text": "#f9fafb"},
+			"light": themecss.DefaultLight,
+			"dark":  themecss.DefaultDark,
 		},
+		"stylesheet_url": themeStylesheetURL(clientID, themecss.Render(nil, nil, nil)),
+		"preview":        false,
 	})
 }
 
+// handleOAuthThemeCSSImpl serves a client's theme as a stylesheet so hosted
+// login pages are styled before the SPA has loaded. The stylesheet_url from
+// /api/oauth/theme carries the content version (v=) and is cached for a year;
+// other URLs are revalidated hourly against the strong ETag. Previews are
+// never cached.
+func (s *Server) handleOAuthThemeCSSImpl(w http.ResponseWriter, r *http.Request) {
+	clientID := r.URL.Query().Get("client_id")
+	if clientID == "" {
+		jsonError(w, "client_id is required", http.StatusBadRequest)
+		return
+	}
+	grant, ok := s.themePreviewGrant(w, r, clientID)
+	if !ok {
+		return
+	}
+
+	if grant != nil {
+		preview, ok := s.loadThemePreview(w, r, *grant)
+		if !ok {
+			return
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Content-Type", "text/css; charset=utf-8")
+		w.Write(themecss.Render(preview.theme, preview.configs, s.themeAssetOrigins()))
+		return
+	}
+
+	css, cacheable := s.themeStylesheet(r.Context(), clientID)
+	version := themecss.Version(css)
+	switch {
+	case !cacheable:
+		// A fallback to the built-in theme while the database is unavailable
+		w.Header().Set("Cache-Control", "no-cache")
+	case r.URL.Query().Get("v") == version:
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	default:
+		w.Header().Set("Cache-Control", "public, max-age=3600, stale-while-revalidate=86400")
+	}
+	w.Header().Set("ETag", `"`+version+`"`)
+	w.Header().Set("Content-Type", "text/css; charset=utf-8")
+
+	// Answers If-None-Match with 304 Not Modified
+	http.ServeContent(w, r, "theme.css", time.Time{}, bytes.NewReader(css))
+}
+
 // handleOAuthConsentDataImpl returns consent page data as JSON for the Vue SPA to render.
 func (s *Server) handleOAuthConsentDataImpl(w http.ResponseWriter, r *http.Request) {
-	logger := s.logger.With("handler", "consent_data")
+	logger := s.requestLogger(r, "consent_data")
 
 	// Read OAuth context
 	oauthCtx, err := middleware.GetOAuthContext(r)
 	if err != nil {
 		logger.Warn("no OAuth context for consent data", "error", err)
+		metrics.OAuthEvent(metrics.OAuthContextExpired)
 		jsonError(w, "OAuth session expired", http.StatusBadRequest)
 		return
 	}
@@ -243,11 +309,8 @@ func (s *Server) handleOAuthConsentDataImpl(w http.ResponseWriter, r *http.Reque
 		}
 	}
 
-	// Get CSRF token for the consent form
-	csrfToken := ""
-	if cookie, err := r.Cookie(middleware.CSRFCookieName); err == nil {
-		csrfToken = cookie.Value
-	}
+	// Get CSRF token for the consent form (bound to this OAuth flow)
+	csrfToken := middleware.CSRFToken(r)
 
 	// Build response
 	w.Header().Set("Content-Type", "application/json")
@@ -312,6 +375,83 @@ func (s *Server) handleOAuthResendOTPImpl(w http.ResponseWriter, r *http.Request
 // Helpers
 // ──────────────────────────────────────────────────────────────────────────────
 
+// oauthThemeResponse is the /api/oauth/theme body for theme. logoURL, from the
+// client's registration, takes precedence over the theme's logo.
+func oauthThemeResponse(clientID, clientName, logoURL string, theme *models.ClientTheme, stylesheetURL string) map[string]interface{} {
+	if logoURL == "" && theme.LogoURL != nil {
+		logoURL = *theme.LogoURL
+	}
+	defaultMode := "light"
+	if theme.DefaultMode != nil {
+		defaultMode = *theme.DefaultMode
+	}
+	return map[string]interface{}{
+		"client_id":         clientID,
+		"client_name":       clientName,
+		"logo_url":          logoURL,
+		"default_mode":      defaultMode,
+		"allow_mode_toggle": theme.AllowModeToggle,
+		"colors": map[string]interface{}{
+			"light": theme.GetLightColors(),
+			"dark":  theme.GetDarkColors(),
+		},
+		"stylesheet_url": stylesheetURL,
+		"preview":        false,
+	}
+}
+
+// themeStylesheet renders the stylesheet of a client's active default theme,
+// or the built-in theme when it has none. cacheable is false when the theme
+// could not be read and the built-in theme stands in for it.
+func (s *Server) themeStylesheet(ctx context.Context, clientID string) (css []byte, cacheable bool) {
+	themes := s.themeStore()
+	if themes == nil {
+		return themecss.Render(nil, nil, nil), false
+	}
+	theme, err := themes.FindDefaultTheme(ctx, clientID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return themecss.Render(nil, nil, nil), true
+	}
+	if err != nil {
+		s.logger.Warn("failed to load theme for stylesheet", "client_id", clientID, "error", err)
+		return themecss.Render(nil, nil, nil), false
+	}
+	return s.renderThemeStylesheet(ctx, themes, theme)
+}
+
+// renderThemeStylesheet renders theme with its custom CSS configuration.
+func (s *Server) renderThemeStylesheet(ctx context.Context, themes repository.ThemeStore, theme *models.ClientTheme) ([]byte, bool) {
+	configs, err := themes.FindConfigurationsByThemeID(ctx, theme.ID)
+	if err != nil {
+		s.logger.Warn("failed to load theme configuration for stylesheet", "theme_id", theme.ID, "error", err)
+		return themecss.Render(theme, nil, nil), false
+	}
+	return themecss.Render(theme, configs, s.themeAssetOrigins()), true
+}
+
+// themeAssetOrigins are the absolute origins custom theme CSS may load from:
+// the BFF's own.
+func (s *Server) themeAssetOrigins() []string {
+	if s.springConfig == nil {
+		return nil
+	}
+	u, err := url.Parse(s.springConfig.BFFBaseURL)
+	if err != nil || u.Host == "" {
+		return nil
+	}
+	return []string{u.Scheme + "://" + u.Host}
+}
+
+// themeStylesheetURL is the versioned, long-cacheable URL of css, or the
+// unversioned, revalidated URL of the client's stylesheet when css is nil.
+func themeStylesheetURL(clientID string, css []byte) string {
+	query := url.Values{"client_id": {clientID}}
+	if css != nil {
+		query.Set("v", themecss.Version(css))
+	}
+	return "/api/oauth/theme.css?" + query.Encode()
+}
+
 func splitScopes(scope string) []string {
 	if scope == "" {
 		return []string{}
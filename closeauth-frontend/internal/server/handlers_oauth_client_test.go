@@ -0,0 +1,149 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"closeauth-frontend/internal/database/models"
+	"closeauth-frontend/internal/database/repository"
+)
+
+func TestHandleOAuthTheme(t *testing.T) {
+	dark := "dark"
+	primary := "#ff0000"
+	store := repository.NewMemoryThemeStore()
+	if _, err := store.AddTheme(models.ClientTheme{
+		ClientID: "acme", ThemeName: "default", IsActive: true, IsDefault: true,
+		LightPrimaryColor: &primary, DefaultMode: &dark,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{themes: store, logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+
+	tests := []struct {
+		clientID    string
+		wantMode    string
+		wantPrimary string
+	}{
+		{"acme", "dark", "#ff0000"},
+		{"unknown", "light", "#3b82f6"}, // built-in defaults
+	}
+	for _, tt := range tests {
+		t.Run(tt.clientID, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			s.handleOAuthThemeImpl(rec, httptest.NewRequest(http.MethodGet, "/api/oauth/theme?client_id="+tt.clientID, nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+			}
+
+			var body struct {
+				ClientName  string `json:"client_name"`
+				DefaultMode string `json:"default_mode"`
+				Colors      struct {
+					Light struct {
+						Primary string `json:"primary"`
+					} `json:"light"`
+				} `json:"colors"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if body.ClientName != tt.clientID || body.DefaultMode != tt.wantMode || body.Colors.Light.Primary != tt.wantPrimary {
+				t.Errorf("response = %+v, want mode %q and primary %q", body, tt.wantMode, tt.wantPrimary)
+			}
+		})
+	}
+}
+
+func TestHandleOAuthThemeCSS(t *testing.T) {
+	primary := "#ff0000"
+	store := repository.NewMemoryThemeStore()
+	theme, err := store.AddTheme(models.ClientTheme{ClientID: "acme", ThemeName: "default", IsActive: true, IsDefault: true, LightPrimaryColor: &primary})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.AddConfiguration(models.ThemeConfiguration{
+		ThemeID: theme.ID, ConfigKey: "custom", ConfigType: "css",
+		ConfigValue: `.btn { background: url(https://evil.test/x.png) }`,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{themes: store, logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+
+	get := func(target, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rec := httptest.NewRecorder()
+		s.handleOAuthThemeCSSImpl(rec, req)
+		return rec
+	}
+
+	rec := get("/api/oauth/theme.css?client_id=acme", "")
+	body := rec.Body.String()
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/css; charset=utf-8" {
+		t.Fatalf("status = %d, content type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(body, "--theme-primary: #ff0000;") || !strings.Contains(body, ".btn { background: none }") {
+		t.Errorf("stylesheet = %s, want the theme colour and sanitized custom CSS", body)
+	}
+	etag := rec.Header().Get("ETag")
+	if etag == "" || strings.HasPrefix(etag, "W/") {
+		t.Fatalf("ETag = %q, want a strong ETag", etag)
+	}
+	if cc := rec.Header().Get("Cache-Control"); strings.Contains(cc, "immutable") {
+		t.Errorf("unversioned Cache-Control = %q, want revalidation", cc)
+	}
+
+	if rec := get("/api/oauth/theme.css?client_id=acme", etag); rec.Code != http.StatusNotModified {
+		t.Errorf("If-None-Match status = %d, want 304", rec.Code)
+	}
+
+	// The JSON theme links the versioned stylesheet, which is immutable
+	rec = httptest.NewRecorder()
+	s.handleOAuthThemeImpl(rec, httptest.NewRequest(http.MethodGet, "/api/oauth/theme?client_id=acme", nil))
+	var resp struct {
+		StylesheetURL string `json:"stylesheet_url"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if want := strings.Trim(etag, `"`); !strings.Contains(resp.StylesheetURL, "v="+want) {
+		t.Fatalf("stylesheet_url = %q, want version %s", resp.StylesheetURL, want)
+	}
+	if cc := get(resp.StylesheetURL, "").Header().Get("Cache-Control"); !strings.Contains(cc, "immutable") {
+		t.Errorf("versioned Cache-Control = %q, want immutable", cc)
+	}
+
+	if rec := get("/api/oauth/theme.css", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("missing client_id status = %d, want 400", rec.Code)
+	}
+
+	// A fallback render is never advertised under an immutable version
+	s.themes = configErrorStore{store}
+	rec = httptest.NewRecorder()
+	s.handleOAuthThemeImpl(rec, httptest.NewRequest(http.MethodGet, "/api/oauth/theme?client_id=acme", nil))
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StylesheetURL != "/api/oauth/theme.css?client_id=acme" {
+		t.Errorf("stylesheet_url after a configuration error = %q, want it unversioned", resp.StylesheetURL)
+	}
+}
+
+// configErrorStore fails every configuration read.
+type configErrorStore struct {
+	repository.ThemeStore
+}
+
+func (configErrorStore) FindConfigurationsByThemeID(context.Context, int) ([]models.ThemeConfiguration, error) {
+	return nil, errors.New("connection reset")
+}
@@ -9,6 +9,7 @@ import (
 	"net/url"
 	"strings"
 
+	"closeauth-frontend/internal/metrics"
 	"closeauth-frontend/internal/middleware"
 	"closeauth-frontend/internal/spring"
 )
@@ -29,7 +30,7 @@ import (
 //  3. If Spring 302 → consent: redirect to /oauth/consent
 //  4. If Spring 302 → external client: redirect to client callback with auth code
 func (s *Server) handleAuthorizeImpl(w http.ResponseWriter, r *http.Request) {
-	logger := s.logger.With("handler", "authorize")
+	logger := s.requestLogger(r, "authorize")
 
 	// Validate required OAuth params
 	query := r.URL.Query()
@@ -61,7 +62,7 @@ func (s *Server) handleAuthorizeImpl(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Forward any cookies from Spring to the browser
-	forwardSpringCookies(w, result.Cookies, s.springConfig.IsProduction())
+	forwardSpringCookies(w, result.Cookies, s.secureCookies())
 
 	// Handle redirect responses
 	if result.StatusCode >= 300 && result.StatusCode < 400 && result.Location != "" {
@@ -140,7 +141,7 @@ func (s *Server) handleUnauthenticatedRedirect(w http.ResponseWriter, r *http.Re
 		Username:        username,
 	}
 
-	if err := middleware.SaveOAuthContext(w, oauthCtx, s.springConfig.IsProduction()); err != nil {
+	if err := middleware.SaveOAuthContext(w, oauthCtx, s.secureCookies()); err != nil {
 		s.logger.Error("failed to save OAuth context", "error", err)
 		http.Error(w, "Failed to save authorization context", http.StatusInternalServerError)
 		return
@@ -185,7 +186,7 @@ func (s *Server) handleTokenImpl(w http.ResponseWriter, r *http.Request) {
 // handleConsentPostImpl processes consent form submission (native HTML form POST).
 // Redirects the browser directly to the external client's redirect_uri.
 func (s *Server) handleConsentPostImpl(w http.ResponseWriter, r *http.Request) {
-	logger := s.logger.With("handler", "consent_post")
+	logger := s.requestLogger(r, "consent_post")
 
 	if err := r.ParseForm(); err != nil {
 		http.Error(w, "Failed to parse form", http.StatusBadRequest)
@@ -202,6 +203,7 @@ func (s *Server) handleConsentPostImpl(w http.ResponseWriter, r *http.Request) {
 	oauthCtx, err := middleware.GetOAuthContext(r)
 	if err != nil {
 		logger.Error("failed to get OAuth context for consent", "error", err)
+		metrics.OAuthEvent(metrics.OAuthContextExpired)
 		http.Error(w, "Session expired. Please start the login process again.", http.StatusBadRequest)
 		return
 	}
@@ -221,6 +223,12 @@ func (s *Server) handleConsentPostImpl(w http.ResponseWriter, r *http.Request) {
 	// Clear OAuth context — flow is complete
 	middleware.ClearOAuthContext(w)
 
+	if consent == "approve" {
+		metrics.OAuthEvent(metrics.OAuthConsentApprove)
+	} else {
+		metrics.OAuthEvent(metrics.OAuthConsentDeny)
+	}
+
 	// Spring should return 302 to client redirect_uri with auth code
 	if result.StatusCode == http.StatusFound && result.Location != "" {
 		logger.Info("consent complete, redirecting to client", "location", result.Location)
@@ -0,0 +1,368 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"closeauth-frontend/internal/database/models"
+	"closeauth-frontend/internal/database/repository"
+	"closeauth-frontend/internal/themeasset"
+)
+
+// ──────────────────────────────────────────────────────────────────────────────
+// Theme Assets
+//
+// Admins upload logos and backgrounds as multipart forms. The image is sniffed
+// and sanitized or re-encoded into a few sizes (see package themeasset), each
+// stored once under its content hash in the database or under
+// THEME_ASSET_DIR, and served from the BFF origin at /api/assets/<hash>.<ext>.
+// A URL never changes meaning, so it is cacheable forever and can go straight
+// into a theme's logo_url or CSS. Assets work with either THEME_SOURCE.
+// ──────────────────────────────────────────────────────────────────────────────
+
+const (
+	// defaultAssetMaxUploadBytes applies when the server was built without a
+	// themes configuration
+	defaultAssetMaxUploadBytes = 2 << 20
+
+	// assetFormOverhead allows for the multipart framing and the kind field
+	assetFormOverhead = 64 << 10
+
+	// assetCacheControl lets browsers and CDNs keep a content-hashed asset forever
+	assetCacheControl = "public, max-age=31536000, immutable"
+
+	// svgAssetCSP stops a sanitized SVG opened on its own from running or
+	// loading anything, should the sanitizer ever miss something
+	svgAssetCSP = "default-src 'none'; style-src 'unsafe-inline'; frame-ancestors 'none'; sandbox"
+)
+
+// themeAssetResponse is an uploaded asset; URL is its largest variant.
+type themeAssetResponse struct {
+	ID          int64                       `json:"id"`
+	Kind        string                      `json:"kind"`
+	Filename    string                      `json:"filename"`
+	ContentType string                      `json:"contentType"`
+	Width       int                         `json:"width"`
+	Height      int                         `json:"height"`
+	URL         string                      `json:"url"`
+	Variants    []themeAssetVariantResponse `json:"variants"`
+	CreatedBy   string                      `json:"createdBy"`
+	CreatedAt   time.Time                   `json:"createdAt"`
+}
+
+type themeAssetVariantResponse struct {
+	Name        string `json:"name"`
+	URL         string `json:"url"`
+	ContentType string `json:"contentType"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	Size        int64  `json:"size"`
+}
+
+func newThemeAssetResponse(asset *models.ThemeAsset) themeAssetResponse {
+	resp := themeAssetResponse{
+		ID:          asset.ID,
+		Kind:        asset.Kind,
+		Filename:    asset.Filename,
+		ContentType: asset.ContentType,
+		Width:       asset.Width,
+		Height:      asset.Height,
+		Variants:    make([]themeAssetVariantResponse, len(asset.Variants)),
+		CreatedBy:   asset.CreatedBy,
+		CreatedAt:   asset.CreatedAt,
+	}
+	for i, v := range asset.Variants {
+		resp.Variants[i] = themeAssetVariantResponse{
+			Name:        v.Name,
+			URL:         themeAssetURL(v.Key),
+			ContentType: v.ContentType,
+			Width:       v.Width,
+			Height:      v.Height,
+			Size:        v.Size,
+		}
+		resp.URL = resp.Variants[i].URL
+	}
+	return resp
+}
+
+// themeAssetURL is where the BFF serves a blob.
+func themeAssetURL(key string) string {
+	return "/api/assets/" + key
+}
+
+// handleUploadThemeAsset processes and stores an image uploaded as the
+// multipart fields kind (logo, the default, or background) and file.
+func (s *Server) handleUploadThemeAsset(w http.ResponseWriter, r *http.Request) {
+	assets, blobs, ok := s.themeAssetStores(w)
+	if !ok {
+		return
+	}
+	audit := s.startThemeAudit(r)
+	defer s.recordAudit(r, audit)
+
+	maxUpload := s.assetMaxUploadBytes()
+	r.Body = http.MaxBytesReader(w, r.Body, int64(maxUpload+assetFormOverhead))
+	kind, filename, data, status, msg := readAssetUpload(r, maxUpload)
+	if status != 0 {
+		writeThemeError(w, audit, status, msg)
+		return
+	}
+
+	result, err := themeasset.Process(kind, data)
+	if err != nil {
+		writeThemeError(w, audit, assetErrorStatus(err), err.Error())
+		return
+	}
+
+	asset := &models.ThemeAsset{
+		ClientID:    chi.URLParam(r, "clientId"),
+		Kind:        kind,
+		Filename:    filename,
+		ContentType: result.ContentType,
+		Width:       result.Width,
+		Height:      result.Height,
+		CreatedBy:   repository.AuthorFromContext(r.Context()),
+	}
+	for _, v := range result.Variants {
+		asset.Variants = append(asset.Variants, models.ThemeAssetVariant{
+			Name:        v.Name,
+			Key:         v.Key(),
+			ContentType: v.ContentType,
+			Width:       v.Width,
+			Height:      v.Height,
+			Size:        int64(len(v.Data)),
+		})
+	}
+	// The asset goes first: once it refers to its blobs, deleting another
+	// asset that shares them keeps them, and putting them afterwards restores
+	// any that such a delete removed just before
+	if err := assets.CreateAsset(r.Context(), asset); err != nil {
+		s.writeThemeStoreError(w, r, audit, err, "store theme asset")
+		return
+	}
+	for i, v := range result.Variants {
+		err := blobs.PutBlob(r.Context(), &models.AssetBlob{Key: asset.Variants[i].Key, ContentType: v.ContentType, Data: v.Data})
+		if err == nil {
+			continue
+		}
+		if err := assets.DeleteAsset(context.WithoutCancel(r.Context()), asset.ClientID, asset.ID); err != nil {
+			s.requestLogger(r, "themes").Error("failed to remove theme asset without content", "asset_id", asset.ID, "error", err)
+		}
+		s.writeThemeStoreError(w, r, audit, err, "store theme asset")
+		return
+	}
+
+	s.requestLogger(r, "themes").Info("theme asset uploaded",
+		"client_id", asset.ClientID, "asset_id", asset.ID, "kind", asset.Kind,
+		"content_type", asset.ContentType, "bytes", len(data), "variants", len(asset.Variants))
+	writeThemeResponse(w, audit, http.StatusCreated, "Theme asset uploaded successfully", newThemeAssetResponse(asset))
+}
+
+// readAssetUpload streams the form, keeping at most maxUpload bytes of the
+// file. A non-zero status reports why the form was rejected.
+func readAssetUpload(r *http.Request, maxUpload int) (kind, filename string, data []byte, status int, msg string) {
+	form, err := r.MultipartReader()
+	if err != nil {
+		return "", "", nil, http.StatusBadRequest, "Expected a multipart/form-data upload"
+	}
+
+	kind = models.AssetKindLogo
+	for {
+		part, err := form.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return uploadReadError(err)
+		}
+
+		switch part.FormName() {
+		case "kind":
+			value, err := io.ReadAll(io.LimitReader(part, 64))
+			if err != nil {
+				return uploadReadError(err)
+			}
+			kind = strings.TrimSpace(string(value))
+		case "file":
+			if data != nil {
+				return "", "", nil, http.StatusBadRequest, "Upload one file at a time"
+			}
+			filename = themeasset.CleanFilename(part.FileName())
+			data, err = io.ReadAll(io.LimitReader(part, int64(maxUpload)+1))
+			if err != nil {
+				return uploadReadError(err)
+			}
+			if len(data) > maxUpload {
+				return "", "", nil, http.StatusRequestEntityTooLarge, "File exceeds the " + strconv.Itoa(maxUpload>>10) + " KiB upload limit"
+			}
+		}
+		part.Close()
+	}
+
+	if len(data) == 0 {
+		return "", "", nil, http.StatusBadRequest, "The file field is required"
+	}
+	return kind, filename, data, 0, ""
+}
+
+// uploadReadError reports a form that could not be read: too large for
+// http.MaxBytesReader, or malformed.
+func uploadReadError(err error) (string, string, []byte, int, string) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return "", "", nil, http.StatusRequestEntityTooLarge, "Upload too large"
+	}
+	return "", "", nil, http.StatusBadRequest, "Malformed multipart upload"
+}
+
+// assetErrorStatus maps themeasset errors to status codes.
+func assetErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, themeasset.ErrUnsupportedType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, themeasset.ErrInvalidImage), errors.Is(err, themeasset.ErrImageTooLarge):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+func (s *Server) handleListThemeAssets(w http.ResponseWriter, r *http.Request) {
+	assets, _, ok := s.themeAssetStores(w)
+	if !ok {
+		return
+	}
+	list, err := assets.ListAssets(r.Context(), chi.URLParam(r, "clientId"))
+	if err != nil {
+		s.writeThemeStoreError(w, r, nil, err, "list theme assets")
+		return
+	}
+	data := make([]themeAssetResponse, len(list))
+	for i := range list {
+		data[i] = newThemeAssetResponse(&list[i])
+	}
+	writeThemeResponse(w, nil, http.StatusOK, "Theme assets retrieved successfully", data)
+}
+
+func (s *Server) handleGetThemeAsset(w http.ResponseWriter, r *http.Request) {
+	assets, _, ok := s.themeAssetStores(w)
+	if !ok {
+		return
+	}
+	asset, ok := s.loadThemeAsset(w, r, assets, nil)
+	if !ok {
+		return
+	}
+	writeThemeResponse(w, nil, http.StatusOK, "Theme asset retrieved successfully", newThemeAssetResponse(asset))
+}
+
+// handleDeleteThemeAsset deletes an asset and the blobs no other asset shares.
+// Themes still pointing at its URLs lose the image.
+func (s *Server) handleDeleteThemeAsset(w http.ResponseWriter, r *http.Request) {
+	assets, _, ok := s.themeAssetStores(w)
+	if !ok {
+		return
+	}
+	audit := s.startThemeAudit(r)
+	defer s.recordAudit(r, audit)
+
+	asset, ok := s.loadThemeAsset(w, r, assets, audit)
+	if !ok {
+		return
+	}
+	err := assets.DeleteAsset(r.Context(), asset.ClientID, asset.ID)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeThemeError(w, audit, http.StatusNotFound, "Theme asset not found")
+		return
+	}
+	if err != nil {
+		s.writeThemeStoreError(w, r, audit, err, "delete theme asset")
+		return
+	}
+	audit.Before = themeAuditSummary(newThemeAssetResponse(asset))
+	writeThemeResponse(w, audit, http.StatusOK, "Theme asset deleted successfully", nil)
+}
+
+// handleServeThemeAsset serves a blob by key to anyone: keys are content
+// hashes, so they reveal nothing that isn't already on a login page.
+func (s *Server) handleServeThemeAsset(w http.ResponseWriter, r *http.Request) {
+	key := chi.URLParam(r, "key")
+	if !models.IsAssetBlobKey(key) {
+		jsonError(w, "Asset not found", http.StatusNotFound)
+		return
+	}
+	blobs := s.blobStore()
+	if blobs == nil {
+		jsonError(w, "Asset storage unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	blob, err := blobs.GetBlob(r.Context(), key)
+	if errors.Is(err, repository.ErrNotFound) {
+		jsonError(w, "Asset not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		s.requestLogger(r, "themes").Error("failed to load theme asset", "key", key, "error", err)
+		jsonError(w, "Failed to load asset", http.StatusInternalServerError)
+		return
+	}
+
+	h := w.Header()
+	h.Set("Content-Type", blob.ContentType)
+	h.Set("Cache-Control", assetCacheControl)
+	h.Set("ETag", `"`+key[:64]+`"`) // the content hash
+	h.Set("X-Content-Type-Options", "nosniff")
+	if blob.ContentType == themeasset.TypeSVG {
+		h.Set("Content-Security-Policy", svgAssetCSP)
+	}
+	http.ServeContent(w, r, key, blob.CreatedAt, bytes.NewReader(blob.Data))
+}
+
+// ── Helpers ──────────────────────────────────────────────────────────────────
+
+// themeAssetStores returns the asset and blob stores, answering 503 while
+// either is unavailable.
+func (s *Server) themeAssetStores(w http.ResponseWriter) (repository.AssetStore, repository.BlobStore, bool) {
+	assets, blobs := s.assetStore(), s.blobStore()
+	if assets == nil || blobs == nil {
+		writeThemeError(w, nil, http.StatusServiceUnavailable, "Asset storage unavailable")
+		return nil, nil, false
+	}
+	return assets, blobs, true
+}
+
+// loadThemeAsset loads {assetId}, answering 404 unless it belongs to {clientId}.
+func (s *Server) loadThemeAsset(w http.ResponseWriter, r *http.Request, assets repository.AssetStore, audit *models.AuditEvent) (*models.ThemeAsset, bool) {
+	assetID, err := strconv.ParseInt(chi.URLParam(r, "assetId"), 10, 64)
+	if err != nil {
+		writeThemeError(w, audit, http.StatusBadRequest, "Invalid asset id")
+		return nil, false
+	}
+	asset, err := assets.FindAsset(r.Context(), chi.URLParam(r, "clientId"), assetID)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeThemeError(w, audit, http.StatusNotFound, "Theme asset not found")
+		return nil, false
+	}
+	if err != nil {
+		s.writeThemeStoreError(w, r, audit, err, "load theme asset")
+		return nil, false
+	}
+	return asset, true
+}
+
+// assetMaxUploadBytes is THEME_ASSET_MAX_UPLOAD_BYTES, or 2 MiB when the
+// server was built without a themes configuration.
+func (s *Server) assetMaxUploadBytes() int {
+	if s.themesCfg != nil && s.themesCfg.AssetMaxUploadBytes > 0 {
+		return s.themesCfg.AssetMaxUploadBytes
+	}
+	return defaultAssetMaxUploadBytes
+}
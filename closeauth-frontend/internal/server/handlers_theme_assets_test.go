@@ -0,0 +1,165 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"closeauth-frontend/internal/config"
+	"closeauth-frontend/internal/database/repository"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestThemeAssets(t *testing.T) {
+	store := repository.NewMemoryAssetStore()
+	s := &Server{
+		assets:    store,
+		blobs:     store,
+		themesCfg: &config.ThemesConfig{Source: config.ThemeSourceSpring, AssetMaxUploadBytes: 64 << 10},
+		logger:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	r := chi.NewRouter()
+	r.Post("/clients/{clientId}/assets", s.withThemeAuthor(s.handleUploadThemeAsset))
+	r.Get("/clients/{clientId}/assets", s.handleListThemeAssets)
+	r.Get("/clients/{clientId}/assets/{assetId}", s.handleGetThemeAsset)
+	r.Delete("/clients/{clientId}/assets/{assetId}", s.handleDeleteThemeAsset)
+	r.Get("/api/assets/{key}", s.handleServeThemeAsset)
+
+	upload := func(kind, filename string, data []byte) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		if kind != "" {
+			form.WriteField("kind", kind)
+		}
+		part, _ := form.CreateFormFile("file", filename)
+		part.Write(data)
+		form.Close()
+		req := httptest.NewRequest(http.MethodPost, "/clients/acme/assets", &body)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+	get := func(path string, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+	type assetBody struct {
+		Data themeAssetResponse `json:"data"`
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, 600, 300))
+	for x := range 600 {
+		img.Set(x, x/2, color.NRGBA{R: 255, A: 255})
+	}
+	var logo bytes.Buffer
+	png.Encode(&logo, img)
+
+	// A raster logo is re-encoded into sizes served from the BFF origin
+	rec := upload("", "../../My Logo.png", logo.Bytes())
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("upload = %d %s", rec.Code, rec.Body)
+	}
+	var created assetBody
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatal(err)
+	}
+	asset := created.Data
+	if asset.Kind != "logo" || asset.Filename != "My Logo.png" || asset.Width != 600 || len(asset.Variants) != 3 {
+		t.Fatalf("uploaded asset = %+v, want a 600px logo with three variants", asset)
+	}
+	if last := asset.Variants[2]; asset.URL != last.URL || last.Width != 512 || !strings.HasPrefix(last.URL, "/api/assets/") {
+		t.Errorf("asset url = %q, want the largest variant's, got %+v", asset.URL, last)
+	}
+
+	rec = get(asset.URL)
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "image/png" {
+		t.Fatalf("GET asset = %d %s", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if cc := rec.Header().Get("Cache-Control"); cc != assetCacheControl {
+		t.Errorf("Cache-Control = %q, want %q", cc, assetCacheControl)
+	}
+	if cfg, err := png.DecodeConfig(rec.Body); err != nil || cfg.Width != 512 {
+		t.Errorf("served variant = %+v, %v; want a 512px PNG", cfg, err)
+	}
+	if rec := get(asset.URL, "If-None-Match", rec.Header().Get("ETag")); rec.Code != http.StatusNotModified {
+		t.Errorf("conditional GET = %d, want 304", rec.Code)
+	}
+
+	// SVG is sanitized and served with a CSP of its own
+	rec = upload("background", "bg.svg", []byte(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 10 10" onload="alert(1)"><script>alert(2)</script><rect width="10" height="10"/></svg>`))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("upload svg = %d %s", rec.Code, rec.Body)
+	}
+	var svg assetBody
+	json.NewDecoder(rec.Body).Decode(&svg)
+	rec = get(svg.Data.URL)
+	if body := rec.Body.String(); strings.Contains(body, "alert") || !strings.Contains(body, "<rect") {
+		t.Errorf("served svg = %s, want it sanitized", body)
+	}
+	if csp := rec.Header().Get("Content-Security-Policy"); csp != svgAssetCSP {
+		t.Errorf("svg CSP = %q, want %q", csp, svgAssetCSP)
+	}
+
+	rejected := []struct {
+		name       string
+		kind, file string
+		data       []byte
+		wantStatus int
+	}{
+		{"html", "logo", "x.png", []byte("<html><script>alert(1)</script></html>"), http.StatusUnsupportedMediaType},
+		{"truncated", "logo", "x.png", logo.Bytes()[:100], http.StatusUnprocessableEntity},
+		{"unknown kind", "favicon", "x.png", logo.Bytes(), http.StatusBadRequest},
+		{"too large", "logo", "x.png", bytes.Repeat([]byte{0}, 65<<10), http.StatusRequestEntityTooLarge},
+		{"no file", "logo", "x.png", nil, http.StatusBadRequest},
+	}
+	for _, tt := range rejected {
+		if rec := upload(tt.kind, tt.file, tt.data); rec.Code != tt.wantStatus {
+			t.Errorf("upload %s = %d %s, want %d", tt.name, rec.Code, rec.Body, tt.wantStatus)
+		}
+	}
+
+	var list struct {
+		Data []themeAssetResponse `json:"data"`
+	}
+	json.NewDecoder(get("/clients/acme/assets").Body).Decode(&list)
+	if len(list.Data) != 2 || list.Data[0].ID != svg.Data.ID {
+		t.Errorf("list = %+v, want both assets newest first", list.Data)
+	}
+	assetPath := "/clients/acme/assets/" + strconv.FormatInt(asset.ID, 10)
+	if rec := get("/clients/globex/assets/" + strconv.FormatInt(asset.ID, 10)); rec.Code != http.StatusNotFound {
+		t.Errorf("GET another client's asset = %d, want 404", rec.Code)
+	}
+
+	// Deleting the asset deletes its blobs
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, assetPath, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("delete = %d %s", rec.Code, rec.Body)
+	}
+	if rec := get(assetPath); rec.Code != http.StatusNotFound {
+		t.Errorf("GET deleted asset = %d, want 404", rec.Code)
+	}
+	if rec := get(asset.URL); rec.Code != http.StatusNotFound {
+		t.Errorf("GET deleted blob = %d, want 404", rec.Code)
+	}
+	if rec := get("/api/assets/..%2Fsecret.png"); rec.Code != http.StatusNotFound {
+		t.Errorf("GET malformed key = %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,230 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"closeauth-frontend/internal/config"
+	"closeauth-frontend/internal/database/models"
+	"closeauth-frontend/internal/database/repository"
+	"closeauth-frontend/internal/themelint"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ──────────────────────────────────────────────────────────────────────────────
+// Theme Contrast Linting
+//
+// Theme creates and updates are checked against the WCAG contrast level in
+// themes.contrast_level before they are saved, from either theme source.
+// Under themes.contrast_enforcement=warn the write goes ahead and local
+// writes report the findings as "warnings"; reject answers 422 instead.
+// ──────────────────────────────────────────────────────────────────────────────
+
+var (
+	errInvalidThemeBody        = errors.New("invalid theme body")
+	errThemeStorageUnavailable = errors.New("theme storage unavailable")
+)
+
+// localThemes reports whether the admin theme API is served from the BFF's
+// own ThemeStore (THEME_SOURCE=local).
+func (s *Server) localThemes() bool {
+	return s.themesCfg != nil && s.themesCfg.Source == config.ThemeSourceLocal
+}
+
+// contrastPolicy returns the configured contrast level and enforcement, or
+// AA and warn when the server was built without a themes configuration.
+func (s *Server) contrastPolicy() (themelint.Level, string) {
+	if s.themesCfg == nil {
+		return themelint.AA, config.ContrastWarn
+	}
+	return themelint.Level(s.themesCfg.ContrastLevel), s.themesCfg.ContrastEnforcement
+}
+
+// checkContrast lints theme under the contrast policy. It returns the findings
+// a write goes ahead with, or false after answering 422 when the policy
+// rejects them.
+func (s *Server) checkContrast(w http.ResponseWriter, audit *models.AuditEvent, theme *models.ClientTheme) ([]themelint.Finding, bool) {
+	level, enforcement := s.contrastPolicy()
+	if enforcement == config.ContrastOff {
+		return nil, true
+	}
+	findings := themelint.Check(theme, level)
+	if len(findings) > 0 && enforcement == config.ContrastReject {
+		writeContrastRejection(w, audit, level, findings)
+		return nil, false
+	}
+	return findings, true
+}
+
+// lintBeforeProxy checks a theme create or update before next proxies it to
+// Spring. Spring's response has no room for warnings, so under warn they are
+// only logged. Bodies the linter cannot read are passed on for Spring to reject.
+func (s *Server) lintBeforeProxy(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		level, enforcement := s.contrastPolicy()
+		if enforcement == config.ContrastOff {
+			next(w, r)
+			return
+		}
+
+		logger := s.requestLogger(r, "themes")
+		body, err := readBody(r)
+		if err != nil {
+			jsonError(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		theme, err := s.themeUnderEdit(r, body)
+		if err != nil {
+			logger.Debug("skipping contrast check", "error", err)
+			next(w, r)
+			return
+		}
+
+		audit := s.startThemeAudit(r)
+		findings, ok := s.checkContrast(w, audit, theme)
+		if !ok {
+			audit.After = auditSummary(body) // The change that was attempted
+			s.recordAudit(r, audit)
+			return
+		}
+		if len(findings) > 0 {
+			logger.Warn("saving theme below contrast level", "level", level, "findings", contrastSummary(findings))
+		}
+		next(w, r)
+	}
+}
+
+// handleValidateTheme lints a theme create (POST .../themes/validate) or an
+// update of {themeId} (POST .../themes/{themeId}/validate) without saving it.
+// ?level=AA|AAA checks against another level than the configured one.
+func (s *Server) handleValidateTheme(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxThemeBodySize))
+	if err != nil {
+		writeThemeError(w, nil, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	level, enforcement := s.contrastPolicy()
+	if l := r.URL.Query().Get("level"); l != "" {
+		if l != config.ContrastAA && l != config.ContrastAAA {
+			writeThemeError(w, nil, http.StatusBadRequest, "level must be AA or AAA")
+			return
+		}
+		level = themelint.Level(l)
+	}
+
+	theme, err := s.themeUnderEdit(r, body)
+	switch {
+	case errors.Is(err, errInvalidThemeBody):
+		writeThemeError(w, nil, http.StatusBadRequest, "Invalid request body")
+		return
+	case errors.Is(err, repository.ErrNotFound):
+		writeThemeError(w, nil, http.StatusNotFound, "Theme not found")
+		return
+	case errors.Is(err, errThemeStorageUnavailable):
+		writeThemeError(w, nil, http.StatusServiceUnavailable, "Theme storage unavailable")
+		return
+	case err != nil:
+		s.requestLogger(r, "themes").Error("failed to load theme for validation", "error", err)
+		writeThemeError(w, nil, http.StatusServiceUnavailable, "Service unavailable")
+		return
+	}
+
+	findings := themelint.Check(theme, level)
+	if findings == nil {
+		findings = []themelint.Finding{}
+	}
+	writeThemeResponse(w, nil, http.StatusOK, "Theme validated", map[string]any{
+		"level":       level,
+		"enforcement": enforcement,
+		"passed":      len(findings) == 0,
+		"findings":    findings,
+	})
+}
+
+// themeUnderEdit is the theme a create (no {themeId}) or update would save:
+// body's fields applied over the stored theme, if any.
+func (s *Server) themeUnderEdit(r *http.Request, body []byte) (*models.ClientTheme, error) {
+	theme := &models.ClientTheme{ClientID: chi.URLParam(r, "clientId"), AllowModeToggle: true}
+	if themeID := chi.URLParam(r, "themeId"); themeID != "" {
+		current, err := s.currentTheme(r, themeID)
+		if err != nil {
+			return nil, err
+		}
+		theme = current
+	}
+
+	var req themeRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, errInvalidThemeBody
+	}
+	req.apply(theme)
+	return theme, nil
+}
+
+// currentTheme loads one of {clientId}'s themes from the configured source.
+func (s *Server) currentTheme(r *http.Request, themeID string) (*models.ClientTheme, error) {
+	clientID := chi.URLParam(r, "clientId")
+	if s.localThemes() {
+		store := s.themeStore()
+		if store == nil {
+			return nil, errThemeStorageUnavailable
+		}
+		id, err := strconv.Atoi(themeID)
+		if err != nil {
+			return nil, repository.ErrNotFound
+		}
+		theme, err := store.FindThemeByID(r.Context(), id)
+		if err == nil && theme.ClientID != clientID {
+			err = repository.ErrNotFound
+		}
+		return theme, err
+	}
+
+	result, err := s.springClient.ProxyAdminAuth(r.Context(), http.MethodGet, s.springConfig.ClientThemeURL(clientID, themeID), nil, getUserToken(r))
+	if err != nil {
+		return nil, err
+	}
+	switch result.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, repository.ErrNotFound
+	default:
+		return nil, fmt.Errorf("loading theme from Spring: status %d", result.StatusCode)
+	}
+	var envelope struct {
+		Data themeRequest `json:"data"`
+	}
+	if err := json.Unmarshal(result.Body, &envelope); err != nil {
+		return nil, fmt.Errorf("decoding Spring theme: %w", err)
+	}
+	theme := &models.ClientTheme{ClientID: clientID, AllowModeToggle: true}
+	envelope.Data.apply(theme)
+	return theme, nil
+}
+
+// writeContrastRejection answers 422 with the failing pairs in "findings".
+func writeContrastRejection(w http.ResponseWriter, audit *models.AuditEvent, level themelint.Level, findings []themelint.Finding) {
+	message := fmt.Sprintf("Theme colours do not meet WCAG %s contrast: %s", level, contrastSummary(findings))
+	writeThemeError(w, audit, http.StatusUnprocessableEntity, message, findings...)
+}
+
+func contrastSummary(findings []themelint.Finding) string {
+	parts := make([]string, len(findings))
+	for i, f := range findings {
+		parts[i] = f.String()
+		if f.Suggestion != "" {
+			parts[i] += fmt.Sprintf(" (try %s for %s)", f.Suggestion, f.Field)
+		}
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"closeauth-frontend/internal/config"
+	"closeauth-frontend/internal/database/models"
+	"closeauth-frontend/internal/database/repository"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestThemeContrastLinting(t *testing.T) {
+	grey, white := "#999999", "#ffffff"
+	store := repository.NewMemoryThemeStore()
+	current, err := store.AddTheme(models.ClientTheme{
+		ClientID: "acme", ThemeName: "current", IsActive: true, IsDefault: true,
+		LightTextColor: &grey, LightBackgroundColor: &white,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newRouter := func(enforcement string) http.Handler {
+		s := &Server{
+			themes: store,
+			themesCfg: &config.ThemesConfig{
+				Source:              config.ThemeSourceLocal,
+				ContrastLevel:       config.ContrastAA,
+				ContrastEnforcement: enforcement,
+			},
+			logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		}
+		themes := s.themeHandlers()
+		r := chi.NewRouter()
+		r.Route("/clients/{clientId}/themes", func(r chi.Router) {
+			r.Post("/", themes.create)
+			r.Put("/{themeId}", themes.update)
+			r.Post("/validate", s.handleValidateTheme)
+			r.Post("/{themeId}/validate", s.handleValidateTheme)
+		})
+		return r
+	}
+	existing := "/clients/acme/themes/" + strconv.Itoa(current.ID)
+
+	tests := []struct {
+		name         string
+		enforcement  string
+		method, path string
+		body         string
+		wantStatus   int
+		wantFindings int
+	}{
+		{"reject create", config.ContrastReject, http.MethodPost, "/clients/acme/themes/", `{"themeName":"pale","lightTextColor":"#aaaaaa"}`, http.StatusUnprocessableEntity, 1},
+		{"warn create", config.ContrastWarn, http.MethodPost, "/clients/acme/themes/", `{"themeName":"pale","lightTextColor":"#aaaaaa"}`, http.StatusCreated, 1},
+		{"off create", config.ContrastOff, http.MethodPost, "/clients/acme/themes/", `{"themeName":"paler","lightTextColor":"#bbbbbb"}`, http.StatusCreated, 0},
+		{"reject update merged with stored colours", config.ContrastReject, http.MethodPut, existing, `{"logoUrl":"/logo.png"}`, http.StatusUnprocessableEntity, 1},
+		{"update fixing the colour", config.ContrastReject, http.MethodPut, existing, `{"lightTextColor":"#767676"}`, http.StatusOK, 0},
+		{"dry run new theme", config.ContrastReject, http.MethodPost, "/clients/acme/themes/validate", `{"darkButtonColor":"#1f2937"}`, http.StatusOK, 1},
+		{"dry run AAA", config.ContrastReject, http.MethodPost, existing + "/validate?level=AAA", `{}`, http.StatusOK, 1},
+		{"dry run unknown theme", config.ContrastReject, http.MethodPost, "/clients/acme/themes/999/validate", `{}`, http.StatusNotFound, 0},
+		{"dry run bad level", config.ContrastReject, http.MethodPost, "/clients/acme/themes/validate?level=A", `{}`, http.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			newRouter(tt.enforcement).ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+
+			var resp struct {
+				Message  string          `json:"message"`
+				Findings json.RawMessage `json:"findings"`
+				Warnings json.RawMessage `json:"warnings"`
+				Data     struct {
+					Findings json.RawMessage `json:"findings"`
+				} `json:"data"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+			raw := resp.Findings
+			switch {
+			case resp.Warnings != nil:
+				raw = resp.Warnings
+			case resp.Data.Findings != nil:
+				raw = resp.Data.Findings
+			}
+			var findings []map[string]any
+			if raw != nil {
+				if err := json.Unmarshal(raw, &findings); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if len(findings) != tt.wantFindings {
+				t.Errorf("findings = %s, want %d", raw, tt.wantFindings)
+			}
+			if tt.wantStatus == http.StatusUnprocessableEntity && !strings.Contains(resp.Message, "try #") {
+				t.Errorf("message = %q, want a suggested colour", resp.Message)
+			}
+		})
+	}
+
+	// Warned writes are saved, rejected ones are not
+	all, err := store.FindAllByClientID(t.Context(), "acme")
+	if err != nil || len(all) != 3 {
+		t.Errorf("acme themes = %d, %v; want current, pale and paler", len(all), err)
+	}
+	theme, err := store.FindThemeByID(t.Context(), current.ID)
+	if err != nil || theme.LogoURL != nil || *theme.LightTextColor != "#767676" {
+		t.Errorf("rejected update was saved: %+v, %v", theme, err)
+	}
+}
@@ -0,0 +1,171 @@
+package server
+
+import (
+	"crypto/sha256"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"closeauth-frontend/internal/database/models"
+	"closeauth-frontend/internal/database/repository"
+	"closeauth-frontend/internal/middleware"
+	"closeauth-frontend/internal/themepreview"
+)
+
+// ──────────────────────────────────────────────────────────────────────────────
+// Theme Previews
+//
+// An admin can create a signed, expiring link that shows one theme on the real
+// hosted login page before it is published or activated: its draft when it
+// has one, else the theme as saved. /api/oauth/theme and theme.css serve that
+// theme instead of the client's default while ?preview_token= is valid, flag
+// the response as a preview and forbid caching it. The parameter name keeps
+// the token out of logs and audit summaries (see redact).
+// ──────────────────────────────────────────────────────────────────────────────
+
+// themePreviewParam is the query parameter that carries a preview token.
+const themePreviewParam = "preview_token"
+
+// themePreviewResponse is a created preview link and where it applies.
+type themePreviewResponse struct {
+	ThemeID       int       `json:"themeId"`
+	Draft         bool      `json:"draft"`
+	Token         string    `json:"token"`
+	ExpiresAt     time.Time `json:"expiresAt"`
+	PreviewURL    string    `json:"previewUrl"`
+	ThemeURL      string    `json:"themeUrl"`
+	StylesheetURL string    `json:"stylesheetUrl"`
+}
+
+// themePreview is the theme a valid preview token shows.
+type themePreview struct {
+	theme   *models.ClientTheme
+	configs []models.ThemeConfiguration
+	draft   bool
+	grant   themepreview.Grant
+}
+
+// handleCreateThemePreview signs a preview link for {themeId}. The link shows
+// whatever the draft holds when it is opened, not when it was created.
+func (s *Server) handleCreateThemePreview(w http.ResponseWriter, r *http.Request) {
+	store, ok := s.localThemeStore(w)
+	if !ok {
+		return
+	}
+	audit := s.startThemeAudit(r)
+	defer s.recordAudit(r, audit)
+
+	theme, ok := s.loadClientTheme(w, r, store, audit)
+	if !ok {
+		return
+	}
+	_, err := store.FindThemeDraft(r.Context(), theme.ClientID, theme.ID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		s.writeThemeStoreError(w, r, audit, err, "create theme preview")
+		return
+	}
+
+	token, grant := s.themePreviewSigner().Sign(theme.ClientID, theme.ID, time.Now())
+	query := url.Values{"client_id": {theme.ClientID}, themePreviewParam: {token}}.Encode()
+	writeThemeResponse(w, audit, http.StatusCreated, "Theme preview link created successfully", themePreviewResponse{
+		ThemeID:       theme.ID,
+		Draft:         err == nil,
+		Token:         token,
+		ExpiresAt:     grant.ExpiresAt.UTC(),
+		PreviewURL:    "/oauth/login?" + query,
+		ThemeURL:      "/api/oauth/theme?" + query,
+		StylesheetURL: themePreviewStylesheetURL(theme.ClientID, token),
+	})
+}
+
+// themePreviewSigner signs preview links with THEME_PREVIEW_SIGNING_KEY, or a
+// key derived from the cookie encryption key, valid for THEME_PREVIEW_TTL (an
+// hour when the server was built without a themes configuration).
+func (s *Server) themePreviewSigner() *themepreview.Signer {
+	var key []byte
+	ttl := time.Hour
+	if s.themesCfg != nil {
+		key = []byte(s.themesCfg.PreviewSigningKey)
+		ttl = s.themesCfg.PreviewTTL
+	}
+	if len(key) == 0 {
+		derived := sha256.Sum256(append([]byte("closeauth-theme-preview:"), middleware.GetEncryptionKey()...))
+		key = derived[:]
+	}
+	return themepreview.NewSigner(key, ttl)
+}
+
+// themePreviewGrant checks the request's preview token for clientID. It
+// returns nil when there is none, or false after answering 403 when the token
+// is invalid or expired: an admin must not mistake the live theme for theirs.
+func (s *Server) themePreviewGrant(w http.ResponseWriter, r *http.Request, clientID string) (*themepreview.Grant, bool) {
+	token := r.URL.Query().Get(themePreviewParam)
+	if token == "" {
+		return nil, true
+	}
+	grant, err := s.themePreviewSigner().Verify(token, clientID, time.Now())
+	if err != nil {
+		s.requestLogger(r, "theme_preview").Info("rejected theme preview token", "client_id", clientID, "error", err)
+		if errors.Is(err, themepreview.ErrExpired) {
+			jsonError(w, "Preview link has expired", http.StatusForbidden)
+		} else {
+			jsonError(w, "Invalid preview link", http.StatusForbidden)
+		}
+		return nil, false
+	}
+	return &grant, true
+}
+
+// loadThemePreview loads the theme grant is for, with its draft applied when
+// it has one.
+func (s *Server) loadThemePreview(w http.ResponseWriter, r *http.Request, grant themepreview.Grant) (*themePreview, bool) {
+	themes := s.themeStore()
+	if themes == nil {
+		jsonError(w, "Theme storage unavailable", http.StatusServiceUnavailable)
+		return nil, false
+	}
+
+	preview := &themePreview{grant: grant}
+	theme, err := themes.FindThemeByID(r.Context(), grant.ThemeID)
+	if err == nil && theme.ClientID != grant.ClientID {
+		err = repository.ErrNotFound
+	}
+	if err == nil {
+		preview.theme = theme
+		var draft *models.ThemeDraft
+		draft, err = themes.FindThemeDraft(r.Context(), grant.ClientID, grant.ThemeID)
+		switch {
+		case err == nil:
+			preview.draft = true
+			draft.Snapshot.ApplyTo(theme)
+			for _, c := range draft.Snapshot.Configurations {
+				preview.configs = append(preview.configs, models.ThemeConfiguration{
+					ThemeID: theme.ID, ConfigKey: c.Key, ConfigValue: c.Value, ConfigType: c.Type,
+				})
+			}
+		case errors.Is(err, repository.ErrNotFound):
+			preview.configs, err = themes.FindConfigurationsByThemeID(r.Context(), theme.ID)
+		}
+	}
+
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		jsonError(w, "Preview theme not found", http.StatusNotFound)
+		return nil, false
+	case err != nil:
+		s.requestLogger(r, "theme_preview").Error("failed to load preview theme", "theme_id", grant.ThemeID, "error", err)
+		jsonError(w, "Failed to load preview theme", http.StatusInternalServerError)
+		return nil, false
+	}
+	return preview, true
+}
+
+// themePreviewStylesheetURL is the stylesheet of a preview. It carries the
+// token instead of a content version and is never cached.
+func themePreviewStylesheetURL(clientID, token string) string {
+	return "/api/oauth/theme.css?" + url.Values{
+		"client_id":       {clientID},
+		themePreviewParam: {token},
+	}.Encode()
+}
@@ -0,0 +1,130 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"closeauth-frontend/internal/config"
+	"closeauth-frontend/internal/database/models"
+	"closeauth-frontend/internal/database/repository"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestThemePreview(t *testing.T) {
+	live, next := "#111111", "#222222"
+	store := repository.NewMemoryThemeStore()
+	if _, err := store.AddTheme(models.ClientTheme{ClientID: "acme", ThemeName: "live", IsActive: true, IsDefault: true, LightPrimaryColor: &live}); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := store.AddTheme(models.ClientTheme{ClientID: "acme", ThemeName: "next", LightPrimaryColor: &next})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{
+		themes: store,
+		themesCfg: &config.ThemesConfig{
+			Source:            config.ThemeSourceLocal,
+			PreviewSigningKey: strings.Repeat("k", 32),
+			PreviewTTL:        time.Hour,
+		},
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	r := chi.NewRouter()
+	r.Post("/clients/{clientId}/themes/{themeId}/preview", s.themeHandlers().preview)
+	r.Get("/api/oauth/theme", s.handleOAuthThemeImpl)
+	r.Get("/api/oauth/theme.css", s.handleOAuthThemeCSSImpl)
+
+	createPreview := func(clientID string, themeID int) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/clients/"+clientID+"/themes/"+strconv.Itoa(themeID)+"/preview", nil))
+		return rec
+	}
+	previewToken := func() string {
+		rec := createPreview("acme", saved.ID)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("create preview = %d %s", rec.Code, rec.Body)
+		}
+		var body struct {
+			Data struct {
+				Token      string `json:"token"`
+				PreviewURL string `json:"previewUrl"`
+			} `json:"data"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(body.Data.PreviewURL, "preview_token="+url.QueryEscape(body.Data.Token)) {
+			t.Errorf("previewUrl = %q, want the token", body.Data.PreviewURL)
+		}
+		return body.Data.Token
+	}
+
+	// The saved theme first, then its draft once it has one
+	token := previewToken()
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/oauth/theme?client_id=acme&preview_token="+token, nil))
+	if body := rec.Body.String(); !strings.Contains(body, `"primary":"#222222"`) || !strings.Contains(body, `"preview_draft":false`) {
+		t.Errorf("preview without a draft = %d %s, want the saved theme", rec.Code, body)
+	}
+	draft := models.NewThemeSnapshot(&saved, nil)
+	draftPrimary := "#333333"
+	draft.LightPrimaryColor = &draftPrimary
+	if err := store.SaveThemeDraft(t.Context(), "acme", &models.ThemeDraft{ThemeID: saved.ID, Snapshot: draft}); err != nil {
+		t.Fatal(err)
+	}
+	draftToken := previewToken()
+	expired, _ := s.themePreviewSigner().Sign("acme", saved.ID, time.Now().Add(-2*time.Hour))
+	missing, _ := s.themePreviewSigner().Sign("acme", 999, time.Now())
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantBody   []string
+	}{
+		{"no token", "client_id=acme", http.StatusOK, []string{`"primary":"#111111"`, `"preview":false`}},
+		{"preview", "client_id=acme&preview_token=" + token, http.StatusOK, []string{`"primary":"#333333"`, `"preview":true`, `"preview_draft":true`}},
+		{"draft shown when opened", "client_id=acme&preview_token=" + draftToken, http.StatusOK, []string{`"primary":"#333333"`}},
+		{"other client", "client_id=globex&preview_token=" + token, http.StatusForbidden, []string{"Invalid preview link"}},
+		{"tampered", "client_id=acme&preview_token=" + token + "x", http.StatusForbidden, []string{"Invalid preview link"}},
+		{"expired", "client_id=acme&preview_token=" + expired, http.StatusForbidden, []string{"Preview link has expired"}},
+		{"theme gone", "client_id=acme&preview_token=" + missing, http.StatusNotFound, []string{"Preview theme not found"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/oauth/theme?"+tt.query, nil))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d %s, want %d", rec.Code, rec.Body, tt.wantStatus)
+			}
+			for _, want := range tt.wantBody {
+				if !strings.Contains(rec.Body.String(), want) {
+					t.Errorf("body = %s, want %s", rec.Body, want)
+				}
+			}
+		})
+	}
+
+	// The preview's stylesheet renders the draft and is never cached
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, themePreviewStylesheetURL("acme", token), nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "--theme-primary: #333333;") {
+		t.Errorf("preview stylesheet = %d %s", rec.Code, rec.Body)
+	}
+	if cc := rec.Header().Get("Cache-Control"); cc != "no-store" {
+		t.Errorf("preview Cache-Control = %q, want no-store", cc)
+	}
+
+	if rec := createPreview("globex", saved.ID); rec.Code != http.StatusNotFound {
+		t.Errorf("preview of another client's theme = %d, want 404", rec.Code)
+	}
+}
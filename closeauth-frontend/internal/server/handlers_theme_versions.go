@@ -0,0 +1,402 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"closeauth-frontend/internal/config"
+	"closeauth-frontend/internal/database/models"
+	"closeauth-frontend/internal/database/repository"
+	"closeauth-frontend/internal/middleware"
+	"closeauth-frontend/internal/themelint"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ──────────────────────────────────────────────────────────────────────────────
+// Theme Versions and Drafts
+//
+// With THEME_SOURCE=local every change to a theme's content or configuration
+// is kept as an immutable version (see repository.ThemeStore). Admins can list
+// and diff versions, roll back to one, and stage edits in a draft that only
+// reaches the login pages when published. Spring keeps no history, so these
+// routes answer 501 under THEME_SOURCE=spring.
+// ──────────────────────────────────────────────────────────────────────────────
+
+// themeSnapshotResponse is a version's or draft's content, in the camelCase of
+// themeResponse.
+type themeSnapshotResponse struct {
+	LogoURL              *string              `json:"logoUrl"`
+	LightPrimaryColor    *string              `json:"lightPrimaryColor"`
+	LightBackgroundColor *string              `json:"lightBackgroundColor"`
+	LightButtonColor     *string              `json:"lightButtonColor"`
+	LightTextColor       *string              `json:"lightTextColor"`
+	DarkPrimaryColor     *string              `json:"darkPrimaryColor"`
+	DarkBackgroundColor  *string              `json:"darkBackgroundColor"`
+	DarkButtonColor      *string              `json:"darkButtonColor"`
+	DarkTextColor        *string              `json:"darkTextColor"`
+	DefaultMode          *string              `json:"defaultMode"`
+	AllowModeToggle      bool                 `json:"allowModeToggle"`
+	Configurations       []themeConfigRequest `json:"configurations"`
+}
+
+func newThemeSnapshotResponse(s models.ThemeSnapshot) themeSnapshotResponse {
+	resp := themeSnapshotResponse{
+		LogoURL:           s.LogoURL,
+		LightPrimaryColor: s.LightPrimaryColor, LightBackgroundColor: s.LightBackgroundColor,
+		LightButtonColor: s.LightButtonColor, LightTextColor: s.LightTextColor,
+		DarkPrimaryColor: s.DarkPrimaryColor, DarkBackgroundColor: s.DarkBackgroundColor,
+		DarkButtonColor: s.DarkButtonColor, DarkTextColor: s.DarkTextColor,
+		DefaultMode: s.DefaultMode, AllowModeToggle: s.AllowModeToggle,
+		Configurations: make([]themeConfigRequest, 0, len(s.Configurations)),
+	}
+	for _, c := range s.Configurations {
+		resp.Configurations = append(resp.Configurations, themeConfigRequest{ConfigKey: c.Key, ConfigValue: c.Value, ConfigType: c.Type})
+	}
+	return resp
+}
+
+type themeVersionResponse struct {
+	ThemeID       int                   `json:"themeId"`
+	Version       int                   `json:"version"`
+	Author        string                `json:"author"`
+	Change        string                `json:"change"`
+	SourceVersion *int                  `json:"sourceVersion,omitempty"`
+	CreatedAt     time.Time             `json:"createdAt"`
+	Snapshot      themeSnapshotResponse `json:"snapshot"`
+}
+
+func newThemeVersionResponse(v *models.ThemeVersion) themeVersionResponse {
+	return themeVersionResponse{
+		ThemeID: v.ThemeID, Version: v.Version, Author: v.Author, Change: v.Change,
+		SourceVersion: v.SourceVersion, CreatedAt: v.CreatedAt,
+		Snapshot: newThemeSnapshotResponse(v.Snapshot),
+	}
+}
+
+// themeDraftResponse carries the draft and its changes against the theme as
+// currently published.
+type themeDraftResponse struct {
+	ThemeID     int                   `json:"themeId"`
+	BaseVersion int                   `json:"baseVersion"`
+	Author      string                `json:"author"`
+	CreatedAt   time.Time             `json:"createdAt"`
+	UpdatedAt   time.Time             `json:"updatedAt"`
+	Snapshot    themeSnapshotResponse `json:"snapshot"`
+	Changes     []models.FieldChange  `json:"changes"`
+}
+
+// themeDraftRequest is the body of a draft save: themeRequest's fields over the
+// current draft, or the published theme when there is none. The name and the
+// flags are ignored; configurations, when present, replace the whole set.
+type themeDraftRequest struct {
+	themeRequest
+	Configurations *[]themeConfigRequest `json:"configurations"`
+}
+
+// withThemeAuthor attributes the theme versions and drafts a handler writes
+// to the signed-in admin.
+func (s *Server) withThemeAuthor(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if session, err := middleware.GetSession(r); err == nil {
+			author := session.Email
+			if author == "" {
+				author = session.UserID
+			}
+			r = r.WithContext(repository.WithAuthor(r.Context(), author))
+		}
+		next(w, r)
+	}
+}
+
+// handleThemeVersionsUnsupported answers the version, draft and preview routes
+// under THEME_SOURCE=spring.
+func (s *Server) handleThemeVersionsUnsupported(w http.ResponseWriter, r *http.Request) {
+	jsonError(w, "Theme versions, drafts and previews require THEME_SOURCE="+config.ThemeSourceLocal, http.StatusNotImplemented)
+}
+
+// ── Versions ─────────────────────────────────────────────────────────────────
+
+func (s *Server) handleGetThemeVersions(w http.ResponseWriter, r *http.Request) {
+	store, ok := s.localThemeStore(w)
+	if !ok {
+		return
+	}
+	theme, ok := s.loadClientTheme(w, r, store, nil)
+	if !ok {
+		return
+	}
+	versions, err := store.ListThemeVersions(r.Context(), theme.ClientID, theme.ID)
+	if err != nil {
+		s.writeThemeStoreError(w, r, nil, err, "list theme versions")
+		return
+	}
+	data := make([]themeVersionResponse, 0, len(versions))
+	for i := range versions {
+		data = append(data, newThemeVersionResponse(&versions[i]))
+	}
+	writeThemeResponse(w, nil, http.StatusOK, "Theme versions retrieved successfully", data)
+}
+
+func (s *Server) handleGetThemeVersion(w http.ResponseWriter, r *http.Request) {
+	store, ok := s.localThemeStore(w)
+	if !ok {
+		return
+	}
+	version, ok := s.loadThemeVersion(w, r, store, nil, chi.URLParam(r, "version"))
+	if !ok {
+		return
+	}
+	writeThemeResponse(w, nil, http.StatusOK, "Theme version retrieved successfully", newThemeVersionResponse(version))
+}
+
+// handleDiffThemeVersions lists the field changes between ?from= and ?to=.
+func (s *Server) handleDiffThemeVersions(w http.ResponseWriter, r *http.Request) {
+	store, ok := s.localThemeStore(w)
+	if !ok {
+		return
+	}
+	from, ok := s.loadThemeVersion(w, r, store, nil, r.URL.Query().Get("from"))
+	if !ok {
+		return
+	}
+	to, ok := s.loadThemeVersion(w, r, store, nil, r.URL.Query().Get("to"))
+	if !ok {
+		return
+	}
+	writeThemeResponse(w, nil, http.StatusOK, "Theme versions compared successfully", map[string]any{
+		"from":    from.Version,
+		"to":      to.Version,
+		"changes": from.Snapshot.Diff(to.Snapshot),
+	})
+}
+
+// handleRollbackTheme restores {version} as the theme's newest version. The
+// restored colours go through the contrast policy like any other write.
+func (s *Server) handleRollbackTheme(w http.ResponseWriter, r *http.Request) {
+	store, ok := s.localThemeStore(w)
+	if !ok {
+		return
+	}
+	audit := s.startThemeAudit(r)
+	defer s.recordAudit(r, audit)
+
+	target, ok := s.loadThemeVersion(w, r, store, audit, chi.URLParam(r, "version"))
+	if !ok {
+		return
+	}
+	warnings, ok := s.checkSnapshotContrast(w, r, store, audit, target.Snapshot)
+	if !ok {
+		return
+	}
+
+	restored, err := store.RollbackTheme(r.Context(), chi.URLParam(r, "clientId"), target.ThemeID, target.Version)
+	if err != nil {
+		s.writeThemeStoreError(w, r, audit, err, "roll back theme")
+		return
+	}
+	message := "Theme rolled back to version " + strconv.Itoa(target.Version)
+	writeThemeResponse(w, audit, http.StatusOK, message, newThemeVersionResponse(restored), warnings...)
+}
+
+// ── Drafts ───────────────────────────────────────────────────────────────────
+
+func (s *Server) handleGetThemeDraft(w http.ResponseWriter, r *http.Request) {
+	store, ok := s.localThemeStore(w)
+	if !ok {
+		return
+	}
+	theme, ok := s.loadClientTheme(w, r, store, nil)
+	if !ok {
+		return
+	}
+	draft, err := store.FindThemeDraft(r.Context(), theme.ClientID, theme.ID)
+	if err != nil {
+		s.writeThemeDraftError(w, r, nil, err, "get theme draft")
+		return
+	}
+	published, err := s.publishedSnapshot(r, store, theme)
+	if err != nil {
+		s.writeThemeStoreError(w, r, nil, err, "get theme draft")
+		return
+	}
+	writeThemeResponse(w, nil, http.StatusOK, "Theme draft retrieved successfully", newThemeDraftResponse(draft, published))
+}
+
+// handleSaveThemeDraft creates or edits the draft. Drafts are never rejected
+// for contrast; findings are returned as warnings and enforced on publish.
+func (s *Server) handleSaveThemeDraft(w http.ResponseWriter, r *http.Request) {
+	store, ok := s.localThemeStore(w)
+	if !ok {
+		return
+	}
+	audit := s.startThemeAudit(r)
+	defer s.recordAudit(r, audit)
+
+	var req themeDraftRequest
+	if !decodeThemeRequest(w, r, audit, &req) {
+		return
+	}
+	theme, ok := s.loadClientTheme(w, r, store, audit)
+	if !ok {
+		return
+	}
+	published, err := s.publishedSnapshot(r, store, theme)
+	if err != nil {
+		s.writeThemeStoreError(w, r, audit, err, "save theme draft")
+		return
+	}
+
+	snapshot := published
+	if current, err := store.FindThemeDraft(r.Context(), theme.ClientID, theme.ID); err == nil {
+		snapshot = current.Snapshot
+	}
+	audit.Before = themeAuditSummary(newThemeSnapshotResponse(snapshot))
+
+	edited := *theme
+	snapshot.ApplyTo(&edited)
+	req.apply(&edited)
+	next := models.NewThemeSnapshot(&edited, nil)
+	next.Configurations = snapshot.Configurations
+	if req.Configurations != nil {
+		next.Configurations = make([]models.SnapshotConfig, 0, len(*req.Configurations))
+		for _, c := range *req.Configurations {
+			next.Configurations = append(next.Configurations, models.SnapshotConfig{Key: c.ConfigKey, Value: c.ConfigValue, Type: c.ConfigType})
+		}
+	}
+
+	draft := &models.ThemeDraft{ThemeID: theme.ID, Snapshot: next}
+	if err := store.SaveThemeDraft(r.Context(), theme.ClientID, draft); err != nil {
+		s.writeThemeStoreError(w, r, audit, err, "save theme draft")
+		return
+	}
+
+	var warnings []themelint.Finding
+	if level, enforcement := s.contrastPolicy(); enforcement != config.ContrastOff {
+		warnings = themelint.Check(&edited, level)
+	}
+	writeThemeResponse(w, audit, http.StatusOK, "Theme draft saved successfully", newThemeDraftResponse(draft, published), warnings...)
+}
+
+func (s *Server) handleDiscardThemeDraft(w http.ResponseWriter, r *http.Request) {
+	store, ok := s.localThemeStore(w)
+	if !ok {
+		return
+	}
+	audit := s.startThemeAudit(r)
+	defer s.recordAudit(r, audit)
+
+	theme, ok := s.loadClientTheme(w, r, store, audit)
+	if !ok {
+		return
+	}
+	if err := store.DiscardThemeDraft(r.Context(), theme.ClientID, theme.ID); err != nil {
+		s.writeThemeDraftError(w, r, audit, err, "discard theme draft")
+		return
+	}
+	writeThemeResponse(w, audit, http.StatusOK, "Theme draft discarded successfully", nil)
+}
+
+// handlePublishThemeDraft makes the draft the theme's newest version. A draft
+// overtaken by another change since it was started answers 409.
+func (s *Server) handlePublishThemeDraft(w http.ResponseWriter, r *http.Request) {
+	store, ok := s.localThemeStore(w)
+	if !ok {
+		return
+	}
+	audit := s.startThemeAudit(r)
+	defer s.recordAudit(r, audit)
+
+	theme, ok := s.loadClientTheme(w, r, store, audit)
+	if !ok {
+		return
+	}
+	audit.Before = themeAuditSummary(newThemeResponse(theme))
+
+	draft, err := store.FindThemeDraft(r.Context(), theme.ClientID, theme.ID)
+	if err != nil {
+		s.writeThemeDraftError(w, r, audit, err, "publish theme draft")
+		return
+	}
+	warnings, ok := s.checkSnapshotContrast(w, r, store, audit, draft.Snapshot)
+	if !ok {
+		return
+	}
+
+	published, err := store.PublishThemeDraft(r.Context(), theme.ClientID, theme.ID)
+	if err != nil {
+		s.writeThemeDraftError(w, r, audit, err, "publish theme draft")
+		return
+	}
+	writeThemeResponse(w, audit, http.StatusOK, "Theme draft published successfully", newThemeVersionResponse(published), warnings...)
+}
+
+// ── Helpers ──────────────────────────────────────────────────────────────────
+
+// loadThemeVersion loads version raw of {themeId}, answering 400 if raw is not
+// a version number and 404 unless the theme belongs to {clientId}.
+func (s *Server) loadThemeVersion(w http.ResponseWriter, r *http.Request, store repository.ThemeStore, audit *models.AuditEvent, raw string) (*models.ThemeVersion, bool) {
+	theme, ok := s.loadClientTheme(w, r, store, audit)
+	if !ok {
+		return nil, false
+	}
+	version, err := strconv.Atoi(raw)
+	if err != nil || version < 1 {
+		writeThemeError(w, audit, http.StatusBadRequest, "Invalid theme version")
+		return nil, false
+	}
+	v, err := store.FindThemeVersion(r.Context(), theme.ClientID, theme.ID, version)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeThemeError(w, audit, http.StatusNotFound, "Theme version not found")
+		return nil, false
+	}
+	if err != nil {
+		s.writeThemeStoreError(w, r, audit, err, "get theme version")
+		return nil, false
+	}
+	if audit != nil {
+		audit.Before = themeAuditSummary(newThemeResponse(theme))
+	}
+	return v, true
+}
+
+// writeThemeDraftError is writeThemeStoreError for a theme already loaded, so
+// a missing row is its draft.
+func (s *Server) writeThemeDraftError(w http.ResponseWriter, r *http.Request, audit *models.AuditEvent, err error, action string) {
+	if errors.Is(err, repository.ErrNotFound) {
+		writeThemeError(w, audit, http.StatusNotFound, "Theme has no draft")
+		return
+	}
+	s.writeThemeStoreError(w, r, audit, err, action)
+}
+
+// publishedSnapshot captures the theme as the login pages currently see it.
+func (s *Server) publishedSnapshot(r *http.Request, store repository.ThemeStore, theme *models.ClientTheme) (models.ThemeSnapshot, error) {
+	configs, err := store.FindConfigurationsByThemeID(r.Context(), theme.ID)
+	if err != nil {
+		return models.ThemeSnapshot{}, err
+	}
+	return models.NewThemeSnapshot(theme, configs), nil
+}
+
+// checkSnapshotContrast applies the contrast policy to {themeId} as it would
+// be with snapshot applied.
+func (s *Server) checkSnapshotContrast(w http.ResponseWriter, r *http.Request, store repository.ThemeStore, audit *models.AuditEvent, snapshot models.ThemeSnapshot) ([]themelint.Finding, bool) {
+	theme, ok := s.loadClientTheme(w, r, store, audit)
+	if !ok {
+		return nil, false
+	}
+	snapshot.ApplyTo(theme)
+	return s.checkContrast(w, audit, theme)
+}
+
+func newThemeDraftResponse(draft *models.ThemeDraft, published models.ThemeSnapshot) themeDraftResponse {
+	return themeDraftResponse{
+		ThemeID: draft.ThemeID, BaseVersion: draft.BaseVersion, Author: draft.Author,
+		CreatedAt: draft.CreatedAt, UpdatedAt: draft.UpdatedAt,
+		Snapshot: newThemeSnapshotResponse(draft.Snapshot),
+		Changes:  published.Diff(draft.Snapshot),
+	}
+}
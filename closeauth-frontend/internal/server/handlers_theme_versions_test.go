@@ -0,0 +1,108 @@
+package server
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"closeauth-frontend/internal/config"
+	"closeauth-frontend/internal/database/models"
+	"closeauth-frontend/internal/database/repository"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestThemeVersionHandlers(t *testing.T) {
+	store := repository.NewMemoryThemeStore()
+	current, err := store.AddTheme(models.ClientTheme{ClientID: "acme", ThemeName: "current", IsActive: true, IsDefault: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newRouter := func(source string) http.Handler {
+		s := &Server{
+			themes: store,
+			themesCfg: &config.ThemesConfig{
+				Source:              source,
+				ContrastLevel:       config.ContrastAA,
+				ContrastEnforcement: config.ContrastReject,
+			},
+			logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		}
+		themes := s.themeHandlers()
+		r := chi.NewRouter()
+		r.Route("/clients/{clientId}/themes/{themeId}", func(r chi.Router) {
+			r.Put("/", themes.update)
+			r.Patch("/activate", themes.activate)
+			r.Get("/versions", themes.versions)
+			r.Get("/versions/diff", themes.diffVersions)
+			r.Get("/versions/{version}", themes.version)
+			r.Post("/versions/{version}/rollback", themes.rollback)
+			r.Get("/draft", themes.draft)
+			r.Put("/draft", themes.saveDraft)
+			r.Delete("/draft", themes.discardDraft)
+			r.Post("/draft/publish", themes.publishDraft)
+		})
+		return r
+	}
+	local := newRouter(config.ThemeSourceLocal)
+	path := "/clients/acme/themes/" + strconv.Itoa(current.ID)
+
+	// Each step runs against the state the previous ones left
+	steps := []struct {
+		name         string
+		method, path string
+		body         string
+		wantStatus   int
+		wantBody     string
+	}{
+		{"update", http.MethodPut, path + "/", `{"lightTextColor":"#222222"}`, http.StatusOK, `"SUCCESS"`},
+		{"update again", http.MethodPut, path + "/", `{"lightTextColor":"#333333","logoUrl":"/logo.png"}`, http.StatusOK, `"SUCCESS"`},
+		{"list", http.MethodGet, path + "/versions", "", http.StatusOK, `"version":2,"author":"","change":"update"`},
+		{"get", http.MethodGet, path + "/versions/1", "", http.StatusOK, `"lightTextColor":"#222222"`},
+		{"get missing", http.MethodGet, path + "/versions/9", "", http.StatusNotFound, "Theme version not found"},
+		{"get bad number", http.MethodGet, path + "/versions/latest", "", http.StatusBadRequest, "Invalid theme version"},
+		{"get other client's", http.MethodGet, "/clients/globex/themes/" + strconv.Itoa(current.ID) + "/versions/1", "", http.StatusNotFound, "Theme not found"},
+		{"diff", http.MethodGet, path + "/versions/diff?from=1&to=2", "", http.StatusOK, `"changes":[{"field":"logo_url","from":null,"to":"/logo.png"},{"field":"light_text_color","from":"#222222","to":"#333333"}]`},
+		{"diff without to", http.MethodGet, path + "/versions/diff?from=1", "", http.StatusBadRequest, "Invalid theme version"},
+		{"rollback", http.MethodPost, path + "/versions/1/rollback", "", http.StatusOK, `"version":3,"author":"","change":"rollback","sourceVersion":1`},
+		{"no draft", http.MethodGet, path + "/draft", "", http.StatusNotFound, "Theme has no draft"},
+		{"save draft", http.MethodPut, path + "/draft", `{"lightTextColor":"#aaaaaa","configurations":[{"configKey":"radius","configValue":"4","configType":"number"}]}`, http.StatusOK, `"warnings"`},
+		{"save invalid draft", http.MethodPut, path + "/draft", `{"configurations":[{"configKey":"radius","configValue":"four","configType":"number"}]}`, http.StatusBadRequest, "config_value must be a number"},
+		{"get draft", http.MethodGet, path + "/draft", "", http.StatusOK, `"baseVersion":3`},
+		{"publish below contrast", http.MethodPost, path + "/draft/publish", "", http.StatusUnprocessableEntity, "WCAG AA"},
+		{"fix draft", http.MethodPut, path + "/draft", `{"lightTextColor":"#444444"}`, http.StatusOK, `"changes":[{"field":"light_text_color","from":"#222222","to":"#444444"},{"field":"configurations.radius","from":null,"to":{"key":"radius","value":"4","type":"number"}}]`},
+		{"publish", http.MethodPost, path + "/draft/publish", "", http.StatusOK, `"version":4,"author":"","change":"publish","sourceVersion":3`},
+		{"publish again", http.MethodPost, path + "/draft/publish", "", http.StatusNotFound, "Theme has no draft"},
+		{"new draft", http.MethodPut, path + "/draft", `{}`, http.StatusOK, `"baseVersion":4`},
+		{"discarded", http.MethodDelete, path + "/draft", "", http.StatusOK, `"SUCCESS"`},
+		{"activate", http.MethodPatch, path + "/activate", "", http.StatusOK, `"SUCCESS"`},
+		{"activation recorded", http.MethodGet, path + "/versions", "", http.StatusOK, `"version":5,"author":"","change":"activate"`},
+	}
+	for _, step := range steps {
+		rec := httptest.NewRecorder()
+		local.ServeHTTP(rec, httptest.NewRequest(step.method, step.path, strings.NewReader(step.body)))
+		if rec.Code != step.wantStatus || !strings.Contains(rec.Body.String(), step.wantBody) {
+			t.Errorf("%s: %s %s = %d %s; want %d containing %s", step.name, step.method, step.path, rec.Code, rec.Body, step.wantStatus, step.wantBody)
+		}
+	}
+
+	theme, err := store.FindThemeByID(t.Context(), current.ID)
+	if err != nil || *theme.LightTextColor != "#444444" || theme.LogoURL != nil {
+		t.Errorf("published theme = %+v, %v", theme, err)
+	}
+	if configs, _ := store.FindConfigurationsByThemeID(t.Context(), current.ID); len(configs) != 1 {
+		t.Errorf("published configuration = %+v, want radius", configs)
+	}
+
+	// Spring keeps no history
+	rec := httptest.NewRecorder()
+	newRouter(config.ThemeSourceSpring).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path+"/versions", nil))
+	if rec.Code != http.StatusNotImplemented {
+		t.Errorf("versions under THEME_SOURCE=spring = %d, want 501", rec.Code)
+	}
+}
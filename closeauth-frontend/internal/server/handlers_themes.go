@@ -0,0 +1,609 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"closeauth-frontend/internal/database/models"
+	"closeauth-frontend/internal/database/repository"
+	"closeauth-frontend/internal/themelint"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ──────────────────────────────────────────────────────────────────────────────
+// Local Theme Handlers
+//
+// With THEME_SOURCE=local the admin theme API is served from the BFF's own
+// ThemeStore instead of being proxied to Spring. Requests and responses keep
+// Spring's shape (camelCase DTOs in a {message, status, timestamp, data}
+// envelope) so the admin SPA works against either source.
+// ──────────────────────────────────────────────────────────────────────────────
+
+// maxThemeBodySize caps theme and configuration request bodies.
+const maxThemeBodySize = 64 << 10
+
+// themeRoutes are the handlers behind /admin/clients/{clientId}/themes.
+type themeRoutes struct {
+	create, list, active, get, update, delete, activate http.HandlerFunc
+
+	createConfig, listConfigs, getConfig, updateConfig, deleteConfig http.HandlerFunc
+
+	versions, version, diffVersions, rollback http.HandlerFunc
+
+	draft, saveDraft, discardDraft, publishDraft http.HandlerFunc
+
+	preview http.HandlerFunc
+}
+
+// themeHandlers picks the Spring proxies or the local handlers by THEME_SOURCE.
+func (s *Server) themeHandlers() themeRoutes {
+	if s.localThemes() {
+		return themeRoutes{
+			create:       s.withThemeAuthor(s.handleCreateThemeLocal),
+			list:         s.handleGetThemesLocal,
+			active:       s.handleGetActiveThemeLocal,
+			get:          s.handleGetThemeLocal,
+			update:       s.withThemeAuthor(s.handleUpdateThemeLocal),
+			delete:       s.withThemeAuthor(s.handleDeleteThemeLocal),
+			activate:     s.withThemeAuthor(s.handleActivateThemeLocal),
+			createConfig: s.withThemeAuthor(s.handleCreateThemeConfigLocal),
+			listConfigs:  s.handleGetThemeConfigsLocal,
+			getConfig:    s.handleGetThemeConfigLocal,
+			updateConfig: s.withThemeAuthor(s.handleUpdateThemeConfigLocal),
+			deleteConfig: s.withThemeAuthor(s.handleDeleteThemeConfigLocal),
+			versions:     s.handleGetThemeVersions,
+			version:      s.handleGetThemeVersion,
+			diffVersions: s.handleDiffThemeVersions,
+			rollback:     s.withThemeAuthor(s.handleRollbackTheme),
+			draft:        s.handleGetThemeDraft,
+			saveDraft:    s.withThemeAuthor(s.handleSaveThemeDraft),
+			discardDraft: s.handleDiscardThemeDraft,
+			publishDraft: s.withThemeAuthor(s.handlePublishThemeDraft),
+			preview:      s.handleCreateThemePreview,
+		}
+	}
+	unsupported := s.handleThemeVersionsUnsupported
+	return themeRoutes{
+		create:       s.lintBeforeProxy(s.handleCreateTheme),
+		list:         s.handleGetThemes,
+		active:       s.handleGetActiveTheme,
+		get:          s.handleGetTheme,
+		update:       s.lintBeforeProxy(s.handleUpdateTheme),
+		delete:       s.handleDeleteTheme,
+		activate:     s.handleActivateTheme,
+		createConfig: s.handleCreateThemeConfig,
+		listConfigs:  s.handleGetThemeConfigs,
+		getConfig:    s.handleGetThemeConfig,
+		updateConfig: s.handleUpdateThemeConfig,
+		deleteConfig: s.handleDeleteThemeConfig,
+		versions:     unsupported,
+		version:      unsupported,
+		diffVersions: unsupported,
+		rollback:     unsupported,
+		draft:        unsupported,
+		saveDraft:    unsupported,
+		discardDraft: unsupported,
+		publishDraft: unsupported,
+		preview:      unsupported,
+	}
+}
+
+// themeResponse mirrors Spring's ThemeResponse.
+type themeResponse struct {
+	ID                   int       `json:"id"`
+	ClientID             string    `json:"clientId"`
+	ThemeName            string    `json:"themeName"`
+	IsActive             bool      `json:"isActive"`
+	IsDefault            bool      `json:"isDefault"`
+	LogoURL              *string   `json:"logoUrl"`
+	LightPrimaryColor    *string   `json:"lightPrimaryColor"`
+	LightBackgroundColor *string   `json:"lightBackgroundColor"`
+	LightButtonColor     *string   `json:"lightButtonColor"`
+	LightTextColor       *string   `json:"lightTextColor"`
+	DarkPrimaryColor     *string   `json:"darkPrimaryColor"`
+	DarkBackgroundColor  *string   `json:"darkBackgroundColor"`
+	DarkButtonColor      *string   `json:"darkButtonColor"`
+	DarkTextColor        *string   `json:"darkTextColor"`
+	DefaultMode          *string   `json:"defaultMode"`
+	AllowModeToggle      bool      `json:"allowModeToggle"`
+	CreatedAt            time.Time `json:"createdAt"`
+	UpdatedAt            time.Time `json:"updatedAt"`
+}
+
+func newThemeResponse(t *models.ClientTheme) themeResponse {
+	return themeResponse{
+		ID: t.ID, ClientID: t.ClientID, ThemeName: t.ThemeName,
+		IsActive: t.IsActive, IsDefault: t.IsDefault, LogoURL: t.LogoURL,
+		LightPrimaryColor: t.LightPrimaryColor, LightBackgroundColor: t.LightBackgroundColor,
+		LightButtonColor: t.LightButtonColor, LightTextColor: t.LightTextColor,
+		DarkPrimaryColor: t.DarkPrimaryColor, DarkBackgroundColor: t.DarkBackgroundColor,
+		DarkButtonColor: t.DarkButtonColor, DarkTextColor: t.DarkTextColor,
+		DefaultMode: t.DefaultMode, AllowModeToggle: t.AllowModeToggle,
+		CreatedAt: t.CreatedAt, UpdatedAt: t.UpdatedAt,
+	}
+}
+
+// themeConfigResponse mirrors Spring's ThemeConfigResponse.
+type themeConfigResponse struct {
+	ID          int       `json:"id"`
+	ThemeID     int       `json:"themeId"`
+	ConfigKey   string    `json:"configKey"`
+	ConfigValue string    `json:"configValue"`
+	ConfigType  string    `json:"configType"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+func newThemeConfigResponse(c *models.ThemeConfiguration) themeConfigResponse {
+	return themeConfigResponse{
+		ID: c.ID, ThemeID: c.ThemeID, ConfigKey: c.ConfigKey, ConfigValue: c.ConfigValue,
+		ConfigType: c.ConfigType, CreatedAt: c.CreatedAt, UpdatedAt: c.UpdatedAt,
+	}
+}
+
+// themeRequest is the body of create and update. Nil fields are left unset on
+// create and unchanged on update; the name is fixed once created.
+type themeRequest struct {
+	ThemeName            string  `json:"themeName"`
+	LogoURL              *string `json:"logoUrl"`
+	LightPrimaryColor    *string `json:"lightPrimaryColor"`
+	LightBackgroundColor *string `json:"lightBackgroundColor"`
+	LightButtonColor     *string `json:"lightButtonColor"`
+	LightTextColor       *string `json:"lightTextColor"`
+	DarkPrimaryColor     *string `json:"darkPrimaryColor"`
+	DarkBackgroundColor  *string `json:"darkBackgroundColor"`
+	DarkButtonColor      *string `json:"darkButtonColor"`
+	DarkTextColor        *string `json:"darkTextColor"`
+	DefaultMode          *string `json:"defaultMode"`
+	AllowModeToggle      *bool   `json:"allowModeToggle"`
+	IsDefault            *bool   `json:"isDefault"` // create only
+	IsActive             *bool   `json:"isActive"`  // update only; true activates, false is refused for the active theme
+}
+
+// apply copies the set fields onto theme.
+func (req *themeRequest) apply(theme *models.ClientTheme) {
+	set := func(dst **string, src *string) {
+		if src != nil {
+			*dst = src
+		}
+	}
+	set(&theme.LogoURL, req.LogoURL)
+	set(&theme.LightPrimaryColor, req.LightPrimaryColor)
+	set(&theme.LightBackgroundColor, req.LightBackgroundColor)
+	set(&theme.LightButtonColor, req.LightButtonColor)
+	set(&theme.LightTextColor, req.LightTextColor)
+	set(&theme.DarkPrimaryColor, req.DarkPrimaryColor)
+	set(&theme.DarkBackgroundColor, req.DarkBackgroundColor)
+	set(&theme.DarkButtonColor, req.DarkButtonColor)
+	set(&theme.DarkTextColor, req.DarkTextColor)
+	set(&theme.DefaultMode, req.DefaultMode)
+	if req.AllowModeToggle != nil {
+		theme.AllowModeToggle = *req.AllowModeToggle
+	}
+}
+
+// themeConfigRequest is the body of configuration create and update. The key
+// is fixed once created.
+type themeConfigRequest struct {
+	ConfigKey   string `json:"configKey"`
+	ConfigValue string `json:"configValue"`
+	ConfigType  string `json:"configType"`
+}
+
+// ── Themes ───────────────────────────────────────────────────────────────────
+
+func (s *Server) handleCreateThemeLocal(w http.ResponseWriter, r *http.Request) {
+	store, ok := s.localThemeStore(w)
+	if !ok {
+		return
+	}
+	audit := s.startThemeAudit(r)
+	defer s.recordAudit(r, audit)
+
+	var req themeRequest
+	if !decodeThemeRequest(w, r, audit, &req) {
+		return
+	}
+	theme := &models.ClientTheme{
+		ClientID:        chi.URLParam(r, "clientId"),
+		ThemeName:       req.ThemeName,
+		IsDefault:       req.IsDefault != nil && *req.IsDefault,
+		AllowModeToggle: true,
+	}
+	req.apply(theme)
+	warnings, ok := s.checkContrast(w, audit, theme)
+	if !ok {
+		return
+	}
+
+	if err := store.CreateTheme(r.Context(), theme); err != nil {
+		s.writeThemeStoreError(w, r, audit, err, "create theme")
+		return
+	}
+	writeThemeResponse(w, audit, http.StatusCreated, "Theme created successfully", newThemeResponse(theme), warnings...)
+}
+
+func (s *Server) handleGetThemesLocal(w http.ResponseWriter, r *http.Request) {
+	store, ok := s.localThemeStore(w)
+	if !ok {
+		return
+	}
+	themes, err := store.FindAllByClientID(r.Context(), chi.URLParam(r, "clientId"))
+	if err != nil {
+		s.writeThemeStoreError(w, r, nil, err, "list themes")
+		return
+	}
+	data := make([]themeResponse, 0, len(themes))
+	for i := range themes {
+		data = append(data, newThemeResponse(&themes[i]))
+	}
+	writeThemeResponse(w, nil, http.StatusOK, "Themes retrieved successfully", data)
+}
+
+func (s *Server) handleGetThemeLocal(w http.ResponseWriter, r *http.Request) {
+	store, ok := s.localThemeStore(w)
+	if !ok {
+		return
+	}
+	theme, ok := s.loadClientTheme(w, r, store, nil)
+	if !ok {
+		return
+	}
+	writeThemeResponse(w, nil, http.StatusOK, "Theme retrieved successfully", newThemeResponse(theme))
+}
+
+func (s *Server) handleGetActiveThemeLocal(w http.ResponseWriter, r *http.Request) {
+	store, ok := s.localThemeStore(w)
+	if !ok {
+		return
+	}
+	theme, err := store.FindDefaultTheme(r.Context(), chi.URLParam(r, "clientId"))
+	if err != nil {
+		s.writeThemeStoreError(w, r, nil, err, "get active theme")
+		return
+	}
+	writeThemeResponse(w, nil, http.StatusOK, "Active theme retrieved successfully", newThemeResponse(theme))
+}
+
+func (s *Server) handleUpdateThemeLocal(w http.ResponseWriter, r *http.Request) {
+	store, ok := s.localThemeStore(w)
+	if !ok {
+		return
+	}
+	audit := s.startThemeAudit(r)
+	defer s.recordAudit(r, audit)
+
+	var req themeRequest
+	if !decodeThemeRequest(w, r, audit, &req) {
+		return
+	}
+	theme, ok := s.loadClientTheme(w, r, store, audit)
+	if !ok {
+		return
+	}
+	audit.Before = themeAuditSummary(newThemeResponse(theme))
+
+	// A client always has an active theme, so it can only be replaced
+	if req.IsActive != nil && !*req.IsActive && theme.IsActive {
+		writeThemeError(w, audit, http.StatusConflict, "Cannot deactivate the active theme, activate another theme first")
+		return
+	}
+	activate := req.IsActive != nil && *req.IsActive && !theme.IsActive
+
+	req.apply(theme)
+	warnings, ok := s.checkContrast(w, audit, theme)
+	if !ok {
+		return
+	}
+	update := store.UpdateTheme
+	if activate {
+		update = store.UpdateAndActivateTheme
+	}
+	if err := update(r.Context(), theme); err != nil {
+		s.writeThemeStoreError(w, r, audit, err, "update theme")
+		return
+	}
+	writeThemeResponse(w, audit, http.StatusOK, "Theme updated successfully", newThemeResponse(theme), warnings...)
+}
+
+func (s *Server) handleDeleteThemeLocal(w http.ResponseWriter, r *http.Request) {
+	store, ok := s.localThemeStore(w)
+	if !ok {
+		return
+	}
+	audit := s.startThemeAudit(r)
+	defer s.recordAudit(r, audit)
+
+	theme, ok := s.loadClientTheme(w, r, store, audit)
+	if !ok {
+		return
+	}
+	audit.Before = themeAuditSummary(newThemeResponse(theme))
+
+	if err := store.DeleteTheme(r.Context(), theme.ClientID, theme.ID); err != nil {
+		s.writeThemeStoreError(w, r, audit, err, "delete theme")
+		return
+	}
+	writeThemeResponse(w, audit, http.StatusOK, "Theme deleted successfully", nil)
+}
+
+func (s *Server) handleActivateThemeLocal(w http.ResponseWriter, r *http.Request) {
+	store, ok := s.localThemeStore(w)
+	if !ok {
+		return
+	}
+	audit := s.startThemeAudit(r)
+	defer s.recordAudit(r, audit)
+
+	theme, ok := s.loadClientTheme(w, r, store, audit)
+	if !ok {
+		return
+	}
+	theme, err := store.ActivateTheme(r.Context(), theme.ClientID, theme.ID)
+	if err != nil {
+		s.writeThemeStoreError(w, r, audit, err, "activate theme")
+		return
+	}
+	writeThemeResponse(w, audit, http.StatusOK, "Theme activated successfully", newThemeResponse(theme))
+}
+
+// ── Theme Configurations ─────────────────────────────────────────────────────
+
+func (s *Server) handleCreateThemeConfigLocal(w http.ResponseWriter, r *http.Request) {
+	store, ok := s.localThemeStore(w)
+	if !ok {
+		return
+	}
+	audit := s.startThemeAudit(r)
+	defer s.recordAudit(r, audit)
+
+	var req themeConfigRequest
+	if !decodeThemeRequest(w, r, audit, &req) {
+		return
+	}
+	theme, ok := s.loadClientTheme(w, r, store, audit)
+	if !ok {
+		return
+	}
+	cfg := &models.ThemeConfiguration{
+		ThemeID:     theme.ID,
+		ConfigKey:   req.ConfigKey,
+		ConfigValue: req.ConfigValue,
+		ConfigType:  req.ConfigType,
+	}
+	if err := store.CreateConfiguration(r.Context(), cfg); err != nil {
+		s.writeThemeStoreError(w, r, audit, err, "create theme configuration")
+		return
+	}
+	writeThemeResponse(w, audit, http.StatusCreated, "Theme configuration created successfully", newThemeConfigResponse(cfg))
+}
+
+func (s *Server) handleGetThemeConfigsLocal(w http.ResponseWriter, r *http.Request) {
+	store, ok := s.localThemeStore(w)
+	if !ok {
+		return
+	}
+	theme, ok := s.loadClientTheme(w, r, store, nil)
+	if !ok {
+		return
+	}
+	configs, err := store.FindConfigurationsByThemeID(r.Context(), theme.ID)
+	if err != nil {
+		s.writeThemeStoreError(w, r, nil, err, "list theme configurations")
+		return
+	}
+	data := make([]themeConfigResponse, 0, len(configs))
+	for i := range configs {
+		data = append(data, newThemeConfigResponse(&configs[i]))
+	}
+	writeThemeResponse(w, nil, http.StatusOK, "Theme configurations retrieved successfully", data)
+}
+
+func (s *Server) handleGetThemeConfigLocal(w http.ResponseWriter, r *http.Request) {
+	store, ok := s.localThemeStore(w)
+	if !ok {
+		return
+	}
+	cfg, ok := s.loadThemeConfig(w, r, store, nil)
+	if !ok {
+		return
+	}
+	writeThemeResponse(w, nil, http.StatusOK, "Theme configuration retrieved successfully", newThemeConfigResponse(cfg))
+}
+
+func (s *Server) handleUpdateThemeConfigLocal(w http.ResponseWriter, r *http.Request) {
+	store, ok := s.localThemeStore(w)
+	if !ok {
+		return
+	}
+	audit := s.startThemeAudit(r)
+	defer s.recordAudit(r, audit)
+
+	var req themeConfigRequest
+	if !decodeThemeRequest(w, r, audit, &req) {
+		return
+	}
+	cfg, ok := s.loadThemeConfig(w, r, store, audit)
+	if !ok {
+		return
+	}
+	audit.Before = themeAuditSummary(newThemeConfigResponse(cfg))
+
+	cfg.ConfigValue = req.ConfigValue
+	if req.ConfigType != "" {
+		cfg.ConfigType = req.ConfigType
+	}
+	if err := store.UpdateConfiguration(r.Context(), cfg); err != nil {
+		s.writeThemeStoreError(w, r, audit, err, "update theme configuration")
+		return
+	}
+	writeThemeResponse(w, audit, http.StatusOK, "Theme configuration updated successfully", newThemeConfigResponse(cfg))
+}
+
+func (s *Server) handleDeleteThemeConfigLocal(w http.ResponseWriter, r *http.Request) {
+	store, ok := s.localThemeStore(w)
+	if !ok {
+		return
+	}
+	audit := s.startThemeAudit(r)
+	defer s.recordAudit(r, audit)
+
+	cfg, ok := s.loadThemeConfig(w, r, store, audit)
+	if !ok {
+		return
+	}
+	audit.Before = themeAuditSummary(newThemeConfigResponse(cfg))
+
+	if err := store.DeleteConfiguration(r.Context(), cfg.ThemeID, cfg.ID); err != nil {
+		s.writeThemeStoreError(w, r, audit, err, "delete theme configuration")
+		return
+	}
+	writeThemeResponse(w, audit, http.StatusOK, "Theme configuration deleted successfully", nil)
+}
+
+// ── Helpers ──────────────────────────────────────────────────────────────────
+
+// localThemeStore returns the theme store, answering 503 while the database
+// is unavailable.
+func (s *Server) localThemeStore(w http.ResponseWriter) (repository.ThemeStore, bool) {
+	store := s.themeStore()
+	if store == nil {
+		writeThemeError(w, nil, http.StatusServiceUnavailable, "Theme storage unavailable")
+		return nil, false
+	}
+	return store, true
+}
+
+// startThemeAudit begins the audit event for a theme mutation. The response
+// writers below fill in its status and outcome.
+func (s *Server) startThemeAudit(r *http.Request) *models.AuditEvent {
+	action, target := auditActionFor(r)
+	return &models.AuditEvent{
+		TenantID: chi.URLParam(r, "clientId"),
+		Action:   action,
+		Target:   target,
+	}
+}
+
+// loadClientTheme loads {themeId}, answering 404 unless it belongs to {clientId}.
+func (s *Server) loadClientTheme(w http.ResponseWriter, r *http.Request, store repository.ThemeStore, audit *models.AuditEvent) (*models.ClientTheme, bool) {
+	themeID, err := strconv.Atoi(chi.URLParam(r, "themeId"))
+	if err != nil {
+		writeThemeError(w, audit, http.StatusBadRequest, "Invalid theme id")
+		return nil, false
+	}
+	theme, err := store.FindThemeByID(r.Context(), themeID)
+	if err == nil && theme.ClientID != chi.URLParam(r, "clientId") {
+		err = repository.ErrNotFound
+	}
+	if err != nil {
+		s.writeThemeStoreError(w, r, audit, err, "get theme")
+		return nil, false
+	}
+	return theme, true
+}
+
+// loadThemeConfig loads {configId} of a theme that belongs to {clientId}.
+func (s *Server) loadThemeConfig(w http.ResponseWriter, r *http.Request, store repository.ThemeStore, audit *models.AuditEvent) (*models.ThemeConfiguration, bool) {
+	theme, ok := s.loadClientTheme(w, r, store, audit)
+	if !ok {
+		return nil, false
+	}
+	configID, err := strconv.Atoi(chi.URLParam(r, "configId"))
+	if err != nil {
+		writeThemeError(w, audit, http.StatusBadRequest, "Invalid configuration id")
+		return nil, false
+	}
+	cfg, err := store.FindConfigurationByID(r.Context(), theme.ID, configID)
+	if err != nil {
+		s.writeThemeStoreError(w, r, audit, err, "get theme configuration")
+		return nil, false
+	}
+	return cfg, true
+}
+
+// decodeThemeRequest reads a JSON body into v, answering 400 if it is invalid.
+func decodeThemeRequest(w http.ResponseWriter, r *http.Request, audit *models.AuditEvent, v any) bool {
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxThemeBodySize)).Decode(v); err != nil {
+		writeThemeError(w, audit, http.StatusBadRequest, "Invalid request body")
+		return false
+	}
+	if audit != nil {
+		audit.After = themeAuditSummary(v) // The change that was attempted
+	}
+	return true
+}
+
+// writeThemeStoreError maps store errors to Spring's status codes: validation
+// failures are 400, unknown ids 404, duplicates and invariant breaks 409.
+func (s *Server) writeThemeStoreError(w http.ResponseWriter, r *http.Request, audit *models.AuditEvent, err error, action string) {
+	var (
+		invalid  *repository.ValidationError
+		conflict *repository.ConflictError
+	)
+	switch {
+	case errors.As(err, &invalid):
+		writeThemeError(w, audit, http.StatusBadRequest, invalid.Error())
+	case errors.Is(err, repository.ErrNotFound):
+		writeThemeError(w, audit, http.StatusNotFound, "Theme not found")
+	case errors.As(err, &conflict):
+		writeThemeError(w, audit, http.StatusConflict, conflict.Message)
+	default:
+		s.requestLogger(r, "themes").Error("failed to "+action, "error", err)
+		writeThemeError(w, audit, http.StatusInternalServerError, "Failed to "+action)
+	}
+}
+
+// writeThemeResponse writes Spring's success envelope. warnings lists the
+// contrast findings a write was accepted with.
+func writeThemeResponse(w http.ResponseWriter, audit *models.AuditEvent, status int, message string, data any, warnings ...themelint.Finding) {
+	body := map[string]any{
+		"message":   message,
+		"status":    "SUCCESS",
+		"timestamp": time.Now().UTC(),
+		"data":      data,
+	}
+	if len(warnings) > 0 {
+		body["warnings"] = warnings
+	}
+	if audit != nil {
+		audit.StatusCode = status
+		if data != nil {
+			audit.After = themeAuditSummary(data)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// writeThemeError writes Spring's failure envelope. findings lists the
+// contrast failures a write was rejected for.
+func writeThemeError(w http.ResponseWriter, audit *models.AuditEvent, status int, message string, findings ...themelint.Finding) {
+	body := map[string]any{
+		"message":   message,
+		"status":    "FAILED",
+		"timestamp": time.Now().UTC(),
+	}
+	if len(findings) > 0 {
+		body["findings"] = findings
+	}
+	if audit != nil {
+		audit.StatusCode = status
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func themeAuditSummary(v any) models.JSONText {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return auditSummary(body)
+}
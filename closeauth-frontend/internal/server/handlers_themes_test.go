@@ -0,0 +1,132 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"closeauth-frontend/internal/config"
+	"closeauth-frontend/internal/database/models"
+	"closeauth-frontend/internal/database/repository"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestLocalThemeHandlers(t *testing.T) {
+	store := repository.NewMemoryThemeStore()
+	current, err := store.AddTheme(models.ClientTheme{ClientID: "acme", ThemeName: "current", IsActive: true, IsDefault: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := store.AddTheme(models.ClientTheme{ClientID: "globex", ThemeName: "other", IsActive: true, IsDefault: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &Server{themes: store, themesCfg: &config.ThemesConfig{Source: config.ThemeSourceLocal}, logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	themes := s.themeHandlers()
+	router := chi.NewRouter()
+	router.Route("/clients/{clientId}/themes", func(r chi.Router) {
+		r.Post("/", themes.create)
+		r.Get("/", themes.list)
+		r.Get("/active", themes.active)
+		r.Get("/{themeId}", themes.get)
+		r.Put("/{themeId}", themes.update)
+		r.Delete("/{themeId}", themes.delete)
+		r.Patch("/{themeId}/activate", themes.activate)
+		r.Post("/{themeId}/configurations", themes.createConfig)
+	})
+
+	do := func(method, path, body string) (int, map[string]any) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		var resp map[string]any
+		json.NewDecoder(rec.Body).Decode(&resp)
+		return rec.Code, resp
+	}
+	path := func(id int) string { return "/clients/acme/themes/" + strconv.Itoa(id) }
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantResult string
+	}{
+		{"create", http.MethodPost, "/clients/acme/themes/", `{"themeName":"spring","lightPrimaryColor":"#112233"}`, http.StatusCreated, "SUCCESS"},
+		{"create duplicate", http.MethodPost, "/clients/acme/themes/", `{"themeName":"spring"}`, http.StatusConflict, "FAILED"},
+		{"create invalid colour", http.MethodPost, "/clients/acme/themes/", `{"themeName":"x","darkTextColor":"red"}`, http.StatusBadRequest, "FAILED"},
+		{"create malformed body", http.MethodPost, "/clients/acme/themes/", `{`, http.StatusBadRequest, "FAILED"},
+		{"list", http.MethodGet, "/clients/acme/themes/", "", http.StatusOK, "SUCCESS"},
+		{"get other client's theme", http.MethodGet, path(other.ID), "", http.StatusNotFound, "FAILED"},
+		{"get bad id", http.MethodGet, "/clients/acme/themes/abc", "", http.StatusBadRequest, "FAILED"},
+		{"update", http.MethodPut, path(current.ID), `{"defaultMode":"dark"}`, http.StatusOK, "SUCCESS"},
+		{"update invalid mode", http.MethodPut, path(current.ID), `{"defaultMode":"sepia"}`, http.StatusBadRequest, "FAILED"},
+		{"deactivate active", http.MethodPut, path(current.ID), `{"isActive":false}`, http.StatusConflict, "FAILED"},
+		{"delete active", http.MethodDelete, path(current.ID), "", http.StatusConflict, "FAILED"},
+		{"create config", http.MethodPost, path(current.ID) + "/configurations", `{"configKey":"radius","configValue":"4","configType":"number"}`, http.StatusCreated, "SUCCESS"},
+		{"create config invalid", http.MethodPost, path(current.ID) + "/configurations", `{"configKey":"radius2","configValue":"four","configType":"number"}`, http.StatusBadRequest, "FAILED"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, resp := do(tt.method, tt.path, tt.body)
+			if status != tt.wantStatus || resp["status"] != tt.wantResult {
+				t.Errorf("%s %s = %d %v, want %d %s", tt.method, tt.path, status, resp, tt.wantStatus, tt.wantResult)
+			}
+		})
+	}
+
+	// Failures carry a message fit for the admin
+	if _, resp := do(http.MethodPost, "/clients/acme/themes/", `{"themeName":"spring"}`); resp["message"] != "theme 'spring' already exists for client acme" {
+		t.Errorf("duplicate create = %v, want the conflict message", resp)
+	}
+
+	// Activating the new theme moves the active default and frees the old one for deletion
+	created, err := store.FindAllByClientID(t.Context(), "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var springID int
+	for _, th := range created {
+		if th.ThemeName == "spring" {
+			springID = th.ID
+		}
+	}
+	if status, resp := do(http.MethodPatch, path(springID)+"/activate", ""); status != http.StatusOK {
+		t.Fatalf("activate = %d %v", status, resp)
+	}
+	status, resp := do(http.MethodGet, "/clients/acme/themes/active", "")
+	data, _ := resp["data"].(map[string]any)
+	if status != http.StatusOK || data["themeName"] != "spring" || data["lightPrimaryColor"] != "#112233" {
+		t.Errorf("active theme after activate = %d %v, want spring", status, resp)
+	}
+	if status, resp := do(http.MethodDelete, path(current.ID), ""); status != http.StatusOK {
+		t.Errorf("delete previous active theme = %d %v, want 200", status, resp)
+	}
+
+	// isActive:true updates and activates together, or not at all
+	autumn, err := store.AddTheme(models.ClientTheme{ClientID: "acme", ThemeName: "autumn"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status, resp := do(http.MethodPut, path(autumn.ID), `{"isActive":true,"darkTextColor":"red"}`); status != http.StatusBadRequest {
+		t.Errorf("invalid update with activation = %d %v, want 400", status, resp)
+	}
+	if _, resp := do(http.MethodGet, "/clients/acme/themes/active", ""); resp["data"].(map[string]any)["themeName"] != "spring" {
+		t.Errorf("active theme after failed update = %v, want spring still", resp)
+	}
+	status, resp = do(http.MethodPut, path(autumn.ID), `{"isActive":true,"lightPrimaryColor":"#445566"}`)
+	data, _ = resp["data"].(map[string]any)
+	if status != http.StatusOK || data["isActive"] != true || data["lightPrimaryColor"] != "#445566" {
+		t.Errorf("update with activation = %d %v, want autumn active with the new colour", status, resp)
+	}
+	if _, resp := do(http.MethodGet, "/clients/acme/themes/active", ""); resp["data"].(map[string]any)["themeName"] != "autumn" {
+		t.Errorf("active theme after update = %v, want autumn", resp)
+	}
+}
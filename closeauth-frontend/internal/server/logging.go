@@ -0,0 +1,23 @@
+package server
+
+import (
+	"log/slog"
+	"net/http"
+
+	"closeauth-frontend/internal/requestid"
+	"closeauth-frontend/internal/tracing"
+)
+
+// requestLogger returns the server logger scoped to one handler invocation,
+// carrying the request id and trace/span ids so log lines can be joined to
+// the access log and to traces.
+func (s *Server) requestLogger(r *http.Request, handler string) *slog.Logger {
+	args := []any{"handler", handler}
+	if id := requestid.FromContext(r.Context()); id != "" {
+		args = append(args, "request_id", id)
+	}
+	for _, attr := range tracing.LogAttrs(r.Context()) {
+		args = append(args, attr)
+	}
+	return s.logger.With(args...)
+}
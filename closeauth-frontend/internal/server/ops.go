@@ -0,0 +1,205 @@
+package server
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"strings"
+	"time"
+
+	"closeauth-frontend/internal/config"
+	"closeauth-frontend/internal/logger"
+	"closeauth-frontend/internal/metrics"
+)
+
+// ──────────────────────────────────────────────────────────────────────────────
+// Internal Ops Listener
+//
+// OPS_ADDR starts a second listener (TCP or "unix:/path") for operators:
+// readiness, metrics, pprof, runtime log level and diagnostics. The probes
+// and metrics are then no longer served on the public port. It has no
+// session or CSRF handling, so it must never be exposed publicly — bind it to
+// localhost, a private interface or a socket (created with mode 0660).
+// ──────────────────────────────────────────────────────────────────────────────
+
+// OpsServer is the internal ops listener. It is shut down together with the
+// main server.
+type OpsServer struct {
+	*http.Server
+	network string
+	address string
+}
+
+// opsSocketMode restricts the Unix socket to the BFF's user and group.
+const opsSocketMode = 0o660
+
+// ListenAndServe listens on the configured TCP address or Unix socket. A stale
+// socket file left by a previous process is removed first, and the socket is
+// removed again on shutdown.
+func (o *OpsServer) ListenAndServe() error {
+	if o.network == "unix" {
+		if err := removeSocket(o.address); err != nil {
+			return err
+		}
+	}
+	ln, err := net.Listen(o.network, o.address)
+	if err != nil {
+		return err
+	}
+	if o.network == "unix" {
+		if err := os.Chmod(o.address, opsSocketMode); err != nil {
+			ln.Close()
+			return err
+		}
+	}
+	return o.Serve(ln)
+}
+
+func removeSocket(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// String returns the listener address for logs, e.g. "unix:/run/bff/ops.sock".
+func (o *OpsServer) String() string {
+	if o.network == "unix" {
+		return "unix:" + o.address
+	}
+	return o.address
+}
+
+// newOpsServer builds the ops listener, or returns nil when it is disabled.
+func (s *Server) newOpsServer(cfg *config.OpsConfig) *OpsServer {
+	if cfg.Addr == "" {
+		return nil
+	}
+	network, address := cfg.Network()
+	ops := &OpsServer{
+		Server: &http.Server{
+			Handler:           s.opsRoutes(cfg),
+			ReadHeaderTimeout: 5 * time.Second,
+		},
+		network: network,
+		address: address,
+	}
+	if network == "unix" {
+		ops.RegisterOnShutdown(func() {
+			if err := removeSocket(address); err != nil {
+				s.logger.Warn("failed to remove ops socket", "path", address, "error", err)
+			}
+		})
+	}
+	return ops
+}
+
+func (s *Server) opsRoutes(cfg *config.OpsConfig) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", s.handleHealthz)
+	mux.HandleFunc("GET /readyz", s.handleReadyzDetails)
+	if s.metricsCfg.Enabled {
+		mux.Handle("GET "+s.metricsCfg.Path, metrics.Handler())
+	}
+	if cfg.Pprof {
+		mux.HandleFunc("/debug/pprof/", pprof.Index)
+		mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	}
+	mux.HandleFunc("GET /loglevel", s.handleGetLogLevel)
+	mux.HandleFunc("PUT /loglevel", s.handleSetLogLevel)
+	mux.HandleFunc("DELETE /loglevel/{component}", s.handleClearLogLevel)
+	mux.HandleFunc("GET /config/discovered", s.handleDiscoveredConfig)
+	mux.HandleFunc("GET /token", s.handleTokenStatus)
+	return mux
+}
+
+// logLevelRequest changes the global level, or one component's level when
+// Component is set. RevertAfter (e.g. "15m") restores the previous level.
+type logLevelRequest struct {
+	Level       string `json:"level"`
+	Component   string `json:"component,omitempty"`
+	RevertAfter string `json:"revert_after,omitempty"`
+}
+
+func (s *Server) handleGetLogLevel(w http.ResponseWriter, r *http.Request) {
+	writeOpsJSON(w, logger.Levels())
+}
+
+// handleSetLogLevel changes a level until it reverts, the next config reload
+// or a restart.
+func (s *Server) handleSetLogLevel(w http.ResponseWriter, r *http.Request) {
+	var req logLevelRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1024)).Decode(&req); err != nil {
+		jsonError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	var revertAfter time.Duration
+	if req.RevertAfter != "" {
+		d, err := time.ParseDuration(req.RevertAfter)
+		if err != nil || d <= 0 {
+			jsonError(w, "revert_after must be a positive duration such as 15m", http.StatusBadRequest)
+			return
+		}
+		revertAfter = d
+	}
+
+	var err error
+	if req.Component == "" {
+		err = logger.SetLevelFor(req.Level, revertAfter)
+	} else {
+		err = logger.SetComponentLevel(req.Component, req.Level, revertAfter)
+	}
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.logger.Info("log level changed via ops listener",
+		"target", cmp.Or(req.Component, "global"),
+		"level", strings.ToLower(req.Level),
+		"revert_after", revertAfter,
+	)
+	writeOpsJSON(w, logger.Levels())
+}
+
+func (s *Server) handleClearLogLevel(w http.ResponseWriter, r *http.Request) {
+	component := r.PathValue("component")
+	logger.ClearComponentLevel(component)
+	s.logger.Info("component log level override removed via ops listener", "target", component)
+	writeOpsJSON(w, logger.Levels())
+}
+
+// handleDiscoveredConfig dumps what the BFF learned from Spring at startup.
+func (s *Server) handleDiscoveredConfig(w http.ResponseWriter, r *http.Request) {
+	d := s.springConfig.Discovered
+	if d == nil {
+		writeOpsJSON(w, map[string]any{"available": false})
+		return
+	}
+	writeOpsJSON(w, map[string]any{
+		"available":  d.Available,
+		"fetched_at": d.FetchedAt,
+		"bff_config": d.BffConfig,
+		"oidc":       d.OIDC,
+	})
+}
+
+// handleTokenStatus reports the client_credentials token without revealing it.
+func (s *Server) handleTokenStatus(w http.ResponseWriter, r *http.Request) {
+	writeOpsJSON(w, s.tokenManager.Status())
+}
+
+func writeOpsJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,176 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"closeauth-frontend/internal/config"
+	"closeauth-frontend/internal/logger"
+	"closeauth-frontend/internal/spring"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func newOpsTestServer() *Server {
+	return &Server{
+		springConfig: &spring.Config{Discovered: &spring.DiscoveredConfig{Available: true, FetchedAt: time.Now()}},
+		tokenManager: spring.NewTokenManager(slog.New(slog.NewTextHandler(io.Discard, nil))),
+		metricsCfg:   &config.MetricsConfig{},
+		logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+}
+
+func TestOpsRoutes_LogLevel(t *testing.T) {
+	t.Cleanup(func() {
+		logger.SetLevel("info")
+		logger.SetComponentLevels(nil)
+	})
+	h := newOpsTestServer().opsRoutes(&config.OpsConfig{})
+
+	tests := []struct {
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantGlobal string
+		wantComps  map[string]string
+	}{
+		{http.MethodPut, "/loglevel", `{"level":"debug"}`, http.StatusOK, "debug", map[string]string{}},
+		{http.MethodPut, "/loglevel", `{"level":"verbose"}`, http.StatusBadRequest, "debug", map[string]string{}},
+		{http.MethodPut, "/loglevel", `not json`, http.StatusBadRequest, "debug", map[string]string{}},
+		{http.MethodPut, "/loglevel", `{"level":"WARN"}`, http.StatusOK, "warn", map[string]string{}},
+		{http.MethodPut, "/loglevel", `{"level":"debug","component":"spring_client","revert_after":"1h"}`, http.StatusOK, "warn", map[string]string{"spring_client": "debug"}},
+		{http.MethodPut, "/loglevel", `{"level":"debug","revert_after":"soon"}`, http.StatusBadRequest, "warn", map[string]string{"spring_client": "debug"}},
+		{http.MethodDelete, "/loglevel/spring_client", ``, http.StatusOK, "warn", map[string]string{}},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%s %s %s: status = %d, want %d", tt.method, tt.path, tt.body, rec.Code, tt.wantStatus)
+		}
+
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/loglevel", nil))
+		var got logger.LevelState
+		json.NewDecoder(rec.Body).Decode(&got)
+		if got.Global != tt.wantGlobal || !maps.Equal(got.Components, tt.wantComps) {
+			t.Errorf("after %s %s %s: levels = %s %v, want %s %v",
+				tt.method, tt.path, tt.body, got.Global, got.Components, tt.wantGlobal, tt.wantComps)
+		}
+	}
+}
+
+func TestOpsRoutes_Endpoints(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        *config.OpsConfig
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"token status", &config.OpsConfig{}, "/token", http.StatusOK, `"has_token":false`},
+		{"discovered config", &config.OpsConfig{}, "/config/discovered", http.StatusOK, `"available":true`},
+		{"pprof enabled", &config.OpsConfig{Pprof: true}, "/debug/pprof/", http.StatusOK, "goroutine"},
+		{"pprof disabled", &config.OpsConfig{}, "/debug/pprof/", http.StatusNotFound, ""},
+		{"metrics disabled", &config.OpsConfig{}, "/metrics", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			newOpsTestServer().opsRoutes(tt.cfg).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body = %s, want it to contain %s", rec.Body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestOpsServer_UnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "ops.sock")
+	ops := newOpsTestServer().newOpsServer(&config.OpsConfig{Addr: "unix:" + socket})
+
+	errc := make(chan error, 1)
+	go func() { errc <- ops.ListenAndServe() }()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+	var resp *http.Response
+	var err error
+	for range 50 {
+		if resp, err = client.Get("http://ops/healthz"); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("GET /healthz over socket: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want 200", resp.StatusCode)
+	}
+	if info, err := os.Stat(socket); err != nil || info.Mode().Perm() != opsSocketMode {
+		t.Errorf("socket mode = %v, %v; want %v", info.Mode().Perm(), err, os.FileMode(opsSocketMode))
+	}
+
+	if err := ops.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if err := <-errc; err != http.ErrServerClosed {
+		t.Errorf("ListenAndServe() = %v, want http.ErrServerClosed", err)
+	}
+	for range 50 {
+		if _, err = os.Stat(socket); errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("socket still present after shutdown: %v", err)
+	}
+}
+
+func TestRegisterRoutes_ProbesMoveToOps(t *testing.T) {
+	tests := []struct {
+		name        string
+		ops         *config.OpsConfig
+		wantMounted bool
+	}{
+		{"no ops listener", &config.OpsConfig{}, true},
+		{"ops listener", &config.OpsConfig{Addr: "127.0.0.1:9091"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newOpsTestServer()
+			s.opsCfg = tt.ops
+			s.metricsCfg = &config.MetricsConfig{Enabled: true, Path: "/metrics"}
+			routes := s.RegisterRoutes().(chi.Routes)
+
+			for _, path := range []string{"/healthz", "/readyz", "/api/health", "/metrics"} {
+				if got := routes.Match(chi.NewRouteContext(), http.MethodGet, path); got != tt.wantMounted {
+					t.Errorf("public router serves %s = %t, want %t", path, got, tt.wantMounted)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync/atomic"
+	"syscall"
+
+	"closeauth-frontend/internal/config"
+	"closeauth-frontend/internal/database/models"
+	"closeauth-frontend/internal/logger"
+	"closeauth-frontend/internal/middleware"
+	"closeauth-frontend/internal/requestid"
+
+	"github.com/go-chi/cors"
+)
+
+// ──────────────────────────────────────────────────────────────────────────────
+// Hot Configuration Reload
+//
+// SIGHUP or POST /api/admin/config/reload re-reads the configuration (same
+// file and --set flags as at startup) and validates it. An invalid config is
+// rejected and the running one kept. Settings listed in hotReloadable are
+// swapped in atomically; any other change is logged as needing a restart.
+// ──────────────────────────────────────────────────────────────────────────────
+
+// hotReloadable are the settings applyConfig can change on a running server.
+var hotReloadable = map[string]bool{
+	"logging.level":                   true,
+	"logging.component_levels":        true,
+	"security.cors_allowed_origins":   true,
+	"security.embedded_login_clients": true,
+	"security.hsts_max_age":           true,
+	"tls.cert_file":                   true, // only while TLS is enabled
+	"tls.key_file":                    true,
+}
+
+var errReloadDisabled = errors.New("configuration reload is not available")
+
+// reloadResult lists the changed settings by whether they took effect.
+type reloadResult struct {
+	Applied         []string `json:"applied"`
+	RestartRequired []string `json:"restart_required"`
+}
+
+// swappableMiddleware delegates to a middleware that can be replaced while
+// requests are in flight. Each request uses whichever version was current
+// when it arrived.
+type swappableMiddleware struct {
+	current atomic.Pointer[func(http.Handler) http.Handler]
+}
+
+func (m *swappableMiddleware) Store(mw func(http.Handler) http.Handler) {
+	m.current.Store(&mw)
+}
+
+func (m *swappableMiddleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		(*m.current.Load())(next).ServeHTTP(w, r)
+	})
+}
+
+// applyConfig installs the hot-reloadable parts of cfg.
+func (s *Server) applyConfig(cfg *config.Config) {
+	// Replaces any level changed at runtime through the ops listener
+	if err := logger.SetLevel(cfg.Logging.Level); err != nil {
+		s.logger.Warn("invalid log level", "error", err)
+	}
+	if err := logger.SetComponentLevels(cfg.Logging.ComponentLevels); err != nil {
+		s.logger.Warn("invalid component log level", "error", err)
+	}
+
+	s.cors.Store(cors.Handler(cors.Options{
+		AllowedOrigins:   cfg.Security.CORSAllowedOrigins,
+		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", requestid.Header},
+		ExposedHeaders:   []string{requestid.Header},
+		AllowCredentials: true,
+		MaxAge:           300,
+	}))
+
+	// CSP (with per-request nonce for the SPA), HSTS in production,
+	// and frame-ancestors 'none' on OAuth pages unless the client allows embedding
+	s.securityHeaders.Store(middleware.SecurityHeadersMiddleware(middleware.SecurityHeadersConfig{
+		IsProduction:         s.secureCookies(),
+		HSTSMaxAge:           cfg.Security.HSTSMaxAge,
+		EmbeddedLoginOrigins: cfg.Security.EmbeddedLoginOrigins,
+	}))
+}
+
+// Reload re-reads and validates the configuration and applies what it can.
+// Concurrent reloads are serialised.
+func (s *Server) Reload() (*reloadResult, error) {
+	if s.loadConfig == nil {
+		return nil, errReloadDisabled
+	}
+
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	cfg, err := s.loadConfig()
+	if err == nil && s.certs != nil && cfg.TLS.Enabled() {
+		// Always re-read so a reload also picks up certificates rotated in place
+		err = s.certs.SetFiles(cfg.TLS.CertFile, cfg.TLS.KeyFile)
+	}
+	if err != nil {
+		s.logger.Error("configuration reload rejected, keeping current configuration", "error", err)
+		return nil, err
+	}
+
+	result := &reloadResult{Applied: []string{}, RestartRequired: []string{}}
+	for _, key := range s.cfg.Changed(cfg) {
+		if hotReloadable[key] && (s.certs != nil || !strings.HasPrefix(key, "tls.")) {
+			result.Applied = append(result.Applied, key)
+		} else {
+			result.RestartRequired = append(result.RestartRequired, key)
+		}
+	}
+
+	s.applyConfig(cfg)
+	s.cfg = cfg
+
+	s.logger.Info("configuration reloaded", "applied", result.Applied)
+	if len(result.RestartRequired) > 0 {
+		s.logger.Warn("changed settings take effect only after a restart", "settings", result.RestartRequired)
+	}
+	return result, nil
+}
+
+// reloadOnSignal reloads the configuration on every SIGHUP until ctx ends.
+func (s *Server) reloadOnSignal(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			s.logger.Info("SIGHUP received, reloading configuration")
+			s.Reload()
+		}
+	}
+}
+
+func (s *Server) handleReloadConfig(w http.ResponseWriter, r *http.Request) {
+	audit := &models.AuditEvent{Action: "config.reload", Target: "config"}
+	defer s.recordAudit(r, audit)
+
+	result, err := s.Reload()
+	switch {
+	case errors.Is(err, errReloadDisabled):
+		audit.StatusCode = http.StatusNotImplemented
+		jsonError(w, err.Error(), http.StatusNotImplemented)
+		return
+	case err != nil:
+		audit.StatusCode = http.StatusUnprocessableEntity
+		jsonError(w, "Invalid configuration: "+err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	body, _ := json.Marshal(result)
+	audit.StatusCode = http.StatusOK
+	audit.After = models.JSONText(body)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
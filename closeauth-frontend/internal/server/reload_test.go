@@ -0,0 +1,93 @@
+package server
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"closeauth-frontend/internal/config"
+	"closeauth-frontend/internal/spring"
+)
+
+func loadTestConfig(t *testing.T, env map[string]string) (*config.Config, error) {
+	t.Helper()
+	return config.Load(config.Options{LookupEnv: func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}})
+}
+
+func newReloadTestServer(t *testing.T, env *map[string]string) *Server {
+	t.Helper()
+	cfg, err := loadTestConfig(t, *env)
+	if err != nil {
+		t.Fatalf("initial config: %v", err)
+	}
+	s := &Server{
+		springConfig: &spring.Config{Environment: "development"},
+		logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+		cfg:          cfg,
+		loadConfig:   func() (*config.Config, error) { return loadTestConfig(t, *env) },
+	}
+	s.applyConfig(cfg)
+	return s
+}
+
+func TestReload_ClassifiesChanges(t *testing.T) {
+	env := map[string]string{"CORS_ALLOWED_ORIGINS": "https://admin.example.com"}
+	s := newReloadTestServer(t, &env)
+
+	env = map[string]string{
+		"CORS_ALLOWED_ORIGINS": "https://console.example.com",
+		"PORT":                 "9090",
+	}
+	result, err := s.Reload()
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if !slices.Equal(result.Applied, []string{"security.cors_allowed_origins"}) {
+		t.Errorf("Applied = %v", result.Applied)
+	}
+	if !slices.Equal(result.RestartRequired, []string{"server.port"}) {
+		t.Errorf("RestartRequired = %v", result.RestartRequired)
+	}
+
+	// The swapped CORS middleware is used by the next request
+	handler := s.cors.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for origin, allowed := range map[string]bool{
+		"https://console.example.com": true,
+		"https://admin.example.com":   false,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/api/csrf", nil)
+		req.Header.Set("Origin", origin)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if got := rec.Header().Get("Access-Control-Allow-Origin") == origin; got != allowed {
+			t.Errorf("origin %s allowed = %v, want %v", origin, got, allowed)
+		}
+	}
+}
+
+func TestReload_RejectsInvalidConfig(t *testing.T) {
+	env := map[string]string{}
+	s := newReloadTestServer(t, &env)
+	current := s.cfg
+
+	env = map[string]string{"LOG_FORMAT": "xml"}
+	if _, err := s.Reload(); err == nil {
+		t.Fatal("Reload() should reject an invalid configuration")
+	}
+	if s.cfg != current {
+		t.Error("invalid configuration replaced the running one")
+	}
+}
+
+func TestReload_Disabled(t *testing.T) {
+	s := &Server{}
+	if _, err := s.Reload(); err != errReloadDisabled {
+		t.Errorf("Reload() error = %v, want errReloadDisabled", err)
+	}
+}
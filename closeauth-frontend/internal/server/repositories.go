@@ -0,0 +1,56 @@
+package server
+
+import "closeauth-frontend/internal/database/repository"
+
+// Repositories are built on demand from the supervised connection, so they
+// become available as soon as the database (re)connects and disappear while
+// it is unreachable. Both return nil in that case.
+
+// themeStore returns the injected store when set (tests), otherwise the
+// database-backed repository.
+func (s *Server) themeStore() repository.ThemeStore {
+	if s.themes != nil {
+		return s.themes
+	}
+	if db := s.db.DB(); db != nil {
+		return repository.NewThemeRepository(db)
+	}
+	return nil
+}
+
+// assetStore returns the injected asset store when set (tests), otherwise
+// the database-backed repository, deleting blobs from the filesystem store
+// when one is set.
+func (s *Server) assetStore() repository.AssetStore {
+	if s.assets != nil {
+		return s.assets
+	}
+	if db := s.db.DB(); db != nil {
+		return repository.NewAssetRepository(db, s.blobs)
+	}
+	return nil
+}
+
+// blobStore returns the filesystem or injected blob store when set,
+// otherwise the asset_blobs table.
+func (s *Server) blobStore() repository.BlobStore {
+	if s.blobs != nil {
+		return s.blobs
+	}
+	if db := s.db.DB(); db != nil {
+		return repository.NewAssetRepository(db, nil)
+	}
+	return nil
+}
+
+// auditStore returns the injected audit store when set (tests), otherwise
+// the database-backed repository.
+func (s *Server) auditStore() repository.AuditStore {
+	if s.audits != nil {
+		return s.audits
+	}
+	if db := s.db.DB(); db != nil {
+		return repository.NewAuditRepository(db)
+	}
+	return nil
+}
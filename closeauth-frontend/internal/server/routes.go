@@ -5,38 +5,51 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"time"
 
+	"closeauth-frontend/internal/database/models"
+	"closeauth-frontend/internal/metrics"
 	"closeauth-frontend/internal/middleware"
+	"closeauth-frontend/internal/requestid"
 	"closeauth-frontend/internal/static"
+	"closeauth-frontend/internal/tracing"
 
 	"github.com/go-chi/chi/v5"
 	chimw "github.com/go-chi/chi/v5/middleware"
-	"github.com/go-chi/cors"
 )
 
 func (s *Server) RegisterRoutes() http.Handler {
 	r := chi.NewRouter()
-	r.Use(chimw.Logger)
+	r.Use(tracing.Middleware)
+	r.Use(requestid.Middleware)
+	r.Use(metrics.HTTPMiddleware)
+	r.Use(middleware.AccessLog(s.logger))
 	r.Use(chimw.Recoverer)
 
-	// CORS — allow Vue dev server and same-origin in production
-	r.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"https://*", "http://*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		AllowCredentials: true,
-		MaxAge:           300,
-	}))
+	// Security headers and CORS are swapped on configuration reload (see reload.go)
+	r.Use(s.securityHeaders.Handler)
+	r.Use(s.cors.Handler)
 
-	// CSRF token generation (on every request)
-	isProduction := s.springConfig.IsProduction()
-	r.Use(middleware.CSRFTokenMiddleware(isProduction))
+	// CSRF token generation (on every request) — bound to session / OAuth flow / pre-session
+	r.Use(middleware.CSRFTokenMiddleware)
+
+	// Probes and metrics move to the ops listener when OPS_ADDR is set
+	public := s.opsCfg == nil || s.opsCfg.Addr == ""
+
+	// Prometheus metrics on the main port unless a separate METRICS_ADDR is set
+	if public && s.metricsCfg != nil && s.metricsCfg.Enabled && s.metricsCfg.Addr == "" {
+		r.Handle(s.metricsCfg.Path, metrics.Handler())
+	}
 
 	// ──────────────────────────────────────────────────────────────────────────
 	// Tier 1: Browser-navigation OAuth routes (native http.Redirect)
 	// These are hit by browser navigation, NOT SPA fetch.
 	// ──────────────────────────────────────────────────────────────────────────
+	// Orchestrator probes
+	if public {
+		r.Get("/healthz", s.handleHealthz)
+		r.Get("/readyz", s.handleReadyz)
+	}
+
 	r.Route("/closeauth", func(r chi.Router) {
 		r.Get("/oauth2/authorize", s.handleAuthorize)
 		r.Post("/oauth2/token", s.handleToken)
@@ -49,15 +62,21 @@ func (s *Server) RegisterRoutes() http.Handler {
 	// Tier 2: JSON API routes for SPA fetch
 	// ──────────────────────────────────────────────────────────────────────────
 	r.Route("/api", func(r chi.Router) {
+		// Admin API requires a client certificate when tls.client_ca_file is set
+		r.Use(s.requireAdminClientCert)
+
 		// CSRF validation on all mutating API requests
 		r.Use(middleware.CSRFValidationMiddleware)
 
 		// Public API endpoints (no auth required)
 		r.Get("/csrf", middleware.HandleCSRFToken)
-		r.Get("/health", s.handleHealthCheck)
+		if public {
+			r.Get("/health", s.handleReadyz)
+		}
 
 		// Admin auth (public — login/register/forgot-password)
-		r.Post("/admin/login", s.handleAdminLogin)
+		// Login requires a token issued before authentication (login CSRF)
+		r.With(middleware.RequirePreSessionCSRF).Post("/admin/login", s.handleAdminLogin)
 		r.Post("/admin/register", s.handleAdminRegister)
 		r.Post("/admin/register/verify-otp", s.handleAdminVerifyOTP)
 		r.Post("/admin/register/resend-otp", s.handleAdminResendOTP)
@@ -67,12 +86,16 @@ func (s *Server) RegisterRoutes() http.Handler {
 
 		// OAuth client pages (public — theme, login, register, consent-data)
 		r.Get("/oauth/theme", s.handleOAuthTheme)
-		r.Post("/oauth/login", s.handleOAuthLogin)
+		r.Get("/oauth/theme.css", s.handleOAuthThemeCSS)
+		r.With(middleware.RequirePreSessionCSRF).Post("/oauth/login", s.handleOAuthLogin)
 		r.Post("/oauth/register", s.handleOAuthRegister)
 		r.Post("/oauth/register/verify-otp", s.handleOAuthVerifyOTP)
 		r.Post("/oauth/register/resend-otp", s.handleOAuthResendOTP)
 		r.Get("/oauth/consent-data", s.handleOAuthConsentData)
 
+		// Uploaded theme assets (public, content-hashed and immutable)
+		r.Get("/assets/{key}", s.handleServeThemeAsset)
+
 		// Protected admin routes (require session)
 		r.Group(func(r chi.Router) {
 			r.Use(middleware.RequireAuth)
@@ -83,42 +106,85 @@ func (s *Server) RegisterRoutes() http.Handler {
 			r.Post("/admin/logout", s.handleAdminLogout)
 
 			// OIDC Dynamic Client Registration (via BFF)
-			r.Post("/admin/clients", s.handleAdminCreateClient)
+			r.With(middleware.RequirePermission(middleware.PermClientsWrite)).Post("/admin/clients", s.handleAdminCreateClient)
+
+			// Configuration hot reload (same as SIGHUP)
+			r.With(middleware.RequirePermission(middleware.PermAll)).Post("/admin/config/reload", s.handleReloadConfig)
+
+			// Admin audit log (non-super admins only see their own actions)
+			r.Route("/admin/audit", func(r chi.Router) {
+				r.Use(middleware.RequirePermission(middleware.PermAuditRead))
+				r.Get("/", s.handleListAudit)
+				r.Get("/export", s.handleExportAudit)
+				r.With(middleware.RequirePermission(middleware.PermAll)).Get("/verify", s.handleVerifyAudit)
+			})
 
-			// Client Configuration — all proxied to Spring with X-User-Token
+			// Client Configuration — all proxied to Spring with X-User-Token.
+			// Each route declares the permission it needs (see middleware/authz.go),
+			// and the admin must own {clientId} (see client_access.go).
 			r.Route("/admin/clients/{clientId}", func(r chi.Router) {
+				r.Use(s.clientAccess.RequireClientAccess)
+
+				read := middleware.RequirePermission(middleware.PermClientsRead)
+				write := middleware.RequirePermission(middleware.PermClientsWrite)
+				themesWrite := middleware.RequirePermission(middleware.PermThemesWrite)
+				registrationsRead := middleware.RequirePermission(middleware.PermRegistrationsRead)
+				registrationsApprove := middleware.RequirePermission(middleware.PermRegistrationsApprove)
+
 				// Application Roles
-				r.Post("/roles", s.handleCreateRole)
-				r.Get("/roles", s.handleGetRoles)
-				r.Get("/roles/{roleId}", s.handleGetRole)
-				r.Put("/roles/{roleId}", s.handleUpdateRole)
-				r.Delete("/roles/{roleId}", s.handleDeleteRole)
+				r.With(write).Post("/roles", s.handleCreateRole)
+				r.With(read).Get("/roles", s.handleGetRoles)
+				r.With(read).Get("/roles/{roleId}", s.handleGetRole)
+				r.With(write).Put("/roles/{roleId}", s.handleUpdateRole)
+				r.With(write).Delete("/roles/{roleId}", s.handleDeleteRole)
 
 				// Registration Config
-				r.Get("/registration-config", s.handleGetRegistrationConfig)
-				r.Put("/registration-config", s.handleUpdateRegistrationConfig)
-
-				// Themes
-				r.Post("/themes", s.handleCreateTheme)
-				r.Get("/themes", s.handleGetThemes)
-				r.Get("/themes/active", s.handleGetActiveTheme)
-				r.Get("/themes/{themeId}", s.handleGetTheme)
-				r.Put("/themes/{themeId}", s.handleUpdateTheme)
-				r.Delete("/themes/{themeId}", s.handleDeleteTheme)
-				r.Patch("/themes/{themeId}/activate", s.handleActivateTheme)
+				r.With(read).Get("/registration-config", s.handleGetRegistrationConfig)
+				r.With(write).Put("/registration-config", s.handleUpdateRegistrationConfig)
+
+				// Themes — proxied to Spring, or served locally with THEME_SOURCE=local
+				themes := s.themeHandlers()
+				r.With(themesWrite).Post("/themes", themes.create)
+				r.With(read).Get("/themes", themes.list)
+				r.With(read).Get("/themes/active", themes.active)
+				r.With(read).Get("/themes/{themeId}", themes.get)
+				r.With(themesWrite).Put("/themes/{themeId}", themes.update)
+				r.With(themesWrite).Delete("/themes/{themeId}", themes.delete)
+				r.With(themesWrite).Patch("/themes/{themeId}/activate", themes.activate)
+
+				// Contrast dry run for a new or an edited theme (nothing is saved)
+				r.With(read).Post("/themes/validate", s.handleValidateTheme)
+				r.With(read).Post("/themes/{themeId}/validate", s.handleValidateTheme)
 
 				// Theme Configurations
-				r.Post("/themes/{themeId}/configurations", s.handleCreateThemeConfig)
-				r.Get("/themes/{themeId}/configurations", s.handleGetThemeConfigs)
-				r.Get("/themes/{themeId}/configurations/{configId}", s.handleGetThemeConfig)
-				r.Put("/themes/{themeId}/configurations/{configId}", s.handleUpdateThemeConfig)
-				r.Delete("/themes/{themeId}/configurations/{configId}", s.handleDeleteThemeConfig)
+				r.With(themesWrite).Post("/themes/{themeId}/configurations", themes.createConfig)
+				r.With(read).Get("/themes/{themeId}/configurations", themes.listConfigs)
+				r.With(read).Get("/themes/{themeId}/configurations/{configId}", themes.getConfig)
+				r.With(themesWrite).Put("/themes/{themeId}/configurations/{configId}", themes.updateConfig)
+				r.With(themesWrite).Delete("/themes/{themeId}/configurations/{configId}", themes.deleteConfig)
+
+				// Theme version history, drafts and preview links (THEME_SOURCE=local only)
+				r.With(read).Get("/themes/{themeId}/versions", themes.versions)
+				r.With(read).Get("/themes/{themeId}/versions/diff", themes.diffVersions)
+				r.With(read).Get("/themes/{themeId}/versions/{version}", themes.version)
+				r.With(themesWrite).Post("/themes/{themeId}/versions/{version}/rollback", themes.rollback)
+				r.With(read).Get("/themes/{themeId}/draft", themes.draft)
+				r.With(themesWrite).Put("/themes/{themeId}/draft", themes.saveDraft)
+				r.With(themesWrite).Delete("/themes/{themeId}/draft", themes.discardDraft)
+				r.With(themesWrite).Post("/themes/{themeId}/draft/publish", themes.publishDraft)
+				r.With(themesWrite).Post("/themes/{themeId}/preview", themes.preview)
+
+				// Theme assets (logos and backgrounds), with either theme source
+				r.With(themesWrite).Post("/assets", s.withThemeAuthor(s.handleUploadThemeAsset))
+				r.With(read).Get("/assets", s.handleListThemeAssets)
+				r.With(read).Get("/assets/{assetId}", s.handleGetThemeAsset)
+				r.With(themesWrite).Delete("/assets/{assetId}", s.handleDeleteThemeAsset)
 
 				// Admin Approval (Pending Registrations)
-				r.Get("/pending-registrations", s.handleGetPendingRegistrations)
-				r.Get("/pending-registrations/count", s.handleGetPendingRegistrationsCount)
-				r.Post("/pending-registrations/{email}/approve", s.handleApproveRegistration)
-				r.Post("/pending-registrations/{email}/reject", s.handleRejectRegistration)
+				r.With(registrationsRead).Get("/pending-registrations", s.handleGetPendingRegistrations)
+				r.With(registrationsRead).Get("/pending-registrations/count", s.handleGetPendingRegistrationsCount)
+				r.With(registrationsApprove).Post("/pending-registrations/{email}/approve", s.handleApproveRegistration)
+				r.With(registrationsApprove).Post("/pending-registrations/{email}/reject", s.handleRejectRegistration)
 			})
 		})
 	})
@@ -185,6 +251,10 @@ func (s *Server) handleOAuthTheme(w http.ResponseWriter, r *http.Request) {
 	s.handleOAuthThemeImpl(w, r)
 }
 
+func (s *Server) handleOAuthThemeCSS(w http.ResponseWriter, r *http.Request) {
+	s.handleOAuthThemeCSSImpl(w, r)
+}
+
 func (s *Server) handleOAuthLogin(w http.ResponseWriter, r *http.Request) {
 	s.handleOAuthLoginImpl(w, r)
 }
@@ -215,10 +285,12 @@ func (s *Server) handleAdminMe(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"email":    session.Email,
-		"username": session.Username,
-		"role":     session.Role,
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"email":       session.Email,
+		"username":    session.Username,
+		"role":        session.Role,
+		"roles":       session.Roles,
+		"permissions": session.Permissions,
 	})
 }
 
@@ -228,6 +300,9 @@ func (s *Server) handleAdminLogout(w http.ResponseWriter, r *http.Request) {
 	// without registering them in Spring's OAuth2AuthorizationService), so
 	// server-side revocation via /oauth2/revoke is not possible. Cookie cleanup
 	// is the primary logout mechanism; the JWT expires naturally (1h TTL).
+	if session, err := middleware.GetSession(r); err == nil {
+		s.clientAccess.Invalidate(session.SessionID)
+	}
 	middleware.ClearSession(w)
 	middleware.ClearOAuthContext(w)
 	middleware.ClearCSRFToken(w)
@@ -239,7 +314,7 @@ func (s *Server) handleAdminLogout(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handleAdminCreateClient(w http.ResponseWriter, r *http.Request) {
-	logger := s.logger.With("handler", "admin_create_client")
+	logger := s.requestLogger(r, "admin_create_client")
 
 	var formReq spring.ClientFormRequest
 	if err := json.NewDecoder(r.Body).Decode(&formReq); err != nil {
@@ -263,8 +338,15 @@ func (s *Server) handleAdminCreateClient(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	audit := &models.AuditEvent{Action: "clients.create", Target: "clients"}
+	defer s.recordAudit(r, audit)
+
 	regResp, err := s.springClient.RegisterClient(r.Context(), token, &formReq)
 	if err != nil {
+		audit.StatusCode = http.StatusInternalServerError
+		if attempted, err := json.Marshal(formReq); err == nil {
+			audit.After = auditSummary(attempted)
+		}
 		logger.Error("client registration failed", "error", err)
 		jsonError(w, fmt.Sprintf("Client registration failed: %s", err.Error()), http.StatusInternalServerError)
 		return
@@ -272,28 +354,18 @@ func (s *Server) handleAdminCreateClient(w http.ResponseWriter, r *http.Request)
 
 	logger.Info("client registered successfully", "client_id", regResp.ClientID, "client_name", regResp.ClientName)
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(regResp)
-}
-
-// --- Health Check ---
-
-func (s *Server) handleHealthCheck(w http.ResponseWriter, r *http.Request) {
-	health := map[string]interface{}{
-		"status":    "ok",
-		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	audit.StatusCode = http.StatusCreated
+	audit.ClientID = regResp.ClientID
+	if created, err := json.Marshal(regResp); err == nil {
+		audit.After = auditSummary(created) // client_secret is redacted
 	}
 
-	if err := s.HealthCheck(); err != nil {
-		health["status"] = "degraded"
-		health["database"] = map[string]string{"status": "unhealthy", "error": err.Error()}
-		w.WriteHeader(http.StatusServiceUnavailable)
-	} else {
-		health["database"] = map[string]string{"status": "healthy"}
-		w.WriteHeader(http.StatusOK)
+	// The new client must be reachable without waiting for the cache to expire
+	if session, err := middleware.GetSession(r); err == nil {
+		s.clientAccess.Invalidate(session.SessionID)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(health)
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(regResp)
 }
@@ -2,15 +2,21 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
-	"os"
+	"sync"
 	"time"
 
+	"closeauth-frontend/internal/certreload"
 	"closeauth-frontend/internal/config"
 	"closeauth-frontend/internal/database"
+	"closeauth-frontend/internal/database/migrations"
 	"closeauth-frontend/internal/database/repository"
+	"closeauth-frontend/internal/health"
+	"closeauth-frontend/internal/metrics"
 	"closeauth-frontend/internal/middleware"
 	"closeauth-frontend/internal/spring"
 
@@ -20,21 +26,60 @@ import (
 // Server holds all dependencies and serves HTTP requests.
 type Server struct {
 	port         int
-	db           *database.Database
-	themeRepo    *repository.ThemeRepository
+	db           *database.Supervisor  // nil-safe; see repositories.go
+	themes       repository.ThemeStore // overrides the database-backed store when set
+	themesCfg    *config.ThemesConfig
+	assets       repository.AssetStore // overrides the database-backed stores when set
+	blobs        repository.BlobStore  // the filesystem when THEME_ASSET_STORAGE=filesystem
+	audits       repository.AuditStore // overrides the database-backed store when set
 	springClient *spring.SpringClient
+	tokenManager *spring.TokenManager
 	springConfig *spring.Config
+	metricsCfg   *config.MetricsConfig
+	opsCfg       *config.OpsConfig
+	healthCfg    *config.HealthConfig
+	health       *health.Checker
+	clientAccess *clientAccessCache
+	auditQueue   *auditQueue // nil writes audit events synchronously
 	logger       *slog.Logger
+
+	// Native TLS (nil when a proxy terminates TLS, see tls.go)
+	tlsCfg *config.TLSConfig
+	certs  *certreload.Reloader
+
+	// Hot reload (see reload.go)
+	cfg             *config.Config
+	loadConfig      func() (*config.Config, error)
+	reloadMu        sync.Mutex
+	securityHeaders swappableMiddleware
+	cors            swappableMiddleware
 }
 
-func NewServer() *http.Server {
-	// Load server config from environment
-	serverCfg := config.LoadServerConfig()
+// NewServer wires the BFF from a loaded and validated configuration.
+// Background work such as the SIGHUP handler and the database supervisor
+// stops when ctx is cancelled, which should happen at shutdown. loadConfig
+// re-reads the configuration for SIGHUP and the admin reload endpoint; nil
+// disables hot reload. The ops listener is nil unless OPS_ADDR is set.
+func NewServer(ctx context.Context, cfg *config.Config, loadConfig func() (*config.Config, error)) (*http.Server, *OpsServer, error) {
+	serverCfg := cfg.Server
 
 	logger := slog.Default()
 
-	// Load Spring config
-	springCfg := spring.LoadConfig()
+	// Native TLS — certificates are loaded first so a bad key pair fails fast
+	var certs *certreload.Reloader
+	var tlsConfig *tls.Config
+	if cfg.TLS.Enabled() {
+		var err error
+		if certs, err = certreload.New(cfg.TLS.CertFile, cfg.TLS.KeyFile); err != nil {
+			return nil, nil, err
+		}
+		if tlsConfig, err = newTLSConfig(cfg.TLS, certs); err != nil {
+			return nil, nil, err
+		}
+		go certs.Watch(ctx, cfg.TLS.ReloadInterval, logger.With("component", "tls"))
+	}
+
+	springCfg := spring.NewConfig(cfg.Spring)
 
 	// Initialize token manager and Spring client
 	tokenManager := spring.NewTokenManager(logger)
@@ -64,37 +109,100 @@ func NewServer() *http.Server {
 		logger.Warn("  ⚠ Spring discovery incomplete — using env-var defaults")
 	}
 
-	// Initialize database (optional — graceful degradation if DB not available)
+	// Database (optional — theme and audit features follow its availability).
+	// The supervisor keeps retrying in the background if the first attempt fails.
+	db := database.NewSupervisor(cfg.Database, logger, database.SupervisorOptions{
+		OnConnect: func(ctx context.Context, conn *database.Database) error {
+			if err := ensureSchema(ctx, conn, cfg.Database.AutoMigrate, logger); err != nil {
+				return err
+			}
+			if err := metrics.RegisterDB(conn.DB.DB, "closeauth"); err != nil {
+				logger.Warn("failed to register database pool metrics", "error", err)
+			}
+			return nil
+		},
+		OnStateChange: func(state database.State) {
+			metrics.DatabaseState(string(state), state == database.StateConnected)
+		},
+		OnAttempt: metrics.DatabaseConnectAttempt,
+	})
+	connectCtx, connectCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	err := db.Connect(connectCtx)
+	connectCancel()
+	if errors.Is(err, migrations.ErrSchemaBehind) {
+		// Refuse to serve against a schema older than this binary expects
+		return nil, nil, err
+	}
+	go db.Run(ctx)
 
-	dbCfg, dbCfgErr := config.LoadDatabaseConfig()
-	var db *database.Database
-	var themeRepo *repository.ThemeRepository
+	// Session / oauth_context cookie encryption; CSRF tokens are HMAC-bound to
+	// the session / OAuth flow / pre-session
+	middlewareCfg := cfg.Middleware
+	middleware.SetEncryptionKey(middlewareCfg.EncryptionKey)
+	middleware.ConfigureCSRF(middleware.CSRFConfig{
+		IsProduction: springCfg.IsProduction() || cfg.TLS.Enabled(),
+		MaxAge:       middlewareCfg.CSRFTokenMaxAge,
+		TokenLength:  middlewareCfg.CSRFTokenLength,
+		SigningKey:   []byte(middlewareCfg.CSRFSigningKey),
+	})
 
-	if dbCfgErr == nil {
-		var err error
-		db, err = database.NewDatabase(dbCfg)
-		if err != nil {
-			logger.Warn("database connection failed, theme features disabled", "error", err)
-		} else {
-			themeRepo = repository.NewThemeRepository(db)
-		}
-	} else {
-		logger.Warn("database config not available, theme features disabled", "error", dbCfgErr)
+	// Security headers (CSP, HSTS, framing rules for hosted login pages)
+	securityCfg := cfg.Security
+
+	// Prometheus metrics, optionally on a separate listener
+	metricsCfg := cfg.Metrics
+	var metricsServer *http.Server
+	if metricsCfg.Enabled && metricsCfg.Addr != "" {
+		metricsServer = newMetricsServer(metricsCfg)
+		go func() {
+			if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Error("metrics server failed", "addr", metricsCfg.Addr, "error", err)
+			}
+		}()
 	}
 
+	// Readiness checks (DATABASE_REQUIRED decides whether the database gates readiness)
+	healthCfg := cfg.Health
+
 	s := &Server{
 		port:         serverCfg.Port,
 		db:           db,
-		themeRepo:    themeRepo,
 		springClient: springClient,
+		tokenManager: tokenManager,
 		springConfig: springCfg,
+		metricsCfg:   metricsCfg,
+		opsCfg:       cfg.Ops,
+		healthCfg:    healthCfg,
+		themesCfg:    cfg.Themes,
 		logger:       logger,
+		cfg:          cfg,
+		loadConfig:   loadConfig,
+	}
+	if certs != nil {
+		s.tlsCfg = cfg.TLS
+		s.certs = certs
+	}
+	if cfg.Themes.AssetStorage == config.AssetStorageFilesystem {
+		blobs, err := repository.NewFileBlobStore(cfg.Themes.AssetDir)
+		if err != nil {
+			return nil, nil, err
+		}
+		s.blobs = blobs
 	}
+	s.applyConfig(cfg)
+	if loadConfig != nil {
+		go s.reloadOnSignal(ctx)
+	}
+	s.clientAccess = newClientAccessCache(springClient.GetOwnedClients, securityCfg.ClientAccessCacheTTL)
+	s.health = s.newHealthChecker(healthCfg)
+	s.auditQueue = newAuditQueue(auditQueueSize, s.writeAudit)
+	ops := s.newOpsServer(cfg.Ops)
 
 	// ── Startup banner ──────────────────────────────────────────────────────
-	env := os.Getenv("ENVIRONMENT")
-	if env == "" {
-		env = "development"
+	env := springCfg.Environment
+	scheme := "http"
+	if certs != nil {
+		scheme = "https"
 	}
 
 	logger.Info("╔══════════════════════════════════════════════════════╗")
@@ -102,6 +210,20 @@ func NewServer() *http.Server {
 	logger.Info("╚══════════════════════════════════════════════════════╝")
 	logger.Info(fmt.Sprintf("  → Port          : %d", serverCfg.Port))
 	logger.Info(fmt.Sprintf("  → Environment   : %s", env))
+	if certs != nil {
+		http2 := "off"
+		if cfg.TLS.HTTP2 {
+			http2 = "on"
+		}
+		logger.Info(fmt.Sprintf("  → TLS           : TLS %s+, HTTP/2 %s, certificate expires %s",
+			cfg.TLS.MinVersion, http2, certs.NotAfter().Format(time.RFC3339)))
+		if cfg.TLS.RedirectAddr != "" {
+			logger.Info(fmt.Sprintf("  → HTTP redirect : %s → https", cfg.TLS.RedirectAddr))
+		}
+		if cfg.TLS.ClientCAFile != "" {
+			logger.Info("  → Admin API     : client certificate required")
+		}
+	}
 	logger.Info(fmt.Sprintf("  → Spring Server : %s (version: %s)", springCfg.OAuth2ServerURL, springCfg.ServerVersion()))
 
 	if discovered.Available {
@@ -111,17 +233,33 @@ func NewServer() *http.Server {
 		logger.Warn("  → Config Sync   : ⚠ using defaults (Spring unreachable at startup)")
 	}
 
-	if db != nil {
+	switch {
+	case db.DB() != nil:
 		logger.Info("  → Database      : connected ✓")
-	} else {
-		logger.Warn("  → Database      : disconnected (theme features disabled)")
+	case healthCfg.DatabaseRequired:
+		logger.Error("  → Database      : disconnected (required — /readyz will fail)")
+	default:
+		logger.Warn("  → Database      : disconnected (theme features disabled until it reconnects)")
 	}
 
-	logger.Info(fmt.Sprintf("  → SPA (embed)   : serving Vue dist/ on http://localhost:%d", serverCfg.Port))
-	logger.Info(fmt.Sprintf("  → API routes    : http://localhost:%d/api/*", serverCfg.Port))
-	logger.Info(fmt.Sprintf("  → OAuth proxy   : http://localhost:%d/closeauth/oauth2/*", serverCfg.Port))
+	logger.Info(fmt.Sprintf("  → SPA (embed)   : serving Vue dist/ on %s://localhost:%d", scheme, serverCfg.Port))
+	logger.Info(fmt.Sprintf("  → API routes    : %s://localhost:%d/api/*", scheme, serverCfg.Port))
+	logger.Info(fmt.Sprintf("  → OAuth proxy   : %s://localhost:%d/closeauth/oauth2/*", scheme, serverCfg.Port))
+	switch {
+	case !metricsCfg.Enabled:
+		logger.Info("  → Metrics       : disabled")
+	case metricsCfg.Addr != "":
+		logger.Info(fmt.Sprintf("  → Metrics       : http://%s%s", metricsCfg.Addr, metricsCfg.Path))
+	case ops != nil:
+		logger.Info(fmt.Sprintf("  → Metrics       : ops listener %s", metricsCfg.Path))
+	default:
+		logger.Info(fmt.Sprintf("  → Metrics       : %s://localhost:%d%s", scheme, serverCfg.Port, metricsCfg.Path))
+	}
+	if ops != nil {
+		logger.Info(fmt.Sprintf("  → Ops listener  : %s (probes, pprof: %t)", ops, cfg.Ops.Pprof))
+	}
 	logger.Info("──────────────────────────────────────────────────────")
-	logger.Info(fmt.Sprintf("Server starting on http://localhost:%d", serverCfg.Port))
+	logger.Info(fmt.Sprintf("Server starting on %s://localhost:%d", scheme, serverCfg.Port))
 
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%d", serverCfg.Port),
@@ -131,13 +269,78 @@ func NewServer() *http.Server {
 		WriteTimeout: serverCfg.WriteTimeout,
 	}
 
-	return server
+	if metricsServer != nil {
+		server.RegisterOnShutdown(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			metricsServer.Shutdown(ctx)
+		})
+	}
+
+	// Events recorded while in-flight requests finish are written directly.
+	// The database goes last, once the queued events are written.
+	server.RegisterOnShutdown(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), auditWriteTimeout)
+		defer cancel()
+		if err := s.auditQueue.Close(ctx); err != nil {
+			logger.Error("audit queue not drained before shutdown", "error", err)
+		}
+		if err := db.Close(); err != nil {
+			logger.Error("failed to close database", "error", err)
+		}
+	})
+
+	if tlsConfig != nil {
+		server.TLSConfig = tlsConfig
+		server.Protocols = httpProtocols(cfg.TLS)
+
+		if cfg.TLS.RedirectAddr != "" {
+			redirect := newRedirectServer(cfg.TLS.RedirectAddr, serverCfg.Port)
+			go func() {
+				if err := redirect.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+					logger.Error("HTTPS redirect server failed", "addr", cfg.TLS.RedirectAddr, "error", err)
+				}
+			}()
+			server.RegisterOnShutdown(func() { redirect.Close() })
+		}
+	}
+
+	return server, ops, nil
 }
 
-// HealthCheck checks database health.
-func (s *Server) HealthCheck() error {
-	if s.db == nil {
-		return fmt.Errorf("database not connected")
+// ensureSchema applies pending migrations when autoMigrate is set (the
+// migration lock makes this safe with several instances starting at once),
+// then fails if any are still pending.
+func ensureSchema(ctx context.Context, db *database.Database, autoMigrate bool, logger *slog.Logger) error {
+	migrator, err := migrations.New(db.DB, logger)
+	if err != nil {
+		return err
+	}
+
+	// Migrations may outlast a connection attempt's deadline
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 2*time.Minute)
+	defer cancel()
+
+	if autoMigrate {
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		if len(applied) > 0 {
+			logger.Info("database migrations applied", "count", len(applied))
+		}
+	}
+	return migrator.Check(ctx)
+}
+
+// newMetricsServer builds the dedicated metrics listener.
+func newMetricsServer(cfg *config.MetricsConfig) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle(cfg.Path, metrics.Handler())
+
+	return &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
 	}
-	return s.db.HealthCheck()
 }
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"closeauth-frontend/internal/certreload"
+	"closeauth-frontend/internal/config"
+)
+
+// ──────────────────────────────────────────────────────────────────────────────
+// Native TLS
+//
+// When tls.cert_file/tls.key_file are set the BFF terminates TLS itself.
+// Certificates are served through certreload so rotated files apply to new
+// handshakes without a restart.
+// ──────────────────────────────────────────────────────────────────────────────
+
+// secureCookies reports whether cookies get the Secure flag and HSTS is sent:
+// in production, where a proxy terminates TLS, or when the BFF serves TLS itself.
+func (s *Server) secureCookies() bool {
+	return s.springConfig.IsProduction() || s.tlsCfg != nil
+}
+
+// newTLSConfig builds the listener's TLS settings.
+func newTLSConfig(cfg *config.TLSConfig, certs *certreload.Reloader) (*tls.Config, error) {
+	tlsCfg := &tls.Config{
+		MinVersion:     cfg.TLSMinVersion(),
+		CipherSuites:   cfg.CipherSuiteIDs(),
+		GetCertificate: certs.GetCertificate,
+	}
+
+	// Browsers are only asked for a certificate, never required to send one;
+	// requireAdminClientCert enforces it for the admin API.
+	if cfg.ClientCAFile != "" {
+		caPEM, err := os.ReadFile(cfg.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read client CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("client CA file %s contains no PEM certificates", cfg.ClientCAFile)
+		}
+		tlsCfg.ClientCAs = pool
+		tlsCfg.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	return tlsCfg, nil
+}
+
+// httpProtocols returns the protocols served over TLS.
+func httpProtocols(cfg *config.TLSConfig) *http.Protocols {
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(cfg.HTTP2)
+	return protocols
+}
+
+// requireAdminClientCert rejects /api/admin requests that did not present a
+// certificate signed by tls.client_ca_file. It is a no-op when client
+// certificates are not configured.
+func (s *Server) requireAdminClientCert(next http.Handler) http.Handler {
+	if s.tlsCfg == nil || s.tlsCfg.ClientCAFile == "" {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/api/admin/") && (r.TLS == nil || len(r.TLS.VerifiedChains) == 0) {
+			s.requestLogger(r, "admin_client_cert").Warn("admin API request without a verified client certificate",
+				"path", r.URL.Path,
+			)
+			jsonError(w, "Client certificate required", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// newRedirectServer answers plain HTTP on addr with a permanent redirect to
+// the same URL on the HTTPS port.
+func newRedirectServer(addr string, httpsPort int) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		ReadHeaderTimeout: 5 * time.Second,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			host := r.Host
+			if h, _, err := net.SplitHostPort(host); err == nil {
+				host = h
+			}
+			host = strings.Trim(host, "[]")
+			if httpsPort != 443 {
+				host = net.JoinHostPort(host, strconv.Itoa(httpsPort))
+			} else if strings.Contains(host, ":") {
+				host = "[" + host + "]"
+			}
+			http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+		}),
+	}
+}
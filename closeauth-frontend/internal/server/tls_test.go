@@ -0,0 +1,67 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"closeauth-frontend/internal/config"
+)
+
+func TestRequireAdminClientCert(t *testing.T) {
+	s := &Server{
+		tlsCfg: &config.TLSConfig{ClientCAFile: "ca.pem"},
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	handler := s.requireAdminClientCert(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	verified := &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{}}}}
+	tests := []struct {
+		name string
+		path string
+		tls  *tls.ConnectionState
+		want int
+	}{
+		{"admin without certificate", "/api/admin/me", &tls.ConnectionState{}, http.StatusForbidden},
+		{"admin over plain HTTP", "/api/admin/me", nil, http.StatusForbidden},
+		{"admin with verified certificate", "/api/admin/me", verified, http.StatusOK},
+		{"OAuth API needs no certificate", "/api/oauth/theme", &tls.ConnectionState{}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.TLS = tt.tls
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedirectServer(t *testing.T) {
+	tests := []struct {
+		host string
+		port int
+		want string
+	}{
+		{"bff.example.com", 443, "https://bff.example.com/admin?x=1"},
+		{"bff.example.com:80", 8443, "https://bff.example.com:8443/admin?x=1"},
+		{"[::1]:80", 443, "https://[::1]/admin?x=1"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/admin?x=1", nil)
+		req.Host = tt.host
+		rec := httptest.NewRecorder()
+		newRedirectServer(":80", tt.port).Handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusPermanentRedirect || rec.Header().Get("Location") != tt.want {
+			t.Errorf("%s → %d %q, want 308 %q", tt.host, rec.Code, rec.Header().Get("Location"), tt.want)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package spring
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// UserTokenClaims holds the claims the BFF reads from the user JWT issued by
+// Spring's JwtTokenService. Roles are GlobalRoleEnum names (e.g. "CLIENT_ADMIN").
+type UserTokenClaims struct {
+	Subject     string   `json:"sub"`
+	UserID      int64    `json:"userId"`
+	Email       string   `json:"email"`
+	Username    string   `json:"username"`
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions,omitempty"`
+	ExpiresAt   int64    `json:"exp"`
+}
+
+// ParseUserTokenClaims decodes the payload of a user JWT WITHOUT verifying its
+// signature. Only call it on tokens received directly from Spring over the
+// back channel (e.g. the admin login response), never on browser-supplied tokens.
+func ParseUserTokenClaims(token string) (*UserTokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("user token is not a JWT")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("decode user token payload: %w", err)
+	}
+
+	var claims UserTokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("unmarshal user token claims: %w", err)
+	}
+
+	return &claims, nil
+}
@@ -30,10 +30,13 @@ func NewSpringClient(cfg *Config, tokenManager *TokenManager, logger *slog.Logge
 		tokenManager: tokenManager,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
-			Transport: &http.Transport{
-				MaxIdleConns:       20,
-				IdleConnTimeout:    30 * time.Second,
-				DisableCompression: false,
+			Transport: &instrumentedTransport{
+				next: &http.Transport{
+					MaxIdleConns:       20,
+					IdleConnTimeout:    30 * time.Second,
+					DisableCompression: false,
+				},
+				contextPath: normalizeContextPath(cfg.ContextPath),
 			},
 			CheckRedirect: func(req *http.Request, via []*http.Request) error {
 				return http.ErrUseLastResponse // Never auto-follow redirects
@@ -214,6 +217,35 @@ func (c *SpringClient) GetClientInfo(ctx context.Context, clientID string) (*Cli
 	return &info, nil
 }
 
+// GetOwnedClients lists the clients owned by the admin behind userToken.
+func (c *SpringClient) GetOwnedClients(ctx context.Context, userToken string) ([]OwnedClient, error) {
+	if userToken == "" {
+		return nil, fmt.Errorf("user token is required")
+	}
+
+	result, err := c.ProxyAdminAuth(ctx, http.MethodGet, c.config.OwnedClientsURL(), nil, userToken)
+	if err != nil {
+		return nil, fmt.Errorf("owned clients request: %w", err)
+	}
+	if result.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("owned clients request failed (status %d)", result.StatusCode)
+	}
+
+	var envelope CustomApiResponse
+	if err := json.Unmarshal(result.Body, &envelope); err != nil {
+		return nil, fmt.Errorf("decode owned clients response: %w", err)
+	}
+
+	var clients []OwnedClient
+	if len(envelope.Data) > 0 {
+		if err := json.Unmarshal(envelope.Data, &clients); err != nil {
+			return nil, fmt.Errorf("decode owned clients: %w", err)
+		}
+	}
+
+	return clients, nil
+}
+
 // --- OAuth2 Proxy ---
 
 // ProxyAuthorize proxies an authorization request to Spring and returns the result.
@@ -485,7 +517,7 @@ func isRedirect(statusCode int) bool {
 // Returns a DiscoveredConfig. If either call fails, Available is false
 // and the BFF operates with env-var defaults (graceful degradation).
 func (c *SpringClient) FetchServerConfig(ctx context.Context) *DiscoveredConfig {
-	discovered := &DiscoveredConfig{}
+	discovered := &DiscoveredConfig{FetchedAt: time.Now()}
 
 	// 1. Fetch OIDC Discovery
 	oidc, err := c.fetchOIDCDiscovery(ctx)
@@ -576,3 +608,65 @@ func (c *SpringClient) fetchBffConfig(ctx context.Context) (*BffConfigResponse,
 
 	return &bffCfg, nil
 }
+
+// --- Health Probes ---
+
+// CheckReachable reports whether Spring answers HTTP at all. Any response
+// below 500 counts, so the probe does not depend on a particular endpoint's auth rules.
+func (c *SpringClient) CheckReachable(ctx context.Context) error {
+	discoveryURL := c.config.OAuth2ServerURL + "/closeauth/.well-known/openid-configuration"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, discoveryURL, nil)
+	if err != nil {
+		return fmt.Errorf("create reachability request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("execute reachability request: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("spring returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// FetchJWKS fetches Spring's signing keys and returns how many it publishes.
+// The discovered jwks_uri is preferred over the configured default.
+func (c *SpringClient) FetchJWKS(ctx context.Context) (int, error) {
+	jwksURL := c.config.JWKSURL()
+	if d := c.config.Discovered; d != nil && d.OIDC != nil && d.OIDC.JwksURI != "" {
+		jwksURL = d.OIDC.JwksURI
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwksURL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("create JWKS request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("execute JWKS request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("JWKS returned status %d", resp.StatusCode)
+	}
+
+	var jwks struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+		return 0, fmt.Errorf("decode JWKS response: %w", err)
+	}
+	if len(jwks.Keys) == 0 {
+		return 0, fmt.Errorf("JWKS contains no keys")
+	}
+
+	return len(jwks.Keys), nil
+}
@@ -3,12 +3,13 @@ package spring
 import (
 	"fmt"
 	"net/url"
-	"os"
 	"strings"
+
+	"closeauth-frontend/internal/config"
 )
 
 // Config holds all Spring Authorization Server endpoint configuration.
-// Loaded once at startup from environment variables.
+// Built once at startup from config.SpringConfig.
 // Can be augmented at runtime by ApplyDiscoveredConfig().
 type Config struct {
 	// Base URL of the Spring Authorization Server (e.g., "http://localhost:9088")
@@ -33,17 +34,17 @@ type Config struct {
 	Discovered *DiscoveredConfig
 }
 
-// LoadConfig loads Spring configuration from environment variables.
-func LoadConfig() *Config {
+// NewConfig builds the Spring endpoint configuration from the loaded settings.
+func NewConfig(cfg *config.SpringConfig) *Config {
 	return &Config{
-		OAuth2ServerURL:     getEnv("OAUTH2_SERVER_URL", "http://localhost:9088"),
-		ContextPath:         getEnv("OAUTH2_API_CONTEXT_PATH", "/closeauth"),
-		DefaultClientID:     getEnv("DEFAULT_CLIENT_ID", "test1"),
-		DefaultClientSecret: getEnv("DEFAULT_CLIENT_SECRET", "test1"),
-		DefaultRedirectURL:  getEnv("DEFAULT_REDIRECT_URL", "http://127.0.0.1:8083/login/oauth2/code/public-client-react"),
-		DefaultScope:        getEnv("DEFAULT_SCOPE", "client.create"),
-		BFFBaseURL:          getEnv("BFF_BASE_URL", "http://localhost:8080"),
-		Environment:         getEnv("ENVIRONMENT", "development"),
+		OAuth2ServerURL:     cfg.ServerURL,
+		ContextPath:         cfg.ContextPath,
+		DefaultClientID:     cfg.ClientID,
+		DefaultClientSecret: cfg.ClientSecret,
+		DefaultRedirectURL:  cfg.RedirectURL,
+		DefaultScope:        cfg.Scope,
+		BFFBaseURL:          cfg.BFFBaseURL,
+		Environment:         cfg.Environment,
 	}
 }
 
@@ -172,6 +173,11 @@ func (c *Config) RejectRegistrationURL(clientID, email string) string {
 
 // --- Client Configuration Endpoints ---
 
+// OwnedClientsURL lists the clients owned by the admin identified by X-User-Token.
+func (c *Config) OwnedClientsURL() string {
+	return c.baseURL() + "/api/v1/clients"
+}
+
 func (c *Config) ClientRolesURL(clientID string) string {
 	return c.baseURL() + "/api/v1/clients/" + clientID + "/roles"
 }
@@ -210,13 +216,6 @@ func (c *Config) ClientThemeConfigURL(clientID string, themeID string, configID
 
 // --- Helper ---
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
-}
-
 func normalizeContextPath(raw string) string {
 	value := strings.TrimSpace(raw)
 	if value == "" {
@@ -228,6 +227,7 @@ func normalizeContextPath(raw string) string {
 			value = parsed.Path
 		}
 	} else if strings.HasPrefix(value, "http//") || strings.HasPrefix(value, "https//") {
+		value = value[strings.Index(value, "//")+2:]
 		if idx := strings.Index(value, "/"); idx >= 0 {
 			value = value[idx:]
 		} else {
@@ -1,5 +1,7 @@
 package spring
 
+import "time"
+
 // ──────────────────────────────────────────────────────────────────────────────
 // BFF Config Sync — fetched from Spring at startup
 // ──────────────────────────────────────────────────────────────────────────────
@@ -93,5 +95,8 @@ type DiscoveredConfig struct {
 
 	// From /.well-known/openid-configuration
 	OIDC *OIDCDiscovery
+
+	// When discovery ran
+	FetchedAt time.Time
 }
 
@@ -72,6 +72,14 @@ type ClientInfoResponse struct {
 	Scopes     []string `json:"scopes"`
 }
 
+// OwnedClient is a client owned by the calling admin.
+// Maps to: com.anterka.closeauthbackend.client.dto.response.OwnedClientResponse
+type OwnedClient struct {
+	ID         string `json:"id"` // Used in /api/v1/clients/{clientId}/** paths
+	ClientID   string `json:"clientId"`
+	ClientName string `json:"clientName"`
+}
+
 // ──────────────────────────────────────────────────────────────────────────────
 // Admin Auth – matches Spring's UserLoginResponse / UserRegistrationResponse
 // ──────────────────────────────────────────────────────────────────────────────
@@ -85,6 +93,10 @@ type LoginResponse struct {
 	LastName       string `json:"lastName"`
 	AccessToken    string `json:"accessToken"`
 	TokenExpiresAt string `json:"tokenExpiresAt"`
+
+	// Optional — when absent the BFF reads roles/permissions from the accessToken claims
+	Roles       []string `json:"roles,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
 }
 
 // RegisterResponse represents the response from admin registration.
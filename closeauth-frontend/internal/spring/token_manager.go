@@ -6,6 +6,8 @@ import (
 	"log/slog"
 	"sync"
 	"time"
+
+	"closeauth-frontend/internal/metrics"
 )
 
 // TokenManager handles OAuth2 access token lifecycle with automatic refresh.
@@ -16,6 +18,18 @@ type TokenManager struct {
 	mu           sync.RWMutex
 	currentToken *AccessTokenResponse
 	expiresAt    time.Time
+	lastRefresh  time.Time
+	lastError    string
+}
+
+// TokenStatus describes the cached client_credentials token without exposing it.
+type TokenStatus struct {
+	HasToken         bool      `json:"has_token"`
+	Valid            bool      `json:"valid"`
+	ExpiresAt        time.Time `json:"expires_at,omitzero"`
+	ExpiresInSeconds int64     `json:"expires_in_seconds"`
+	LastRefreshAt    time.Time `json:"last_refresh_at,omitzero"`
+	LastError        string    `json:"last_error,omitempty"`
 }
 
 // NewTokenManager creates a new token manager.
@@ -58,6 +72,24 @@ func (tm *TokenManager) InvalidateToken() {
 	tm.expiresAt = time.Time{}
 }
 
+// Status reports the token lifecycle for diagnostics.
+func (tm *TokenManager) Status() TokenStatus {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	status := TokenStatus{
+		HasToken:      tm.currentToken != nil,
+		Valid:         tm.isValid(),
+		LastRefreshAt: tm.lastRefresh,
+		LastError:     tm.lastError,
+	}
+	if status.HasToken {
+		status.ExpiresAt = tm.expiresAt
+		status.ExpiresInSeconds = max(int64(time.Until(tm.expiresAt).Seconds()), 0)
+	}
+	return status
+}
+
 // isValid checks if the current token is valid with a 30-second safety buffer.
 // Must be called with at least a read lock held.
 func (tm *TokenManager) isValid() bool {
@@ -83,11 +115,15 @@ func (tm *TokenManager) refreshToken(ctx context.Context) (string, error) {
 	start := time.Now()
 
 	tokenResp, err := tm.client.fetchAccessToken(ctx)
+	metrics.TokenRefresh(err)
+	tm.lastRefresh = time.Now()
 	if err != nil {
+		tm.lastError = err.Error()
 		tm.logger.Error("failed to fetch access token", "error", err, "duration_ms", time.Since(start).Milliseconds())
 		return "", fmt.Errorf("failed to fetch access token: %w", err)
 	}
 
+	tm.lastError = ""
 	tm.currentToken = tokenResp
 	tm.expiresAt = time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
 
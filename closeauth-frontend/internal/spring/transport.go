@@ -0,0 +1,111 @@
+package spring
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"closeauth-frontend/internal/metrics"
+	"closeauth-frontend/internal/requestid"
+	"closeauth-frontend/internal/tracing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// idCollections are path segments followed by an identifier in Spring URLs.
+var idCollections = map[string]bool{
+	"clients":               true,
+	"themes":                true,
+	"roles":                 true,
+	"configurations":        true,
+	"pending-registrations": true,
+	"register":              true,
+}
+
+// staticRegisterPaths are the fixed children of /oauth2/register/.
+var staticRegisterPaths = map[string]bool{
+	"verify-email":     true,
+	"verify-phone":     true,
+	"resend-email-otp": true,
+	"resend-phone-otp": true,
+}
+
+// instrumentedTransport records latency and outcome of every Spring call and
+// wraps it in a client span. The W3C traceparent and X-Request-ID of the
+// originating browser request are forwarded to Spring.
+type instrumentedTransport struct {
+	next        http.RoundTripper
+	contextPath string
+}
+
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	endpoint := endpointLabel(req.URL.Path, t.contextPath)
+
+	ctx, span := tracing.Tracer().Start(req.Context(), "Spring "+req.Method+" "+endpoint,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(req.Method),
+			semconv.HTTPRoute(endpoint),
+			semconv.ServerAddress(req.URL.Hostname()),
+		),
+	)
+	defer span.End()
+
+	// RoundTrippers must not modify the caller's request
+	req = req.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	if id := requestid.FromContext(ctx); id != "" {
+		req.Header.Set(requestid.Header, id)
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+
+	status := 0
+	if resp != nil {
+		status = resp.StatusCode
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else if status >= 500 {
+		span.SetStatus(codes.Error, http.StatusText(status))
+	}
+	metrics.ObserveSpringRequest(endpoint, req.Method, status, err, time.Since(start))
+
+	return resp, err
+}
+
+// endpointLabel turns a Spring URL path into a low-cardinality template,
+// e.g. "/closeauth/api/v1/clients/7/themes/3" → "/api/v1/clients/{id}/themes/{id}".
+func endpointLabel(path, contextPath string) string {
+	if contextPath != "" {
+		path = strings.TrimPrefix(path, contextPath)
+	}
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i := 1; i < len(segments); i++ {
+		prev, seg := segments[i-1], segments[i]
+		if prev == "register" && staticRegisterPaths[seg] {
+			continue
+		}
+		if idCollections[prev] || isIdentifier(seg) {
+			segments[i] = "{id}"
+		}
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+// isIdentifier reports whether a segment looks like a numeric id, an email or a UUID.
+func isIdentifier(seg string) bool {
+	if strings.Contains(seg, "@") || len(seg) >= 32 {
+		return true
+	}
+	return seg != "" && strings.Trim(seg, "0123456789") == ""
+}
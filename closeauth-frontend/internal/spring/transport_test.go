@@ -0,0 +1,111 @@
+package spring
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"closeauth-frontend/internal/requestid"
+	"closeauth-frontend/internal/tracing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestEndpointLabel(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/closeauth/oauth2/token", "/oauth2/token"},
+		{"/closeauth/api/v1/clients", "/api/v1/clients"},
+		{"/closeauth/api/v1/clients/7/themes/3/configurations/9", "/api/v1/clients/{id}/themes/{id}/configurations/{id}"},
+		{"/closeauth/api/v1/admin/clients/7/pending-registrations/a@b.c/approve", "/api/v1/admin/clients/{id}/pending-registrations/{id}/approve"},
+		{"/closeauth/oauth2/register/my-client", "/oauth2/register/{id}"},
+		{"/closeauth/oauth2/register/verify-email", "/oauth2/register/verify-email"},
+	}
+	for _, tt := range tests {
+		if got := endpointLabel(tt.path, "/closeauth"); got != tt.want {
+			t.Errorf("endpointLabel(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestInstrumentedTransport_PropagatesTraceparent(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	}()
+
+	var received string
+	spring := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer spring.Close()
+
+	client := &http.Client{Transport: &instrumentedTransport{next: http.DefaultTransport, contextPath: "/closeauth"}}
+
+	ctx, parent := tracing.Tracer().Start(context.Background(), "handler")
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, spring.URL+"/closeauth/api/v1/clients/7/roles", nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	parent.End()
+
+	if req.Header.Get("traceparent") != "" {
+		t.Error("transport must not modify the caller's request headers")
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	clientSpan := spans[0]
+	if clientSpan.Name != "Spring GET /api/v1/clients/{id}/roles" {
+		t.Errorf("span name = %q", clientSpan.Name)
+	}
+	if clientSpan.Parent.SpanID() != parent.SpanContext().SpanID() {
+		t.Error("Spring span should be a child of the handler span")
+	}
+
+	want := "00-" + clientSpan.SpanContext.TraceID().String() + "-" + clientSpan.SpanContext.SpanID().String() + "-01"
+	if received != want {
+		t.Errorf("traceparent sent to Spring = %q, want %q", received, want)
+	}
+}
+
+func TestInstrumentedTransport_PropagatesRequestID(t *testing.T) {
+	var received string
+	spring := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get(requestid.Header)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer spring.Close()
+
+	client := &http.Client{Transport: &instrumentedTransport{next: http.DefaultTransport, contextPath: "/closeauth"}}
+
+	ctx := requestid.NewContext(context.Background(), "req-123")
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, spring.URL+"/closeauth/api/v1/clients", nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if received != "req-123" {
+		t.Errorf("Spring received %s %q, want %q", requestid.Header, received, "req-123")
+	}
+	if req.Header.Get(requestid.Header) != "" {
+		t.Error("caller's request was modified")
+	}
+}
@@ -1,10 +1,13 @@
 package static
 
 import (
+	"bytes"
 	"embed"
 	"io/fs"
 	"net/http"
 	"strings"
+
+	"closeauth-frontend/internal/middleware"
 )
 
 //go:embed all:dist
@@ -13,6 +16,9 @@ var distFS embed.FS
 // SPAHandler serves the Vue SPA from the embedded dist/ directory.
 // It serves static assets directly and falls back to index.html for
 // HTML5 history mode routing (any non-file path gets index.html).
+//
+// index.html is rendered per request so the CSP nonce generated by
+// SecurityHeadersMiddleware can be stamped onto its <script>/<style> tags.
 func SPAHandler() http.Handler {
 	// Strip the "dist" prefix from the embedded filesystem
 	distContent, err := fs.Sub(distFS, "dist")
@@ -21,6 +27,7 @@ func SPAHandler() http.Handler {
 	}
 
 	fileServer := http.FileServer(http.FS(distContent))
+	indexHTML, _ := fs.ReadFile(distContent, "index.html")
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		path := r.URL.Path
@@ -32,14 +39,39 @@ func SPAHandler() http.Handler {
 		}
 
 		// Try to serve the file directly (CSS, JS, images, etc.)
-		if file, err := distContent.Open(strings.TrimPrefix(path, "/")); err == nil {
-			file.Close()
+		name := strings.TrimPrefix(path, "/")
+		if name != "index.html" {
+			if file, err := distContent.Open(name); err == nil {
+				file.Close()
+				fileServer.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		// Fallback: serve index.html for SPA client-side routing
+		if indexHTML == nil {
+			r.URL.Path = "/"
 			fileServer.ServeHTTP(w, r)
 			return
 		}
 
-		// Fallback: serve index.html for SPA client-side routing
-		r.URL.Path = "/"
-		fileServer.ServeHTTP(w, r)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Write(injectNonce(indexHTML, middleware.CSPNonce(r.Context())))
 	})
 }
+
+// injectNonce adds nonce attributes to every <script> and <style> tag and exposes
+// the nonce to the SPA via <meta name="csp-nonce"> for runtime-inserted styles.
+func injectNonce(html []byte, nonce string) []byte {
+	if nonce == "" {
+		return html
+	}
+
+	attr := []byte(` nonce="` + nonce + `"`)
+	out := bytes.ReplaceAll(html, []byte("<script"), append([]byte("<script"), attr...))
+	out = bytes.ReplaceAll(out, []byte("<style"), append([]byte("<style"), attr...))
+
+	meta := []byte(`<meta name="csp-nonce" content="` + nonce + `">` + "\n</head>")
+	return bytes.Replace(out, []byte("</head>"), meta, 1)
+}
@@ -0,0 +1,72 @@
+package themeasset
+
+import (
+	"image"
+	"math"
+)
+
+// contribution is the share of one source pixel in a destination pixel.
+type contribution struct {
+	index  int
+	weight float64
+}
+
+// resize scales src down to width×height by area averaging: each destination
+// pixel is the mean of the source pixels it covers, weighted by coverage.
+// Averaging premultiplied RGBA keeps transparent edges free of dark fringes.
+// Rows are resampled one at a time, so memory stays proportional to width.
+func resize(src *image.RGBA, width, height int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	columns := contributions(src.Rect.Dx(), width)
+	rows := contributions(src.Rect.Dy(), height)
+
+	row := make([]float64, width*4)
+	acc := make([]float64, width*4)
+	for y, sources := range rows {
+		clear(acc)
+		for _, c := range sources {
+			resampleRow(src, c.index, columns, row)
+			for i, v := range row {
+				acc[i] += v * c.weight
+			}
+		}
+		out := dst.Pix[y*dst.Stride : y*dst.Stride+width*4]
+		for i, v := range acc {
+			out[i] = uint8(math.Min(255, math.Max(0, math.Round(v))))
+		}
+	}
+	return dst
+}
+
+// contributions maps each of m destination pixels to the n source pixels
+// it covers.
+func contributions(n, m int) [][]contribution {
+	scale := float64(n) / float64(m)
+	out := make([][]contribution, m)
+	for d := range out {
+		start, end := float64(d)*scale, float64(d+1)*scale
+		for s := int(start); s < n && float64(s) < end; s++ {
+			overlap := math.Min(end, float64(s+1)) - math.Max(start, float64(s))
+			if overlap > 0 {
+				out[d] = append(out[d], contribution{s, overlap / scale})
+			}
+		}
+	}
+	return out
+}
+
+// resampleRow scales source row y horizontally into row.
+func resampleRow(src *image.RGBA, y int, columns [][]contribution, row []float64) {
+	pix := src.Pix[y*src.Stride:]
+	for x, sources := range columns {
+		var r, g, b, a float64
+		for _, c := range sources {
+			p := pix[c.index*4 : c.index*4+4]
+			r += float64(p[0]) * c.weight
+			g += float64(p[1]) * c.weight
+			b += float64(p[2]) * c.weight
+			a += float64(p[3]) * c.weight
+		}
+		row[x*4], row[x*4+1], row[x*4+2], row[x*4+3] = r, g, b, a
+	}
+}
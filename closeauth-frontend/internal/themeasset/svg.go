@@ -0,0 +1,253 @@
+package themeasset
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	svgNS   = "http://www.w3.org/2000/svg"
+	xlinkNS = "http://www.w3.org/1999/xlink"
+	xmlNS   = "http://www.w3.org/XML/1998/namespace"
+
+	// maxSVGDepth bounds element nesting
+	maxSVGDepth = 64
+)
+
+// svgElements are kept; any other element is dropped with everything inside
+// it (script, foreignObject, animation that could rewrite an href, ...).
+var svgElements = map[string]bool{
+	"svg": true, "g": true, "defs": true, "symbol": true, "use": true,
+	"title": true, "desc": true, "style": true, "image": true,
+	"path": true, "rect": true, "circle": true, "ellipse": true,
+	"line": true, "polyline": true, "polygon": true,
+	"text": true, "tspan": true, "textPath": true,
+	"linearGradient": true, "radialGradient": true, "stop": true,
+	"clipPath": true, "mask": true, "pattern": true, "marker": true,
+	"filter": true, "feBlend": true, "feColorMatrix": true,
+	"feComponentTransfer": true, "feFuncR": true, "feFuncG": true,
+	"feFuncB": true, "feFuncA": true, "feComposite": true,
+	"feDropShadow": true, "feFlood": true, "feGaussianBlur": true,
+	"feMerge": true, "feMergeNode": true, "feMorphology": true,
+	"feOffset": true,
+}
+
+var (
+	// cssURL and fragmentURL count url() references and those to #fragments
+	cssURL      = regexp.MustCompile(`(?i)url\(`)
+	fragmentURL = regexp.MustCompile(`(?i)url\(\s*['"]?\s*#`)
+
+	// unsafeCSS matches constructs that load or run something
+	unsafeCSS = regexp.MustCompile(`(?i)@import|expression\s*\(|javascript:|-moz-binding|behavior\s*:|\\`)
+
+	// dataImage is the only external reference an <image> may make
+	dataImage = regexp.MustCompile(`(?i)^data:image/(png|jpeg|gif);base64,[a-z0-9+/=\s]*$`)
+
+	// svgLength is a plain or px length, the only kind used for dimensions
+	svgLength = regexp.MustCompile(`^\s*([0-9]*\.?[0-9]+)\s*(px)?\s*$`)
+)
+
+// isSVG reports whether data is an XML document whose root is <svg>.
+func isSVG(data []byte) bool {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return false
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			return t.Name.Local == "svg"
+		case xml.CharData:
+			if len(bytes.TrimSpace(t)) > 0 {
+				return false
+			}
+		}
+	}
+}
+
+// SanitizeSVG rebuilds an SVG document keeping only svgElements and their
+// safe attributes: event handlers, non-fragment links (except inline raster
+// images), url() references outside the document and styles that import or
+// execute are removed, as are comments, processing instructions and the
+// DOCTYPE. Entities other than XML's predefined ones are rejected. It returns
+// the document with its width and height in pixels, or 0 when unknown.
+func SanitizeSVG(data []byte) (clean []byte, width, height int, err error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	d.Strict = true
+
+	var out bytes.Buffer
+	var open []string // kept elements still open
+	var style []byte  // text of the open <style>, checked as a whole
+	depth, skip := 0, 0
+	rooted := false
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, 0, 0, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+			if depth > maxSVGDepth {
+				return nil, 0, 0, fmt.Errorf("%w: elements nested deeper than %d", ErrInvalidImage, maxSVGDepth)
+			}
+			if !rooted {
+				if t.Name.Local != "svg" || (t.Name.Space != "" && t.Name.Space != svgNS) {
+					return nil, 0, 0, fmt.Errorf("%w: root element is not svg", ErrInvalidImage)
+				}
+				rooted = true
+				width, height = svgSize(t.Attr)
+			} else if depth == 1 {
+				return nil, 0, 0, fmt.Errorf("%w: more than one root element", ErrInvalidImage)
+			}
+			inStyle := len(open) > 0 && open[len(open)-1] == "style"
+			if skip > 0 || inStyle || !svgElements[t.Name.Local] || (t.Name.Space != "" && t.Name.Space != svgNS) {
+				skip++
+				continue
+			}
+			writeStart(&out, t, depth == 1)
+			open = append(open, t.Name.Local)
+
+		case xml.EndElement:
+			depth--
+			if skip > 0 {
+				skip--
+				continue
+			}
+			name := open[len(open)-1]
+			if name == "style" {
+				// Split across CDATA sections, harmless pieces can join up
+				if safeCSS(string(style)) {
+					xml.EscapeText(&out, style)
+				}
+				style = style[:0]
+			}
+			out.WriteString("</" + name + ">")
+			open = open[:len(open)-1]
+
+		case xml.CharData:
+			if skip > 0 || len(open) == 0 {
+				continue
+			}
+			if open[len(open)-1] == "style" {
+				style = append(style, t...)
+				continue
+			}
+			xml.EscapeText(&out, t)
+		}
+		// Comments, processing instructions and directives are dropped
+	}
+	if !rooted {
+		return nil, 0, 0, fmt.Errorf("%w: no svg element", ErrInvalidImage)
+	}
+	return out.Bytes(), width, height, nil
+}
+
+// writeStart writes a kept element with its safe attributes. The root
+// declares the only namespaces the output uses.
+func writeStart(out *bytes.Buffer, t xml.StartElement, root bool) {
+	out.WriteString("<" + t.Name.Local)
+	if root {
+		out.WriteString(` xmlns="` + svgNS + `" xmlns:xlink="` + xlinkNS + `"`)
+	}
+	for _, a := range t.Attr {
+		name, ok := attrName(a.Name)
+		if !ok || !safeAttr(t.Name.Local, a.Name.Local, a.Value) {
+			continue
+		}
+		out.WriteString(" " + name + `="`)
+		xml.EscapeText(out, []byte(a.Value))
+		out.WriteString(`"`)
+	}
+	out.WriteString(">")
+}
+
+// attrName is the output name of an attribute, or false for namespace
+// declarations and attributes of foreign namespaces (editor metadata).
+func attrName(n xml.Name) (string, bool) {
+	switch n.Space {
+	case "":
+		return n.Local, n.Local != "xmlns"
+	case xlinkNS:
+		return "xlink:" + n.Local, true
+	case xmlNS:
+		return "xml:" + n.Local, true
+	}
+	return "", false
+}
+
+func safeAttr(element, name, value string) bool {
+	if strings.HasPrefix(strings.ToLower(name), "on") {
+		return false
+	}
+	if name == "href" {
+		v := strings.TrimSpace(value)
+		return strings.HasPrefix(v, "#") || (element == "image" && dataImage.MatchString(v))
+	}
+	if name == "style" || cssURL.MatchString(value) {
+		return safeCSS(value)
+	}
+	return !strings.Contains(strings.ToLower(strings.Join(strings.Fields(value), "")), "javascript:")
+}
+
+// safeCSS reports whether css only refers to the document itself.
+func safeCSS(css string) bool {
+	if unsafeCSS.MatchString(css) {
+		return false
+	}
+	return len(cssURL.FindAllStringIndex(css, -1)) == len(fragmentURL.FindAllStringIndex(css, -1))
+}
+
+// svgSize reads the root's width and height, falling back to its viewBox.
+func svgSize(attrs []xml.Attr) (int, int) {
+	var width, height float64
+	var viewBox string
+	for _, a := range attrs {
+		if a.Name.Space != "" {
+			continue
+		}
+		switch a.Name.Local {
+		case "width":
+			width = svgPixels(a.Value)
+		case "height":
+			height = svgPixels(a.Value)
+		case "viewBox":
+			viewBox = a.Value
+		}
+	}
+	if (width == 0 || height == 0) && viewBox != "" {
+		fields := strings.FieldsFunc(viewBox, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' || r == '\n' })
+		if len(fields) == 4 {
+			w, errW := strconv.ParseFloat(fields[2], 64)
+			h, errH := strconv.ParseFloat(fields[3], 64)
+			if err := errors.Join(errW, errH); err == nil && w > 0 && h > 0 {
+				width, height = w, h
+			}
+		}
+	}
+	if width <= 0 || height <= 0 || width > MaxDimension || height > MaxDimension {
+		return 0, 0
+	}
+	return int(math.Round(width)), int(math.Round(height))
+}
+
+func svgPixels(v string) float64 {
+	m := svgLength.FindStringSubmatch(v)
+	if m == nil {
+		return 0
+	}
+	f, _ := strconv.ParseFloat(m[1], 64)
+	return f
+}
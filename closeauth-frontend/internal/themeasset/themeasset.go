@@ -0,0 +1,224 @@
+// Package themeasset turns an uploaded logo or background image into the
+// variants the BFF serves from its own origin.
+//
+// The upload's declared type and name are not trusted: content is sniffed,
+// SVG is rebuilt from an allowlist of elements with scripts, event handlers
+// and external references removed, and PNG, JPEG and GIF images are decoded
+// and re-encoded (dropping metadata and any trailing payload) into a few
+// sizes. Only PNG (or JPEG for opaque photographs) and sanitized SVG leave
+// this package.
+package themeasset
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif" // registers the GIF decoder; the first frame is kept
+	"image/jpeg"
+	"image/png"
+	"math"
+	"net/http"
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"closeauth-frontend/internal/database/models"
+)
+
+const (
+	// MaxPixels bounds the decoded size of a raster upload, whatever its
+	// compressed size (a decompression bomb is rejected before decoding)
+	MaxPixels = 4096 * 4096
+
+	// MaxDimension bounds either side of a raster upload
+	MaxDimension = 8192
+
+	// jpegQuality is used when re-encoding opaque JPEG uploads
+	jpegQuality = 85
+)
+
+// Content types of the variants this package produces.
+const (
+	TypePNG  = "image/png"
+	TypeJPEG = "image/jpeg"
+	TypeSVG  = "image/svg+xml"
+)
+
+var (
+	ErrUnknownKind     = errors.New("unknown asset kind")
+	ErrUnsupportedType = errors.New("unsupported image type")
+	ErrInvalidImage    = errors.New("invalid image")
+	ErrImageTooLarge   = errors.New("image too large")
+)
+
+// variantSize is a bounding box for the longest side of a variant.
+type variantSize struct {
+	name string
+	max  int
+}
+
+// sizes are the variants produced for each kind of raster asset, smallest
+// first. Images are never enlarged, so a small upload yields fewer variants.
+var sizes = map[string][]variantSize{
+	models.AssetKindLogo:       {{"sm", 128}, {"md", 256}, {"lg", 512}},
+	models.AssetKindBackground: {{"sm", 640}, {"md", 1280}, {"lg", 1920}},
+}
+
+// svgVariant names the single variant of an SVG upload.
+const svgVariant = "svg"
+
+// Variant is one rendition of an upload.
+type Variant struct {
+	Name        string
+	ContentType string
+	Width       int
+	Height      int
+	Data        []byte
+}
+
+// Key is the variant's blob key: its SHA-256 content hash and extension.
+func (v Variant) Key() string {
+	sum := sha256.Sum256(v.Data)
+	ext := ".png"
+	switch v.ContentType {
+	case TypeJPEG:
+		ext = ".jpg"
+	case TypeSVG:
+		ext = ".svg"
+	}
+	return hex.EncodeToString(sum[:]) + ext
+}
+
+// Result is a processed upload.
+type Result struct {
+	ContentType string // of the upload, as sniffed
+	Width       int    // 0 for an SVG without usable dimensions
+	Height      int
+	Variants    []Variant // smallest first
+}
+
+// Process sniffs data and produces the variants for an asset of kind
+// (models.AssetKindLogo or models.AssetKindBackground). Errors wrap one of
+// the Err values above.
+func Process(kind string, data []byte) (*Result, error) {
+	boxes, ok := sizes[kind]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKind, kind)
+	}
+
+	contentType := http.DetectContentType(data)
+	switch contentType {
+	case "image/png", "image/jpeg", "image/gif":
+		return processRaster(contentType, data, boxes)
+	}
+	if isSVG(data) {
+		clean, width, height, err := SanitizeSVG(data)
+		if err != nil {
+			return nil, err
+		}
+		return &Result{
+			ContentType: TypeSVG,
+			Width:       width,
+			Height:      height,
+			Variants:    []Variant{{Name: svgVariant, ContentType: TypeSVG, Width: width, Height: height, Data: clean}},
+		}, nil
+	}
+	return nil, fmt.Errorf("%w %s: use PNG, JPEG, GIF or SVG", ErrUnsupportedType, strings.SplitN(contentType, ";", 2)[0])
+}
+
+func processRaster(contentType string, data []byte, boxes []variantSize) (*Result, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, fmt.Errorf("%w: empty image", ErrInvalidImage)
+	}
+	if cfg.Width > MaxDimension || cfg.Height > MaxDimension || cfg.Width*cfg.Height > MaxPixels {
+		return nil, fmt.Errorf("%w: %dx%d exceeds %d pixels or %d per side", ErrImageTooLarge, cfg.Width, cfg.Height, MaxPixels, MaxDimension)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	src := image.NewRGBA(image.Rect(0, 0, img.Bounds().Dx(), img.Bounds().Dy()))
+	draw.Draw(src, src.Bounds(), img, img.Bounds().Min, draw.Src)
+
+	// Photographs stay JPEG; anything that may be transparent becomes PNG
+	outType := TypePNG
+	if contentType == "image/jpeg" && src.Opaque() {
+		outType = TypeJPEG
+	}
+
+	result := &Result{ContentType: contentType, Width: src.Rect.Dx(), Height: src.Rect.Dy()}
+	for _, box := range boxes {
+		width, height := fit(result.Width, result.Height, box.max)
+		if n := len(result.Variants); n > 0 && result.Variants[n-1].Width == width {
+			continue
+		}
+		scaled := src
+		if width != result.Width || height != result.Height {
+			scaled = resize(src, width, height)
+		}
+		encoded, err := encode(scaled, outType)
+		if err != nil {
+			return nil, err
+		}
+		result.Variants = append(result.Variants, Variant{Name: box.name, ContentType: outType, Width: width, Height: height, Data: encoded})
+	}
+	return result, nil
+}
+
+// fit scales width×height down, keeping the aspect ratio, until the longest
+// side is at most max.
+func fit(width, height, max int) (int, int) {
+	longest := width
+	if height > longest {
+		longest = height
+	}
+	if longest <= max {
+		return width, height
+	}
+	scale := float64(max) / float64(longest)
+	return int(math.Max(1, math.Round(float64(width)*scale))), int(math.Max(1, math.Round(float64(height)*scale)))
+}
+
+func encode(img *image.RGBA, contentType string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	if contentType == TypeJPEG {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	} else {
+		err = (&png.Encoder{CompressionLevel: png.BestCompression}).Encode(&buf, img)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("encoding %s: %w", contentType, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// CleanFilename reduces an upload's file name to a printable base name of at
+// most 255 bytes, for display only.
+func CleanFilename(name string) string {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == unicode.ReplacementChar {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "." || name == "/" {
+		return ""
+	}
+	for len(name) > 255 {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name
+}
@@ -0,0 +1,221 @@
+package themeasset
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+
+	"closeauth-frontend/internal/database/models"
+)
+
+func encodePNG(t *testing.T, width, height int, c color.Color) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestProcess_Raster(t *testing.T) {
+	opaque := color.NRGBA{R: 200, G: 40, B: 40, A: 255}
+	var photo, anim bytes.Buffer
+	if err := jpeg.Encode(&photo, image.NewRGBA(image.Rect(0, 0, 3000, 1500)), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := gif.Encode(&anim, image.NewPaletted(image.Rect(0, 0, 40, 20), []color.Color{color.Black}), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		kind      string
+		data      []byte
+		wantType  string
+		wantSizes [][2]int
+	}{
+		{"large logo", models.AssetKindLogo, encodePNG(t, 1000, 500, opaque), TypePNG, [][2]int{{128, 64}, {256, 128}, {512, 256}}},
+		{"small logo is not enlarged", models.AssetKindLogo, encodePNG(t, 200, 100, opaque), TypePNG, [][2]int{{128, 64}, {200, 100}}},
+		{"tiny logo", models.AssetKindLogo, encodePNG(t, 32, 32, opaque), TypePNG, [][2]int{{32, 32}}},
+		{"photo background stays JPEG", models.AssetKindBackground, photo.Bytes(), TypeJPEG, [][2]int{{640, 320}, {1280, 640}, {1920, 960}}},
+		{"GIF becomes PNG", models.AssetKindLogo, anim.Bytes(), TypePNG, [][2]int{{40, 20}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Process(tt.kind, tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var sizes [][2]int
+			for _, v := range result.Variants {
+				sizes = append(sizes, [2]int{v.Width, v.Height})
+				if v.ContentType != tt.wantType {
+					t.Errorf("variant %s type = %s, want %s", v.Name, v.ContentType, tt.wantType)
+				}
+				cfg, _, err := image.DecodeConfig(bytes.NewReader(v.Data))
+				if err != nil || cfg.Width != v.Width || cfg.Height != v.Height {
+					t.Errorf("variant %s decodes as %dx%d (%v), want %dx%d", v.Name, cfg.Width, cfg.Height, err, v.Width, v.Height)
+				}
+				if !models.IsAssetBlobKey(v.Key()) {
+					t.Errorf("variant %s key %q is not a blob key", v.Name, v.Key())
+				}
+			}
+			if len(sizes) != len(tt.wantSizes) {
+				t.Fatalf("variant sizes = %v, want %v", sizes, tt.wantSizes)
+			}
+			for i := range sizes {
+				if sizes[i] != tt.wantSizes[i] {
+					t.Fatalf("variant sizes = %v, want %v", sizes, tt.wantSizes)
+				}
+			}
+		})
+	}
+}
+
+func TestProcess_Rejects(t *testing.T) {
+	// A valid header claiming 20000×20000 pixels: rejected before decoding
+	var bomb bytes.Buffer
+	png.Encode(&bomb, image.NewGray(image.Rect(0, 0, 1, 1)))
+	header := bomb.Bytes()[:33]
+	copy(header[16:24], []byte{0, 0, 0x4e, 0x20, 0, 0, 0x4e, 0x20})
+	binary.BigEndian.PutUint32(header[29:33], crc32.ChecksumIEEE(header[12:29]))
+
+	tests := []struct {
+		name    string
+		kind    string
+		data    []byte
+		wantErr error
+	}{
+		{"unknown kind", "favicon", encodePNG(t, 1, 1, color.White), ErrUnknownKind},
+		{"text", models.AssetKindLogo, []byte("hello"), ErrUnsupportedType},
+		{"html", models.AssetKindLogo, []byte("<html><script>alert(1)</script></html>"), ErrUnsupportedType},
+		{"webp", models.AssetKindLogo, []byte("RIFF\x00\x00\x00\x00WEBPVP8 "), ErrUnsupportedType},
+		{"truncated png", models.AssetKindLogo, encodePNG(t, 10, 10, color.White)[:60], ErrInvalidImage},
+		{"decompression bomb", models.AssetKindLogo, header, ErrImageTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Process(tt.kind, tt.data); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Process() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResize_AveragesPremultiplied(t *testing.T) {
+	// Half opaque red, half transparent: the average is half-transparent red,
+	// not a darkened one
+	src := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	src.Set(0, 0, color.RGBA{R: 255, A: 255})
+	got := resize(src, 1, 1).RGBAAt(0, 0)
+	if want := (color.RGBA{R: 128, A: 128}); got != want {
+		t.Errorf("resize() = %v, want %v", got, want)
+	}
+}
+
+func TestSanitizeSVG(t *testing.T) {
+	tests := []struct {
+		name     string
+		svg      string
+		want     []string
+		wantNot  []string
+		wantSize [2]int
+	}{
+		{
+			name:     "keeps drawing",
+			svg:      `<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg" width="120" height="40px"><rect width="10" height="10" fill="#ff0000"/></svg>`,
+			want:     []string{`<rect width="10" height="10" fill="#ff0000"></rect>`},
+			wantSize: [2]int{120, 40},
+		},
+		{
+			name:     "drops scripts and handlers",
+			svg:      `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 64 32" onload="alert(1)"><script>alert(2)</script><g onclick="x()"><foreignObject><div>hi</div></foreignObject></g></svg>`,
+			want:     []string{`<g></g>`},
+			wantNot:  []string{"alert", "onclick", "foreignObject", "div"},
+			wantSize: [2]int{64, 32},
+		},
+		{
+			name:    "drops external references",
+			svg:     `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink"><use xlink:href="https://evil.test/x.svg#a"/><use href="#local"/><rect fill="url(https://evil.test/p)"/><rect fill="url(#grad)"/><image href="javascript:alert(1)"/><image href="data:image/png;base64,AAAA"/><a href="https://evil.test"><text>click</text></a></svg>`,
+			want:    []string{`<use href="#local">`, `fill="url(#grad)"`, `<image href="data:image/png;base64,AAAA">`},
+			wantNot: []string{"evil.test", "javascript", "click"},
+		},
+		{
+			name:    "drops styles that load",
+			svg:     `<svg xmlns="http://www.w3.org/2000/svg"><style>@im<![CDATA[port "https://evil.test/a.css";]]></style><style>.a{fill:red}</style><rect style="background:url(https://evil.test/b)"/></svg>`,
+			want:    []string{`<style></style><style>.a{fill:red}</style>`},
+			wantNot: []string{"evil.test", "import"},
+		},
+		{
+			name:    "drops editor metadata",
+			svg:     `<svg xmlns="http://www.w3.org/2000/svg" xmlns:inkscape="http://www.inkscape.org/namespaces/inkscape"><!-- made by hand --><inkscape:grid/><g inkscape:label="Layer 1" xml:space="preserve"></g></svg>`,
+			want:    []string{`<g xml:space="preserve"></g>`},
+			wantNot: []string{"inkscape", "made by hand"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clean, width, height, err := SanitizeSVG([]byte(tt.svg))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := string(clean)
+			if !strings.HasPrefix(got, `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink"`) {
+				t.Errorf("SanitizeSVG() = %s, want an svg root with fixed namespaces", got)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("SanitizeSVG() = %s, want %s", got, want)
+				}
+			}
+			for _, bad := range tt.wantNot {
+				if strings.Contains(got, bad) {
+					t.Errorf("SanitizeSVG() = %s, must not contain %s", got, bad)
+				}
+			}
+			if [2]int{width, height} != tt.wantSize {
+				t.Errorf("SanitizeSVG() size = %dx%d, want %v", width, height, tt.wantSize)
+			}
+		})
+	}
+
+	for _, bad := range []string{
+		`<!DOCTYPE svg [<!ENTITY lol "lol">]><svg xmlns="http://www.w3.org/2000/svg"><text>&lol;</text></svg>`,
+		`<html><svg/></html>`,
+		`<svg xmlns="http://www.w3.org/2000/svg"><g>`,
+	} {
+		if _, _, _, err := SanitizeSVG([]byte(bad)); !errors.Is(err, ErrInvalidImage) {
+			t.Errorf("SanitizeSVG(%s) error = %v, want %v", bad, err, ErrInvalidImage)
+		}
+	}
+}
+
+func TestCleanFilename(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"logo.png", "logo.png"},
+		{`C:\Users\me\logo.png`, "logo.png"},
+		{"../../etc/passwd", "passwd"},
+		{"bad\x00name\n.svg", "badname.svg"},
+		{"", ""},
+		{strings.Repeat("é", 200), strings.Repeat("é", 127)},
+	}
+	for _, tt := range tests {
+		if got := CleanFilename(tt.in); got != tt.want {
+			t.Errorf("CleanFilename(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
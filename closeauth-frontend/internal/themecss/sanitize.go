@@ -0,0 +1,167 @@
+package themecss
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	comment = regexp.MustCompile(`(?s)/\*.*?(?:\*/|$)`)
+	imports = regexp.MustCompile(`(?i)@import[^;]*;?`)
+
+	// Functions that run script (IE expressions) or load resources from
+	// strings rather than url(), which Sanitize cannot vet
+	blockedFunction = regexp.MustCompile(`(?i)(?:expression|(?:-webkit-)?image-set|\bsrc|-moz-element)\s*\(`)
+
+	// Properties that attach behaviour (IE .htc files, XBL bindings)
+	blockedProperty = regexp.MustCompile(`(?i)(?:-moz-binding|\bbehavior)\s*:`)
+
+	urlFunction = regexp.MustCompile(`(?i)url\(`)
+)
+
+// Sanitize makes untrusted custom CSS safe to serve from the BFF's origin:
+// comments and @import rules are removed, script expressions and
+// behaviour bindings are neutralised, and url() references are replaced by
+// none unless they are relative or point at one of allowedOrigins
+// (scheme://host[:port]). Escaped letters are decoded first so obfuscated
+// names such as \75rl( are caught too.
+func Sanitize(css string, allowedOrigins []string) string {
+	css = comment.ReplaceAllString(css, " ")
+	css = unescape(css)
+	css = imports.ReplaceAllString(css, "")
+	css = blockedFunction.ReplaceAllString(css, "invalid(")
+	css = blockedProperty.ReplaceAllString(css, "x-removed:")
+	return sanitizeURLs(css, allowedOrigins)
+}
+
+// sanitizeURLs rewrites every url(...) token, including a quoted argument
+// that contains ')'. An unterminated url( drops the rest of the input.
+func sanitizeURLs(css string, allowedOrigins []string) string {
+	var b strings.Builder
+	for {
+		loc := urlFunction.FindStringIndex(css)
+		if loc == nil {
+			b.WriteString(css)
+			return b.String()
+		}
+		b.WriteString(css[:loc[0]])
+		rest := css[loc[1]:]
+		end := urlEnd(rest)
+		if end < 0 {
+			return b.String()
+		}
+		if ref := unquote(strings.TrimSpace(rest[:end])); allowedURL(ref, allowedOrigins) {
+			b.WriteString(`url("` + ref + `")`)
+		} else {
+			b.WriteString("none")
+		}
+		css = rest[end+1:]
+	}
+}
+
+// urlEnd returns the index of the ')' closing a url( argument, skipping
+// quoted strings and escaped characters, or -1 if there is none.
+func urlEnd(s string) int {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ')':
+			return i
+		}
+	}
+	return -1
+}
+
+// unquote strips one pair of matching quotes.
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// allowedURL reports whether ref is relative to the stylesheet or absolute on
+// an allowed origin.
+func allowedURL(ref string, allowedOrigins []string) bool {
+	if ref == "" || strings.ContainsAny(ref, "\"'()\\ \t\r\n\f") {
+		return false
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return false
+	}
+	if u.Scheme == "" && u.Host == "" && !strings.HasPrefix(ref, "//") {
+		return true
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return false
+	}
+	origin := strings.ToLower(u.Scheme + "://" + u.Host)
+	for _, allowed := range allowedOrigins {
+		if origin == strings.ToLower(strings.TrimSuffix(allowed, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// unescape decodes CSS escapes that stand for ASCII letters, digits, '-' or
+// '_' (\75 or \u for "u"), the characters an attacker would escape to hide a
+// function or at-rule name. Other escapes are kept verbatim so quotes and
+// punctuation inside strings keep their meaning.
+func unescape(css string) string {
+	if !strings.Contains(css, `\`) {
+		return css
+	}
+	var b strings.Builder
+	for i := 0; i < len(css); i++ {
+		if css[i] != '\\' || i+1 >= len(css) {
+			b.WriteByte(css[i])
+			continue
+		}
+		j := i + 1
+		for j < len(css) && j-i <= 6 && isHex(css[j]) {
+			j++
+		}
+		if j == i+1 {
+			// \X for a non-hex character X stands for X itself. Anything
+			// else (\\, \") stays escaped, as a pair
+			if !isIdent(css[j]) {
+				b.WriteByte(css[i])
+			}
+			b.WriteByte(css[j])
+			i = j
+			continue
+		}
+		code, _ := strconv.ParseUint(css[i+1:j], 16, 32)
+		if code > 0x7f || !isIdent(byte(code)) {
+			b.WriteByte(css[i])
+			continue
+		}
+		b.WriteByte(byte(code))
+		// One whitespace character terminates a hex escape
+		if j < len(css) && strings.IndexByte(" \t\n\r\f", css[j]) >= 0 {
+			j++
+		}
+		i = j - 1
+	}
+	return b.String()
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func isIdent(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-' || c == '_'
+}
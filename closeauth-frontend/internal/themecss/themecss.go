@@ -0,0 +1,120 @@
+// Package themecss renders client themes as stylesheets for the hosted login
+// pages. Colours become CSS custom properties (the --theme-* variables the
+// SPA already uses) and custom CSS from 'css'-typed theme configuration is
+// sanitized before it is appended.
+package themecss
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"closeauth-frontend/internal/database/models"
+)
+
+// Built-in colours for themes that leave a colour unset. They match the SPA's
+// defaults in stores/theme.ts.
+var (
+	DefaultLight = models.ThemeColors{Primary: "#3b82f6", Background: "#ffffff", Button: "#3b82f6", Text: "#1f2937"}
+	DefaultDark  = models.ThemeColors{Primary: "#60a5fa", Background: "#1f2937", Button: "#3b82f6", Text: "#f9fafb"}
+)
+
+// ConfigType is the theme_configurations.config_type of custom CSS entries.
+const ConfigType = "css"
+
+var hexColor = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// Render builds the stylesheet for theme, or for the built-in theme when nil.
+//
+// The default mode's colours are set on :root. With DefaultMode "system" the
+// dark colours apply under prefers-color-scheme: dark. When the theme allows
+// a mode toggle, the SPA's data-theme="light|dark" attribute on the root
+// element overrides both. Entries of configs with ConfigType css are
+// sanitized (see Sanitize) and appended in order.
+func Render(theme *models.ClientTheme, configs []models.ThemeConfiguration, allowedOrigins []string) []byte {
+	mode, toggle := "light", true
+	light, dark := DefaultLight, DefaultDark
+	if theme != nil {
+		mode, toggle = theme.GetDefaultMode(), theme.AllowModeToggle
+		light = withDefaults(theme.GetLightColors(), DefaultLight)
+		dark = withDefaults(theme.GetDarkColors(), DefaultDark)
+	}
+
+	var b strings.Builder
+	switch mode {
+	case "system":
+		rule(&b, ":root", "light dark", light)
+		sel := ":root"
+		if toggle {
+			sel = `:root:not([data-theme="light"])`
+		}
+		b.WriteString("@media (prefers-color-scheme: dark) {\n")
+		rule(&b, sel, "", dark)
+		b.WriteString("}\n")
+		if toggle {
+			rule(&b, `:root[data-theme="dark"]`, "dark", dark)
+			rule(&b, `:root[data-theme="light"]`, "light", light)
+		}
+	case "dark":
+		rule(&b, ":root", "dark", dark)
+		if toggle {
+			rule(&b, `:root[data-theme="light"]`, "light", light)
+		}
+	default:
+		rule(&b, ":root", "light", light)
+		if toggle {
+			rule(&b, `:root[data-theme="dark"]`, "dark", dark)
+		}
+	}
+
+	for _, c := range configs {
+		if c.ConfigType != ConfigType {
+			continue
+		}
+		if css := strings.TrimSpace(Sanitize(c.ConfigValue, allowedOrigins)); css != "" {
+			b.WriteString("\n")
+			b.WriteString(css)
+			b.WriteString("\n")
+		}
+	}
+	return []byte(b.String())
+}
+
+// Version is a short content hash of a rendered stylesheet, used as its
+// strong ETag and as the cache-busting v= query parameter.
+func Version(css []byte) string {
+	sum := sha256.Sum256(css)
+	return hex.EncodeToString(sum[:8])
+}
+
+// rule writes selector { color-scheme; --theme-* }. An empty scheme is omitted.
+func rule(b *strings.Builder, selector, scheme string, c models.ThemeColors) {
+	fmt.Fprintf(b, "%s {\n", selector)
+	if scheme != "" {
+		fmt.Fprintf(b, "  color-scheme: %s;\n", scheme)
+	}
+	fmt.Fprintf(b, "  --theme-primary: %s;\n", c.Primary)
+	fmt.Fprintf(b, "  --theme-background: %s;\n", c.Background)
+	fmt.Fprintf(b, "  --theme-button: %s;\n", c.Button)
+	fmt.Fprintf(b, "  --theme-text: %s;\n", c.Text)
+	b.WriteString("}\n")
+}
+
+// withDefaults replaces unset or malformed colours, so a stored value can
+// never inject CSS.
+func withDefaults(c, def models.ThemeColors) models.ThemeColors {
+	pick := func(v, fallback string) string {
+		if hexColor.MatchString(v) {
+			return v
+		}
+		return fallback
+	}
+	return models.ThemeColors{
+		Primary:    pick(c.Primary, def.Primary),
+		Background: pick(c.Background, def.Background),
+		Button:     pick(c.Button, def.Button),
+		Text:       pick(c.Text, def.Text),
+	}
+}
@@ -0,0 +1,105 @@
+package themecss
+
+import (
+	"strings"
+	"testing"
+
+	"closeauth-frontend/internal/database/models"
+)
+
+func TestSanitize(t *testing.T) {
+	origins := []string{"https://login.example.com"}
+	tests := []struct {
+		name string
+		css  string
+		want string
+	}{
+		{"plain rules kept", ".btn { border-radius: 4px; }", ".btn { border-radius: 4px; }"},
+		{"relative url kept", `.a { background: url(/img/bg.png) }`, `.a { background: url("/img/bg.png") }`},
+		{"allowed origin kept", `.a { background: url('https://login.example.com/bg.png') }`, `.a { background: url("https://login.example.com/bg.png") }`},
+		{"foreign origin stripped", `.a { background: url("https://evil.test/x.png") }`, `.a { background: none }`},
+		{"protocol-relative stripped", `.a { background: url(//evil.test/x.png) }`, `.a { background: none }`},
+		{"javascript stripped", `.a { background: URL(javascript:alert(1)) }`, `.a { background: none) }`},
+		{"data stripped", `.a { background: url(data:image/png;base64,AAAA) }`, `.a { background: none }`},
+		{"escaped url stripped", `.a { background: \75 rl(https://evil.test/x.png) }`, `.a { background: none }`},
+		{"import removed", `@import url("https://evil.test/x.css"); .a { color: red }`, ` .a { color: red }`},
+		{"import hidden by comment removed", `@import/**/"https://evil.test/x.css";`, ``},
+		{"expression neutralised", `.a { width: expression(alert(1)) }`, `.a { width: invalid(alert(1)) }`},
+		{"image-set neutralised", `.a { background: image-set("https://evil.test/x.png" 1x) }`, `.a { background: invalid("https://evil.test/x.png" 1x) }`},
+		{"behavior neutralised", `.a { behavior: url(x.htc) }`, `.a { x-removed: url("x.htc") }`},
+		{"escaped quote kept", `.a::before { content: "\"" }`, `.a::before { content: "\"" }`},
+		{"unterminated url dropped", `.a { background: url(https://evil.test`, `.a { background: `},
+		{"quoted paren", `.a { background: url("a)b") red }`, `.a { background: none red }`},
+		{"quoted paren hides a url", `.a { background: url('x)') url(https://evil.test/y.png) }`, `.a { background: none none }`},
+		{"unterminated quoted url dropped", `.a { background: url("x) } .b { color: red }`, `.a { background: `},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sanitize(tt.css, origins); got != tt.want {
+				t.Errorf("Sanitize(%q) = %q, want %q", tt.css, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	str := func(s string) *string { return &s }
+	tests := []struct {
+		name    string
+		theme   *models.ClientTheme
+		configs []models.ThemeConfiguration
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "built-in theme",
+			want: []string{":root {\n  color-scheme: light;\n  --theme-primary: #3b82f6;", `:root[data-theme="dark"]`},
+		},
+		{
+			name:    "dark default without toggle",
+			theme:   &models.ClientTheme{DefaultMode: str("dark"), DarkPrimaryColor: str("#101010")},
+			want:    []string{":root {\n  color-scheme: dark;\n  --theme-primary: #101010;"},
+			notWant: []string{"data-theme"},
+		},
+		{
+			name:  "system with toggle",
+			theme: &models.ClientTheme{DefaultMode: str("system"), AllowModeToggle: true},
+			want: []string{
+				"color-scheme: light dark;",
+				"@media (prefers-color-scheme: dark) {\n:root:not([data-theme=\"light\"]) {",
+				`:root[data-theme="dark"]`,
+			},
+		},
+		{
+			name:    "malformed colour replaced",
+			theme:   &models.ClientTheme{LightTextColor: str("red; } body { display: none")},
+			want:    []string{"--theme-text: #1f2937;"},
+			notWant: []string{"display: none"},
+		},
+		{
+			name:  "custom css appended",
+			theme: &models.ClientTheme{},
+			configs: []models.ThemeConfiguration{
+				{ConfigKey: "buttons", ConfigType: "css", ConfigValue: ".btn { border-radius: 8px; }"},
+				{ConfigKey: "radius", ConfigType: "number", ConfigValue: "8"},
+			},
+			want:    []string{".btn { border-radius: 8px; }"},
+			notWant: []string{"\n8\n"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(Render(tt.theme, tt.configs, nil))
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("Render() = %s\nwant it to contain %q", got, want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("Render() = %s\nwant it not to contain %q", got, notWant)
+				}
+			}
+		})
+	}
+}
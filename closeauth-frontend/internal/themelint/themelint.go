@@ -0,0 +1,223 @@
+// Package themelint checks client theme colours against the WCAG 2.1
+// contrast requirements for text, and suggests the nearest compliant colour
+// for each failing pair.
+package themelint
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"closeauth-frontend/internal/database/models"
+	"closeauth-frontend/internal/themecss"
+)
+
+// Level is a WCAG 2.1 conformance level.
+type Level string
+
+const (
+	AA  Level = "AA"
+	AAA Level = "AAA"
+)
+
+// MinRatio is the minimum contrast ratio for normal-size text at the level
+// (success criteria 1.4.3 and 1.4.6).
+func (l Level) MinRatio() float64 {
+	if l == AAA {
+		return 7
+	}
+	return 4.5
+}
+
+// Finding is a colour pair below the required contrast ratio.
+type Finding struct {
+	Palette    string  `json:"palette"`    // light or dark
+	Pair       string  `json:"pair"`       // text/background or button/text
+	Field      string  `json:"field"`      // the column Suggestion replaces, e.g. light_text_color
+	Foreground string  `json:"foreground"` // text or button label colour
+	Background string  `json:"background"`
+	Ratio      float64 `json:"ratio"` // rounded down to two decimals
+	Required   float64 `json:"required"`
+	Suggestion string  `json:"suggestion,omitempty"` // empty when no colour complies
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s %s contrast %.2f:1 is below %.1f:1", f.Palette, f.Pair, f.Ratio, f.Required)
+}
+
+// Button labels are drawn in the SPA's --primary-foreground, which themes do
+// not set: oklch(0.99 0 0) in light mode and oklch(0.12 0.015 265) in dark.
+const (
+	lightButtonLabel = "#fcfcfc"
+	darkButtonLabel  = "#05070d"
+)
+
+// Check lints the text/background pair and the button/text pair (a button
+// against its label) of both palettes. Pairs the theme leaves entirely to
+// the built-in colours are not linted; unset colours in the other pairs are
+// checked as rendered, with the defaults. Malformed colours are skipped and
+// left to ValidateTheme.
+func Check(theme *models.ClientTheme, level Level) []Finding {
+	required := level.MinRatio()
+	var findings []Finding
+	palettes := []struct {
+		name   string
+		colors models.ThemeColors
+		def    models.ThemeColors
+		label  string
+	}{
+		{"light", theme.GetLightColors(), themecss.DefaultLight, lightButtonLabel},
+		{"dark", theme.GetDarkColors(), themecss.DefaultDark, darkButtonLabel},
+	}
+	for _, p := range palettes {
+		pairs := []struct {
+			name, field   string
+			fg, bg        string
+			set, adjustFg bool
+		}{
+			{
+				name: "text/background", field: p.name + "_text_color",
+				fg:  orDefault(p.colors.Text, p.def.Text),
+				bg:  orDefault(p.colors.Background, p.def.Background),
+				set: p.colors.Text != "" || p.colors.Background != "",
+				// The page background sets the tone; recolour the text
+				adjustFg: true,
+			},
+			{
+				name: "button/text", field: p.name + "_button_color",
+				fg:  p.label,
+				bg:  orDefault(p.colors.Button, p.def.Button),
+				set: p.colors.Button != "",
+			},
+		}
+		for _, pair := range pairs {
+			if !pair.set {
+				continue
+			}
+			ratio, err := ContrastRatio(pair.fg, pair.bg)
+			if err != nil || ratio >= required {
+				continue
+			}
+			f := Finding{
+				Palette:    p.name,
+				Pair:       pair.name,
+				Field:      pair.field,
+				Foreground: pair.fg,
+				Background: pair.bg,
+				Ratio:      math.Floor(ratio*100) / 100,
+				Required:   required,
+			}
+			if pair.adjustFg {
+				f.Suggestion = Suggest(pair.fg, pair.bg, required)
+			} else {
+				f.Suggestion = Suggest(pair.bg, pair.fg, required)
+			}
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// ContrastRatio is the WCAG 2.1 contrast ratio of two #rrggbb colours, from 1
+// to 21.
+func ContrastRatio(a, b string) (float64, error) {
+	ca, err := parseHex(a)
+	if err != nil {
+		return 0, err
+	}
+	cb, err := parseHex(b)
+	if err != nil {
+		return 0, err
+	}
+	return ratio(ca, cb), nil
+}
+
+// Suggest returns the colour closest to adjust, mixed towards white or black,
+// whose contrast with fixed reaches required, or "" if none does.
+func Suggest(adjust, fixed string, required float64) string {
+	ca, err := parseHex(adjust)
+	if err != nil {
+		return ""
+	}
+	cf, err := parseHex(fixed)
+	if err != nil {
+		return ""
+	}
+	if ratio(ca, cf) >= required {
+		return adjust
+	}
+
+	best, bestT := rgb{}, math.Inf(1)
+	for _, target := range []rgb{{255, 255, 255}, {0, 0, 0}} {
+		if ratio(target, cf) < required {
+			continue // Not even pure white or black is enough
+		}
+		// Contrast grows monotonically while mixing towards the far end
+		lo, hi := 0.0, 1.0
+		for range 20 {
+			mid := (lo + hi) / 2
+			if ratio(mix(ca, target, mid), cf) >= required {
+				hi = mid
+			} else {
+				lo = mid
+			}
+		}
+		if c := mix(ca, target, hi); hi < bestT && ratio(c, cf) >= required {
+			best, bestT = c, hi
+		}
+	}
+	if math.IsInf(bestT, 1) {
+		return ""
+	}
+	return best.hex()
+}
+
+type rgb struct{ r, g, b float64 }
+
+func parseHex(s string) (rgb, error) {
+	if len(s) != 7 || s[0] != '#' {
+		return rgb{}, fmt.Errorf("colour %q is not #rrggbb", s)
+	}
+	v, err := strconv.ParseUint(s[1:], 16, 32)
+	if err != nil {
+		return rgb{}, fmt.Errorf("colour %q is not #rrggbb", s)
+	}
+	return rgb{float64(v >> 16 & 0xff), float64(v >> 8 & 0xff), float64(v & 0xff)}, nil
+}
+
+func (c rgb) hex() string {
+	return fmt.Sprintf("#%02x%02x%02x", int(math.Round(c.r)), int(math.Round(c.g)), int(math.Round(c.b)))
+}
+
+// luminance is the WCAG relative luminance of an sRGB colour.
+func (c rgb) luminance() float64 {
+	channel := func(v float64) float64 {
+		v /= 255
+		if v <= 0.03928 {
+			return v / 12.92
+		}
+		return math.Pow((v+0.055)/1.055, 2.4)
+	}
+	return 0.2126*channel(c.r) + 0.7152*channel(c.g) + 0.0722*channel(c.b)
+}
+
+func ratio(a, b rgb) float64 {
+	la, lb := a.luminance(), b.luminance()
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
+
+// mix moves c towards target by t in [0, 1], rounded to whole channels.
+func mix(c, target rgb, t float64) rgb {
+	m := func(a, b float64) float64 { return math.Round(a + (b-a)*t) }
+	return rgb{m(c.r, target.r), m(c.g, target.g), m(c.b, target.b)}
+}
+
+func orDefault(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,127 @@
+package themelint
+
+import (
+	"math"
+	"testing"
+
+	"closeauth-frontend/internal/database/models"
+)
+
+func TestContrastRatio(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"#000000", "#ffffff", 21},
+		{"#ffffff", "#ffffff", 1},
+		{"#777777", "#ffffff", 4.48},
+		{"#1f2937", "#ffffff", 14.68},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			got, err := ContrastRatio(tt.a, tt.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(got-tt.want) > 0.01 {
+				t.Errorf("ContrastRatio(%s, %s) = %.3f, want %.2f", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+	if _, err := ContrastRatio("red", "#ffffff"); err == nil {
+		t.Error("ContrastRatio(red) succeeded, want error")
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	tests := []struct {
+		name          string
+		adjust, bg    string
+		min           float64
+		wantUnchanged bool
+		wantNone      bool
+	}{
+		{"already compliant", "#000000", "#ffffff", 4.5, true, false},
+		{"darkens grey text on white", "#999999", "#ffffff", 4.5, false, false},
+		{"lightens grey text on black", "#444444", "#000000", 7, false, false},
+		{"impossible", "#777777", "#777777", 21, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Suggest(tt.adjust, tt.bg, tt.min)
+			switch {
+			case tt.wantNone:
+				if got != "" {
+					t.Errorf("Suggest() = %q, want none", got)
+				}
+				return
+			case tt.wantUnchanged:
+				if got != tt.adjust {
+					t.Errorf("Suggest() = %q, want %q unchanged", got, tt.adjust)
+				}
+				return
+			}
+			gotRatio, err := ContrastRatio(got, tt.bg)
+			if err != nil || gotRatio < tt.min {
+				t.Fatalf("Suggest() = %q with ratio %.2f, want at least %.1f", got, gotRatio, tt.min)
+			}
+			// Nearest: one step back towards the original no longer complies
+			c, _ := parseHex(got)
+			orig, _ := parseHex(tt.adjust)
+			back := rgb{c.r + sign(orig.r-c.r)*2, c.g + sign(orig.g-c.g)*2, c.b + sign(orig.b-c.b)*2}
+			if ratio(back, mustParse(tt.bg)) >= tt.min {
+				t.Errorf("Suggest() = %q is not the nearest compliant colour; %s also complies", got, back.hex())
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	str := func(s string) *string { return &s }
+	theme := &models.ClientTheme{
+		LightTextColor:       str("#999999"),
+		LightBackgroundColor: str("#ffffff"),
+		LightButtonColor:     str("#1f2937"),
+		DarkTextColor:        str("#f9fafb"),
+		DarkBackgroundColor:  str("#1f2937"),
+		DarkButtonColor:      str("#1f2937"),
+	}
+
+	findings := Check(theme, AA)
+	if len(findings) != 2 {
+		t.Fatalf("Check(AA) = %v, want two findings", findings)
+	}
+	if f := findings[0]; f.Palette != "light" || f.Pair != "text/background" || f.Field != "light_text_color" || f.Ratio != 2.84 || f.Required != 4.5 || f.Suggestion == "" {
+		t.Errorf("Check(AA) light finding = %+v", f)
+	}
+	// Dark labels on a dark button
+	if f := findings[1]; f.Palette != "dark" || f.Pair != "button/text" || f.Field != "dark_button_color" || f.Suggestion == "" {
+		t.Errorf("Check(AA) dark finding = %+v", f)
+	}
+
+	// Colours left to the built-in theme are not the admin's choice
+	if findings := Check(&models.ClientTheme{}, AAA); len(findings) != 0 {
+		t.Errorf("Check(built-in, AAA) = %v, want nothing", findings)
+	}
+	if findings := Check(&models.ClientTheme{LightTextColor: str("grey"), LightButtonColor: str("#3b82f6")}, AA); len(findings) != 1 || findings[0].Pair != "button/text" {
+		t.Errorf("Check(malformed text) = %v, want the malformed pair skipped", findings)
+	}
+}
+
+func sign(v float64) float64 {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	}
+	return 0
+}
+
+func mustParse(s string) rgb {
+	c, err := parseHex(s)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
@@ -0,0 +1,85 @@
+// Package themepreview signs and verifies the expiring tokens that let the
+// hosted login page of a client render one of its themes before it is
+// published or activated.
+//
+// A token is "<theme id>.<expiry unix>.<mac>", the MAC being HMAC-SHA256 over
+// the client id, theme id and expiry. The client id is not in the token: it
+// must be presented alongside it, so a token only works for its own client.
+package themepreview
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrMalformed = errors.New("malformed preview token")
+	ErrInvalid   = errors.New("invalid preview token")
+	ErrExpired   = errors.New("preview token expired")
+)
+
+// Grant is what a valid token allows: previewing ThemeID of ClientID until
+// ExpiresAt.
+type Grant struct {
+	ClientID  string
+	ThemeID   int
+	ExpiresAt time.Time
+}
+
+// Signer issues and checks preview tokens with one key.
+type Signer struct {
+	key []byte
+	ttl time.Duration
+}
+
+// NewSigner returns a Signer whose tokens are valid for ttl.
+func NewSigner(key []byte, ttl time.Duration) *Signer {
+	return &Signer{key: key, ttl: ttl}
+}
+
+// Sign issues a token for previewing themeID of clientID, valid from now.
+func (s *Signer) Sign(clientID string, themeID int, now time.Time) (string, Grant) {
+	g := Grant{ClientID: clientID, ThemeID: themeID, ExpiresAt: now.Add(s.ttl).Truncate(time.Second)}
+	payload := strconv.Itoa(themeID) + "." + strconv.FormatInt(g.ExpiresAt.Unix(), 10)
+	return payload + "." + s.mac(clientID, payload), g
+}
+
+// Verify checks token for clientID at now and returns what it grants.
+func (s *Signer) Verify(token, clientID string, now time.Time) (Grant, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Grant{}, ErrMalformed
+	}
+	themeID, err := strconv.Atoi(parts[0])
+	if err != nil || themeID <= 0 {
+		return Grant{}, ErrMalformed
+	}
+	unix, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return Grant{}, ErrMalformed
+	}
+
+	expected := s.mac(clientID, parts[0]+"."+parts[1])
+	if !hmac.Equal([]byte(parts[2]), []byte(expected)) {
+		return Grant{}, ErrInvalid
+	}
+
+	g := Grant{ClientID: clientID, ThemeID: themeID, ExpiresAt: time.Unix(unix, 0)}
+	if !now.Before(g.ExpiresAt) {
+		return Grant{}, ErrExpired
+	}
+	return g, nil
+}
+
+func (s *Signer) mac(clientID, payload string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(clientID))
+	mac.Write([]byte{0})
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,52 @@
+package themepreview
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	signer := NewSigner([]byte("0123456789abcdef0123456789abcdef"), time.Hour)
+	token, grant := signer.Sign("acme", 7, now)
+	if grant.ThemeID != 7 || !grant.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Fatalf("Sign() grant = %+v", grant)
+	}
+	parts := strings.Split(token, ".")
+
+	tests := []struct {
+		name     string
+		token    string
+		clientID string
+		at       time.Time
+		wantErr  error
+	}{
+		{"valid", token, "acme", now, nil},
+		{"just before expiry", token, "acme", now.Add(time.Hour - time.Second), nil},
+		{"expired", token, "acme", now.Add(time.Hour), ErrExpired},
+		{"other client", token, "globex", now, ErrInvalid},
+		{"other theme", "8." + parts[1] + "." + parts[2], "acme", now, ErrInvalid},
+		{"extended expiry", parts[0] + ".1900000000." + parts[2], "acme", now, ErrInvalid},
+		{"empty", "", "acme", now, ErrMalformed},
+		{"bad theme id", "x." + parts[1] + "." + parts[2], "acme", now, ErrMalformed},
+		{"missing mac", parts[0] + "." + parts[1], "acme", now, ErrMalformed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := signer.Verify(tt.token, tt.clientID, tt.at)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && got != grant {
+				t.Errorf("Verify() = %+v, want %+v", got, grant)
+			}
+		})
+	}
+
+	other := NewSigner([]byte("fedcba9876543210fedcba9876543210"), time.Hour)
+	if _, err := other.Verify(token, "acme", now); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Verify() with another key error = %v, want %v", err, ErrInvalid)
+	}
+}
@@ -0,0 +1,71 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// useInMemoryExporter installs a synchronous in-memory provider for one test.
+func useInMemoryExporter(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+	return exporter
+}
+
+func TestMiddleware_SpanPerRoute(t *testing.T) {
+	exporter := useInMemoryExporter(t)
+
+	var logAttrs int
+	r := chi.NewRouter()
+	r.Use(Middleware)
+	r.Get("/api/admin/clients/{clientId}/roles", func(w http.ResponseWriter, r *http.Request) {
+		logAttrs = len(LogAttrs(r.Context()))
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/clients/7/roles", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	span := spans[0]
+	if span.Name != "GET /api/admin/clients/{clientId}/roles" {
+		t.Errorf("span name = %q, want route pattern", span.Name)
+	}
+	if got := span.SpanContext.TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("trace id = %s, want incoming traceparent's trace id", got)
+	}
+	if span.Status.Code != codes.Error {
+		t.Errorf("status = %v, want Error for 5xx", span.Status.Code)
+	}
+	if logAttrs != 2 {
+		t.Errorf("LogAttrs() inside handler returned %d attrs, want trace_id and span_id", logAttrs)
+	}
+}
+
+func TestLogAttrs_NoSpan(t *testing.T) {
+	if attrs := LogAttrs(context.Background()); attrs != nil {
+		t.Errorf("LogAttrs() = %v, want nil without a span", attrs)
+	}
+}